@@ -162,18 +162,7 @@ func (p *DefaultAccessPolicy) Sanitize(actor *auth.ActorContext, role string, re
 	for _, record := range records {
 		rec := record
 		if isSupport {
-			switch p.metadataExposure {
-			case MetadataExposeAll:
-				// keep metadata as-is
-			case MetadataExposeSanitized:
-				if p.metadataSanitizer != nil {
-					rec.Data = p.metadataSanitizer(actor, role, record)
-				} else {
-					rec = SanitizeRecord(mask, record)
-				}
-			default:
-				rec.Data = nil
-			}
+			rec = ApplyMetadataExposure(p.metadataExposure, mask, p.metadataSanitizer, actor, role, record)
 		} else {
 			rec = SanitizeRecord(mask, record)
 		}
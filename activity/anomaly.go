@@ -0,0 +1,224 @@
+package activity
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Anomaly alert constants used when alerts are emitted as activity records.
+const (
+	AnomalyAlertVerb       = "activity.anomaly.detected"
+	AnomalyAlertObjectType = "activity.anomaly"
+	AnomalyAlertChannel    = "security"
+)
+
+// AnomalySeverity grades alerts emitted by the rules engine.
+type AnomalySeverity string
+
+const (
+	AnomalySeverityLow      AnomalySeverity = "low"
+	AnomalySeverityMedium   AnomalySeverity = "medium"
+	AnomalySeverityHigh     AnomalySeverity = "high"
+	AnomalySeverityCritical AnomalySeverity = "critical"
+)
+
+// AnomalyMatcher reports whether a record participates in a rule.
+type AnomalyMatcher func(record types.ActivityRecord) bool
+
+// AnomalyKeyFunc groups matching records into independent sliding-window
+// counters. Returning an empty key skips the record.
+type AnomalyKeyFunc func(record types.ActivityRecord) string
+
+// AnomalyRule describes a sliding-window threshold evaluated against incoming
+// activity. The rule fires when Threshold matching records sharing the same
+// key are observed within Window.
+type AnomalyRule struct {
+	Name        string
+	Description string
+	Severity    AnomalySeverity
+	Match       AnomalyMatcher
+	GroupBy     AnomalyKeyFunc
+	Window      time.Duration
+	Threshold   int
+}
+
+func (r AnomalyRule) normalized() (AnomalyRule, bool) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || r.Match == nil {
+		return r, false
+	}
+	if r.Threshold <= 0 {
+		r.Threshold = 1
+	}
+	if r.Window <= 0 {
+		r.Window = time.Minute
+	}
+	if r.GroupBy == nil {
+		r.GroupBy = GroupByActor
+	}
+	if r.Severity == "" {
+		r.Severity = AnomalySeverityMedium
+	}
+	return r, true
+}
+
+// AnomalyAlert captures a rule violation together with the record that tripped it.
+type AnomalyAlert struct {
+	Rule       string
+	Severity   AnomalySeverity
+	Key        string
+	Count      int
+	Threshold  int
+	Window     time.Duration
+	DetectedAt time.Time
+	Record     types.ActivityRecord
+}
+
+// ActivityRecord converts the alert into an ActivityRecord suitable for a sink.
+func (a AnomalyAlert) ActivityRecord() types.ActivityRecord {
+	return types.ActivityRecord{
+		UserID:     a.Record.UserID,
+		ActorID:    a.Record.ActorID,
+		Verb:       AnomalyAlertVerb,
+		ObjectType: AnomalyAlertObjectType,
+		ObjectID:   a.Rule,
+		Channel:    AnomalyAlertChannel,
+		TenantID:   a.Record.TenantID,
+		OrgID:      a.Record.OrgID,
+		Data: map[string]any{
+			"rule":           a.Rule,
+			"severity":       string(a.Severity),
+			"key":            a.Key,
+			"count":          a.Count,
+			"threshold":      a.Threshold,
+			"window_seconds": int64(a.Window / time.Second),
+			"trigger_verb":   a.Record.Verb,
+			"trigger_id":     a.Record.ID.String(),
+		},
+		OccurredAt: a.DetectedAt,
+	}
+}
+
+// MatchVerbs matches records whose verb equals one of the provided verbs.
+func MatchVerbs(verbs ...string) AnomalyMatcher {
+	set := make([]string, 0, len(verbs))
+	for _, verb := range verbs {
+		if trimmed := strings.TrimSpace(verb); trimmed != "" {
+			set = append(set, trimmed)
+		}
+	}
+	return func(record types.ActivityRecord) bool {
+		return slices.Contains(set, strings.TrimSpace(record.Verb))
+	}
+}
+
+// MatchVerbPrefix matches records whose verb starts with prefix.
+func MatchVerbPrefix(prefix string) AnomalyMatcher {
+	prefix = strings.TrimSpace(prefix)
+	return func(record types.ActivityRecord) bool {
+		return prefix != "" && strings.HasPrefix(record.Verb, prefix)
+	}
+}
+
+// MatchOutsideHours matches records that occurred outside [startHour, endHour)
+// in the provided location. Weekends are treated as outside business hours when
+// weekdaysOnly is true. A nil location defaults to UTC.
+func MatchOutsideHours(startHour, endHour int, loc *time.Location, weekdaysOnly bool) AnomalyMatcher {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return func(record types.ActivityRecord) bool {
+		if record.OccurredAt.IsZero() {
+			return false
+		}
+		local := record.OccurredAt.In(loc)
+		if weekdaysOnly {
+			if day := local.Weekday(); day == time.Saturday || day == time.Sunday {
+				return true
+			}
+		}
+		hour := local.Hour()
+		return hour < startHour || hour >= endHour
+	}
+}
+
+// MatchAll matches when every matcher matches.
+func MatchAll(matchers ...AnomalyMatcher) AnomalyMatcher {
+	return func(record types.ActivityRecord) bool {
+		for _, matcher := range matchers {
+			if matcher != nil && !matcher(record) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// GroupByActor keys counters by actor identifier.
+func GroupByActor(record types.ActivityRecord) string {
+	if record.ActorID == uuid.Nil {
+		return ""
+	}
+	return record.ActorID.String()
+}
+
+// GroupByUser keys counters by the affected user identifier.
+func GroupByUser(record types.ActivityRecord) string {
+	if record.UserID == uuid.Nil {
+		return ""
+	}
+	return record.UserID.String()
+}
+
+// GroupByDataKey keys counters by a string value stored in record data, such
+// as the identifier used for a password reset request.
+func GroupByDataKey(key string) AnomalyKeyFunc {
+	return func(record types.ActivityRecord) string {
+		if record.Data == nil {
+			return ""
+		}
+		value, ok := record.Data[key].(string)
+		if !ok {
+			return ""
+		}
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+// DefaultAnomalyRules returns a baseline rule set covering lifecycle bursts,
+// off-hours role escalations, and password-reset floods.
+func DefaultAnomalyRules() []AnomalyRule {
+	return []AnomalyRule{
+		{
+			Name:        "lifecycle.burst",
+			Description: "many lifecycle transitions by one actor within a minute",
+			Severity:    AnomalySeverityHigh,
+			Match:       MatchVerbs("user.lifecycle.transition"),
+			GroupBy:     GroupByActor,
+			Window:      time.Minute,
+			Threshold:   10,
+		},
+		{
+			Name:        "role.escalation.off_hours",
+			Description: "role assignment outside business hours",
+			Severity:    AnomalySeverityMedium,
+			Match:       MatchAll(MatchVerbs("role.assigned"), MatchOutsideHours(8, 18, time.UTC, true)),
+			GroupBy:     GroupByActor,
+			Window:      time.Minute,
+			Threshold:   1,
+		},
+		{
+			Name:        "password_reset.burst",
+			Description: "repeated password reset requests for one identifier",
+			Severity:    AnomalySeverityHigh,
+			Match:       MatchVerbs("user.password.reset.requested"),
+			GroupBy:     GroupByDataKey("user_email"),
+			Window:      10 * time.Minute,
+			Threshold:   5,
+		},
+	}
+}
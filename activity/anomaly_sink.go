@@ -0,0 +1,188 @@
+package activity
+
+import (
+	"context"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// AnomalyAlertHook receives alerts raised by AnomalySink.
+type AnomalyAlertHook func(ctx context.Context, alert AnomalyAlert)
+
+// AnomalyErrorHandler receives state-store or alert delivery errors. The
+// original record has already been logged when it is invoked.
+type AnomalyErrorHandler func(ctx context.Context, err error, record types.ActivityRecord)
+
+// AnomalySinkOption customizes AnomalySink.
+type AnomalySinkOption func(*AnomalySink)
+
+// AnomalySink evaluates anomaly rules against records after forwarding them
+// to the wrapped sink. Alerts are logged back through the wrapped sink as
+// activity records (unless disabled) and passed to the configured hook.
+type AnomalySink struct {
+	sink        types.ActivitySink
+	rules       []AnomalyRule
+	store       AnomalyStateStore
+	clock       types.Clock
+	hook        AnomalyAlertHook
+	onError     AnomalyErrorHandler
+	emitRecords bool
+}
+
+var _ types.ActivitySink = (*AnomalySink)(nil)
+
+// NewAnomalySink wraps sink with the provided rules. Invalid rules (missing
+// name or matcher) are ignored.
+func NewAnomalySink(sink types.ActivitySink, rules []AnomalyRule, opts ...AnomalySinkOption) *AnomalySink {
+	s := &AnomalySink{
+		sink:        sink,
+		clock:       types.SystemClock{},
+		emitRecords: true,
+	}
+	for _, rule := range rules {
+		if normalized, ok := rule.normalized(); ok {
+			s.rules = append(s.rules, normalized)
+		}
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	if s.store == nil {
+		s.store = NewMemoryAnomalyStore()
+	}
+	if s.clock == nil {
+		s.clock = types.SystemClock{}
+	}
+	return s
+}
+
+// WithAnomalyStore overrides the in-memory state store.
+func WithAnomalyStore(store AnomalyStateStore) AnomalySinkOption {
+	return func(s *AnomalySink) {
+		if s == nil {
+			return
+		}
+		s.store = store
+	}
+}
+
+// WithAnomalyClock overrides the clock used for records missing OccurredAt.
+func WithAnomalyClock(clock types.Clock) AnomalySinkOption {
+	return func(s *AnomalySink) {
+		if s == nil {
+			return
+		}
+		s.clock = clock
+	}
+}
+
+// WithAnomalyAlertHook registers a callback invoked for every alert.
+func WithAnomalyAlertHook(hook AnomalyAlertHook) AnomalySinkOption {
+	return func(s *AnomalySink) {
+		if s == nil {
+			return
+		}
+		s.hook = hook
+	}
+}
+
+// WithAnomalyErrorHandler registers a callback for evaluation errors.
+func WithAnomalyErrorHandler(handler AnomalyErrorHandler) AnomalySinkOption {
+	return func(s *AnomalySink) {
+		if s == nil {
+			return
+		}
+		s.onError = handler
+	}
+}
+
+// WithAnomalyAlertRecords toggles logging alerts as activity records.
+func WithAnomalyAlertRecords(enabled bool) AnomalySinkOption {
+	return func(s *AnomalySink) {
+		if s == nil {
+			return
+		}
+		s.emitRecords = enabled
+	}
+}
+
+// Log forwards the record to the wrapped sink and evaluates the rules.
+func (s *AnomalySink) Log(ctx context.Context, record types.ActivityRecord) error {
+	if s == nil || s.sink == nil {
+		return types.ErrMissingActivitySink
+	}
+	if err := s.sink.Log(ctx, record); err != nil {
+		return err
+	}
+	if record.Verb == AnomalyAlertVerb {
+		return nil
+	}
+
+	for _, alert := range s.Evaluate(ctx, record) {
+		if s.emitRecords {
+			if err := s.sink.Log(ctx, alert.ActivityRecord()); err != nil {
+				s.handleError(ctx, err, record)
+			}
+		}
+		if s.hook != nil {
+			s.hook(ctx, alert)
+		}
+	}
+	return nil
+}
+
+// Evaluate runs the rules against record and returns the alerts it triggers
+// without logging them. Counters are reset once a rule fires so a sustained
+// burst raises one alert per threshold crossing.
+func (s *AnomalySink) Evaluate(ctx context.Context, record types.ActivityRecord) []AnomalyAlert {
+	if s == nil || len(s.rules) == 0 {
+		return nil
+	}
+	at := record.OccurredAt
+	if at.IsZero() {
+		at = s.clock.Now()
+	}
+	at = at.UTC()
+
+	var alerts []AnomalyAlert
+	for _, rule := range s.rules {
+		if !rule.Match(record) {
+			continue
+		}
+		key := rule.GroupBy(record)
+		if key == "" {
+			continue
+		}
+		stateKey := rule.Name + "|" + key
+		count, err := s.store.Observe(ctx, stateKey, at, rule.Window)
+		if err != nil {
+			s.handleError(ctx, err, record)
+			continue
+		}
+		if count < rule.Threshold {
+			continue
+		}
+		if err := s.store.Reset(ctx, stateKey); err != nil {
+			s.handleError(ctx, err, record)
+		}
+		alerts = append(alerts, AnomalyAlert{
+			Rule:       rule.Name,
+			Severity:   rule.Severity,
+			Key:        key,
+			Count:      count,
+			Threshold:  rule.Threshold,
+			Window:     rule.Window,
+			DetectedAt: at,
+			Record:     record,
+		})
+	}
+	return alerts
+}
+
+func (s *AnomalySink) handleError(ctx context.Context, err error, record types.ActivityRecord) {
+	if s.onError != nil && err != nil {
+		s.onError(ctx, err, record)
+	}
+}
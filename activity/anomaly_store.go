@@ -0,0 +1,59 @@
+package activity
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AnomalyStateStore tracks sliding-window counters for the rules engine.
+// Implementations must be safe for concurrent use.
+type AnomalyStateStore interface {
+	// Observe records an occurrence for key at the given time and returns the
+	// number of occurrences still inside the window (including this one).
+	Observe(ctx context.Context, key string, at time.Time, window time.Duration) (int, error)
+	// Reset clears the counter for key, typically after an alert fires.
+	Reset(ctx context.Context, key string) error
+}
+
+// MemoryAnomalyStore is an in-memory AnomalyStateStore.
+type MemoryAnomalyStore struct {
+	mu     sync.Mutex
+	events map[string][]time.Time
+}
+
+var _ AnomalyStateStore = (*MemoryAnomalyStore)(nil)
+
+// NewMemoryAnomalyStore returns an empty in-memory state store.
+func NewMemoryAnomalyStore() *MemoryAnomalyStore {
+	return &MemoryAnomalyStore{events: make(map[string][]time.Time)}
+}
+
+// Observe appends the occurrence and prunes entries older than the window.
+func (s *MemoryAnomalyStore) Observe(_ context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events == nil {
+		s.events = make(map[string][]time.Time)
+	}
+
+	cutoff := at.Add(-window)
+	current := s.events[key]
+	kept := current[:0]
+	for _, ts := range current {
+		if ts.After(cutoff) {
+			kept = append(kept, ts)
+		}
+	}
+	kept = append(kept, at)
+	s.events[key] = kept
+	return len(kept), nil
+}
+
+// Reset drops all occurrences tracked for key.
+func (s *MemoryAnomalyStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.events, key)
+	return nil
+}
@@ -0,0 +1,92 @@
+package activity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalySinkSlidingWindowThreshold(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingHookSink{}
+	var alerts []AnomalyAlert
+
+	anomaly := NewAnomalySink(sink, []AnomalyRule{{
+		Name:      "lifecycle.burst",
+		Match:     MatchVerbs("user.lifecycle.transition"),
+		Window:    time.Minute,
+		Threshold: 3,
+	}}, WithAnomalyAlertHook(func(_ context.Context, alert AnomalyAlert) {
+		alerts = append(alerts, alert)
+	}))
+
+	actorID := uuid.New()
+	base := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	log := func(offset time.Duration) {
+		require.NoError(t, anomaly.Log(ctx, types.ActivityRecord{
+			ActorID:    actorID,
+			Verb:       "user.lifecycle.transition",
+			ObjectType: "user",
+			OccurredAt: base.Add(offset),
+		}))
+	}
+
+	log(0)
+	log(10 * time.Second)
+	log(2 * time.Minute)
+	require.Empty(t, alerts, "events outside the window should not accumulate")
+
+	log(2*time.Minute + 10*time.Second)
+	log(2*time.Minute + 20*time.Second)
+	require.Len(t, alerts, 1)
+	require.Equal(t, "lifecycle.burst", alerts[0].Rule)
+	require.Equal(t, actorID.String(), alerts[0].Key)
+	require.Equal(t, 3, alerts[0].Count)
+
+	require.Len(t, sink.records, 6)
+	alertRecord := sink.records[5]
+	require.Equal(t, AnomalyAlertVerb, alertRecord.Verb)
+	require.Equal(t, "lifecycle.burst", alertRecord.ObjectID)
+	require.Equal(t, 3, alertRecord.Data["count"])
+}
+
+func TestAnomalySinkOffHoursAndDataKeyRules(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingHookSink{}
+	anomaly := NewAnomalySink(sink, DefaultAnomalyRules(), WithAnomalyAlertRecords(false))
+
+	saturday := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	alerts := anomaly.Evaluate(ctx, types.ActivityRecord{
+		ActorID:    uuid.New(),
+		Verb:       "role.assigned",
+		OccurredAt: saturday,
+	})
+	require.Len(t, alerts, 1)
+	require.Equal(t, "role.escalation.off_hours", alerts[0].Rule)
+
+	monday := time.Date(2024, 3, 4, 11, 0, 0, 0, time.UTC)
+	alerts = anomaly.Evaluate(ctx, types.ActivityRecord{
+		ActorID:    uuid.New(),
+		Verb:       "role.assigned",
+		OccurredAt: monday,
+	})
+	require.Empty(t, alerts)
+
+	var fired []AnomalyAlert
+	for i := range 5 {
+		fired = append(fired, anomaly.Evaluate(ctx, types.ActivityRecord{
+			ActorID:    uuid.New(),
+			Verb:       "user.password.reset.requested",
+			Data:       map[string]any{"user_email": "Target@Example.com"},
+			OccurredAt: monday.Add(time.Duration(i) * time.Minute),
+		})...)
+	}
+	require.Len(t, fired, 1)
+	require.Equal(t, "password_reset.burst", fired[0].Rule)
+	require.Equal(t, "target@example.com", fired[0].Key)
+	require.Empty(t, sink.records)
+}
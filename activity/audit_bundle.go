@@ -0,0 +1,302 @@
+package activity
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// Audit bundle entry names.
+const (
+	AuditBundleManifestFile  = "manifest.json"
+	AuditBundleChecksumsFile = "checksums.sha256"
+	AuditBundleVersion       = "1"
+)
+
+var (
+	// ErrAuditBundleManifestMissing indicates the bundle has no manifest entry.
+	ErrAuditBundleManifestMissing = errors.New("go-users: audit bundle manifest missing")
+	// ErrAuditBundleChecksumMismatch indicates a bundle entry does not match its recorded checksum.
+	ErrAuditBundleChecksumMismatch = errors.New("go-users: audit bundle checksum mismatch")
+	// ErrAuditBundleRecordCountMismatch indicates the records entry does not hold the advertised count.
+	ErrAuditBundleRecordCountMismatch = errors.New("go-users: audit bundle record count mismatch")
+)
+
+// AuditBundleManifest describes the contents of an audit bundle.
+type AuditBundleManifest struct {
+	Version     string            `json:"version"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	GeneratedBy string            `json:"generated_by,omitempty"`
+	Format      ExportFormat      `json:"format"`
+	RecordCount int               `json:"record_count"`
+	Filter      AuditBundleFilter `json:"filter"`
+	Files       []AuditBundleFile `json:"files"`
+}
+
+// AuditBundleFilter records the selection used to produce the bundle.
+type AuditBundleFilter struct {
+	TenantID string     `json:"tenant_id,omitempty"`
+	OrgID    string     `json:"org_id,omitempty"`
+	UserID   string     `json:"user_id,omitempty"`
+	Since    *time.Time `json:"since,omitempty"`
+	Until    *time.Time `json:"until,omitempty"`
+}
+
+// AuditBundleFilterFrom summarizes an activity filter for the manifest.
+func AuditBundleFilterFrom(filter types.ActivityFilter) AuditBundleFilter {
+	return AuditBundleFilter{
+		TenantID: exportUUID(filter.Scope.TenantID),
+		OrgID:    exportUUID(filter.Scope.OrgID),
+		UserID:   exportUUID(filter.UserID),
+		Since:    filter.Since,
+		Until:    filter.Until,
+	}
+}
+
+// AuditBundleFile lists a bundle entry with its checksum.
+type AuditBundleFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// AuditBundleWriter streams activity records into a zip bundle containing the
+// records, a manifest, and a sha256sum-compatible checksum list.
+type AuditBundleWriter struct {
+	zip      *zip.Writer
+	records  ActivityExportWriter
+	hasher   hash.Hash
+	counter  *countingWriter
+	manifest AuditBundleManifest
+	fileName string
+	closed   bool
+}
+
+// NewAuditBundleWriter starts a bundle on w. The manifest's Files and
+// RecordCount are filled in on Close.
+func NewAuditBundleWriter(w io.Writer, format ExportFormat, manifest AuditBundleManifest) (*AuditBundleWriter, error) {
+	format, err := normalizeExportFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	zw := zip.NewWriter(w)
+	fileName := "records." + string(format)
+	entry, err := zw.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(entry, hasher)}
+	records, err := NewActivityExportWriter(format, counter)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Version = AuditBundleVersion
+	manifest.Format = format
+	if manifest.GeneratedAt.IsZero() {
+		manifest.GeneratedAt = time.Now().UTC()
+	}
+	return &AuditBundleWriter{
+		zip:      zw,
+		records:  records,
+		hasher:   hasher,
+		counter:  counter,
+		manifest: manifest,
+		fileName: fileName,
+	}, nil
+}
+
+// Write appends a record to the bundle.
+func (b *AuditBundleWriter) Write(record types.ActivityRecord) error {
+	if err := b.records.Write(record); err != nil {
+		return err
+	}
+	b.manifest.RecordCount++
+	return nil
+}
+
+// Close finalizes the records entry and writes the manifest and checksums.
+func (b *AuditBundleWriter) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if err := b.records.Close(); err != nil {
+		return err
+	}
+	b.manifest.Files = []AuditBundleFile{{
+		Name:   b.fileName,
+		SHA256: hex.EncodeToString(b.hasher.Sum(nil)),
+		Size:   b.counter.n,
+	}}
+
+	manifestBytes, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeZipEntry(b.zip, AuditBundleManifestFile, manifestBytes); err != nil {
+		return err
+	}
+
+	manifestSum := sha256.Sum256(manifestBytes)
+	var checksums strings.Builder
+	fmt.Fprintf(&checksums, "%s  %s\n", b.manifest.Files[0].SHA256, b.fileName)
+	fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(manifestSum[:]), AuditBundleManifestFile)
+	if err := writeZipEntry(b.zip, AuditBundleChecksumsFile, []byte(checksums.String())); err != nil {
+		return err
+	}
+	return b.zip.Close()
+}
+
+// Manifest returns the manifest as written (complete after Close).
+func (b *AuditBundleWriter) Manifest() AuditBundleManifest {
+	return b.manifest
+}
+
+// VerifyAuditBundle checks every checksum in the bundle and returns its
+// manifest. It can run offline against an archived bundle.
+func VerifyAuditBundle(r io.ReaderAt, size int64) (AuditBundleManifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return AuditBundleManifest{}, err
+	}
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, file := range zr.File {
+		entries[file.Name] = file
+	}
+
+	manifestEntry, ok := entries[AuditBundleManifestFile]
+	if !ok {
+		return AuditBundleManifest{}, ErrAuditBundleManifestMissing
+	}
+	manifestBytes, err := readZipEntry(manifestEntry)
+	if err != nil {
+		return AuditBundleManifest{}, err
+	}
+	var manifest AuditBundleManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return AuditBundleManifest{}, err
+	}
+
+	if checksumEntry, ok := entries[AuditBundleChecksumsFile]; ok {
+		listed, err := readZipEntry(checksumEntry)
+		if err != nil {
+			return manifest, err
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(listed)), "\n") {
+			sum, name, found := strings.Cut(strings.TrimSpace(line), "  ")
+			if !found {
+				continue
+			}
+			entry, ok := entries[name]
+			if !ok {
+				return manifest, fmt.Errorf("%w: %s missing", ErrAuditBundleChecksumMismatch, name)
+			}
+			if err := verifyZipEntry(entry, sum); err != nil {
+				return manifest, err
+			}
+		}
+	}
+
+	for _, file := range manifest.Files {
+		entry, ok := entries[file.Name]
+		if !ok {
+			return manifest, fmt.Errorf("%w: %s missing", ErrAuditBundleChecksumMismatch, file.Name)
+		}
+		if err := verifyZipEntry(entry, file.SHA256); err != nil {
+			return manifest, err
+		}
+		count, err := countBundleRecords(entry, manifest.Format)
+		if err != nil {
+			return manifest, err
+		}
+		if count != manifest.RecordCount {
+			return manifest, ErrAuditBundleRecordCountMismatch
+		}
+	}
+	return manifest, nil
+}
+
+func verifyZipEntry(entry *zip.File, expected string) error {
+	payload, err := readZipEntry(entry)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(payload)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), strings.TrimSpace(expected)) {
+		return fmt.Errorf("%w: %s", ErrAuditBundleChecksumMismatch, entry.Name)
+	}
+	return nil
+}
+
+func countBundleRecords(entry *zip.File, format ExportFormat) (int, error) {
+	payload, err := readZipEntry(entry)
+	if err != nil {
+		return 0, err
+	}
+	if normalized, _ := normalizeExportFormat(format); normalized == ExportFormatCSV {
+		return csvRowCount(payload)
+	}
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+func csvRowCount(payload []byte) (int, error) {
+	reader := csv.NewReader(bytes.NewReader(payload))
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return len(rows) - 1, nil
+}
+
+func writeZipEntry(zw *zip.Writer, name string, payload []byte) error {
+	entry, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(payload)
+	return err
+}
+
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,243 @@
+package activity
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goliatone/go-masker"
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// DataKeyChanges stores the structured field diff for update activity.
+const DataKeyChanges = "changes"
+
+// FieldChange captures a single before/after pair for an updated field. Nested
+// map entries use dotted paths (e.g. "metadata.plan").
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+	Masked bool   `json:"masked,omitempty"`
+}
+
+// DiffAuthUser compares the mutable fields of two auth users. A nil before
+// value is treated as an empty user so creations produce a full diff.
+func DiffAuthUser(before, after *types.AuthUser) []FieldChange {
+	if after == nil {
+		return nil
+	}
+	if before == nil {
+		before = &types.AuthUser{}
+	}
+	var changes []FieldChange
+	changes = appendChange(changes, "email", before.Email, after.Email)
+	changes = appendChange(changes, "username", before.Username, after.Username)
+	changes = appendChange(changes, "first_name", before.FirstName, after.FirstName)
+	changes = appendChange(changes, "last_name", before.LastName, after.LastName)
+	changes = appendChange(changes, "role", before.Role, after.Role)
+	changes = appendChange(changes, "status", string(before.Status), string(after.Status))
+	changes = append(changes, DiffMaps("metadata", before.Metadata, after.Metadata)...)
+	return changes
+}
+
+// DiffUserProfile compares the patchable fields of two profiles.
+func DiffUserProfile(before, after *types.UserProfile) []FieldChange {
+	if after == nil {
+		return nil
+	}
+	if before == nil {
+		before = &types.UserProfile{}
+	}
+	var changes []FieldChange
+	changes = appendChange(changes, "display_name", before.DisplayName, after.DisplayName)
+	changes = appendChange(changes, "avatar_url", before.AvatarURL, after.AvatarURL)
+	changes = appendChange(changes, "locale", before.Locale, after.Locale)
+	changes = appendChange(changes, "timezone", before.Timezone, after.Timezone)
+	changes = appendChange(changes, "bio", before.Bio, after.Bio)
+	changes = append(changes, DiffMaps("contact", before.Contact, after.Contact)...)
+	changes = append(changes, DiffMaps("metadata", before.Metadata, after.Metadata)...)
+	changes = append(changes, DiffMaps("custom_fields", before.CustomFields, after.CustomFields)...)
+	return changes
+}
+
+// DiffRoleDefinition compares the mutable fields of two role definitions.
+func DiffRoleDefinition(before, after *types.RoleDefinition) []FieldChange {
+	if after == nil {
+		return nil
+	}
+	if before == nil {
+		before = &types.RoleDefinition{}
+	}
+	var changes []FieldChange
+	changes = appendChange(changes, "name", before.Name, after.Name)
+	changes = appendChange(changes, "order", before.Order, after.Order)
+	changes = appendChange(changes, "description", before.Description, after.Description)
+	changes = appendChange(changes, "role_key", before.RoleKey, after.RoleKey)
+	changes = appendChange(changes, "is_system", before.IsSystem, after.IsSystem)
+	if !slices.Equal(sortedStrings(before.Permissions), sortedStrings(after.Permissions)) {
+		changes = append(changes, FieldChange{
+			Field:  "permissions",
+			Before: sortedStrings(before.Permissions),
+			After:  sortedStrings(after.Permissions),
+		})
+	}
+	changes = append(changes, DiffMaps("metadata", before.Metadata, after.Metadata)...)
+	return changes
+}
+
+// DiffMaps compares two maps key by key and prefixes each field with prefix.
+// Results are ordered by key for stable output.
+func DiffMaps(prefix string, before, after map[string]any) []FieldChange {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+	ordered := make([]string, 0, len(keys))
+	for key := range keys {
+		ordered = append(ordered, key)
+	}
+	sort.Strings(ordered)
+
+	var changes []FieldChange
+	for _, key := range ordered {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		changes = appendChange(changes, field, before[key], after[key])
+	}
+	return changes
+}
+
+// MaskChanges masks before/after values for fields on the masker denylist.
+// The last segment of a dotted field path is used as the lookup key.
+func MaskChanges(mask *masker.Masker, changes []FieldChange) []FieldChange {
+	if len(changes) == 0 {
+		return changes
+	}
+	if mask == nil {
+		mask = DefaultMasker()
+	}
+	out := make([]FieldChange, 0, len(changes))
+	for _, change := range changes {
+		if mask == nil {
+			out = append(out, change)
+			continue
+		}
+		key := change.Field
+		if idx := strings.LastIndex(key, "."); idx >= 0 {
+			key = key[idx+1:]
+		}
+		before, beforeMasked := maskChangeValue(mask, key, change.Before)
+		after, afterMasked := maskChangeValue(mask, key, change.After)
+		change.Before = before
+		change.After = after
+		change.Masked = change.Masked || beforeMasked || afterMasked
+		out = append(out, change)
+	}
+	return out
+}
+
+// AttachChanges masks the changes and stores them under DataKeyChanges. Records
+// are returned untouched when there are no changes.
+func AttachChanges(record types.ActivityRecord, mask *masker.Masker, changes []FieldChange) types.ActivityRecord {
+	if len(changes) == 0 {
+		return record
+	}
+	out := record
+	out.Data = cloneMetadata(record.Data)
+	out.Data[DataKeyChanges] = ChangesToData(MaskChanges(mask, changes))
+	return out
+}
+
+// ChangesToData converts changes into JSON-friendly maps for record data.
+func ChangesToData(changes []FieldChange) []map[string]any {
+	out := make([]map[string]any, 0, len(changes))
+	for _, change := range changes {
+		entry := map[string]any{
+			"field":  change.Field,
+			"before": change.Before,
+			"after":  change.After,
+		}
+		if change.Masked {
+			entry["masked"] = true
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// ChangesFromData decodes the changes stored on a record. It accepts both the
+// in-memory shape produced by ChangesToData and the []any shape returned after
+// a JSON round trip.
+func ChangesFromData(data map[string]any) []FieldChange {
+	if len(data) == 0 {
+		return nil
+	}
+	var entries []map[string]any
+	switch raw := data[DataKeyChanges].(type) {
+	case []map[string]any:
+		entries = raw
+	case []any:
+		for _, item := range raw {
+			if entry, ok := item.(map[string]any); ok {
+				entries = append(entries, entry)
+			}
+		}
+	default:
+		return nil
+	}
+
+	changes := make([]FieldChange, 0, len(entries))
+	for _, entry := range entries {
+		field, _ := entry["field"].(string)
+		if field == "" {
+			continue
+		}
+		masked, _ := entry["masked"].(bool)
+		changes = append(changes, FieldChange{
+			Field:  field,
+			Before: entry["before"],
+			After:  entry["after"],
+			Masked: masked,
+		})
+	}
+	return changes
+}
+
+func appendChange(changes []FieldChange, field string, before, after any) []FieldChange {
+	if reflect.DeepEqual(before, after) {
+		return changes
+	}
+	return append(changes, FieldChange{Field: field, Before: before, After: after})
+}
+
+func maskChangeValue(mask *masker.Masker, key string, value any) (any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	masked, err := mask.Mask(map[string]any{key: value})
+	if err != nil {
+		return nil, true
+	}
+	out, ok := masked.(map[string]any)
+	if !ok {
+		return nil, true
+	}
+	result := out[key]
+	return result, !reflect.DeepEqual(result, value)
+}
+
+func sortedStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := slices.Clone(values)
+	sort.Strings(out)
+	return out
+}
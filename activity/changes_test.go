@@ -0,0 +1,47 @@
+package activity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRoleDefinitionIgnoresPermissionOrder(t *testing.T) {
+	before := &types.RoleDefinition{
+		Name:        "Editors",
+		Permissions: []string{"posts.write", "posts.read"},
+	}
+	after := &types.RoleDefinition{
+		Name:        "Senior Editors",
+		Permissions: []string{"posts.read", "posts.write"},
+		Metadata:    map[string]any{"tier": "gold"},
+	}
+
+	changes := DiffRoleDefinition(before, after)
+	require.Len(t, changes, 2)
+	require.Equal(t, "name", changes[0].Field)
+	require.Equal(t, "metadata.tier", changes[1].Field)
+	require.Nil(t, changes[1].Before)
+	require.Equal(t, "gold", changes[1].After)
+}
+
+func TestAttachChangesRoundTripsThroughJSON(t *testing.T) {
+	record := AttachChanges(types.ActivityRecord{Data: map[string]any{"email": "a@example.com"}}, nil, []FieldChange{
+		{Field: "email", Before: "a@example.com", After: "b@example.com"},
+		{Field: "metadata.api_key", Before: "old-key", After: "new-key"},
+	})
+
+	payload, err := json.Marshal(record.Data)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+
+	changes := ChangesFromData(decoded)
+	require.Len(t, changes, 2)
+	require.Equal(t, "b@example.com", changes[0].After)
+	require.False(t, changes[0].Masked)
+	require.True(t, changes[1].Masked)
+	require.NotEqual(t, "new-key", changes[1].After)
+}
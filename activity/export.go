@@ -0,0 +1,238 @@
+package activity
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// ErrUnsupportedExportFormat indicates an unknown export format was requested.
+var ErrUnsupportedExportFormat = errors.New("go-users: unsupported activity export format")
+
+// ExportFormat selects the serialization used for activity exports.
+type ExportFormat string
+
+const (
+	ExportFormatCSV   ExportFormat = "csv"
+	ExportFormatJSONL ExportFormat = "jsonl"
+)
+
+// DefaultExportBatchSize is the page size used while streaming exports.
+const DefaultExportBatchSize = 500
+
+// ActivityCursorLister is an optional repository extension that pages
+// activity by (created_at, id) cursor instead of offsets.
+type ActivityCursorLister interface {
+	ListActivityByCursor(ctx context.Context, filter types.ActivityFilter, cursor *ActivityCursor, limit int) (ActivityEnrichmentPage, error)
+}
+
+var _ ActivityCursorLister = (*Repository)(nil)
+
+// ListActivityByCursor returns up to limit records older than cursor, newest first.
+func (r *Repository) ListActivityByCursor(ctx context.Context, filter types.ActivityFilter, cursor *ActivityCursor, limit int) (ActivityEnrichmentPage, error) {
+	if r == nil {
+		return ActivityEnrichmentPage{}, errors.New("activity: repository required")
+	}
+	db := r.getDB()
+	if db == nil {
+		return ActivityEnrichmentPage{}, errors.New("activity: cursor listing requires bun DB")
+	}
+	if limit <= 0 {
+		limit = DefaultExportBatchSize
+	}
+	rows := make([]LogEntry, 0, limit)
+	query := db.NewSelect().Model(&rows)
+	query = applyActivityFilter(query, filter)
+	query = ApplyCursorPagination(query, cursor, limit)
+	if err := query.Scan(ctx); err != nil {
+		return ActivityEnrichmentPage{}, err
+	}
+	page := toEnrichmentPage(rows)
+	if len(rows) < limit {
+		page.NextCursor = nil
+	}
+	return page, nil
+}
+
+// StreamActivity walks every record matching filter and invokes fn for each.
+// Cursor pagination is used when repo implements ActivityCursorLister; offset
+// pagination through ListActivity is used otherwise.
+func StreamActivity(ctx context.Context, repo types.ActivityRepository, filter types.ActivityFilter, batchSize int, fn func(types.ActivityRecord) error) error {
+	if repo == nil {
+		return types.ErrMissingActivityRepository
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultExportBatchSize
+	}
+
+	if lister, ok := repo.(ActivityCursorLister); ok {
+		var cursor *ActivityCursor
+		for {
+			page, err := lister.ListActivityByCursor(ctx, filter, cursor, batchSize)
+			if err != nil {
+				return err
+			}
+			for _, record := range page.Records {
+				if err := fn(record); err != nil {
+					return err
+				}
+			}
+			if page.NextCursor == nil || len(page.Records) == 0 {
+				return nil
+			}
+			cursor = page.NextCursor
+		}
+	}
+
+	offset := 0
+	for {
+		filter.Pagination = types.Pagination{Limit: batchSize, Offset: offset}
+		page, err := repo.ListActivity(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, record := range page.Records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore || len(page.Records) == 0 {
+			return nil
+		}
+		offset += len(page.Records)
+	}
+}
+
+// ActivityExportWriter serializes activity records in a specific format.
+type ActivityExportWriter interface {
+	Write(record types.ActivityRecord) error
+	Close() error
+}
+
+// NewActivityExportWriter returns a writer for the requested format.
+func NewActivityExportWriter(format ExportFormat, w io.Writer) (ActivityExportWriter, error) {
+	format, err := normalizeExportFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if format == ExportFormatCSV {
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	}
+	return &jsonlExportWriter{enc: json.NewEncoder(w)}, nil
+}
+
+// normalizeExportFormat lower-cases format, defaults it to JSONL, and rejects
+// unknown formats.
+func normalizeExportFormat(format ExportFormat) (ExportFormat, error) {
+	switch normalized := ExportFormat(strings.ToLower(strings.TrimSpace(string(format)))); normalized {
+	case ExportFormatCSV, ExportFormatJSONL:
+		return normalized, nil
+	case "":
+		return ExportFormatJSONL, nil
+	default:
+		return "", ErrUnsupportedExportFormat
+	}
+}
+
+// ExportRecord is the flat, serialization-friendly shape of an activity record.
+type ExportRecord struct {
+	ID         string         `json:"id"`
+	OccurredAt string         `json:"occurred_at"`
+	UserID     string         `json:"user_id,omitempty"`
+	ActorID    string         `json:"actor_id,omitempty"`
+	TenantID   string         `json:"tenant_id,omitempty"`
+	OrgID      string         `json:"org_id,omitempty"`
+	Verb       string         `json:"verb"`
+	ObjectType string         `json:"object_type"`
+	ObjectID   string         `json:"object_id,omitempty"`
+	Channel    string         `json:"channel,omitempty"`
+	IP         string         `json:"ip,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// ToExportRecord flattens an activity record for export.
+func ToExportRecord(record types.ActivityRecord) ExportRecord {
+	return ExportRecord{
+		ID:         exportUUID(record.ID),
+		OccurredAt: record.OccurredAt.UTC().Format(time.RFC3339Nano),
+		UserID:     exportUUID(record.UserID),
+		ActorID:    exportUUID(record.ActorID),
+		TenantID:   exportUUID(record.TenantID),
+		OrgID:      exportUUID(record.OrgID),
+		Verb:       record.Verb,
+		ObjectType: record.ObjectType,
+		ObjectID:   record.ObjectID,
+		Channel:    record.Channel,
+		IP:         record.IP,
+		Data:       record.Data,
+	}
+}
+
+var exportCSVHeader = []string{
+	"id", "occurred_at", "user_id", "actor_id", "tenant_id", "org_id",
+	"verb", "object_type", "object_id", "channel", "ip", "data",
+}
+
+type csvExportWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (c *csvExportWriter) Write(record types.ActivityRecord) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	row := ToExportRecord(record)
+	data := ""
+	if len(row.Data) > 0 {
+		payload, err := json.Marshal(row.Data)
+		if err != nil {
+			return err
+		}
+		data = string(payload)
+	}
+	return c.w.Write([]string{
+		row.ID, row.OccurredAt, row.UserID, row.ActorID, row.TenantID, row.OrgID,
+		row.Verb, row.ObjectType, row.ObjectID, row.Channel, row.IP, data,
+	})
+}
+
+func (c *csvExportWriter) Close() error {
+	if !c.wroteHeader {
+		if err := c.w.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlExportWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlExportWriter) Write(record types.ActivityRecord) error {
+	return j.enc.Encode(ToExportRecord(record))
+}
+
+func (j *jsonlExportWriter) Close() error {
+	return nil
+}
+
+func exportUUID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
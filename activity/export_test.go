@@ -0,0 +1,154 @@
+package activity
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamActivity_CursorWalksAllPages(t *testing.T) {
+	ctx := context.Background()
+	db := newTestActivityDB(t)
+	applyActivityDDL(t, db)
+	store, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 7 {
+		require.NoError(t, store.Log(ctx, types.ActivityRecord{
+			Verb:       "user.updated",
+			ObjectType: "user",
+			OccurredAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	seen := map[uuid.UUID]bool{}
+	err = StreamActivity(ctx, store, types.ActivityFilter{}, 3, func(record types.ActivityRecord) error {
+		seen[record.ID] = true
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, seen, 7)
+}
+
+func TestActivityExportWriter_CSVAndJSONL(t *testing.T) {
+	record := types.ActivityRecord{
+		ID:         uuid.New(),
+		Verb:       "user.updated",
+		ObjectType: "user",
+		ObjectID:   "abc",
+		OccurredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Data:       map[string]any{"field": "email"},
+	}
+
+	var csvBuf bytes.Buffer
+	w, err := NewActivityExportWriter(ExportFormatCSV, &csvBuf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(record))
+	require.NoError(t, w.Close())
+	rows, err := csv.NewReader(&csvBuf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, exportCSVHeader, rows[0])
+	require.Equal(t, "user.updated", rows[1][6])
+	require.JSONEq(t, `{"field":"email"}`, rows[1][11])
+
+	var jsonBuf bytes.Buffer
+	w, err = NewActivityExportWriter(ExportFormatJSONL, &jsonBuf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(record))
+	require.NoError(t, w.Close())
+	var decoded ExportRecord
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &decoded))
+	require.Equal(t, record.ID.String(), decoded.ID)
+	require.Equal(t, "2024-01-01T00:00:00Z", decoded.OccurredAt)
+
+	_, err = NewActivityExportWriter("xml", &jsonBuf)
+	require.ErrorIs(t, err, ErrUnsupportedExportFormat)
+}
+
+func TestAuditBundle_RoundTripAndTamperDetection(t *testing.T) {
+	for requested, format := range map[ExportFormat]ExportFormat{
+		ExportFormatJSONL: ExportFormatJSONL,
+		ExportFormatCSV:   ExportFormatCSV,
+		"CSV":             ExportFormatCSV,
+	} {
+		t.Run(string(requested), func(t *testing.T) {
+			var buf bytes.Buffer
+			bundle, err := NewAuditBundleWriter(&buf, requested, AuditBundleManifest{GeneratedBy: "auditor"})
+			require.NoError(t, err)
+			for i := range 3 {
+				require.NoError(t, bundle.Write(types.ActivityRecord{
+					ID:         uuid.New(),
+					Verb:       "user.updated",
+					ObjectType: "user",
+					Data:       map[string]any{"index": i},
+				}))
+			}
+			require.NoError(t, bundle.Close())
+
+			manifest, err := VerifyAuditBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+			require.Equal(t, 3, manifest.RecordCount)
+			require.Equal(t, format, manifest.Format)
+			require.Len(t, manifest.Files, 1)
+			require.Equal(t, "records."+string(format), manifest.Files[0].Name)
+			require.Equal(t, "auditor", manifest.GeneratedBy)
+		})
+	}
+
+	var buf bytes.Buffer
+	bundle, err := NewAuditBundleWriter(&buf, ExportFormatJSONL, AuditBundleManifest{})
+	require.NoError(t, err)
+	require.NoError(t, bundle.Write(types.ActivityRecord{ID: uuid.New(), Verb: "user.updated", Data: map[string]any{"note": "original"}}))
+	require.NoError(t, bundle.Close())
+
+	tampered := rewriteBundleEntry(t, buf.Bytes(), "records.jsonl", func(payload []byte) []byte {
+		return bytes.Replace(payload, []byte("original"), []byte("modified"), 1)
+	})
+	_, err = VerifyAuditBundle(bytes.NewReader(tampered), int64(len(tampered)))
+	require.ErrorIs(t, err, ErrAuditBundleChecksumMismatch)
+
+	stripped := rewriteBundleEntry(t, buf.Bytes(), AuditBundleManifestFile, nil)
+	_, err = VerifyAuditBundle(bytes.NewReader(stripped), int64(len(stripped)))
+	require.ErrorIs(t, err, ErrAuditBundleManifestMissing)
+
+	_, err = VerifyAuditBundle(strings.NewReader("not a zip"), 9)
+	require.Error(t, err)
+
+	var empty bytes.Buffer
+	_, err = NewAuditBundleWriter(&empty, "xml", AuditBundleManifest{})
+	require.ErrorIs(t, err, ErrUnsupportedExportFormat)
+	require.Zero(t, empty.Len())
+}
+
+// rewriteBundleEntry copies a bundle, transforming (or dropping when edit is nil) one entry.
+func rewriteBundleEntry(t *testing.T, bundle []byte, name string, edit func([]byte) []byte) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	require.NoError(t, err)
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, file := range zr.File {
+		payload, err := readZipEntry(file)
+		require.NoError(t, err)
+		if file.Name == name {
+			if edit == nil {
+				continue
+			}
+			payload = edit(payload)
+		}
+		require.NoError(t, writeZipEntry(zw, file.Name, payload))
+	}
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
@@ -2,6 +2,7 @@ package activity
 
 import (
 	"github.com/goliatone/go-auth"
+	"github.com/goliatone/go-masker"
 	"github.com/goliatone/go-users/pkg/types"
 )
 
@@ -19,3 +20,21 @@ const (
 
 // MetadataSanitizer customizes sanitized metadata exposure.
 type MetadataSanitizer func(actor *auth.ActorContext, role string, record types.ActivityRecord) map[string]any
+
+// ApplyMetadataExposure shapes record metadata according to strategy. When a
+// sanitizer is supplied it replaces the default masker for sanitized exposure.
+func ApplyMetadataExposure(strategy MetadataExposureStrategy, mask *masker.Masker, sanitizer MetadataSanitizer, actor *auth.ActorContext, role string, record types.ActivityRecord) types.ActivityRecord {
+	switch strategy {
+	case MetadataExposeAll:
+		return record
+	case MetadataExposeSanitized:
+		if sanitizer != nil {
+			record.Data = sanitizer(actor, role, record)
+			return record
+		}
+		return SanitizeRecord(mask, record)
+	default:
+		record.Data = nil
+		return record
+	}
+}
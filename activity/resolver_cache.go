@@ -0,0 +1,97 @@
+package activity
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultResolverCacheSize bounds the LRU cache used by built-in resolvers.
+const DefaultResolverCacheSize = 1024
+
+// DefaultResolverNotFoundTTL is how long built-in resolvers remember ids
+// they could not find, so records created later are picked up.
+const DefaultResolverNotFoundTTL = time.Minute
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// lruCache is a small mutex-guarded LRU used to avoid repeated repository
+// lookups while enriching batches of activity.
+type lruCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[K]*list.Element
+	now      func() time.Time
+}
+
+func newLRUCache[K comparable, V any](capacity int) *lruCache[K, V] {
+	if capacity == 0 {
+		capacity = DefaultResolverCacheSize
+	}
+	return &lruCache[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil || c.capacity < 0 {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[K, V])
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *lruCache[K, V]) Add(key K, value V) {
+	c.AddWithTTL(key, value, 0)
+}
+
+// AddWithTTL stores value until ttl elapses; zero keeps it until evicted and
+// a negative ttl skips caching.
+func (c *lruCache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	if c == nil || c.capacity < 0 || ttl < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
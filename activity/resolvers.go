@@ -0,0 +1,514 @@
+package activity
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Object types understood by the built-in resolvers.
+const (
+	ObjectTypeUser       = "user"
+	ObjectTypeRole       = "role"
+	ObjectTypeProfile    = "profile"
+	ObjectTypePreference = "preference"
+	ObjectTypeToken      = "token"
+)
+
+// resolverBatchSize keeps batched lookups within the page size repositories
+// accept (the Bun repositories cap pages at 200).
+const resolverBatchSize = 200
+
+// RepositoryActorResolverConfig wires the built-in actor resolver.
+type RepositoryActorResolverConfig struct {
+	// Users resolves actor emails and fallback display names (Users or Inventory is required).
+	Users types.AuthRepository
+	// Inventory batch-loads users when supplied; Users is used per-id otherwise.
+	Inventory types.UserInventoryRepository
+	// Profiles provides preferred display names when supplied.
+	Profiles types.ProfileRepository
+	// CacheSize bounds the LRU cache (0 uses the default, negative disables it).
+	CacheSize int
+	// NotFoundTTL bounds how long unknown actors stay cached (0 uses
+	// DefaultResolverNotFoundTTL, negative disables caching them).
+	NotFoundTTL time.Duration
+}
+
+// RepositoryActorResolver resolves actors through go-users repositories.
+type RepositoryActorResolver struct {
+	users       types.AuthRepository
+	inventory   types.UserInventoryRepository
+	profiles    types.ProfileRepository
+	cache       *lruCache[string, actorCacheEntry]
+	notFoundTTL time.Duration
+}
+
+type actorCacheEntry struct {
+	info  ActorInfo
+	found bool
+}
+
+var _ ActorResolver = (*RepositoryActorResolver)(nil)
+
+// NewRepositoryActorResolver constructs the actor resolver.
+func NewRepositoryActorResolver(cfg RepositoryActorResolverConfig) (*RepositoryActorResolver, error) {
+	if cfg.Users == nil && cfg.Inventory == nil {
+		return nil, types.ErrMissingAuthRepository
+	}
+	return &RepositoryActorResolver{
+		users:       cfg.Users,
+		inventory:   cfg.Inventory,
+		profiles:    cfg.Profiles,
+		cache:       newLRUCache[string, actorCacheEntry](cfg.CacheSize),
+		notFoundTTL: notFoundTTL(cfg.NotFoundTTL),
+	}, nil
+}
+
+// ResolveActors returns display/email details for the provided actor ids.
+// Unknown actors are omitted from the result.
+func (r *RepositoryActorResolver) ResolveActors(ctx context.Context, ids []uuid.UUID, meta ResolveContext) (map[uuid.UUID]ActorInfo, error) {
+	out := make(map[uuid.UUID]ActorInfo, len(ids))
+	missing := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil {
+			continue
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		if entry, ok := r.cache.Get(resolverCacheKey(meta.TenantID, id.String())); ok {
+			if entry.found {
+				out[id] = entry.info
+			}
+			continue
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return out, nil
+	}
+
+	users, err := loadAuthUsers(ctx, r.users, r.inventory, missing, meta)
+	if err != nil {
+		return nil, err
+	}
+	var profiles map[uuid.UUID]*types.UserProfile
+	if r.profiles != nil && len(users) > 0 {
+		found := make([]uuid.UUID, 0, len(users))
+		for id := range users {
+			found = append(found, id)
+		}
+		profiles, err = types.ResolveProfiles(ctx, r.profiles, found, types.ScopeFilter{TenantID: meta.TenantID})
+		if err != nil && !repository.IsRecordNotFound(err) {
+			return nil, err
+		}
+	}
+	for _, id := range missing {
+		user, ok := users[id]
+		if !ok {
+			r.cache.AddWithTTL(resolverCacheKey(meta.TenantID, id.String()), actorCacheEntry{}, r.notFoundTTL)
+			continue
+		}
+		info := ActorInfo{
+			ID:      id,
+			Type:    "user",
+			Display: authUserDisplay(user),
+			Email:   user.Email,
+		}
+		if profile := profiles[id]; profile != nil && strings.TrimSpace(profile.DisplayName) != "" {
+			info.Display = strings.TrimSpace(profile.DisplayName)
+		}
+		out[id] = info
+		r.cache.Add(resolverCacheKey(meta.TenantID, id.String()), actorCacheEntry{info: info, found: true})
+	}
+	return out, nil
+}
+
+// RepositoryObjectResolverConfig wires the built-in object resolvers.
+type RepositoryObjectResolverConfig struct {
+	Users       types.AuthRepository
+	Inventory   types.UserInventoryRepository
+	Roles       types.RoleRegistry
+	Profiles    types.ProfileRepository
+	Preferences types.PreferenceRepository
+	Tokens      types.UserTokenRepository
+	// CacheSize bounds each resolver's LRU cache (0 uses the default, negative disables it).
+	CacheSize int
+	// NotFoundTTL bounds how long objects reported as deleted stay cached (0
+	// uses DefaultResolverNotFoundTTL, negative disables caching them).
+	NotFoundTTL time.Duration
+}
+
+// NewRepositoryObjectResolvers returns a registry populated with resolvers for
+// every repository present in cfg.
+func NewRepositoryObjectResolvers(cfg RepositoryObjectResolverConfig) *ObjectResolverRegistry {
+	registry := NewObjectResolverRegistry()
+	cached := func(load objectLoader) *cachedObjectResolver {
+		return newCachedObjectResolver(cfg.CacheSize, notFoundTTL(cfg.NotFoundTTL), load)
+	}
+	if cfg.Users != nil || cfg.Inventory != nil {
+		registry.Register(ObjectTypeUser, cached(userObjectLoader(cfg.Users, cfg.Inventory)))
+	}
+	if cfg.Roles != nil {
+		registry.Register(ObjectTypeRole, cached(roleObjectLoader(cfg.Roles)))
+	}
+	if cfg.Profiles != nil {
+		registry.Register(ObjectTypeProfile, cached(profileObjectLoader(cfg.Profiles)))
+	}
+	if cfg.Preferences != nil {
+		registry.Register(ObjectTypePreference, cached(preferenceObjectLoader(cfg.Preferences)))
+	}
+	if cfg.Tokens != nil {
+		registry.Register(ObjectTypeToken, cached(tokenObjectLoader(cfg.Tokens)))
+	}
+	return registry
+}
+
+// ObjectResolverRegistry dispatches object resolution by object type.
+type ObjectResolverRegistry struct {
+	mu        sync.RWMutex
+	resolvers map[string]ObjectResolver
+}
+
+var _ ObjectResolver = (*ObjectResolverRegistry)(nil)
+
+// NewObjectResolverRegistry returns an empty registry.
+func NewObjectResolverRegistry() *ObjectResolverRegistry {
+	return &ObjectResolverRegistry{resolvers: make(map[string]ObjectResolver)}
+}
+
+// Register associates a resolver with an object type, replacing any previous one.
+func (r *ObjectResolverRegistry) Register(objectType string, resolver ObjectResolver) {
+	objectType = normalizeObjectType(objectType)
+	if r == nil || objectType == "" || resolver == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resolvers == nil {
+		r.resolvers = make(map[string]ObjectResolver)
+	}
+	r.resolvers[objectType] = resolver
+}
+
+// Resolver returns the resolver registered for objectType.
+func (r *ObjectResolverRegistry) Resolver(objectType string) (ObjectResolver, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resolver, ok := r.resolvers[normalizeObjectType(objectType)]
+	return resolver, ok
+}
+
+// ResolveObjects delegates to the registered resolver. Unregistered object
+// types resolve to an empty result.
+func (r *ObjectResolverRegistry) ResolveObjects(ctx context.Context, objectType string, ids []string, meta ResolveContext) (map[string]ObjectInfo, error) {
+	resolver, ok := r.Resolver(objectType)
+	if !ok {
+		return map[string]ObjectInfo{}, nil
+	}
+	return resolver.ResolveObjects(ctx, objectType, ids, meta)
+}
+
+// objectLoader loads the objects it can find; ids absent from the result are
+// reported as deleted.
+type objectLoader func(ctx context.Context, ids []string, meta ResolveContext) (map[string]ObjectInfo, error)
+
+type cachedObjectResolver struct {
+	load        objectLoader
+	cache       *lruCache[string, ObjectInfo]
+	notFoundTTL time.Duration
+}
+
+func newCachedObjectResolver(size int, notFoundTTL time.Duration, load objectLoader) *cachedObjectResolver {
+	return &cachedObjectResolver{
+		load:        load,
+		cache:       newLRUCache[string, ObjectInfo](size),
+		notFoundTTL: notFoundTTL,
+	}
+}
+
+func (r *cachedObjectResolver) ResolveObjects(ctx context.Context, objectType string, ids []string, meta ResolveContext) (map[string]ObjectInfo, error) {
+	objectType = normalizeObjectType(objectType)
+	out := make(map[string]ObjectInfo, len(ids))
+	missing := make([]string, 0, len(ids))
+	for _, id := range uniqueTrimmed(ids) {
+		if info, ok := r.cache.Get(resolverCacheKey(meta.TenantID, id)); ok {
+			out[id] = info
+			continue
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return out, nil
+	}
+
+	loaded, err := r.load(ctx, missing, meta)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range missing {
+		info, ok := loaded[id]
+		if !ok {
+			info = ObjectInfo{ID: id, Type: objectType, Deleted: true}
+		}
+		if info.Type == "" {
+			info.Type = objectType
+		}
+		out[id] = info
+		ttl := time.Duration(0)
+		if info.Deleted {
+			ttl = r.notFoundTTL
+		}
+		r.cache.AddWithTTL(resolverCacheKey(meta.TenantID, id), info, ttl)
+	}
+	return out, nil
+}
+
+func userObjectLoader(users types.AuthRepository, inventory types.UserInventoryRepository) objectLoader {
+	return func(ctx context.Context, ids []string, meta ResolveContext) (map[string]ObjectInfo, error) {
+		parsed := parseUUIDs(ids)
+		loaded, err := loadAuthUsers(ctx, users, inventory, parsed, meta)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[string]ObjectInfo, len(loaded))
+		for id, user := range loaded {
+			out[id.String()] = ObjectInfo{
+				ID:      id.String(),
+				Type:    ObjectTypeUser,
+				Display: authUserDisplay(user),
+				Deleted: user.Status == types.LifecycleStateArchived,
+			}
+		}
+		return out, nil
+	}
+}
+
+func roleObjectLoader(roles types.RoleRegistry) objectLoader {
+	return func(ctx context.Context, ids []string, meta ResolveContext) (map[string]ObjectInfo, error) {
+		parsed := parseUUIDs(ids)
+		out := make(map[string]ObjectInfo, len(parsed))
+		for chunk := range slices.Chunk(parsed, resolverBatchSize) {
+			page, err := roles.ListRoles(ctx, types.RoleFilter{
+				Actor:         resolverActor(meta),
+				Scope:         types.ScopeFilter{TenantID: meta.TenantID},
+				RoleIDs:       chunk,
+				IncludeSystem: true,
+				Pagination:    types.Pagination{Limit: len(chunk)},
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, role := range page.Roles {
+				display := role.Name
+				if display == "" {
+					display = role.RoleKey
+				}
+				out[role.ID.String()] = ObjectInfo{ID: role.ID.String(), Type: ObjectTypeRole, Display: display}
+			}
+		}
+		return out, nil
+	}
+}
+
+func profileObjectLoader(profiles types.ProfileRepository) objectLoader {
+	return func(ctx context.Context, ids []string, meta ResolveContext) (map[string]ObjectInfo, error) {
+		scope := types.ScopeFilter{TenantID: meta.TenantID}
+		out := make(map[string]ObjectInfo, len(ids))
+		for chunk := range slices.Chunk(parseUUIDs(ids), resolverBatchSize) {
+			loaded, err := types.ResolveProfiles(ctx, profiles, chunk, scope)
+			if err != nil {
+				if repository.IsRecordNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			for userID, profile := range loaded {
+				out[userID.String()] = ObjectInfo{ID: userID.String(), Type: ObjectTypeProfile, Display: profile.DisplayName}
+			}
+		}
+		return out, nil
+	}
+}
+
+// preferenceObjectLoader resolves preference ids shaped as "<user_id>:<key>".
+func preferenceObjectLoader(prefs types.PreferenceRepository) objectLoader {
+	return func(ctx context.Context, ids []string, meta ResolveContext) (map[string]ObjectInfo, error) {
+		keysByUser := make(map[uuid.UUID][]string)
+		for _, id := range ids {
+			userID, key, ok := splitObjectRef(id)
+			if !ok {
+				continue
+			}
+			parsed, err := uuid.Parse(userID)
+			if err != nil {
+				continue
+			}
+			keysByUser[parsed] = append(keysByUser[parsed], key)
+		}
+		out := make(map[string]ObjectInfo, len(ids))
+		for userID, keys := range keysByUser {
+			records, err := prefs.ListPreferences(ctx, types.PreferenceFilter{
+				UserID: userID,
+				Scope:  types.ScopeFilter{TenantID: meta.TenantID},
+				Level:  types.PreferenceLevelUser,
+				Keys:   keys,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				id := userID.String() + ":" + record.Key
+				out[id] = ObjectInfo{ID: id, Type: ObjectTypePreference, Display: record.Key}
+			}
+		}
+		return out, nil
+	}
+}
+
+// tokenObjectLoader resolves token ids shaped as "<token_type>:<jti>".
+func tokenObjectLoader(tokens types.UserTokenRepository) objectLoader {
+	return func(ctx context.Context, ids []string, _ ResolveContext) (map[string]ObjectInfo, error) {
+		out := make(map[string]ObjectInfo, len(ids))
+		for _, id := range ids {
+			tokenType, jti, ok := splitObjectRef(id)
+			if !ok {
+				continue
+			}
+			token, err := tokens.GetTokenByJTI(ctx, types.UserTokenType(tokenType), jti)
+			if err != nil {
+				if repository.IsRecordNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if token == nil {
+				continue
+			}
+			out[id] = ObjectInfo{
+				ID:      id,
+				Type:    ObjectTypeToken,
+				Display: string(token.Type) + " token",
+				// Used, expired, and revoked tokens can no longer be redeemed.
+				Deleted: token.Status != types.UserTokenStatusIssued,
+			}
+		}
+		return out, nil
+	}
+}
+
+func loadAuthUsers(ctx context.Context, users types.AuthRepository, inventory types.UserInventoryRepository, ids []uuid.UUID, meta ResolveContext) (map[uuid.UUID]*types.AuthUser, error) {
+	out := make(map[uuid.UUID]*types.AuthUser, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	if inventory != nil {
+		for chunk := range slices.Chunk(ids, resolverBatchSize) {
+			page, err := inventory.ListUsers(ctx, types.UserInventoryFilter{
+				Actor:      resolverActor(meta),
+				Scope:      types.ScopeFilter{TenantID: meta.TenantID},
+				UserIDs:    chunk,
+				Pagination: types.Pagination{Limit: len(chunk)},
+			})
+			if err != nil {
+				return nil, err
+			}
+			for i := range page.Users {
+				user := page.Users[i]
+				out[user.ID] = &user
+			}
+		}
+		return out, nil
+	}
+	for _, id := range ids {
+		user, err := users.GetByID(ctx, id)
+		if err != nil {
+			if repository.IsRecordNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if user != nil {
+			out[id] = user
+		}
+	}
+	return out, nil
+}
+
+func authUserDisplay(user *types.AuthUser) string {
+	if user == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(strings.TrimSpace(user.FirstName) + " " + strings.TrimSpace(user.LastName)); name != "" {
+		return name
+	}
+	if username := strings.TrimSpace(user.Username); username != "" {
+		return username
+	}
+	return strings.TrimSpace(user.Email)
+}
+
+func notFoundTTL(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		return DefaultResolverNotFoundTTL
+	}
+	return ttl
+}
+
+func resolverActor(meta ResolveContext) types.ActorRef {
+	return types.ActorRef{ID: meta.ActorID, Type: "system"}
+}
+
+func resolverCacheKey(tenantID uuid.UUID, id string) string {
+	return tenantID.String() + "|" + id
+}
+
+func normalizeObjectType(objectType string) string {
+	return strings.ToLower(strings.TrimSpace(objectType))
+}
+
+func splitObjectRef(id string) (string, string, bool) {
+	prefix, rest, ok := strings.Cut(strings.TrimSpace(id), ":")
+	if !ok || strings.TrimSpace(prefix) == "" || strings.TrimSpace(rest) == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(prefix), strings.TrimSpace(rest), true
+}
+
+func parseUUIDs(ids []string) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if parsed := parseUUIDOrNil(id); parsed != uuid.Nil {
+			out = append(out, parsed)
+		}
+	}
+	return out
+}
+
+func uniqueTrimmed(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	return out
+}
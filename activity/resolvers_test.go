@@ -0,0 +1,187 @@
+package activity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryActorResolverBatchesAndCaches(t *testing.T) {
+	ctx := context.Background()
+	alice := types.AuthUser{ID: uuid.New(), Email: "alice@example.com", FirstName: "Alice"}
+	bob := types.AuthUser{ID: uuid.New(), Email: "bob@example.com", Username: "bob"}
+	inventory := &stubInventoryRepo{users: []types.AuthUser{alice, bob}}
+	profiles := &stubProfileRepo{profiles: map[uuid.UUID]*types.UserProfile{
+		alice.ID: {UserID: alice.ID, DisplayName: "Alice Liddell"},
+	}}
+
+	resolver, err := NewRepositoryActorResolver(RepositoryActorResolverConfig{
+		Inventory: inventory,
+		Profiles:  profiles,
+	})
+	require.NoError(t, err)
+
+	ghost := uuid.New()
+	ids := []uuid.UUID{alice.ID, bob.ID, ghost, alice.ID}
+	actors, err := resolver.ResolveActors(ctx, ids, ResolveContext{})
+	require.NoError(t, err)
+	require.Len(t, actors, 2)
+	require.Equal(t, "Alice Liddell", actors[alice.ID].Display)
+	require.Equal(t, "alice@example.com", actors[alice.ID].Email)
+	require.Equal(t, "bob", actors[bob.ID].Display)
+	require.Equal(t, 1, inventory.calls)
+
+	_, err = resolver.ResolveActors(ctx, ids, ResolveContext{})
+	require.NoError(t, err)
+	require.Equal(t, 1, inventory.calls, "cached actors (including misses) should not hit the repository")
+}
+
+func TestObjectResolverRegistryDetectsDeletedObjects(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	profiles := &stubProfileRepo{profiles: map[uuid.UUID]*types.UserProfile{
+		userID: {UserID: userID, DisplayName: "Present"},
+	}}
+	registry := NewRepositoryObjectResolvers(RepositoryObjectResolverConfig{Profiles: profiles})
+
+	missingID := uuid.New().String()
+	objects, err := registry.ResolveObjects(ctx, ObjectTypeProfile, []string{userID.String(), missingID}, ResolveContext{})
+	require.NoError(t, err)
+	require.Equal(t, "Present", objects[userID.String()].Display)
+	require.False(t, objects[userID.String()].Deleted)
+	require.True(t, objects[missingID].Deleted)
+
+	unknown, err := registry.ResolveObjects(ctx, "widget", []string{"1"}, ResolveContext{})
+	require.NoError(t, err)
+	require.Empty(t, unknown)
+}
+
+func TestRepositoryActorResolverChunksAndExpiresMisses(t *testing.T) {
+	ctx := context.Background()
+	inventory := &stubInventoryRepo{}
+	ids := make([]uuid.UUID, 0, 450)
+	for range 450 {
+		user := types.AuthUser{ID: uuid.New(), Username: "user"}
+		inventory.users = append(inventory.users, user)
+		ids = append(ids, user.ID)
+	}
+	profiles := &stubProfileRepo{profiles: map[uuid.UUID]*types.UserProfile{}}
+	resolver, err := NewRepositoryActorResolver(RepositoryActorResolverConfig{
+		Inventory: inventory,
+		Profiles:  profiles,
+		CacheSize: 1000,
+	})
+	require.NoError(t, err)
+
+	actors, err := resolver.ResolveActors(ctx, ids, ResolveContext{})
+	require.NoError(t, err)
+	require.Len(t, actors, 450)
+	require.Equal(t, 3, inventory.calls)
+	require.Equal(t, resolverBatchSize, inventory.maxBatch)
+	require.Equal(t, 1, profiles.batchCalls, "profiles are loaded in one batch")
+
+	now := time.Now()
+	resolver.cache.now = func() time.Time { return now }
+	late := types.AuthUser{ID: uuid.New(), Username: "late"}
+	actors, err = resolver.ResolveActors(ctx, []uuid.UUID{late.ID}, ResolveContext{})
+	require.NoError(t, err)
+	require.Empty(t, actors)
+
+	inventory.users = append(inventory.users, late)
+	actors, err = resolver.ResolveActors(ctx, []uuid.UUID{late.ID}, ResolveContext{})
+	require.NoError(t, err)
+	require.Empty(t, actors, "misses are cached until the TTL elapses")
+
+	now = now.Add(DefaultResolverNotFoundTTL)
+	actors, err = resolver.ResolveActors(ctx, []uuid.UUID{late.ID}, ResolveContext{})
+	require.NoError(t, err)
+	require.Equal(t, "late", actors[late.ID].Display)
+}
+
+func TestTokenObjectResolverMarksRedeemedTokensDeleted(t *testing.T) {
+	tokens := &stubTokenRepo{tokens: map[string]types.UserToken{
+		"issued": {Type: types.UserTokenInvite, JTI: "issued", Status: types.UserTokenStatusIssued},
+		"used":   {Type: types.UserTokenInvite, JTI: "used", Status: types.UserTokenStatusUsed},
+		"old":    {Type: types.UserTokenInvite, JTI: "old", Status: types.UserTokenStatusExpired},
+	}}
+	registry := NewRepositoryObjectResolvers(RepositoryObjectResolverConfig{Tokens: tokens})
+
+	prefix := string(types.UserTokenInvite) + ":"
+	objects, err := registry.ResolveObjects(context.Background(), ObjectTypeToken, []string{prefix + "issued", prefix + "used", prefix + "old"}, ResolveContext{})
+	require.NoError(t, err)
+	require.False(t, objects[prefix+"issued"].Deleted)
+	require.True(t, objects[prefix+"used"].Deleted)
+	require.True(t, objects[prefix+"old"].Deleted)
+}
+
+type stubTokenRepo struct {
+	tokens map[string]types.UserToken
+}
+
+func (s *stubTokenRepo) CreateToken(_ context.Context, token types.UserToken) (*types.UserToken, error) {
+	return &token, nil
+}
+
+func (s *stubTokenRepo) GetTokenByJTI(_ context.Context, _ types.UserTokenType, jti string) (*types.UserToken, error) {
+	token, ok := s.tokens[jti]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+func (s *stubTokenRepo) UpdateTokenStatus(context.Context, types.UserTokenType, string, types.UserTokenStatus, time.Time) error {
+	return nil
+}
+
+type stubInventoryRepo struct {
+	users    []types.AuthUser
+	calls    int
+	maxBatch int
+}
+
+func (s *stubInventoryRepo) ListUsers(_ context.Context, filter types.UserInventoryFilter) (types.UserInventoryPage, error) {
+	s.calls++
+	s.maxBatch = max(s.maxBatch, len(filter.UserIDs))
+	page := types.UserInventoryPage{}
+	for _, user := range s.users {
+		for _, id := range filter.UserIDs {
+			if user.ID == id {
+				page.Users = append(page.Users, user)
+			}
+		}
+	}
+	page.Total = len(page.Users)
+	return page, nil
+}
+
+type stubProfileRepo struct {
+	profiles   map[uuid.UUID]*types.UserProfile
+	batchCalls int
+}
+
+func (s *stubProfileRepo) GetProfiles(_ context.Context, userIDs []uuid.UUID, scope types.ScopeFilter) (map[uuid.UUID]*types.UserProfile, error) {
+	s.batchCalls++
+	out := make(map[uuid.UUID]*types.UserProfile, len(userIDs))
+	if scope.TenantID != uuid.Nil || scope.OrgID != uuid.Nil {
+		return out, nil
+	}
+	for _, id := range userIDs {
+		if profile, ok := s.profiles[id]; ok {
+			out[id] = profile
+		}
+	}
+	return out, nil
+}
+
+func (s *stubProfileRepo) GetProfile(_ context.Context, userID uuid.UUID, _ types.ScopeFilter) (*types.UserProfile, error) {
+	return s.profiles[userID], nil
+}
+
+func (s *stubProfileRepo) UpsertProfile(_ context.Context, profile types.UserProfile) (*types.UserProfile, error) {
+	return &profile, nil
+}
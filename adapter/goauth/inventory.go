@@ -0,0 +1,90 @@
+package goauth
+
+import (
+	"context"
+	"strings"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/uptrace/bun"
+)
+
+const (
+	defaultInventoryLimit = 50
+	maxInventoryLimit     = 200
+)
+
+var _ types.UserInventoryRepository = (*UsersAdapter)(nil)
+
+// ListUsers implements types.UserInventoryRepository over the go-auth users
+// table. Keyword matches email, username, first name, and last name.
+// ProfileFields and SortProfileField are applied with
+// profile.InventoryCriteria against the user_profiles table, resolved for
+// filter.Scope. go-auth users carry no tenant column, so the scope does not
+// narrow the user rows themselves.
+func (a *UsersAdapter) ListUsers(ctx context.Context, filter types.UserInventoryFilter) (types.UserInventoryPage, error) {
+	limit := filter.Pagination.Limit
+	if limit <= 0 {
+		limit = defaultInventoryLimit
+	}
+	limit = min(limit, maxInventoryLimit)
+	offset := max(filter.Pagination.Offset, 0)
+
+	records, total, err := a.repo.List(ctx,
+		inventoryCriteria(filter),
+		profile.InventoryCriteria(filter, "usr.id"),
+		func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.OrderExpr("usr.created_at ASC").OrderExpr("usr.id ASC")
+		},
+		repository.SelectPaginate(limit, offset),
+	)
+	if err != nil {
+		return types.UserInventoryPage{}, err
+	}
+	page := types.UserInventoryPage{
+		Users: make([]types.AuthUser, 0, len(records)),
+		Total: total,
+	}
+	for _, record := range records {
+		if user := toAuthUser(record); user != nil {
+			page.Users = append(page.Users, *user)
+		}
+	}
+	page.NextOffset = offset + len(page.Users)
+	page.HasMore = page.NextOffset < total
+	return page, nil
+}
+
+func inventoryCriteria(filter types.UserInventoryFilter) repository.SelectCriteria {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		if len(filter.Statuses) > 0 {
+			statuses := make([]string, 0, len(filter.Statuses))
+			for _, status := range filter.Statuses {
+				statuses = append(statuses, string(status))
+			}
+			q = q.Where("usr.status IN (?)", bun.In(statuses))
+		}
+		if role := strings.TrimSpace(filter.Role); role != "" {
+			q = q.Where("usr.user_role = ?", role)
+		}
+		if len(filter.UserIDs) > 0 {
+			ids := make([]string, 0, len(filter.UserIDs))
+			for _, id := range filter.UserIDs {
+				ids = append(ids, id.String())
+			}
+			q = q.Where("usr.id IN (?)", bun.In(ids))
+		}
+		if keyword := strings.ToLower(strings.TrimSpace(filter.Keyword)); keyword != "" {
+			like := "%" + keyword + "%"
+			q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.
+					Where("lower(usr.email) LIKE ?", like).
+					WhereOr("lower(usr.username) LIKE ?", like).
+					WhereOr("lower(usr.first_name) LIKE ?", like).
+					WhereOr("lower(usr.last_name) LIKE ?", like)
+			})
+		}
+		return q
+	}
+}
@@ -0,0 +1,107 @@
+package goauth
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	auth "github.com/goliatone/go-auth"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/goliatone/go-users/query"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestUsersAdapter_ListUsersAppliesProfileFields(t *testing.T) {
+	ctx := context.Background()
+	db := newInventoryTestDB(t)
+	users := auth.NewUsersRepository(db)
+	profiles, err := profile.NewRepository(profile.RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	fields, err := profile.NewFieldRepository(profile.FieldRepositoryConfig{DB: db})
+	require.NoError(t, err)
+	_, err = fields.UpsertProfileField(ctx, types.ProfileFieldDefinition{Key: "level", Type: types.ProfileFieldTypeNumber})
+	require.NoError(t, err)
+	_, err = fields.UpsertProfileField(ctx, types.ProfileFieldDefinition{Key: "remote", Type: types.ProfileFieldTypeBoolean})
+	require.NoError(t, err)
+
+	tenant := types.ScopeFilter{TenantID: uuid.New()}
+	levels := []int{3, 10, 7}
+	ids := make([]uuid.UUID, len(levels))
+	for i, level := range levels {
+		created, err := users.Create(ctx, &auth.User{
+			ID:        uuid.New(),
+			Role:      auth.UserRole("member"),
+			Status:    auth.UserStatus("active"),
+			Email:     "user" + string(rune('a'+i)) + "@example.com",
+			Username:  "user" + string(rune('a'+i)),
+			FirstName: "User",
+			LastName:  strings.ToUpper(string(rune('a' + i))),
+		})
+		require.NoError(t, err)
+		ids[i] = created.ID
+		// The legacy global profile is shadowed by the tenant profile.
+		_, err = profiles.UpsertProfile(ctx, types.UserProfile{UserID: created.ID, CustomFields: map[string]any{"level": 100, "remote": true}})
+		require.NoError(t, err)
+		_, err = profiles.UpsertProfile(ctx, types.UserProfile{UserID: created.ID, Scope: tenant, CustomFields: map[string]any{"level": level, "remote": i != 1}})
+		require.NoError(t, err)
+	}
+
+	inventory := query.NewUserInventoryQuery(NewUsersAdapter(users), nil, nil, query.WithInventoryProfileFields(fields))
+	page, err := inventory.Query(ctx, types.UserInventoryFilter{
+		Actor:            types.ActorRef{ID: uuid.New()},
+		Scope:            tenant,
+		ProfileFields:    map[string]any{"remote": true},
+		SortProfileField: "level",
+		SortDescending:   true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Len(t, page.Users, 2)
+	require.Equal(t, ids[2], page.Users[0].ID)
+	require.Equal(t, ids[0], page.Users[1].ID)
+
+	page, err = inventory.Query(ctx, types.UserInventoryFilter{
+		Actor:   types.ActorRef{ID: uuid.New()},
+		Keyword: "userb",
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	require.Equal(t, ids[1], page.Users[0].ID)
+}
+
+func newInventoryTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	_, err = db.NewCreateTable().Model((*auth.User)(nil)).Exec(context.Background())
+	require.NoError(t, err)
+	for _, path := range []string{
+		"../../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql",
+		"../../data/sql/migrations/sqlite/00015_profile_custom_fields.up.sql",
+		"../../data/sql/migrations/sqlite/00016_profile_versions.up.sql",
+		"../../data/sql/migrations/sqlite/00017_profile_scopes.up.sql",
+	} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for stmt := range strings.SplitSeq(string(content), ";") {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err, stmt)
+		}
+	}
+	return db
+}
@@ -261,6 +261,16 @@ func (a *UsersAdapter) ClearTemporaryPassword(ctx context.Context, id uuid.UUID)
 	return err
 }
 
+func (a *UsersAdapter) MarkPasswordChanged(ctx context.Context, id uuid.UUID, changedAt time.Time) error {
+	record, err := a.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record.Metadata = types.MarkPasswordChangedMetadata(record.Metadata, changedAt)
+	_, err = a.Update(ctx, record)
+	return err
+}
+
 func buildGoAuthOptions(cfg types.TransitionConfig) []auth.TransitionOption {
 	opts := make([]auth.TransitionOption, 0, 3)
 	if cfg.Reason != "" {
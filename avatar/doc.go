@@ -0,0 +1,3 @@
+// Package avatar validates avatar images, derives their storage keys, and
+// provides in-memory and filesystem implementations of types.BlobStorage.
+package avatar
@@ -0,0 +1,190 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for dimension checks
+	_ "image/jpeg" // register JPEG decoder for dimension checks
+	_ "image/png"  // register PNG decoder for dimension checks
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxBytes caps avatar uploads when Policy.MaxBytes is zero.
+const DefaultMaxBytes int64 = 5 << 20
+
+// DefaultContentTypes lists the image types accepted when
+// Policy.AllowedContentTypes is empty.
+var DefaultContentTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// Policy describes the images accepted as avatars. Zero dimension bounds are
+// not enforced.
+type Policy struct {
+	MaxBytes            int64
+	AllowedContentTypes []string
+	MinWidth            int
+	MinHeight           int
+	MaxWidth            int
+	MaxHeight           int
+}
+
+// DefaultPolicy returns a policy accepting PNG, JPEG, and GIF images up to
+// DefaultMaxBytes and 4096x4096 pixels.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxBytes:            DefaultMaxBytes,
+		AllowedContentTypes: append([]string(nil), DefaultContentTypes...),
+		MaxWidth:            4096,
+		MaxHeight:           4096,
+	}
+}
+
+// Image is a validated avatar.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	Size        int64
+	// Checksum is the hex encoded SHA-256 of Data.
+	Checksum string
+}
+
+// Read consumes r up to the size limit and validates the result. declared is
+// the client supplied content type; when set it must agree with the sniffed
+// type.
+func (p Policy) Read(r io.Reader, declared string) (Image, error) {
+	if r == nil {
+		return Image{}, types.ErrAvatarRequired
+	}
+	limit := p.maxBytes()
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return Image{}, err
+	}
+	return p.Validate(data, declared)
+}
+
+// Validate checks data against the policy and returns its metadata.
+func (p Policy) Validate(data []byte, declared string) (Image, error) {
+	if len(data) == 0 {
+		return Image{}, types.ErrAvatarRequired
+	}
+	if int64(len(data)) > p.maxBytes() {
+		return Image{}, fmt.Errorf("%w: limit is %d bytes", types.ErrAvatarTooLarge, p.maxBytes())
+	}
+	sniffed := normalizeContentType(http.DetectContentType(data))
+	if declared = normalizeContentType(declared); declared != "" && declared != sniffed {
+		return Image{}, fmt.Errorf("%w: declared %s but content is %s", types.ErrAvatarContentType, declared, sniffed)
+	}
+	if !slices.Contains(p.allowedContentTypes(), sniffed) {
+		return Image{}, fmt.Errorf("%w: %s", types.ErrAvatarContentType, sniffed)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", types.ErrAvatarContentType, err)
+	}
+	if err := p.checkDimensions(cfg.Width, cfg.Height); err != nil {
+		return Image{}, err
+	}
+	sum := sha256.Sum256(data)
+	return Image{
+		Data:        data,
+		ContentType: sniffed,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Key returns the deterministic storage key for img owned by userID:
+// avatars/<tenant>/<user>/<checksum><ext>, with "global" standing in for an
+// empty tenant and an /orgs/<org> segment after the tenant for org scoped
+// profiles. Identical uploads to the same profile map to the same key.
+func Key(scope types.ScopeFilter, userID uuid.UUID, img Image) string {
+	return keyPrefix(scope, userID) + img.Checksum + extension(img.ContentType)
+}
+
+// OwnsKey reports whether key is an avatar blob Key would place under userID
+// in scope. Callers use it before deleting a key derived from a stored URL,
+// which profile writes can set to any location.
+func OwnsKey(scope types.ScopeFilter, userID uuid.UUID, key string) bool {
+	name, ok := strings.CutPrefix(key, keyPrefix(scope, userID))
+	return ok && name != "" && !strings.Contains(name, "/") && name != "." && name != ".."
+}
+
+func keyPrefix(scope types.ScopeFilter, userID uuid.UUID) string {
+	prefix := "avatars/global"
+	if scope.TenantID != uuid.Nil {
+		prefix = "avatars/" + scope.TenantID.String()
+	}
+	if scope.OrgID != uuid.Nil {
+		prefix += "/orgs/" + scope.OrgID.String()
+	}
+	return prefix + "/" + userID.String() + "/"
+}
+
+func (p Policy) checkDimensions(width, height int) error {
+	switch {
+	case p.MinWidth > 0 && width < p.MinWidth,
+		p.MinHeight > 0 && height < p.MinHeight,
+		p.MaxWidth > 0 && width > p.MaxWidth,
+		p.MaxHeight > 0 && height > p.MaxHeight:
+		return fmt.Errorf("%w: %dx%d", types.ErrAvatarDimensions, width, height)
+	}
+	return nil
+}
+
+func (p Policy) maxBytes() int64 {
+	if p.MaxBytes <= 0 {
+		return DefaultMaxBytes
+	}
+	return p.MaxBytes
+}
+
+func (p Policy) allowedContentTypes() []string {
+	if len(p.AllowedContentTypes) == 0 {
+		return DefaultContentTypes
+	}
+	allowed := make([]string, 0, len(p.AllowedContentTypes))
+	for _, contentType := range p.AllowedContentTypes {
+		allowed = append(allowed, normalizeContentType(contentType))
+	}
+	return allowed
+}
+
+func normalizeContentType(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(value)
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func pngBytes(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestPolicy_ValidateAcceptsImage(t *testing.T) {
+	data := pngBytes(t, 64, 32)
+
+	img, err := DefaultPolicy().Read(bytes.NewReader(data), "image/png; charset=binary")
+	require.NoError(t, err)
+	require.Equal(t, "image/png", img.ContentType)
+	require.Equal(t, 64, img.Width)
+	require.Equal(t, 32, img.Height)
+	require.Equal(t, int64(len(data)), img.Size)
+	require.Len(t, img.Checksum, 64)
+}
+
+func TestPolicy_ValidateRejections(t *testing.T) {
+	data := pngBytes(t, 10, 10)
+
+	_, err := DefaultPolicy().Validate(nil, "")
+	require.ErrorIs(t, err, types.ErrAvatarRequired)
+
+	_, err = Policy{MaxBytes: 16}.Read(bytes.NewReader(data), "")
+	require.ErrorIs(t, err, types.ErrAvatarTooLarge)
+
+	_, err = DefaultPolicy().Validate(data, "image/jpeg")
+	require.ErrorIs(t, err, types.ErrAvatarContentType)
+
+	_, err = DefaultPolicy().Validate([]byte(strings.Repeat("plain text ", 10)), "")
+	require.ErrorIs(t, err, types.ErrAvatarContentType)
+
+	_, err = Policy{AllowedContentTypes: []string{"image/jpeg"}}.Validate(data, "")
+	require.ErrorIs(t, err, types.ErrAvatarContentType)
+
+	_, err = Policy{MinWidth: 32, MinHeight: 32}.Validate(data, "")
+	require.ErrorIs(t, err, types.ErrAvatarDimensions)
+
+	_, err = Policy{MaxWidth: 8}.Validate(data, "")
+	require.ErrorIs(t, err, types.ErrAvatarDimensions)
+}
+
+func TestKey_IsDeterministic(t *testing.T) {
+	userID := uuid.New()
+	tenantID := uuid.New()
+	img, err := DefaultPolicy().Validate(pngBytes(t, 4, 4), "")
+	require.NoError(t, err)
+
+	key := Key(types.ScopeFilter{TenantID: tenantID}, userID, img)
+	require.Equal(t, "avatars/"+tenantID.String()+"/"+userID.String()+"/"+img.Checksum+".png", key)
+	require.Equal(t, key, Key(types.ScopeFilter{TenantID: tenantID}, userID, img))
+	require.Equal(t, "avatars/global/"+userID.String()+"/"+img.Checksum+".png", Key(types.ScopeFilter{}, userID, img))
+	orgID := uuid.New()
+	require.Equal(t, "avatars/"+tenantID.String()+"/orgs/"+orgID.String()+"/"+userID.String()+"/"+img.Checksum+".png",
+		Key(types.ScopeFilter{TenantID: tenantID, OrgID: orgID}, userID, img))
+}
+
+func TestOwnsKey(t *testing.T) {
+	tenantID, userID := uuid.New(), uuid.New()
+	scope := types.ScopeFilter{TenantID: tenantID}
+	img := Image{Checksum: "abc", ContentType: "image/png"}
+
+	require.True(t, OwnsKey(scope, userID, Key(scope, userID, img)))
+	require.False(t, OwnsKey(scope, uuid.New(), Key(scope, userID, img)))
+	require.False(t, OwnsKey(types.ScopeFilter{}, userID, Key(scope, userID, img)))
+	require.False(t, OwnsKey(scope, userID, "avatars/"+tenantID.String()+"/"+userID.String()+"/../other.png"))
+	require.False(t, OwnsKey(scope, userID, "avatars/"+tenantID.String()+"/"+userID.String()+"/"))
+}
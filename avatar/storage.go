@@ -0,0 +1,243 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// ErrInvalidKey indicates a blob key that is empty or escapes the storage root.
+var ErrInvalidKey = errors.New("avatar: invalid blob key")
+
+type memoryBlob struct {
+	data        []byte
+	contentType string
+}
+
+// MemoryStorage keeps blobs in process memory. It is intended for tests and
+// single-process development setups.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	blobs   map[string]memoryBlob
+	baseURL string
+}
+
+var _ types.BlobStorage = (*MemoryStorage)(nil)
+
+// NewMemoryStorage returns an empty in-memory store whose URLs are prefixed
+// with baseURL (defaults to "memory://").
+func NewMemoryStorage(baseURL string) *MemoryStorage {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = "memory://"
+	}
+	return &MemoryStorage{blobs: make(map[string]memoryBlob), baseURL: baseURL}
+}
+
+// Put implements types.BlobStorage.
+func (s *MemoryStorage) Put(_ context.Context, key, contentType string, r io.Reader) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = memoryBlob{data: data, contentType: contentType}
+	return nil
+}
+
+// Open implements types.BlobStorage.
+func (s *MemoryStorage) Open(_ context.Context, key string) (io.ReadCloser, string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blob, ok := s.blobs[key]
+	if !ok {
+		return nil, "", types.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(blob.data)), blob.contentType, nil
+}
+
+// Delete implements types.BlobStorage.
+func (s *MemoryStorage) Delete(_ context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+// URL implements types.BlobStorage.
+func (s *MemoryStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// KeyForURL implements types.BlobStorage.
+func (s *MemoryStorage) KeyForURL(url string) (string, bool) {
+	return splitURL(s.baseURL, url)
+}
+
+// Keys returns the stored keys, for tests and diagnostics.
+func (s *MemoryStorage) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.blobs))
+	for key := range s.blobs {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FileStorage stores blobs as files below a root directory. Content types
+// are derived from the key's extension when blobs are opened.
+type FileStorage struct {
+	root    string
+	baseURL string
+}
+
+var _ types.BlobStorage = (*FileStorage)(nil)
+
+// NewFileStorage returns a store rooted at root, creating the directory when
+// needed. baseURL is the public prefix the root is served under, for example
+// "https://cdn.example.com/uploads".
+func NewFileStorage(root, baseURL string) (*FileStorage, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, errors.New("avatar: storage root required")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStorage{root: root, baseURL: baseURL}, nil
+}
+
+// Put implements types.BlobStorage. Blobs are written to a temporary file and
+// renamed into place so readers never observe partial content.
+func (s *FileStorage) Put(_ context.Context, key, _ string, r io.Reader) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Open implements types.BlobStorage.
+func (s *FileStorage) Open(_ context.Context, key string) (io.ReadCloser, string, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", types.ErrBlobNotFound
+		}
+		return nil, "", err
+	}
+	return file, mime.TypeByExtension(filepath.Ext(target)), nil
+}
+
+// Delete implements types.BlobStorage.
+func (s *FileStorage) Delete(_ context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL implements types.BlobStorage.
+func (s *FileStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// KeyForURL implements types.BlobStorage.
+func (s *FileStorage) KeyForURL(url string) (string, bool) {
+	return splitURL(s.baseURL, url)
+}
+
+func (s *FileStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// cleanKey normalizes key to a relative slash separated path that stays
+// inside the storage root.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || strings.Contains(key, `\`) {
+		return "", ErrInvalidKey
+	}
+	key = path.Clean(key)
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+func joinURL(base, key string) string {
+	if base == "" {
+		return key
+	}
+	if strings.HasSuffix(base, "/") {
+		return base + key
+	}
+	return base + "/" + key
+}
+
+func splitURL(base, url string) (string, bool) {
+	if url == "" {
+		return "", false
+	}
+	prefix := base
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	key, err := cleanKey(strings.TrimPrefix(url, prefix))
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,54 @@
+package avatar
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageImplementations(t *testing.T) {
+	fileStore, err := NewFileStorage(t.TempDir(), "https://cdn.example.com/uploads")
+	require.NoError(t, err)
+
+	stores := map[string]types.BlobStorage{
+		"memory": NewMemoryStorage("https://cdn.example.com/uploads/"),
+		"file":   fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "avatars/global/user/abc.png"
+
+			require.NoError(t, store.Put(ctx, key, "image/png", strings.NewReader("first")))
+			require.NoError(t, store.Put(ctx, key, "image/png", strings.NewReader("second")))
+
+			rc, contentType, err := store.Open(ctx, key)
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			require.Equal(t, "second", string(data))
+			require.Equal(t, "image/png", contentType)
+
+			url := store.URL(key)
+			require.Equal(t, "https://cdn.example.com/uploads/"+key, url)
+			got, ok := store.KeyForURL(url)
+			require.True(t, ok)
+			require.Equal(t, key, got)
+			_, ok = store.KeyForURL("https://elsewhere.example.com/" + key)
+			require.False(t, ok)
+
+			require.NoError(t, store.Delete(ctx, key))
+			require.NoError(t, store.Delete(ctx, key))
+			_, _, err = store.Open(ctx, key)
+			require.ErrorIs(t, err, types.ErrBlobNotFound)
+
+			require.ErrorIs(t, store.Put(ctx, "../escape.png", "image/png", strings.NewReader("x")), ErrInvalidKey)
+			require.ErrorIs(t, store.Put(ctx, "/abs.png", "image/png", strings.NewReader("x")), ErrInvalidKey)
+		})
+	}
+}
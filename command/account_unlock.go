@@ -0,0 +1,182 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// UserUnlockInput lifts an account lock recorded by failed sign-ins.
+type UserUnlockInput struct {
+	UserID uuid.UUID
+	Actor  types.ActorRef
+	Scope  types.ScopeFilter
+	Reason string
+	Result *types.AuthUser
+}
+
+// Type implements gocommand.Message.
+func (UserUnlockInput) Type() string {
+	return "command.user.unlock"
+}
+
+// Validate implements gocommand.Message.
+func (input UserUnlockInput) Validate() error {
+	switch {
+	case input.UserID == uuid.Nil:
+		return ErrUserIDRequired
+	case input.Actor.ID == uuid.Nil:
+		return ErrActorRequired
+	default:
+		return nil
+	}
+}
+
+// UserUnlockCommand returns a locked account to active, clears its lock
+// metadata, and resets the failure counters for its identifiers.
+type UserUnlockCommand struct {
+	repo      types.AuthRepository
+	lifecycle *UserLifecycleTransitionCommand
+	attempts  types.LoginAttemptStore
+	clock     types.Clock
+	sink      types.ActivitySink
+	hooks     types.Hooks
+	logger    types.Logger
+	guard     scope.Guard
+}
+
+// UserUnlockCommandConfig wires the unlock command.
+type UserUnlockCommandConfig struct {
+	Repository types.AuthRepository
+	// Lifecycle performs the transition back to active. When nil one is built
+	// from the remaining fields.
+	Lifecycle *UserLifecycleTransitionCommand
+	// Attempts, when set, has the user's identifier counters reset.
+	Attempts   types.LoginAttemptStore
+	Clock      types.Clock
+	Activity   types.ActivitySink
+	Hooks      types.Hooks
+	Logger     types.Logger
+	ScopeGuard scope.Guard
+}
+
+// NewUserUnlockCommand constructs the unlock handler.
+func NewUserUnlockCommand(cfg UserUnlockCommandConfig) *UserUnlockCommand {
+	lifecycle := cfg.Lifecycle
+	if lifecycle == nil {
+		lifecycle = NewUserLifecycleTransitionCommand(LifecycleCommandConfig{
+			Repository: cfg.Repository,
+			Clock:      cfg.Clock,
+			Logger:     cfg.Logger,
+			Hooks:      cfg.Hooks,
+			Activity:   cfg.Activity,
+			ScopeGuard: cfg.ScopeGuard,
+		})
+	}
+	return &UserUnlockCommand{
+		repo:      cfg.Repository,
+		lifecycle: lifecycle,
+		attempts:  cfg.Attempts,
+		clock:     safeClock(cfg.Clock),
+		sink:      safeActivitySink(cfg.Activity),
+		hooks:     safeHooks(cfg.Hooks),
+		logger:    safeLogger(cfg.Logger),
+		guard:     safeScopeGuard(cfg.ScopeGuard),
+	}
+}
+
+var _ gocommand.Commander[UserUnlockInput] = (*UserUnlockCommand)(nil)
+
+// Execute unlocks the account. Users without lock metadata are rejected with
+// ErrAccountNotLocked so manual suspensions are left alone.
+func (c *UserUnlockCommand) Execute(ctx context.Context, input UserUnlockInput) error {
+	if c == nil || c.repo == nil {
+		return types.ErrMissingAuthRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionUsersWrite, input.UserID)
+	if err != nil {
+		return err
+	}
+	user, err := c.repo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	lockedUntil, locked := types.AccountLockedUntil(user.Metadata)
+	if !locked {
+		return ErrAccountNotLocked
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		reason = "account_unlocked"
+	}
+	if user.Status != types.LifecycleStateActive {
+		if err := c.lifecycle.Execute(ctx, UserLifecycleTransitionInput{
+			UserID: input.UserID,
+			Target: types.LifecycleStateActive,
+			Actor:  input.Actor,
+			Reason: reason,
+			Scope:  input.Scope,
+		}); err != nil {
+			return err
+		}
+		if user, err = c.repo.GetByID(ctx, input.UserID); err != nil {
+			return err
+		}
+	}
+	user.Metadata = types.ClearAccountLockedMetadata(user.Metadata)
+	updated, err := c.repo.Update(ctx, user)
+	if err != nil {
+		return err
+	}
+	c.resetAttempts(ctx, updated)
+
+	data := map[string]any{
+		"reason": reason,
+	}
+	if !lockedUntil.IsZero() {
+		data["locked_until"] = lockedUntil
+	}
+	record := types.ActivityRecord{
+		UserID:     updated.ID,
+		ActorID:    input.Actor.ID,
+		Verb:       "user.account.unlocked",
+		ObjectType: "user",
+		ObjectID:   updated.ID.String(),
+		Channel:    "auth",
+		TenantID:   scope.TenantID,
+		OrgID:      scope.OrgID,
+		Data:       data,
+		OccurredAt: now(c.clock),
+	}
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+
+	if input.Result != nil {
+		*input.Result = *updated
+	}
+	return nil
+}
+
+func (c *UserUnlockCommand) resetAttempts(ctx context.Context, user *types.AuthUser) {
+	if c.attempts == nil {
+		return
+	}
+	for _, identifier := range []string{user.Email, user.Username} {
+		if strings.TrimSpace(identifier) == "" {
+			continue
+		}
+		if err := c.attempts.ResetFailures(ctx, types.LoginAttemptIdentifierKey(identifier)); err != nil {
+			c.logger.Error("login attempt reset failed", err, "user_id", user.ID)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserUnlockCommand_RestoresLockedAccount(t *testing.T) {
+	userID := uuid.New()
+	lockedAt := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{
+		ID:       userID,
+		Email:    "user@example.com",
+		Status:   types.LifecycleStateSuspended,
+		Metadata: types.MarkAccountLockedMetadata(map[string]any{"plan": "pro"}, lockedAt, lockedAt.Add(time.Hour)),
+	}
+	attempts := &resettingAttemptStore{}
+	sink := &recordingActivitySink{}
+	cmd := NewUserUnlockCommand(UserUnlockCommandConfig{
+		Repository: repo,
+		Attempts:   attempts,
+		Clock:      fixedClock{t: lockedAt.Add(time.Minute)},
+		Activity:   sink,
+	})
+
+	var result types.AuthUser
+	err := cmd.Execute(context.Background(), UserUnlockInput{
+		UserID: userID,
+		Actor:  types.ActorRef{ID: uuid.New(), Type: "admin"},
+		Result: &result,
+	})
+	require.NoError(t, err)
+	require.Equal(t, types.LifecycleStateActive, result.Status)
+	require.Equal(t, map[string]any{"plan": "pro"}, result.Metadata)
+	require.Equal(t, "account_unlocked", repo.lastTransitionReason)
+	require.Equal(t, []string{types.LoginAttemptIdentifierKey("user@example.com")}, attempts.reset)
+	require.NotEmpty(t, sink.records)
+	require.Equal(t, "user.account.unlocked", sink.records[len(sink.records)-1].Verb)
+}
+
+func TestUserUnlockCommand_RejectsAccountWithoutLock(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Status: types.LifecycleStateSuspended}
+	cmd := NewUserUnlockCommand(UserUnlockCommandConfig{Repository: repo})
+
+	err := cmd.Execute(context.Background(), UserUnlockInput{
+		UserID: userID,
+		Actor:  types.ActorRef{ID: uuid.New()},
+	})
+	require.ErrorIs(t, err, ErrAccountNotLocked)
+	require.False(t, repo.transitionCalled)
+}
+
+type resettingAttemptStore struct {
+	reset []string
+}
+
+func (s *resettingAttemptStore) RecordFailure(context.Context, string, time.Time, time.Duration) (int, error) {
+	return 0, nil
+}
+
+func (s *resettingAttemptStore) CountFailures(context.Context, string, time.Time) (int, error) {
+	return 0, nil
+}
+
+func (s *resettingAttemptStore) ResetFailures(_ context.Context, key string) error {
+	s.reset = append(s.reset, key)
+	return nil
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/goliatone/go-auth"
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/authctx"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// ActivityExportInput selects the activity extract to stream to Writer.
+type ActivityExportInput struct {
+	Actor  types.ActorRef
+	Scope  types.ScopeFilter
+	UserID uuid.UUID
+	Verbs  []string
+	Since  *time.Time
+	Until  *time.Time
+	Format activity.ExportFormat
+	// Bundle wraps the records in a verifiable zip (manifest + checksums).
+	Bundle bool
+	Writer io.Writer
+	Result *ActivityExportResult
+}
+
+// ActivityExportResult summarizes a completed export.
+type ActivityExportResult struct {
+	Records  int
+	Format   activity.ExportFormat
+	Manifest *activity.AuditBundleManifest
+}
+
+// Type implements gocommand.Message.
+func (ActivityExportInput) Type() string {
+	return "command.activity.export"
+}
+
+// Validate implements gocommand.Message.
+func (input ActivityExportInput) Validate() error {
+	switch {
+	case input.Actor.ID == uuid.Nil:
+		return ErrActorRequired
+	case input.Writer == nil:
+		return ErrExportWriterRequired
+	case input.Since != nil && input.Until != nil && input.Until.Before(*input.Since):
+		return ErrExportRangeInvalid
+	default:
+		return nil
+	}
+}
+
+// ActivityExportCommand streams activity extracts for auditors.
+type ActivityExportCommand struct {
+	repo      types.ActivityRepository
+	guard     scope.Guard
+	policy    activity.ActivityAccessPolicy
+	sink      types.ActivitySink
+	hooks     types.Hooks
+	clock     types.Clock
+	batchSize int
+}
+
+// ActivityExportConfig wires dependencies for the export command.
+type ActivityExportConfig struct {
+	Repository types.ActivityRepository
+	ScopeGuard scope.Guard
+	// Policy restricts and sanitizes exported records the same way it does
+	// for the activity feed; pass the policy given to the feed query. When
+	// nil, record data is dropped from the extract.
+	Policy activity.ActivityAccessPolicy
+	// Activity records an activity.exported entry after each export.
+	Activity  types.ActivitySink
+	Hooks     types.Hooks
+	Clock     types.Clock
+	BatchSize int
+}
+
+// NewActivityExportCommand constructs the export handler.
+func NewActivityExportCommand(cfg ActivityExportConfig) *ActivityExportCommand {
+	return &ActivityExportCommand{
+		repo:      cfg.Repository,
+		guard:     safeScopeGuard(cfg.ScopeGuard),
+		policy:    cfg.Policy,
+		sink:      safeActivitySink(cfg.Activity),
+		hooks:     safeHooks(cfg.Hooks),
+		clock:     safeClock(cfg.Clock),
+		batchSize: cfg.BatchSize,
+	}
+}
+
+var _ gocommand.Commander[ActivityExportInput] = (*ActivityExportCommand)(nil)
+
+// Execute streams matching records to the input writer.
+func (c *ActivityExportCommand) Execute(ctx context.Context, input ActivityExportInput) error {
+	if c == nil || c.repo == nil {
+		return types.ErrMissingActivityRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	filter := types.ActivityFilter{
+		Actor:  input.Actor,
+		Scope:  input.Scope,
+		UserID: input.UserID,
+		Verbs:  append([]string(nil), input.Verbs...),
+		Since:  input.Since,
+		Until:  input.Until,
+	}
+	var actorCtx *auth.ActorContext
+	if c.policy != nil {
+		var err error
+		actorCtx, err = authctx.ResolveActorContext(ctx)
+		if err != nil {
+			return err
+		}
+		filter, err = c.policy.Apply(actorCtx, "", filter)
+		if err != nil {
+			return err
+		}
+	}
+	scopeFilter, err := c.guard.Enforce(ctx, filter.Actor, filter.Scope, types.PolicyActionActivityRead, filter.UserID)
+	if err != nil {
+		return err
+	}
+	filter.Scope = scopeFilter
+
+	format := input.Format
+	if format == "" {
+		format = activity.ExportFormatJSONL
+	}
+
+	var (
+		writer activity.ActivityExportWriter
+		bundle *activity.AuditBundleWriter
+	)
+	if input.Bundle {
+		bundle, err = activity.NewAuditBundleWriter(input.Writer, format, activity.AuditBundleManifest{
+			GeneratedAt: now(c.clock),
+			GeneratedBy: input.Actor.ID.String(),
+			Filter:      activity.AuditBundleFilterFrom(filter),
+		})
+		writer = bundle
+	} else {
+		writer, err = activity.NewActivityExportWriter(format, input.Writer)
+	}
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = activity.StreamActivity(ctx, c.repo, filter, c.batchSize, func(record types.ActivityRecord) error {
+		record, ok := c.sanitize(actorCtx, record)
+		if !ok {
+			return nil
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	result := ActivityExportResult{Records: count, Format: format}
+	if bundle != nil {
+		manifest := bundle.Manifest()
+		result.Manifest = &manifest
+	}
+	if input.Result != nil {
+		*input.Result = result
+	}
+
+	record := types.ActivityRecord{
+		UserID:     filter.UserID,
+		ActorID:    input.Actor.ID,
+		Verb:       "activity.exported",
+		ObjectType: "activity",
+		Channel:    "audit",
+		TenantID:   scopeFilter.TenantID,
+		OrgID:      scopeFilter.OrgID,
+		Data: map[string]any{
+			"format":  string(format),
+			"records": count,
+			"bundle":  input.Bundle,
+		},
+		OccurredAt: now(c.clock),
+	}
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+	return nil
+}
+
+// sanitize runs record through the access policy. It reports false when the
+// policy withholds the record.
+func (c *ActivityExportCommand) sanitize(actor *auth.ActorContext, record types.ActivityRecord) (types.ActivityRecord, bool) {
+	if c.policy == nil {
+		record.Data = nil
+		return record, true
+	}
+	out := c.policy.Sanitize(actor, "", []types.ActivityRecord{record})
+	if len(out) == 0 {
+		return types.ActivityRecord{}, false
+	}
+	return out[0], true
+}
@@ -0,0 +1,179 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-auth"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityExportCommand_StreamsJSONLThroughAccessPolicy(t *testing.T) {
+	userID := uuid.New()
+	repo := &pagedActivityRepo{}
+	for i := range 5 {
+		repo.records = append(repo.records, types.ActivityRecord{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Verb:       "user.updated",
+			ObjectType: "user",
+			IP:         "203.0.113.7",
+			Data:       map[string]any{"index": i, "password": "secret"},
+		})
+	}
+	sink := &recordingActivitySink{}
+	cmd := NewActivityExportCommand(ActivityExportConfig{
+		Repository: repo,
+		Policy:     activity.NewDefaultAccessPolicy(activity.WithMetadataExposure(activity.MetadataExposeSanitized)),
+		Activity:   sink,
+		BatchSize:  2,
+	})
+
+	actorID := uuid.New()
+	ctx := auth.WithActorContext(context.Background(), &auth.ActorContext{
+		ActorID: actorID.String(),
+		Role:    types.ActorRoleTenantAdmin,
+	})
+	var out bytes.Buffer
+	var result ActivityExportResult
+	err := cmd.Execute(ctx, ActivityExportInput{
+		Actor:  types.ActorRef{ID: actorID},
+		UserID: userID,
+		Writer: &out,
+		Result: &result,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Records)
+	require.Equal(t, activity.ExportFormatJSONL, result.Format)
+	require.Nil(t, result.Manifest)
+	require.Len(t, strings.Split(strings.TrimSpace(out.String()), "\n"), 5)
+	require.NotContains(t, out.String(), "secret")
+	require.NotContains(t, out.String(), "203.0.113.7")
+	require.Equal(t, userID, repo.lastFilter.UserID)
+
+	require.Len(t, sink.records, 1)
+	require.Equal(t, "activity.exported", sink.records[0].Verb)
+	require.Equal(t, 5, sink.records[0].Data["records"])
+}
+
+func TestActivityExportCommand_PolicyRestrictsNonAdminToSelf(t *testing.T) {
+	actorID := uuid.New()
+	tenantID := uuid.New()
+	repo := &pagedActivityRepo{}
+	cmd := NewActivityExportCommand(ActivityExportConfig{
+		Repository: repo,
+		Policy:     activity.NewDefaultAccessPolicy(),
+	})
+
+	ctx := auth.WithActorContext(context.Background(), &auth.ActorContext{
+		ActorID:  actorID.String(),
+		Role:     types.ActorRoleSupport,
+		TenantID: tenantID.String(),
+	})
+	err := cmd.Execute(ctx, ActivityExportInput{
+		Actor:  types.ActorRef{ID: actorID},
+		UserID: uuid.New(),
+		Writer: &bytes.Buffer{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, actorID, repo.lastFilter.UserID)
+	require.Equal(t, tenantID, repo.lastFilter.Scope.TenantID)
+
+	err = cmd.Execute(context.Background(), ActivityExportInput{
+		Actor:  types.ActorRef{ID: actorID},
+		Writer: &bytes.Buffer{},
+	})
+	require.Error(t, err)
+}
+
+func TestActivityExportCommand_DropsDataWithoutPolicy(t *testing.T) {
+	repo := &pagedActivityRepo{records: []types.ActivityRecord{
+		{ID: uuid.New(), Verb: "user.updated", ObjectType: "user", Data: map[string]any{"note": "internal"}},
+	}}
+	cmd := NewActivityExportCommand(ActivityExportConfig{Repository: repo})
+
+	var out bytes.Buffer
+	require.NoError(t, cmd.Execute(context.Background(), ActivityExportInput{
+		Actor:  types.ActorRef{ID: uuid.New()},
+		Writer: &out,
+	}))
+	require.NotContains(t, out.String(), "internal")
+}
+
+func TestActivityExportCommand_BundleVerifies(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &pagedActivityRepo{records: []types.ActivityRecord{
+		{ID: uuid.New(), Verb: "role.assigned", ObjectType: "role", Data: map[string]any{"role": "admin"}},
+	}}
+	cmd := NewActivityExportCommand(ActivityExportConfig{Repository: repo, Clock: fixedClock{since}})
+
+	var out bytes.Buffer
+	var result ActivityExportResult
+	err := cmd.Execute(context.Background(), ActivityExportInput{
+		Actor:  types.ActorRef{ID: uuid.New()},
+		Since:  &since,
+		Format: activity.ExportFormatCSV,
+		Bundle: true,
+		Writer: &out,
+		Result: &result,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Manifest)
+
+	manifest, err := activity.VerifyAuditBundle(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	require.Equal(t, 1, manifest.RecordCount)
+	require.Equal(t, activity.ExportFormatCSV, manifest.Format)
+	require.Equal(t, since, manifest.GeneratedAt)
+	require.NotNil(t, manifest.Filter.Since)
+}
+
+func TestActivityExportCommand_Validation(t *testing.T) {
+	cmd := NewActivityExportCommand(ActivityExportConfig{Repository: &pagedActivityRepo{}})
+	err := cmd.Execute(context.Background(), ActivityExportInput{Actor: types.ActorRef{ID: uuid.New()}})
+	require.ErrorIs(t, err, ErrExportWriterRequired)
+
+	since := time.Now()
+	until := since.Add(-time.Hour)
+	err = cmd.Execute(context.Background(), ActivityExportInput{
+		Actor:  types.ActorRef{ID: uuid.New()},
+		Writer: &bytes.Buffer{},
+		Since:  &since,
+		Until:  &until,
+	})
+	require.ErrorIs(t, err, ErrExportRangeInvalid)
+
+	err = cmd.Execute(context.Background(), ActivityExportInput{
+		Actor:  types.ActorRef{ID: uuid.New()},
+		Writer: &bytes.Buffer{},
+		Format: "xml",
+	})
+	require.ErrorIs(t, err, activity.ErrUnsupportedExportFormat)
+}
+
+// pagedActivityRepo serves records with offset pagination only.
+type pagedActivityRepo struct {
+	records    []types.ActivityRecord
+	lastFilter types.ActivityFilter
+}
+
+func (r *pagedActivityRepo) ListActivity(_ context.Context, filter types.ActivityFilter) (types.ActivityPage, error) {
+	r.lastFilter = filter
+	start := min(filter.Pagination.Offset, len(r.records))
+	end := min(start+filter.Pagination.Limit, len(r.records))
+	return types.ActivityPage{
+		Records: append([]types.ActivityRecord(nil), r.records[start:end]...),
+		Total:   len(r.records),
+		HasMore: end < len(r.records),
+	}, nil
+}
+
+func (r *pagedActivityRepo) ActivityStats(context.Context, types.ActivityStatsFilter) (types.ActivityStats, error) {
+	return types.ActivityStats{}, nil
+}
@@ -0,0 +1,190 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/avatar"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// AvatarCommandConfig wires dependencies for the avatar upload command.
+type AvatarCommandConfig struct {
+	Repository types.ProfileRepository
+	Storage    types.BlobStorage
+	// Policy validates uploads; the zero value applies avatar.DefaultMaxBytes
+	// and avatar.DefaultContentTypes without dimension bounds.
+	Policy     avatar.Policy
+	Activity   types.ActivitySink
+	Hooks      types.Hooks
+	Clock      types.Clock
+	ScopeGuard scope.Guard
+}
+
+// AvatarUploadInput carries an avatar image for a user. ContentType is the
+// client supplied type and, when set, must match the image content.
+type AvatarUploadInput struct {
+	UserID      uuid.UUID
+	Image       io.Reader
+	ContentType string
+	Scope       types.ScopeFilter
+	Actor       types.ActorRef
+	Result      *types.UserProfile
+}
+
+// Type implements gocommand.Message.
+func (AvatarUploadInput) Type() string {
+	return "command.profile.avatar.upload"
+}
+
+// Validate implements gocommand.Message.
+func (input AvatarUploadInput) Validate() error {
+	if input.UserID == uuid.Nil {
+		return types.ErrUserIDRequired
+	}
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	if input.Image == nil {
+		return types.ErrAvatarRequired
+	}
+	return nil
+}
+
+// AvatarUploadCommand stores avatar images and points the profile at them.
+type AvatarUploadCommand struct {
+	repo    types.ProfileRepository
+	storage types.BlobStorage
+	policy  avatar.Policy
+	sink    types.ActivitySink
+	hooks   types.Hooks
+	clock   types.Clock
+	guard   scope.Guard
+}
+
+// NewAvatarUploadCommand constructs the avatar upload handler.
+func NewAvatarUploadCommand(cfg AvatarCommandConfig) *AvatarUploadCommand {
+	return &AvatarUploadCommand{
+		repo:    cfg.Repository,
+		storage: cfg.Storage,
+		policy:  cfg.Policy,
+		sink:    safeActivitySink(cfg.Activity),
+		hooks:   safeHooks(cfg.Hooks),
+		clock:   safeClock(cfg.Clock),
+		guard:   safeScopeGuard(cfg.ScopeGuard),
+	}
+}
+
+var _ gocommand.Commander[AvatarUploadInput] = (*AvatarUploadCommand)(nil)
+
+// Execute validates the image, stores it under its deterministic key, updates
+// the profile's AvatarURL, and removes the previous avatar blob. Failing to
+// remove the previous blob does not fail the upload; it is recorded on the
+// activity record instead.
+func (c *AvatarUploadCommand) Execute(ctx context.Context, input AvatarUploadInput) error {
+	if c.repo == nil {
+		return types.ErrMissingProfileRepository
+	}
+	if c.storage == nil {
+		return types.ErrMissingAvatarStorage
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionProfilesWrite, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	img, err := c.policy.Read(input.Image, input.ContentType)
+	if err != nil {
+		return err
+	}
+
+	draft, err := loadProfileDraft(ctx, c.repo, input.UserID, scope)
+	if err != nil {
+		return err
+	}
+	profile := draft.profile
+	before := draft.effective
+	// Only the avatar stored for this scope is replaced; a fallback avatar
+	// still belongs to the broader profile. The stored URL is user supplied,
+	// so the blob is only removed when it sits under this user's own prefix.
+	var previousKey string
+	var ownsPrevious bool
+	if draft.stored != nil {
+		previousKey, ownsPrevious = c.storage.KeyForURL(draft.stored.AvatarURL)
+		ownsPrevious = ownsPrevious && avatar.OwnsKey(scope, input.UserID, previousKey)
+	}
+
+	key := avatar.Key(scope, input.UserID, img)
+	if err := c.storage.Put(ctx, key, img.ContentType, bytes.NewReader(img.Data)); err != nil {
+		return err
+	}
+
+	if profile.CreatedBy == uuid.Nil {
+		profile.CreatedBy = input.Actor.ID
+	}
+	profile.UpdatedBy = input.Actor.ID
+	profile.AvatarURL = c.storage.URL(key)
+	updated, err := c.repo.UpsertProfile(ctx, *profile)
+	if err != nil {
+		if !ownsPrevious || previousKey != key {
+			_ = c.storage.Delete(ctx, key)
+		}
+		return err
+	}
+	if updated == nil {
+		updated = profile
+	}
+	eventProfile := draft.resolve(*updated)
+	if input.Result != nil {
+		*input.Result = eventProfile
+	}
+
+	data := map[string]any{
+		"key":          key,
+		"content_type": img.ContentType,
+		"size":         img.Size,
+		"width":        img.Width,
+		"height":       img.Height,
+	}
+	if ownsPrevious && previousKey != key {
+		data["previous_key"] = previousKey
+		if err := c.storage.Delete(ctx, previousKey); err != nil {
+			data["cleanup_error"] = err.Error()
+		}
+	}
+
+	eventTime := now(c.clock)
+	emitProfileHook(ctx, c.hooks, types.ProfileEvent{
+		UserID:     input.UserID,
+		Scope:      scope,
+		ActorID:    input.Actor.ID,
+		OccurredAt: eventTime,
+		Profile:    eventProfile,
+	})
+
+	record := types.ActivityRecord{
+		UserID:     input.UserID,
+		ActorID:    input.Actor.ID,
+		Verb:       "profile.avatar.updated",
+		ObjectType: "profile",
+		ObjectID:   input.UserID.String(),
+		Channel:    "profile",
+		TenantID:   scope.TenantID,
+		OrgID:      scope.OrgID,
+		Data:       data,
+		OccurredAt: eventTime,
+	}
+	record = activity.AttachChanges(record, nil, activity.DiffUserProfile(before, &eventProfile))
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+	return nil
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/goliatone/go-users/avatar"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func avatarPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestAvatarUploadCommand_ReplacesPreviousBlob(t *testing.T) {
+	ctx := context.Background()
+	storage := avatar.NewMemoryStorage("https://cdn.example.com")
+	repo := &fakeProfileRepo{}
+	sink := &recordingActivitySink{}
+	var events []types.ProfileEvent
+	cmd := NewAvatarUploadCommand(AvatarCommandConfig{
+		Repository: repo,
+		Storage:    storage,
+		Activity:   sink,
+		Hooks: types.Hooks{
+			AfterProfileChange: func(_ context.Context, e types.ProfileEvent) {
+				events = append(events, e)
+			},
+		},
+	})
+	userID := uuid.New()
+	actor := types.ActorRef{ID: userID}
+
+	var first types.UserProfile
+	require.NoError(t, cmd.Execute(ctx, AvatarUploadInput{
+		UserID:      userID,
+		Image:       bytes.NewReader(avatarPNG(t, 8, 8)),
+		ContentType: "image/png",
+		Actor:       actor,
+		Result:      &first,
+	}))
+	firstKey, ok := storage.KeyForURL(first.AvatarURL)
+	require.True(t, ok)
+	require.Equal(t, []string{firstKey}, storage.Keys())
+
+	var second types.UserProfile
+	require.NoError(t, cmd.Execute(ctx, AvatarUploadInput{
+		UserID: userID,
+		Image:  bytes.NewReader(avatarPNG(t, 16, 16)),
+		Actor:  actor,
+		Result: &second,
+	}))
+	secondKey, ok := storage.KeyForURL(second.AvatarURL)
+	require.True(t, ok)
+	require.NotEqual(t, firstKey, secondKey)
+	require.Equal(t, []string{secondKey}, storage.Keys())
+	require.Equal(t, second.AvatarURL, repo.stored.AvatarURL)
+
+	require.Len(t, events, 2)
+	require.Equal(t, second.AvatarURL, events[1].Profile.AvatarURL)
+	require.Len(t, sink.records, 2)
+	record := sink.records[1]
+	require.Equal(t, "profile.avatar.updated", record.Verb)
+	require.Equal(t, secondKey, record.Data["key"])
+	require.Equal(t, firstKey, record.Data["previous_key"])
+	require.Equal(t, 16, record.Data["width"])
+	require.NotContains(t, record.Data, "cleanup_error")
+}
+
+func TestAvatarUploadCommand_KeepsBlobOwnedByAnotherUser(t *testing.T) {
+	ctx := context.Background()
+	storage := avatar.NewMemoryStorage("https://cdn.example.com")
+	otherKey := "avatars/global/" + uuid.NewString() + "/victim.png"
+	require.NoError(t, storage.Put(ctx, otherKey, "image/png", bytes.NewReader(avatarPNG(t, 8, 8))))
+
+	userID := uuid.New()
+	repo := &fakeProfileRepo{stored: &types.UserProfile{
+		UserID:    userID,
+		AvatarURL: storage.URL(otherKey),
+	}}
+	cmd := NewAvatarUploadCommand(AvatarCommandConfig{
+		Repository: repo,
+		Storage:    storage,
+	})
+
+	var result types.UserProfile
+	require.NoError(t, cmd.Execute(ctx, AvatarUploadInput{
+		UserID: userID,
+		Image:  bytes.NewReader(avatarPNG(t, 16, 16)),
+		Actor:  types.ActorRef{ID: userID},
+		Result: &result,
+	}))
+	newKey, ok := storage.KeyForURL(result.AvatarURL)
+	require.True(t, ok)
+	require.ElementsMatch(t, []string{otherKey, newKey}, storage.Keys())
+}
+
+func TestAvatarUploadCommand_RejectsInvalidImages(t *testing.T) {
+	storage := avatar.NewMemoryStorage("")
+	cmd := NewAvatarUploadCommand(AvatarCommandConfig{
+		Repository: &fakeProfileRepo{},
+		Storage:    storage,
+		Policy:     avatar.Policy{MinWidth: 32},
+	})
+
+	err := cmd.Execute(context.Background(), AvatarUploadInput{
+		UserID: uuid.New(),
+		Image:  bytes.NewReader(avatarPNG(t, 8, 8)),
+		Actor:  types.ActorRef{ID: uuid.New()},
+	})
+	require.ErrorIs(t, err, types.ErrAvatarDimensions)
+	require.Empty(t, storage.Keys())
+
+	err = NewAvatarUploadCommand(AvatarCommandConfig{Repository: &fakeProfileRepo{}}).Execute(context.Background(), AvatarUploadInput{})
+	require.ErrorIs(t, err, types.ErrMissingAvatarStorage)
+}
+
+type failingProfileRepo struct {
+	fakeProfileRepo
+}
+
+func (failingProfileRepo) UpsertProfile(context.Context, types.UserProfile) (*types.UserProfile, error) {
+	return nil, errors.New("write failed")
+}
+
+func TestAvatarUploadCommand_RemovesBlobWhenProfileUpdateFails(t *testing.T) {
+	storage := avatar.NewMemoryStorage("")
+	cmd := NewAvatarUploadCommand(AvatarCommandConfig{
+		Repository: &failingProfileRepo{},
+		Storage:    storage,
+	})
+
+	err := cmd.Execute(context.Background(), AvatarUploadInput{
+		UserID: uuid.New(),
+		Image:  bytes.NewReader(avatarPNG(t, 8, 8)),
+		Actor:  types.ActorRef{ID: uuid.New()},
+	})
+	require.Error(t, err)
+	require.Empty(t, storage.Keys())
+}
@@ -14,11 +14,13 @@ import (
 
 const DefaultTemporaryPasswordTTL = 24 * time.Hour
 
-// UserBootstrapPasswordInput creates or refreshes a bootstrap user with a temporary password.
+// UserBootstrapPasswordInput creates or refreshes a bootstrap user with a
+// temporary password. Supply either PasswordHash or a plaintext Password.
 type UserBootstrapPasswordInput struct {
 	User         *types.AuthUser
 	Identifier   string
 	PasswordHash string
+	Password     string
 	TTL          time.Duration
 	Actor        types.ActorRef
 	Scope        types.ScopeFilter
@@ -39,8 +41,10 @@ func (input UserBootstrapPasswordInput) Validate() error {
 		return ErrUserEmailRequired
 	case input.Actor.ID == uuid.Nil:
 		return ErrActorRequired
-	case strings.TrimSpace(input.PasswordHash) == "":
+	case strings.TrimSpace(input.PasswordHash) == "" && input.Password == "":
 		return ErrPasswordHashRequired
+	case strings.TrimSpace(input.PasswordHash) != "" && input.Password != "":
+		return ErrPasswordInputConflict
 	default:
 		return nil
 	}
@@ -55,10 +59,11 @@ type UserBootstrapPasswordResult struct {
 
 // UserBootstrapPasswordCommand composes create/reset commands for instance bootstrap users.
 type UserBootstrapPasswordCommand struct {
-	repo   types.AuthRepository
-	create *UserCreateCommand
-	reset  *UserPasswordResetCommand
-	clock  types.Clock
+	repo      types.AuthRepository
+	create    *UserCreateCommand
+	reset     *UserPasswordResetCommand
+	clock     types.Clock
+	passwords types.PasswordService
 }
 
 // BootstrapPasswordCommandConfig wires the bootstrap password command.
@@ -71,6 +76,9 @@ type BootstrapPasswordCommandConfig struct {
 	Hooks      types.Hooks
 	Logger     types.Logger
 	ScopeGuard scope.Guard
+	// Passwords checks and hashes plaintext Password inputs before the user
+	// is created.
+	Passwords types.PasswordService
 }
 
 // NewUserBootstrapPasswordCommand constructs the bootstrap handler.
@@ -99,13 +107,15 @@ func NewUserBootstrapPasswordCommand(cfg BootstrapPasswordCommandConfig) *UserBo
 			Hooks:      cfg.Hooks,
 			Logger:     cfg.Logger,
 			ScopeGuard: cfg.ScopeGuard,
+			Passwords:  cfg.Passwords,
 		})
 	}
 	return &UserBootstrapPasswordCommand{
-		repo:   cfg.Repository,
-		create: create,
-		reset:  reset,
-		clock:  clock,
+		repo:      cfg.Repository,
+		create:    create,
+		reset:     reset,
+		clock:     clock,
+		passwords: cfg.Passwords,
 	}
 }
 
@@ -117,6 +127,11 @@ func (c *UserBootstrapPasswordCommand) Execute(ctx context.Context, input UserBo
 	if err := input.Validate(); err != nil {
 		return err
 	}
+	passwordHash, err := c.passwordHash(ctx, input)
+	if err != nil {
+		return err
+	}
+	input.PasswordHash = passwordHash
 	ttl := input.TTL
 	if ttl <= 0 {
 		ttl = DefaultTemporaryPasswordTTL
@@ -155,6 +170,18 @@ func (c *UserBootstrapPasswordCommand) Execute(ctx context.Context, input UserBo
 	return nil
 }
 
+// passwordHash checks and hashes a plaintext Password against the bootstrap
+// user so policy violations surface before the user is created.
+func (c *UserBootstrapPasswordCommand) passwordHash(ctx context.Context, input UserBootstrapPasswordInput) (string, error) {
+	if input.Password == "" {
+		return strings.TrimSpace(input.PasswordHash), nil
+	}
+	if c.passwords == nil {
+		return "", types.ErrMissingPasswordService
+	}
+	return c.passwords.HashPassword(ctx, input.Password, input.User)
+}
+
 func (c *UserBootstrapPasswordCommand) createBootstrapUser(ctx context.Context, input UserBootstrapPasswordInput, issuedAt, expiresAt time.Time) (*types.AuthUser, error) {
 	user := *input.User
 	result := &types.AuthUser{}
@@ -9,6 +9,7 @@ import (
 
 	featuregate "github.com/goliatone/go-featuregate/gate"
 	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/activity"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -959,6 +960,11 @@ func TestUserUpdateCommand_LogsActivity(t *testing.T) {
 	require.Equal(t, "after@example.com", result.Email)
 	require.Equal(t, "user.updated", recorded.Verb)
 	require.Equal(t, userID, recorded.UserID)
+	changes := activity.ChangesFromData(recorded.Data)
+	require.Len(t, changes, 1)
+	require.Equal(t, "email", changes[0].Field)
+	require.Equal(t, "before@example.com", changes[0].Before)
+	require.Equal(t, "after@example.com", changes[0].After)
 }
 
 func TestUserUpdateCommand_EnforcesLifecyclePolicy(t *testing.T) {
@@ -1045,6 +1051,72 @@ func TestProfileUpsertCommand_EmitsHook(t *testing.T) {
 	require.Equal(t, "New Name", event.Profile.DisplayName)
 }
 
+func TestProfileUpsertCommand_ExpectedVersion(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeProfileRepo{stored: &types.UserProfile{UserID: userID, DisplayName: "Current", Version: 2}}
+	cmd := NewProfileUpsertCommand(ProfileCommandConfig{Repository: repo})
+	display := "Next"
+
+	stale := 1
+	err := cmd.Execute(context.Background(), ProfileUpsertInput{
+		UserID:          userID,
+		Patch:           types.ProfilePatch{DisplayName: &display},
+		Actor:           types.ActorRef{ID: uuid.New()},
+		ExpectedVersion: &stale,
+	})
+	require.ErrorIs(t, err, types.ErrProfileVersionConflict)
+	var conflict *types.ProfileVersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, "Current", conflict.Current.DisplayName)
+	require.Equal(t, "Current", repo.stored.DisplayName)
+
+	current := 2
+	err = cmd.Execute(context.Background(), ProfileUpsertInput{
+		UserID:          userID,
+		Patch:           types.ProfilePatch{DisplayName: &display},
+		Actor:           types.ActorRef{ID: uuid.New()},
+		ExpectedVersion: &current,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Next", repo.stored.DisplayName)
+	require.NotNil(t, repo.stored.ExpectedVersion)
+	require.Equal(t, 2, *repo.stored.ExpectedVersion)
+}
+
+func TestProfileUpsertCommand_LogsFieldChanges(t *testing.T) {
+	before := "Old Name"
+	repo := &fakeProfileRepo{stored: &types.UserProfile{
+		DisplayName: before,
+		Metadata:    map[string]any{"password": "hunter2"},
+	}}
+	sink := &recordingActivitySink{}
+	cmd := NewProfileUpsertCommand(ProfileCommandConfig{
+		Repository: repo,
+		Activity:   sink,
+	})
+
+	display := "New Name"
+	err := cmd.Execute(context.Background(), ProfileUpsertInput{
+		UserID: uuid.New(),
+		Patch: types.ProfilePatch{
+			DisplayName: &display,
+			Metadata:    map[string]any{"password": "correct-horse"},
+		},
+		Actor: types.ActorRef{ID: uuid.New()},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, sink.records, 1)
+	require.Equal(t, "profile.updated", sink.records[0].Verb)
+	changes := activity.ChangesFromData(sink.records[0].Data)
+	require.Len(t, changes, 2)
+	require.Equal(t, "display_name", changes[0].Field)
+	require.Equal(t, "Old Name", changes[0].Before)
+	require.Equal(t, "metadata.password", changes[1].Field)
+	require.True(t, changes[1].Masked)
+	require.NotEqual(t, "correct-horse", changes[1].After)
+}
+
 func TestProfileUpsertCommand_CanonicalizesLocalePatch(t *testing.T) {
 	repo := &fakeProfileRepo{}
 	cmd := NewProfileUpsertCommand(ProfileCommandConfig{Repository: repo})
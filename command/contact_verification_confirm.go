@@ -0,0 +1,303 @@
+package command
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	gocommand "github.com/goliatone/go-command"
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/notification"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// ContactVerificationConfirmInput confirms a pending contact change with
+// either the link token or the change's verification ID (its JTI) and code.
+type ContactVerificationConfirmInput struct {
+	Token          string
+	VerificationID string
+	Code           string
+	// UserID, when set, must own the pending change.
+	UserID uuid.UUID
+	Scope  types.ScopeFilter
+	// Actor defaults to the user that owns the change.
+	Actor  types.ActorRef
+	Result *ContactVerificationConfirmResult
+}
+
+// Type implements gocommand.Message.
+func (ContactVerificationConfirmInput) Type() string {
+	return "command.user.contact_verification.confirm"
+}
+
+// Validate implements gocommand.Message.
+func (input ContactVerificationConfirmInput) Validate() error {
+	switch {
+	case strings.TrimSpace(input.Token) != "":
+		return nil
+	case strings.TrimSpace(input.VerificationID) == "":
+		return ErrTokenRequired
+	case strings.TrimSpace(input.Code) == "":
+		return ErrContactVerificationCodeRequired
+	default:
+		return nil
+	}
+}
+
+// ContactVerificationConfirmResult exposes the applied change. User is set for
+// email changes and Profile for phone changes.
+type ContactVerificationConfirmResult struct {
+	Change  *types.ContactChange
+	User    *types.AuthUser
+	Profile *types.UserProfile
+}
+
+// ContactVerificationConfirmCommand verifies a pending contact change and
+// applies it.
+type ContactVerificationConfirmCommand struct {
+	validator   tokenValidator
+	repo        types.AuthRepository
+	profiles    types.ProfileRepository
+	changes     types.ContactChangeRepository
+	tokens      types.UserTokenRepository
+	clock       types.Clock
+	sink        types.ActivitySink
+	hooks       types.Hooks
+	logger      types.Logger
+	maxAttempts int
+	notifier    notification.Notifier
+}
+
+// NewContactVerificationConfirmCommand constructs the confirm handler.
+func NewContactVerificationConfirmCommand(cfg ContactVerificationConfig) *ContactVerificationConfirmCommand {
+	clock := safeClock(cfg.Clock)
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultContactCodeAttempts
+	}
+	return &ContactVerificationConfirmCommand{
+		validator:   newTokenValidator(clock, cfg.TokenRepository, cfg.SecureLinks, cfg.ScopeEnforcer),
+		repo:        cfg.Repository,
+		profiles:    cfg.Profiles,
+		changes:     cfg.Changes,
+		tokens:      cfg.TokenRepository,
+		clock:       clock,
+		sink:        safeActivitySink(cfg.Activity),
+		hooks:       safeHooks(cfg.Hooks),
+		logger:      safeLogger(cfg.Logger),
+		maxAttempts: maxAttempts,
+		notifier:    cfg.Notifier,
+	}
+}
+
+var _ gocommand.Commander[ContactVerificationConfirmInput] = (*ContactVerificationConfirmCommand)(nil)
+
+// Execute verifies the token or code, applies the new value, consumes the
+// token, and notifies the previous address.
+func (c *ContactVerificationConfirmCommand) Execute(ctx context.Context, input ContactVerificationConfirmInput) error {
+	switch {
+	case c.repo == nil:
+		return types.ErrMissingAuthRepository
+	case c.changes == nil:
+		return types.ErrMissingContactChangeRepository
+	case c.tokens == nil:
+		return types.ErrMissingUserTokenRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	change, err := c.loadChange(ctx, input)
+	if err != nil {
+		return err
+	}
+	actor := input.Actor
+	if actor.ID == uuid.Nil {
+		actor = types.ActorRef{ID: change.UserID, Type: "user"}
+	}
+	if strings.TrimSpace(input.Token) == "" {
+		if err := c.checkCode(ctx, actor, change, input.Code); err != nil {
+			return err
+		}
+	}
+	if change.Channel == types.ContactChannelPhone && c.profiles == nil {
+		return types.ErrMissingProfileRepository
+	}
+
+	confirmedAt := now(c.clock)
+	user, err := c.repo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	result := ContactVerificationConfirmResult{Change: change}
+	record := contactActivityRecord("user.contact.changed", actor.ID, *change, confirmedAt)
+	record.Data["old_value"] = change.OldValue
+	if change.Channel == types.ContactChannelPhone {
+		result.Profile, err = c.applyPhone(ctx, actor, change, &record)
+	} else {
+		result.User, err = c.applyEmail(ctx, user, change, &record)
+	}
+	if err != nil {
+		return err
+	}
+	// The token is consumed only once the value is applied so a failed write
+	// leaves the change confirmable. A concurrent confirm that loses the
+	// consume race has written the same verified value and reports the
+	// conflict without emitting events.
+	if err := c.tokens.UpdateTokenStatus(ctx, types.UserTokenContactVerification, change.JTI, types.UserTokenStatusUsed, confirmedAt); err != nil {
+		return tokenConsumeError(ctx, c.tokens, types.UserTokenContactVerification, change.JTI, confirmedAt, err)
+	}
+	if err := c.changes.UpdateContactChangeStatus(ctx, change.JTI, types.ContactChangeStatusConfirmed, confirmedAt); err != nil {
+		return err
+	}
+	change.Status = types.ContactChangeStatusConfirmed
+	change.ConfirmedAt = confirmedAt
+
+	if result.Profile != nil {
+		emitProfileHook(ctx, c.hooks, types.ProfileEvent{
+			UserID:     change.UserID,
+			Scope:      change.Scope,
+			ActorID:    actor.ID,
+			OccurredAt: confirmedAt,
+			Profile:    *result.Profile,
+		})
+	}
+
+	emitContactChangeHook(ctx, c.hooks, types.ContactChangeEvent{
+		UserID:     change.UserID,
+		ActorID:    actor.ID,
+		Scope:      change.Scope,
+		Channel:    change.Channel,
+		Stage:      types.ContactChangeStageConfirmed,
+		OldValue:   change.OldValue,
+		NewValue:   change.NewValue,
+		ExpiresAt:  change.ExpiresAt,
+		OccurredAt: confirmedAt,
+	})
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+	if change.OldValue != "" {
+		notifyUser(ctx, c.notifier, c.logger, contactNotification(notification.KindContactChanged, user, actor, *change, change.OldValue, map[string]any{
+			"old_value": change.OldValue,
+		}))
+	}
+	if input.Result != nil {
+		*input.Result = result
+	}
+	return nil
+}
+
+// loadChange resolves the pending change from the link token or the
+// verification ID, rejecting used, expired, and superseded changes.
+func (c *ContactVerificationConfirmCommand) loadChange(ctx context.Context, input ContactVerificationConfirmInput) (*types.ContactChange, error) {
+	var record *types.UserToken
+	var err error
+	if token := strings.TrimSpace(input.Token); token != "" {
+		_, record, err = c.validator.validate(ctx, token, types.UserTokenContactVerification, input.Scope)
+	} else {
+		record, err = c.validator.loadUsableToken(ctx, types.UserTokenContactVerification, strings.TrimSpace(input.VerificationID), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	change, err := c.changes.GetContactChangeByJTI(ctx, record.JTI)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, ErrTokenNotFound
+	}
+	if input.UserID != uuid.Nil && input.UserID != change.UserID {
+		return nil, ErrTokenUserMismatch
+	}
+	switch change.Status {
+	case types.ContactChangeStatusPending:
+		return change, nil
+	case types.ContactChangeStatusSuperseded:
+		return nil, ErrContactChangeSuperseded
+	case types.ContactChangeStatusConfirmed:
+		return nil, ErrTokenAlreadyUsed
+	default:
+		return nil, ErrTokenExpired
+	}
+}
+
+// checkCode compares code with the stored hash, counting wrong guesses and
+// expiring the change once MaxAttempts is reached.
+func (c *ContactVerificationConfirmCommand) checkCode(ctx context.Context, actor types.ActorRef, change *types.ContactChange, code string) error {
+	if change.CodeHash == "" {
+		return ErrContactVerificationCodeInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(hashContactCode(change.JTI, code)), []byte(change.CodeHash)) == 1 {
+		return nil
+	}
+	attempts, err := c.changes.RecordContactChangeAttempt(ctx, change.JTI)
+	if err != nil {
+		if repository.IsSQLExpectedCountViolation(err) {
+			return ErrTokenExpired
+		}
+		return err
+	}
+	failedAt := now(c.clock)
+	record := contactActivityRecord("user.contact.verification.failed", actor.ID, *change, failedAt)
+	record.Data["attempts"] = attempts
+	exhausted := attempts >= c.maxAttempts
+	if exhausted {
+		record.Data["exhausted"] = true
+		if err := c.changes.UpdateContactChangeStatus(ctx, change.JTI, types.ContactChangeStatusExpired, failedAt); err != nil && !repository.IsSQLExpectedCountViolation(err) {
+			c.logger.Error("contact change expiry failed", err, "jti", change.JTI)
+		}
+		_ = c.tokens.UpdateTokenStatus(ctx, types.UserTokenContactVerification, change.JTI, types.UserTokenStatusExpired, time.Time{})
+	}
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+	if exhausted {
+		return ErrContactVerificationAttemptsExceeded
+	}
+	return ErrContactVerificationCodeInvalid
+}
+
+func (c *ContactVerificationConfirmCommand) applyEmail(ctx context.Context, user *types.AuthUser, change *types.ContactChange, record *types.ActivityRecord) (*types.AuthUser, error) {
+	before := cloneAuthUser(user)
+	user.Email = change.NewValue
+	updated, err := c.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	*record = activity.AttachChanges(*record, nil, activity.DiffAuthUser(before, updated))
+	return updated, nil
+}
+
+func (c *ContactVerificationConfirmCommand) applyPhone(ctx context.Context, actor types.ActorRef, change *types.ContactChange, record *types.ActivityRecord) (*types.UserProfile, error) {
+	draft, err := loadProfileDraft(ctx, c.profiles, change.UserID, change.Scope)
+	if err != nil {
+		return nil, err
+	}
+	profile := draft.profile
+	before := draft.effective
+	if profile.CreatedBy == uuid.Nil {
+		profile.CreatedBy = actor.ID
+	}
+	contact := cloneMap(profile.Contact)
+	contact[types.ContactProfileKeyPhone] = change.NewValue
+	profile.Contact = contact
+	profile.UpdatedBy = actor.ID
+
+	updated, err := c.profiles.UpsertProfile(ctx, *profile)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		updated = profile
+	}
+	resolved := draft.resolve(*updated)
+	record.ObjectType = "profile"
+	*record = activity.AttachChanges(*record, nil, activity.DiffUserProfile(before, &resolved))
+	return &resolved, nil
+}
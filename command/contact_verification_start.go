@@ -0,0 +1,431 @@
+package command
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/notification"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultContactVerificationTTL = 1 * time.Hour
+	defaultContactCodeTTL         = 10 * time.Minute
+	defaultContactCodeLength      = 6
+	defaultContactCodeAttempts    = 5
+)
+
+// ContactVerificationConfig wires dependencies for the contact verification
+// start and confirm commands.
+type ContactVerificationConfig struct {
+	// Repository loads users and applies confirmed email changes.
+	Repository types.AuthRepository
+	// Profiles loads and applies confirmed phone changes.
+	Profiles        types.ProfileRepository
+	Changes         types.ContactChangeRepository
+	TokenRepository types.UserTokenRepository
+	// SecureLinks issues and validates link tokens; OTP verification works
+	// without it.
+	SecureLinks types.SecureLinkManager
+	Clock       types.Clock
+	IDGen       types.IDGenerator
+	Activity    types.ActivitySink
+	Hooks       types.Hooks
+	Logger      types.Logger
+	ScopeGuard  scope.Guard
+	// TokenTTL bounds link verification (defaults to the securelink expiration,
+	// then one hour).
+	TokenTTL time.Duration
+	// CodeTTL bounds OTP verification (defaults to ten minutes).
+	CodeTTL time.Duration
+	// CodeLength sets the number of OTP digits (defaults to six).
+	CodeLength int
+	// MaxAttempts expires an OTP change after this many wrong codes (defaults
+	// to five).
+	MaxAttempts   int
+	Route         string
+	ScopeEnforcer types.ScopeEnforcer
+	// Notifier sends the link or code to the new address and tells the old
+	// address once the change is confirmed.
+	Notifier notification.Notifier
+}
+
+// ContactVerificationStartInput requests an email or phone change that stays
+// pending until the new address is verified.
+type ContactVerificationStartInput struct {
+	UserID  uuid.UUID
+	Channel types.ContactChannel
+	// Value is the new email address or phone number.
+	Value string
+	// Method defaults to ContactVerificationLink.
+	Method types.ContactVerificationMethod
+	Scope  types.ScopeFilter
+	Actor  types.ActorRef
+	Result *ContactVerificationStartResult
+}
+
+// Type implements gocommand.Message.
+func (ContactVerificationStartInput) Type() string {
+	return "command.user.contact_verification.start"
+}
+
+// Validate implements gocommand.Message.
+func (input ContactVerificationStartInput) Validate() error {
+	switch {
+	case input.UserID == uuid.Nil:
+		return ErrUserIDRequired
+	case input.Actor.ID == uuid.Nil:
+		return ErrActorRequired
+	case input.Channel != types.ContactChannelEmail && input.Channel != types.ContactChannelPhone:
+		return types.ErrContactChannelInvalid
+	case strings.TrimSpace(input.Value) == "":
+		return types.ErrContactValueRequired
+	case input.Method != "" && input.Method != types.ContactVerificationLink && input.Method != types.ContactVerificationOTP:
+		return types.ErrContactVerificationMethodInvalid
+	default:
+		return nil
+	}
+}
+
+// ContactVerificationStartResult exposes the pending change and the secret
+// delivered to the new address. Token is set for link verification and Code
+// for OTP verification; neither should be echoed back to the requester.
+type ContactVerificationStartResult struct {
+	Change    *types.ContactChange
+	Token     string
+	Code      string
+	ExpiresAt time.Time
+}
+
+// ContactVerificationStartCommand records a pending contact change and issues
+// its verification token.
+type ContactVerificationStartCommand struct {
+	repo     types.AuthRepository
+	profiles types.ProfileRepository
+	changes  types.ContactChangeRepository
+	tokens   types.UserTokenRepository
+	manager  types.SecureLinkManager
+	clock    types.Clock
+	idGen    types.IDGenerator
+	sink     types.ActivitySink
+	hooks    types.Hooks
+	logger   types.Logger
+	guard    scope.Guard
+	tokenTTL time.Duration
+	codeTTL  time.Duration
+	codeLen  int
+	route    string
+	notifier notification.Notifier
+}
+
+// NewContactVerificationStartCommand constructs the start handler.
+func NewContactVerificationStartCommand(cfg ContactVerificationConfig) *ContactVerificationStartCommand {
+	tokenTTL := cfg.TokenTTL
+	if tokenTTL == 0 && cfg.SecureLinks != nil {
+		tokenTTL = cfg.SecureLinks.GetExpiration()
+	}
+	if tokenTTL == 0 {
+		tokenTTL = defaultContactVerificationTTL
+	}
+	codeTTL := cfg.CodeTTL
+	if codeTTL == 0 {
+		codeTTL = defaultContactCodeTTL
+	}
+	codeLen := cfg.CodeLength
+	if codeLen <= 0 {
+		codeLen = defaultContactCodeLength
+	}
+	idGen := cfg.IDGen
+	if idGen == nil {
+		idGen = types.UUIDGenerator{}
+	}
+	route := strings.TrimSpace(cfg.Route)
+	if route == "" {
+		route = SecureLinkRouteContactVerification
+	}
+	return &ContactVerificationStartCommand{
+		repo:     cfg.Repository,
+		profiles: cfg.Profiles,
+		changes:  cfg.Changes,
+		tokens:   cfg.TokenRepository,
+		manager:  cfg.SecureLinks,
+		clock:    safeClock(cfg.Clock),
+		idGen:    idGen,
+		sink:     safeActivitySink(cfg.Activity),
+		hooks:    safeHooks(cfg.Hooks),
+		logger:   safeLogger(cfg.Logger),
+		guard:    safeScopeGuard(cfg.ScopeGuard),
+		tokenTTL: tokenTTL,
+		codeTTL:  codeTTL,
+		codeLen:  codeLen,
+		route:    route,
+		notifier: cfg.Notifier,
+	}
+}
+
+var _ gocommand.Commander[ContactVerificationStartInput] = (*ContactVerificationStartCommand)(nil)
+
+// Execute stores the pending change, issues the link or code, and sends it to
+// the new address. The user's current contact details are left untouched.
+func (c *ContactVerificationStartCommand) Execute(ctx context.Context, input ContactVerificationStartInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	method := input.Method
+	if method == "" {
+		method = types.ContactVerificationLink
+	}
+	if err := c.checkDependencies(input.Channel, method); err != nil {
+		return err
+	}
+
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, contactPolicyAction(input.Channel), input.UserID)
+	if err != nil {
+		return err
+	}
+	user, err := c.repo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	oldValue, err := currentContactValue(ctx, c.profiles, user, input.Channel, scope)
+	if err != nil {
+		return err
+	}
+	newValue := strings.TrimSpace(input.Value)
+	if sameContactValue(input.Channel, oldValue, newValue) {
+		return types.ErrContactValueUnchanged
+	}
+
+	issuedAt := now(c.clock)
+	change := types.ContactChange{
+		UserID:      user.ID,
+		Channel:     input.Channel,
+		Method:      method,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		JTI:         c.idGen.UUID().String(),
+		Status:      types.ContactChangeStatusPending,
+		Scope:       scope,
+		RequestedBy: input.Actor.ID,
+		IssuedAt:    issuedAt,
+	}
+	token, code, err := c.issueSecret(user, &change)
+	if err != nil {
+		return err
+	}
+	if _, err := c.tokens.CreateToken(ctx, types.UserToken{
+		UserID:    user.ID,
+		Type:      types.UserTokenContactVerification,
+		JTI:       change.JTI,
+		Status:    types.UserTokenStatusIssued,
+		IssuedAt:  change.IssuedAt,
+		ExpiresAt: change.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+	stored, err := c.changes.CreateContactChange(ctx, change)
+	if err != nil {
+		// Expire the token so it cannot outlive the change it was issued for.
+		if expireErr := c.tokens.UpdateTokenStatus(ctx, types.UserTokenContactVerification, change.JTI, types.UserTokenStatusExpired, issuedAt); expireErr != nil {
+			return errors.Join(err, expireErr)
+		}
+		return err
+	}
+	if stored == nil {
+		stored = &change
+	}
+
+	c.emitStarted(ctx, input.Actor, *stored)
+	notifyUser(ctx, c.notifier, c.logger, contactNotification(notification.KindContactVerification, user, input.Actor, *stored, newValue, map[string]any{
+		"token":      token,
+		"code":       code,
+		"expires_at": stored.ExpiresAt,
+	}))
+	if input.Result != nil {
+		*input.Result = ContactVerificationStartResult{
+			Change:    stored,
+			Token:     token,
+			Code:      code,
+			ExpiresAt: stored.ExpiresAt,
+		}
+	}
+	return nil
+}
+
+func (c *ContactVerificationStartCommand) checkDependencies(channel types.ContactChannel, method types.ContactVerificationMethod) error {
+	switch {
+	case c.repo == nil:
+		return types.ErrMissingAuthRepository
+	case c.changes == nil:
+		return types.ErrMissingContactChangeRepository
+	case c.tokens == nil:
+		return types.ErrMissingUserTokenRepository
+	case method == types.ContactVerificationLink && c.manager == nil:
+		return types.ErrMissingSecureLinkManager
+	case channel == types.ContactChannelPhone && c.profiles == nil:
+		return types.ErrMissingProfileRepository
+	default:
+		return nil
+	}
+}
+
+// issueSecret generates the link token or OTP for change and sets its expiry
+// and code hash.
+func (c *ContactVerificationStartCommand) issueSecret(user *types.AuthUser, change *types.ContactChange) (string, string, error) {
+	if change.Method == types.ContactVerificationOTP {
+		change.ExpiresAt = change.IssuedAt.Add(c.codeTTL)
+		code, err := generateContactCode(c.codeLen)
+		if err != nil {
+			return "", "", err
+		}
+		change.CodeHash = hashContactCode(change.JTI, code)
+		return "", code, nil
+	}
+	change.ExpiresAt = change.IssuedAt.Add(c.tokenTTL)
+	payload := buildSecureLinkPayload(SecureLinkActionContactVerification, user, change.Scope, change.JTI, change.IssuedAt, change.ExpiresAt, secureLinkSourceDefault)
+	payload["channel"] = string(change.Channel)
+	token, err := c.manager.Generate(c.route, payload)
+	return token, "", err
+}
+
+func (c *ContactVerificationStartCommand) emitStarted(ctx context.Context, actor types.ActorRef, change types.ContactChange) {
+	emitContactChangeHook(ctx, c.hooks, types.ContactChangeEvent{
+		UserID:     change.UserID,
+		ActorID:    actor.ID,
+		Scope:      change.Scope,
+		Channel:    change.Channel,
+		Stage:      types.ContactChangeStageStarted,
+		OldValue:   change.OldValue,
+		NewValue:   change.NewValue,
+		ExpiresAt:  change.ExpiresAt,
+		OccurredAt: change.IssuedAt,
+	})
+	record := contactActivityRecord("user.contact.verification.started", actor.ID, change, change.IssuedAt)
+	record.Data["expires_at"] = change.ExpiresAt
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+}
+
+func contactPolicyAction(channel types.ContactChannel) types.PolicyAction {
+	if channel == types.ContactChannelPhone {
+		return types.PolicyActionProfilesWrite
+	}
+	return types.PolicyActionUsersWrite
+}
+
+// currentContactValue returns the email on user or the phone on the profile
+// resolved for scope.
+func currentContactValue(ctx context.Context, profiles types.ProfileRepository, user *types.AuthUser, channel types.ContactChannel, scope types.ScopeFilter) (string, error) {
+	if channel == types.ContactChannelEmail {
+		return strings.TrimSpace(user.Email), nil
+	}
+	profile, err := types.ResolveProfile(ctx, profiles, user.ID, scope)
+	if err != nil || profile == nil {
+		return "", err
+	}
+	return profilePhone(profile), nil
+}
+
+func profilePhone(profile *types.UserProfile) string {
+	if profile == nil {
+		return ""
+	}
+	value, ok := profile.Contact[types.ContactProfileKeyPhone]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
+
+func sameContactValue(channel types.ContactChannel, current, next string) bool {
+	if channel == types.ContactChannelEmail {
+		return strings.EqualFold(current, next)
+	}
+	return current == next
+}
+
+func generateContactCode(length int) (string, error) {
+	var b strings.Builder
+	for range length {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + digit.Int64()))
+	}
+	return b.String(), nil
+}
+
+// hashContactCode binds code to the change JTI so equal codes never share a
+// hash.
+func hashContactCode(jti, code string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(jti) + ":" + strings.TrimSpace(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func contactActivityRecord(verb string, actorID uuid.UUID, change types.ContactChange, occurredAt time.Time) types.ActivityRecord {
+	if actorID == uuid.Nil {
+		actorID = change.UserID
+	}
+	return types.ActivityRecord{
+		UserID:     change.UserID,
+		ActorID:    actorID,
+		Verb:       verb,
+		ObjectType: "user",
+		ObjectID:   change.UserID.String(),
+		Channel:    "contact",
+		TenantID:   change.Scope.TenantID,
+		OrgID:      change.Scope.OrgID,
+		Data: map[string]any{
+			"channel":   string(change.Channel),
+			"method":    string(change.Method),
+			"jti":       change.JTI,
+			"new_value": change.NewValue,
+		},
+		OccurredAt: occurredAt,
+	}
+}
+
+// contactNotification addresses n to address over the channel matching the
+// contact change, bypassing the user's channel preferences.
+func contactNotification(kind notification.Kind, user *types.AuthUser, actor types.ActorRef, change types.ContactChange, address string, data map[string]any) notification.Notification {
+	recipient := notification.Recipient{Name: userDisplayName(user)}
+	channel := notification.ChannelEmail
+	if change.Channel == types.ContactChannelPhone {
+		recipient.Phone = address
+		channel = notification.ChannelSMS
+	} else {
+		recipient.Email = address
+	}
+	payload := map[string]any{
+		"channel":   string(change.Channel),
+		"new_value": change.NewValue,
+	}
+	for key, value := range data {
+		if value != "" {
+			payload[key] = value
+		}
+	}
+	return notification.Notification{
+		Kind:      kind,
+		UserID:    change.UserID,
+		ActorID:   actor.ID,
+		Scope:     change.Scope,
+		Recipient: recipient,
+		Data:      payload,
+		Channels:  []notification.Channel{channel},
+	}
+}
@@ -0,0 +1,432 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/notification"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactVerification_EmailLinkFlow(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "old@example.com", FirstName: "Ada"}
+	tokens := newMemoryTokenRepo()
+	changes := newMemoryContactChangeRepo()
+	manager := &stubSecureLinkManager{token: "link-token"}
+	sink := &recordingActivitySink{}
+	notifier := &recordingNotifier{}
+	var events []types.ContactChangeEvent
+	cfg := ContactVerificationConfig{
+		Repository:      repo,
+		Changes:         changes,
+		TokenRepository: tokens,
+		SecureLinks:     manager,
+		Clock:           fixedClock{t: time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)},
+		Activity:        sink,
+		Hooks: types.Hooks{AfterContactChange: func(_ context.Context, event types.ContactChangeEvent) {
+			events = append(events, event)
+		}},
+		Notifier: notifier,
+	}
+	actor := types.ActorRef{ID: userID, Type: "user"}
+
+	var started ContactVerificationStartResult
+	require.NoError(t, NewContactVerificationStartCommand(cfg).Execute(ctx, ContactVerificationStartInput{
+		UserID:  userID,
+		Channel: types.ContactChannelEmail,
+		Value:   " new@example.com ",
+		Actor:   actor,
+		Result:  &started,
+	}))
+	require.Equal(t, "link-token", started.Token)
+	require.Empty(t, started.Code)
+	require.Equal(t, "old@example.com", repo.users[userID].Email, "email must stay unchanged until confirmed")
+	require.Equal(t, types.ContactChangeStatusPending, started.Change.Status)
+	require.Equal(t, "new@example.com", started.Change.NewValue)
+	require.Equal(t, SecureLinkRouteContactVerification, manager.lastRoute)
+	require.Equal(t, SecureLinkActionContactVerification, manager.lastPayloads[0]["action"])
+	require.Equal(t, types.UserTokenContactVerification, tokens.lastCreated.Type)
+	require.Len(t, notifier.sent, 1)
+	require.Equal(t, notification.KindContactVerification, notifier.sent[0].Kind)
+	require.Equal(t, "new@example.com", notifier.sent[0].Recipient.Email)
+	require.Equal(t, []notification.Channel{notification.ChannelEmail}, notifier.sent[0].Channels)
+	require.Len(t, events, 1)
+	require.Equal(t, types.ContactChangeStageStarted, events[0].Stage)
+	require.Equal(t, "old@example.com", events[0].OldValue)
+
+	manager.validatePayload = types.SecureLinkPayload{"jti": started.Change.JTI, "user_id": userID.String()}
+	var confirmed ContactVerificationConfirmResult
+	require.NoError(t, NewContactVerificationConfirmCommand(cfg).Execute(ctx, ContactVerificationConfirmInput{
+		Token:  started.Token,
+		Result: &confirmed,
+	}))
+	require.Equal(t, "new@example.com", repo.users[userID].Email)
+	require.Equal(t, "new@example.com", confirmed.User.Email)
+	require.Equal(t, types.ContactChangeStatusConfirmed, changes.changes[started.Change.JTI].Status)
+	require.Equal(t, types.UserTokenStatusUsed, tokens.tokens[tokenKey(types.UserTokenContactVerification, started.Change.JTI)].Status)
+	require.Len(t, events, 2)
+	require.Equal(t, types.ContactChangeStageConfirmed, events[1].Stage)
+	require.Len(t, notifier.sent, 2)
+	require.Equal(t, notification.KindContactChanged, notifier.sent[1].Kind)
+	require.Equal(t, "old@example.com", notifier.sent[1].Recipient.Email)
+
+	verbs := make([]string, 0, len(sink.records))
+	for _, record := range sink.records {
+		verbs = append(verbs, record.Verb)
+	}
+	require.Equal(t, []string{"user.contact.verification.started", "user.contact.changed"}, verbs)
+	require.Equal(t, "old@example.com", sink.records[1].Data["old_value"])
+}
+
+func TestContactVerification_PhoneOTPFlow(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	profiles := &fakeProfileRepo{stored: &types.UserProfile{
+		UserID:  userID,
+		Contact: map[string]any{"phone": "+15550100", "website": "https://example.com"},
+	}}
+	changes := newMemoryContactChangeRepo()
+	sink := &recordingActivitySink{}
+	notifier := &recordingNotifier{}
+	cfg := ContactVerificationConfig{
+		Repository:      repo,
+		Profiles:        profiles,
+		Changes:         changes,
+		TokenRepository: newMemoryTokenRepo(),
+		Activity:        sink,
+		Notifier:        notifier,
+	}
+	actor := types.ActorRef{ID: userID, Type: "user"}
+
+	var started ContactVerificationStartResult
+	require.NoError(t, NewContactVerificationStartCommand(cfg).Execute(ctx, ContactVerificationStartInput{
+		UserID:  userID,
+		Channel: types.ContactChannelPhone,
+		Value:   "+15550199",
+		Method:  types.ContactVerificationOTP,
+		Actor:   actor,
+		Result:  &started,
+	}))
+	require.Len(t, started.Code, defaultContactCodeLength)
+	require.Empty(t, started.Token)
+	require.NotContains(t, started.Change.CodeHash, started.Code)
+	require.Equal(t, "+15550199", notifier.sent[0].Recipient.Phone)
+	require.Equal(t, []notification.Channel{notification.ChannelSMS}, notifier.sent[0].Channels)
+	require.Equal(t, started.Code, notifier.sent[0].Data["code"])
+
+	confirm := NewContactVerificationConfirmCommand(cfg)
+	err := confirm.Execute(ctx, ContactVerificationConfirmInput{
+		VerificationID: started.Change.JTI,
+		Code:           wrongCode(started.Code),
+	})
+	require.ErrorIs(t, err, ErrContactVerificationCodeInvalid)
+	require.Equal(t, 1, changes.changes[started.Change.JTI].Attempts)
+	require.Equal(t, "+15550100", profiles.stored.Contact["phone"])
+
+	var confirmed ContactVerificationConfirmResult
+	require.NoError(t, confirm.Execute(ctx, ContactVerificationConfirmInput{
+		VerificationID: started.Change.JTI,
+		Code:           started.Code,
+		UserID:         userID,
+		Result:         &confirmed,
+	}))
+	require.Equal(t, "+15550199", profiles.stored.Contact["phone"])
+	require.Equal(t, "https://example.com", profiles.stored.Contact["website"])
+	require.Equal(t, "+15550199", confirmed.Profile.Contact["phone"])
+	require.Equal(t, "+15550100", notifier.sent[len(notifier.sent)-1].Recipient.Phone)
+
+	verbs := make([]string, 0, len(sink.records))
+	for _, record := range sink.records {
+		verbs = append(verbs, record.Verb)
+	}
+	require.Equal(t, []string{
+		"user.contact.verification.started",
+		"user.contact.verification.failed",
+		"user.contact.changed",
+	}, verbs)
+
+	err = confirm.Execute(ctx, ContactVerificationConfirmInput{
+		VerificationID: started.Change.JTI,
+		Code:           started.Code,
+	})
+	require.ErrorIs(t, err, ErrTokenAlreadyUsed)
+}
+
+func TestContactVerification_FailedApplyKeepsTokenUsable(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	profiles := &flakyProfileRepo{fakeProfileRepo: &fakeProfileRepo{}, failures: 1}
+	tokens := newMemoryTokenRepo()
+	changes := newMemoryContactChangeRepo()
+	cfg := ContactVerificationConfig{
+		Repository:      repo,
+		Profiles:        profiles,
+		Changes:         changes,
+		TokenRepository: tokens,
+	}
+
+	var started ContactVerificationStartResult
+	require.NoError(t, NewContactVerificationStartCommand(cfg).Execute(ctx, ContactVerificationStartInput{
+		UserID:  userID,
+		Channel: types.ContactChannelPhone,
+		Value:   "+15550199",
+		Method:  types.ContactVerificationOTP,
+		Actor:   types.ActorRef{ID: userID, Type: "user"},
+		Result:  &started,
+	}))
+
+	confirm := NewContactVerificationConfirmCommand(cfg)
+	input := ContactVerificationConfirmInput{VerificationID: started.Change.JTI, Code: started.Code}
+	require.ErrorIs(t, confirm.Execute(ctx, input), errProfileWriteFailed)
+	require.Equal(t, types.UserTokenStatusIssued, tokens.tokens[tokenKey(types.UserTokenContactVerification, started.Change.JTI)].Status)
+	require.Equal(t, types.ContactChangeStatusPending, changes.changes[started.Change.JTI].Status)
+
+	require.NoError(t, confirm.Execute(ctx, input))
+	require.Equal(t, "+15550199", profiles.stored.Contact["phone"])
+	require.Equal(t, types.UserTokenStatusUsed, tokens.tokens[tokenKey(types.UserTokenContactVerification, started.Change.JTI)].Status)
+}
+
+func TestContactVerificationStart_ExpiresTokenWhenChangeIsNotStored(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	tokens := newMemoryTokenRepo()
+	boom := errors.New("insert failed")
+
+	err := NewContactVerificationStartCommand(ContactVerificationConfig{
+		Repository:      repo,
+		Profiles:        &fakeProfileRepo{},
+		Changes:         failingContactChangeRepo{memoryContactChangeRepo: newMemoryContactChangeRepo(), err: boom},
+		TokenRepository: tokens,
+	}).Execute(ctx, ContactVerificationStartInput{
+		UserID:  userID,
+		Channel: types.ContactChannelPhone,
+		Value:   "+15550199",
+		Method:  types.ContactVerificationOTP,
+		Actor:   types.ActorRef{ID: userID, Type: "user"},
+	})
+	require.ErrorIs(t, err, boom)
+	require.Len(t, tokens.tokens, 1)
+	for _, token := range tokens.tokens {
+		require.Equal(t, types.UserTokenStatusExpired, token.Status)
+	}
+}
+
+type failingContactChangeRepo struct {
+	*memoryContactChangeRepo
+	err error
+}
+
+func (f failingContactChangeRepo) CreateContactChange(context.Context, types.ContactChange) (*types.ContactChange, error) {
+	return nil, f.err
+}
+
+var errProfileWriteFailed = errors.New("profile write failed")
+
+type flakyProfileRepo struct {
+	*fakeProfileRepo
+	failures int
+}
+
+func (f *flakyProfileRepo) UpsertProfile(ctx context.Context, profile types.UserProfile) (*types.UserProfile, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errProfileWriteFailed
+	}
+	return f.fakeProfileRepo.UpsertProfile(ctx, profile)
+}
+
+func TestContactVerification_OTPAttemptsExhausted(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "old@example.com"}
+	tokens := newMemoryTokenRepo()
+	changes := newMemoryContactChangeRepo()
+	cfg := ContactVerificationConfig{
+		Repository:      repo,
+		Changes:         changes,
+		TokenRepository: tokens,
+		MaxAttempts:     2,
+	}
+
+	var started ContactVerificationStartResult
+	require.NoError(t, NewContactVerificationStartCommand(cfg).Execute(ctx, ContactVerificationStartInput{
+		UserID:  userID,
+		Channel: types.ContactChannelEmail,
+		Value:   "new@example.com",
+		Method:  types.ContactVerificationOTP,
+		Actor:   types.ActorRef{ID: userID},
+		Result:  &started,
+	}))
+
+	confirm := NewContactVerificationConfirmCommand(cfg)
+	input := ContactVerificationConfirmInput{VerificationID: started.Change.JTI, Code: wrongCode(started.Code)}
+	require.ErrorIs(t, confirm.Execute(ctx, input), ErrContactVerificationCodeInvalid)
+	require.ErrorIs(t, confirm.Execute(ctx, input), ErrContactVerificationAttemptsExceeded)
+	require.Equal(t, types.ContactChangeStatusExpired, changes.changes[started.Change.JTI].Status)
+
+	input.Code = started.Code
+	require.ErrorIs(t, confirm.Execute(ctx, input), ErrTokenExpired)
+	require.Equal(t, "old@example.com", repo.users[userID].Email)
+}
+
+func TestContactVerification_NewerRequestSupersedesPending(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "old@example.com"}
+	cfg := ContactVerificationConfig{
+		Repository:      repo,
+		Changes:         newMemoryContactChangeRepo(),
+		TokenRepository: newMemoryTokenRepo(),
+		IDGen:           &sequenceIDGenerator{},
+	}
+	start := NewContactVerificationStartCommand(cfg)
+	input := ContactVerificationStartInput{
+		UserID:  userID,
+		Channel: types.ContactChannelEmail,
+		Method:  types.ContactVerificationOTP,
+		Actor:   types.ActorRef{ID: userID},
+	}
+
+	var first, second ContactVerificationStartResult
+	input.Value, input.Result = "first@example.com", &first
+	require.NoError(t, start.Execute(ctx, input))
+	input.Value, input.Result = "second@example.com", &second
+	require.NoError(t, start.Execute(ctx, input))
+
+	err := NewContactVerificationConfirmCommand(cfg).Execute(ctx, ContactVerificationConfirmInput{
+		VerificationID: first.Change.JTI,
+		Code:           first.Code,
+	})
+	require.ErrorIs(t, err, ErrContactChangeSuperseded)
+
+	input.Value = "OLD@example.com"
+	require.ErrorIs(t, start.Execute(ctx, input), types.ErrContactValueUnchanged)
+}
+
+func TestUserUpdate_RequireEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "old@example.com", FirstName: "Ada"}
+	cmd := NewUserUpdateCommand(UserUpdateCommandConfig{Repository: repo, RequireEmailVerification: true})
+	actor := types.ActorRef{ID: uuid.New()}
+
+	err := cmd.Execute(ctx, UserUpdateInput{
+		User:  &types.AuthUser{ID: userID, Email: "new@example.com"},
+		Actor: actor,
+	})
+	require.ErrorIs(t, err, ErrContactVerificationRequired)
+	require.Nil(t, repo.lastUpdated)
+
+	require.NoError(t, cmd.Execute(ctx, UserUpdateInput{
+		User:  &types.AuthUser{ID: userID, Email: "old@example.com", FirstName: "Grace"},
+		Actor: actor,
+	}))
+	require.Equal(t, "Grace", repo.users[userID].FirstName)
+}
+
+func TestProfileUpsert_RequirePhoneVerification(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := &fakeProfileRepo{stored: &types.UserProfile{UserID: userID, Contact: map[string]any{"phone": "+15550100"}}}
+	cmd := NewProfileUpsertCommand(ProfileCommandConfig{Repository: repo, RequirePhoneVerification: true})
+	actor := types.ActorRef{ID: userID}
+
+	err := cmd.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Patch:  types.ProfilePatch{Contact: map[string]any{"phone": "+15550199"}},
+		Actor:  actor,
+	})
+	require.ErrorIs(t, err, ErrContactVerificationRequired)
+
+	require.NoError(t, cmd.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Patch:  types.ProfilePatch{Contact: map[string]any{"phone": "+15550100", "website": "https://example.com"}},
+		Actor:  actor,
+	}))
+	require.Equal(t, "https://example.com", repo.stored.Contact["website"])
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+type sequenceIDGenerator struct {
+	next int
+}
+
+func (s *sequenceIDGenerator) UUID() uuid.UUID {
+	s.next++
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte{byte(s.next)})
+}
+
+type memoryContactChangeRepo struct {
+	changes map[string]*types.ContactChange
+}
+
+func newMemoryContactChangeRepo() *memoryContactChangeRepo {
+	return &memoryContactChangeRepo{changes: map[string]*types.ContactChange{}}
+}
+
+func (m *memoryContactChangeRepo) CreateContactChange(_ context.Context, change types.ContactChange) (*types.ContactChange, error) {
+	for _, existing := range m.changes {
+		if existing.UserID == change.UserID && existing.Channel == change.Channel && existing.Status == types.ContactChangeStatusPending {
+			existing.Status = types.ContactChangeStatusSuperseded
+		}
+	}
+	copy := change
+	if copy.ID == uuid.Nil {
+		copy.ID = uuid.New()
+	}
+	m.changes[copy.JTI] = &copy
+	result := copy
+	return &result, nil
+}
+
+func (m *memoryContactChangeRepo) GetContactChangeByJTI(_ context.Context, jti string) (*types.ContactChange, error) {
+	change, ok := m.changes[jti]
+	if !ok {
+		return nil, nil
+	}
+	copy := *change
+	return &copy, nil
+}
+
+func (m *memoryContactChangeRepo) RecordContactChangeAttempt(_ context.Context, jti string) (int, error) {
+	change, ok := m.changes[jti]
+	if !ok || change.Status != types.ContactChangeStatusPending {
+		return 0, errors.New("not pending")
+	}
+	change.Attempts++
+	return change.Attempts, nil
+}
+
+func (m *memoryContactChangeRepo) UpdateContactChangeStatus(_ context.Context, jti string, status types.ContactChangeStatus, at time.Time) error {
+	change, ok := m.changes[jti]
+	if !ok || change.Status != types.ContactChangeStatusPending {
+		return errors.New("not pending")
+	}
+	change.Status = status
+	if status == types.ContactChangeStatusConfirmed {
+		change.ConfirmedAt = at
+	}
+	return nil
+}
@@ -25,6 +25,10 @@ var (
 	ErrInviteDisabled = errors.New("go-users: invite disabled")
 	// ErrPasswordHashRequired occurs when a password reset omits the hashed password.
 	ErrPasswordHashRequired = errors.New("go-users: password reset requires password hash")
+	// ErrPasswordInputConflict occurs when a password reset supplies both a plaintext password and a hash.
+	ErrPasswordInputConflict = errors.New("go-users: password reset accepts either a password or a password hash")
+	// ErrAccountNotLocked indicates an unlock was requested for an account without a lockout.
+	ErrAccountNotLocked = errors.New("go-users: account is not locked")
 	// ErrPasswordResetDisabled indicates password reset is disabled via feature gate.
 	ErrPasswordResetDisabled = errors.New("go-users: password reset disabled")
 	// ErrUserIDsRequired occurs when bulk handlers are invoked without targets.
@@ -39,6 +43,10 @@ var (
 	ErrUserIDRequired = types.ErrUserIDRequired
 	// ErrActivityVerbRequired indicates an activity log entry is missing a verb.
 	ErrActivityVerbRequired = errors.New("go-users: activity verb required")
+	// ErrExportWriterRequired indicates an activity export lacks an output writer.
+	ErrExportWriterRequired = errors.New("go-users: activity export requires writer")
+	// ErrExportRangeInvalid indicates the export Until bound precedes Since.
+	ErrExportRangeInvalid = errors.New("go-users: activity export range invalid")
 	// ErrPreferenceKeyRequired indicates the preference key was missing.
 	ErrPreferenceKeyRequired = errors.New("go-users: preference key required")
 	// ErrPreferenceValueRequired indicates the preference value payload was missing.
@@ -47,8 +55,22 @@ var (
 	ErrPreferenceValuesRequired = errors.New("go-users: preference values required")
 	// ErrPreferenceKeysRequired indicates bulk preference keys were missing.
 	ErrPreferenceKeysRequired = errors.New("go-users: preference keys required")
+	// ErrPreferenceVersionRequired indicates a restore request without a target version.
+	ErrPreferenceVersionRequired = errors.New("go-users: preference version required")
+	// ErrPreferenceLockLevelInvalid indicates a lock was requested on a user- or role-level preference.
+	ErrPreferenceLockLevelInvalid = errors.New("go-users: preferences can only be locked at system, tenant, or org level")
 	// ErrPreferenceDuplicateKey indicates bulk payload keys collide after normalization.
 	ErrPreferenceDuplicateKey = errors.New("go-users: duplicate preference key")
+	// ErrPreferenceExportOutputRequired indicates a preference export lacks both a writer and a result.
+	ErrPreferenceExportOutputRequired = errors.New("go-users: preference export requires writer or result")
+	// ErrPreferenceImportSourceRequired indicates a preference import lacks both a reader and a document.
+	ErrPreferenceImportSourceRequired = errors.New("go-users: preference import requires reader or document")
+	// ErrPreferenceCopyLevelInvalid indicates a copy was requested outside the tenant or org level.
+	ErrPreferenceCopyLevelInvalid = errors.New("go-users: preferences can only be copied at tenant or org level")
+	// ErrPreferenceCopyScopeRequired indicates a copy source or target lacks the scope ID for its level.
+	ErrPreferenceCopyScopeRequired = errors.New("go-users: preference copy requires source and target scopes")
+	// ErrPreferenceCopySameScope indicates a copy source and target are the same scope.
+	ErrPreferenceCopySameScope = errors.New("go-users: preference copy source and target must differ")
 	// ErrTokenRequired indicates a securelink token was missing.
 	ErrTokenRequired = errors.New("go-users: token required")
 	// ErrTokenTypeRequired indicates a token type was missing.
@@ -71,4 +93,15 @@ var (
 	ErrResetCommandRequired = errors.New("go-users: password reset command required")
 	// ErrSignupDisabled indicates self-registration is disabled via feature gate.
 	ErrSignupDisabled = errors.New("go-users: signup disabled")
+	// ErrContactVerificationRequired indicates an email or phone change was
+	// attempted directly while verification is required.
+	ErrContactVerificationRequired = errors.New("go-users: contact change requires verification")
+	// ErrContactVerificationCodeRequired indicates an OTP confirmation without a code.
+	ErrContactVerificationCodeRequired = errors.New("go-users: contact verification code required")
+	// ErrContactVerificationCodeInvalid indicates the OTP did not match.
+	ErrContactVerificationCodeInvalid = errors.New("go-users: contact verification code invalid")
+	// ErrContactVerificationAttemptsExceeded indicates the OTP was guessed wrong too many times.
+	ErrContactVerificationAttemptsExceeded = errors.New("go-users: contact verification attempts exceeded")
+	// ErrContactChangeSuperseded indicates a newer change replaced the pending one.
+	ErrContactChangeSuperseded = errors.New("go-users: contact change superseded")
 )
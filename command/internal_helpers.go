@@ -131,3 +131,10 @@ func emitProfileHook(ctx context.Context, hooks types.Hooks, event types.Profile
 	}
 	hooks.AfterProfileChange(ctx, event)
 }
+
+func emitContactChangeHook(ctx context.Context, hooks types.Hooks, event types.ContactChangeEvent) {
+	if hooks.AfterContactChange == nil {
+		return
+	}
+	hooks.AfterContactChange(ctx, event)
+}
@@ -8,6 +8,7 @@ import (
 
 	gocommand "github.com/goliatone/go-command"
 	featuregate "github.com/goliatone/go-featuregate/gate"
+	"github.com/goliatone/go-users/notification"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -66,6 +67,7 @@ type UserInviteCommand struct {
 	guard       scope.Guard
 	featureGate featuregate.FeatureGate
 	route       string
+	notifier    notification.Notifier
 }
 
 // InviteCommandConfig holds dependencies for the invite flow.
@@ -82,6 +84,8 @@ type InviteCommandConfig struct {
 	ScopeGuard      scope.Guard
 	FeatureGate     featuregate.FeatureGate
 	Route           string
+	// Notifier delivers the invite link when configured.
+	Notifier notification.Notifier
 }
 
 // NewUserInviteCommand constructs the invite handler.
@@ -103,6 +107,7 @@ func NewUserInviteCommand(cfg InviteCommandConfig) *UserInviteCommand {
 		repo:        cfg.Repository,
 		tokens:      cfg.TokenRepository,
 		featureGate: cfg.FeatureGate,
+		notifier:    cfg.Notifier,
 	}
 	cmd.applyRuntime(runtime)
 	return cmd
@@ -152,6 +157,7 @@ func (c *UserInviteCommand) Execute(ctx context.Context, input UserInviteInput)
 		return err
 	}
 	c.emitInviteActivity(ctx, created, input.Actor, scope, jti, issuedAt, expiresAt)
+	notifyUser(ctx, c.notifier, c.logger, secureLinkNotification(notification.KindInvite, created, input.Actor, scope, token, expiresAt))
 	setInviteResult(input.Result, created, token, expiresAt)
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/notification"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -54,6 +55,8 @@ type UserLifecycleTransitionCommand struct {
 	hooks    types.Hooks
 	activity types.ActivitySink
 	guard    scope.Guard
+	notifier notification.Notifier
+	history  types.PasswordHistory
 }
 
 // LifecycleCommandConfig configures the lifecycle command handler.
@@ -65,6 +68,10 @@ type LifecycleCommandConfig struct {
 	Hooks      types.Hooks
 	Activity   types.ActivitySink
 	ScopeGuard scope.Guard
+	// Notifier tells the user about the status change when configured.
+	Notifier notification.Notifier
+	// PasswordHistory is purged when a user is archived (deleted).
+	PasswordHistory types.PasswordHistory
 }
 
 // NewUserLifecycleTransitionCommand wires the lifecycle handler.
@@ -81,6 +88,8 @@ func NewUserLifecycleTransitionCommand(cfg LifecycleCommandConfig) *UserLifecycl
 		hooks:    safeHooks(cfg.Hooks),
 		activity: safeActivitySink(cfg.Activity),
 		guard:    safeScopeGuard(cfg.ScopeGuard),
+		notifier: cfg.Notifier,
+		history:  cfg.PasswordHistory,
 	}
 }
 
@@ -105,6 +114,7 @@ func (c *UserLifecycleTransitionCommand) Execute(ctx context.Context, input User
 	if policyErr := c.enforcePolicy(current, input.Target); policyErr != nil {
 		return policyErr
 	}
+	fromState := current.Status
 	opts := make([]types.TransitionOption, 0, 2)
 	if input.Reason != "" {
 		opts = append(opts, types.WithTransitionReason(input.Reason))
@@ -116,6 +126,11 @@ func (c *UserLifecycleTransitionCommand) Execute(ctx context.Context, input User
 	if err != nil {
 		return err
 	}
+	if input.Target == types.LifecycleStateArchived && c.history != nil {
+		if err := c.history.PurgePasswordHistory(ctx, updated.ID); err != nil {
+			c.logger.Error("password history purge failed", err, "user_id", updated.ID)
+		}
+	}
 
 	eventTime := now(c.clock)
 	record := types.ActivityRecord{
@@ -128,7 +143,7 @@ func (c *UserLifecycleTransitionCommand) Execute(ctx context.Context, input User
 		TenantID:   scope.TenantID,
 		OrgID:      scope.OrgID,
 		Data: map[string]any{
-			"from_state": current.Status,
+			"from_state": fromState,
 			"to_state":   input.Target,
 			"reason":     input.Reason,
 			"metadata":   input.Metadata,
@@ -141,7 +156,7 @@ func (c *UserLifecycleTransitionCommand) Execute(ctx context.Context, input User
 	emitLifecycleHook(ctx, c.hooks, types.LifecycleEvent{
 		UserID:     updated.ID,
 		ActorID:    input.Actor.ID,
-		FromState:  current.Status,
+		FromState:  fromState,
 		ToState:    input.Target,
 		Reason:     input.Reason,
 		OccurredAt: eventTime,
@@ -149,6 +164,22 @@ func (c *UserLifecycleTransitionCommand) Execute(ctx context.Context, input User
 		Metadata:   input.Metadata,
 	})
 
+	notifyUser(ctx, c.notifier, c.logger, notification.Notification{
+		Kind:    notification.KindLifecycleChanged,
+		UserID:  updated.ID,
+		ActorID: input.Actor.ID,
+		Scope:   scope,
+		Recipient: notification.Recipient{
+			Name:  userDisplayName(updated),
+			Email: updated.Email,
+		},
+		Data: map[string]any{
+			"from_state": string(fromState),
+			"to_state":   string(input.Target),
+			"reason":     input.Reason,
+		},
+	})
+
 	if input.Result != nil {
 		input.Result.User = updated
 	}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-users/notification"
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// notifyUser hands n to the notifier. Delivery is best effort: failures are
+// recorded by the notifier and logged here, but never fail the command.
+func notifyUser(ctx context.Context, notifier notification.Notifier, logger types.Logger, n notification.Notification) {
+	if notifier == nil {
+		return
+	}
+	if err := notifier.Notify(ctx, n); err != nil {
+		safeLogger(logger).Error("notification delivery failed", err, "kind", n.Kind, "user_id", n.UserID)
+	}
+}
+
+func secureLinkNotification(kind notification.Kind, user *types.AuthUser, actor types.ActorRef, scope types.ScopeFilter, token string, expiresAt time.Time) notification.Notification {
+	return notification.Notification{
+		Kind:    kind,
+		UserID:  user.ID,
+		ActorID: actor.ID,
+		Scope:   scope,
+		Recipient: notification.Recipient{
+			Name:  userDisplayName(user),
+			Email: user.Email,
+		},
+		Data: map[string]any{
+			"token":      token,
+			"expires_at": expiresAt,
+		},
+	}
+}
+
+func userDisplayName(user *types.AuthUser) string {
+	if user == nil {
+		return ""
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/notification"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserInviteCommand_NotifiesInvitee(t *testing.T) {
+	transport := notification.NewMemoryTransport()
+	dispatcher := notification.NewDispatcher(notification.Config{
+		Transports: map[notification.Channel]notification.Transport{notification.ChannelEmail: transport},
+	})
+	cmd := NewUserInviteCommand(InviteCommandConfig{
+		Repository:      newFakeAuthRepo(),
+		TokenRepository: newMemoryTokenRepo(),
+		SecureLinks:     &stubSecureLinkManager{token: "secure-link", expiration: time.Hour},
+		Notifier:        dispatcher,
+	})
+
+	err := cmd.Execute(context.Background(), UserInviteInput{
+		Email:     "new@example.com",
+		FirstName: "Ada",
+		Actor:     types.ActorRef{ID: uuid.New()},
+	})
+	require.NoError(t, err)
+
+	messages := transport.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, notification.KindInvite, messages[0].Kind)
+	require.Equal(t, "new@example.com", messages[0].To)
+	require.Contains(t, messages[0].Body, "Hello Ada,")
+	require.Contains(t, messages[0].Body, "secure-link")
+}
+
+func TestUserLifecycleTransitionCommand_NotifiesUser(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com", Status: types.LifecycleStateActive}
+	notifier := &recordingNotifier{err: errors.New("transport down")}
+	cmd := NewUserLifecycleTransitionCommand(LifecycleCommandConfig{
+		Repository: repo,
+		Notifier:   notifier,
+	})
+
+	err := cmd.Execute(context.Background(), UserLifecycleTransitionInput{
+		UserID: userID,
+		Target: types.LifecycleStateSuspended,
+		Actor:  types.ActorRef{ID: uuid.New()},
+		Reason: "abuse",
+	})
+	require.NoError(t, err, "delivery failures must not fail the transition")
+	require.Len(t, notifier.sent, 1)
+	sent := notifier.sent[0]
+	require.Equal(t, notification.KindLifecycleChanged, sent.Kind)
+	require.Equal(t, "user@example.com", sent.Recipient.Email)
+	require.Equal(t, string(types.LifecycleStateActive), sent.Data["from_state"])
+	require.Equal(t, string(types.LifecycleStateSuspended), sent.Data["to_state"])
+	require.Equal(t, "abuse", sent.Data["reason"])
+}
+
+func TestAssignRoleCommand_NotifiesGrantee(t *testing.T) {
+	notifier := &recordingNotifier{}
+	cmd := NewAssignRoleCommand(&fakeRoleRegistry{}, scope.NopGuard(), WithRoleNotifier(notifier, nil))
+	userID := uuid.New()
+	roleID := uuid.New()
+
+	require.NoError(t, cmd.Execute(context.Background(), AssignRoleInput{
+		UserID: userID,
+		RoleID: roleID,
+		Actor:  types.ActorRef{ID: uuid.New()},
+	}))
+	require.Len(t, notifier.sent, 1)
+	require.Equal(t, notification.KindRoleGranted, notifier.sent[0].Kind)
+	require.Equal(t, userID, notifier.sent[0].UserID)
+	require.Equal(t, roleID.String(), notifier.sent[0].Data["role_id"])
+}
+
+type recordingNotifier struct {
+	sent []notification.Notification
+	err  error
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n notification.Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
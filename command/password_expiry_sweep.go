@@ -0,0 +1,226 @@
+package command
+
+import (
+	"context"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+const defaultPasswordExpirySweepBatchSize = 100
+
+// PasswordExpirySweepInput scans active users in scope for expired
+// passwords.
+type PasswordExpirySweepInput struct {
+	Actor types.ActorRef
+	Scope types.ScopeFilter
+	// BatchSize is the inventory page size. Defaults to 100.
+	BatchSize int
+	Result    *PasswordExpirySweepResult
+}
+
+// Type implements gocommand.Message.
+func (PasswordExpirySweepInput) Type() string {
+	return "command.user.password.expiry_sweep"
+}
+
+// Validate implements gocommand.Message.
+func (input PasswordExpirySweepInput) Validate() error {
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	return nil
+}
+
+// PasswordExpirySweepResult reports the sweep outcome.
+type PasswordExpirySweepResult struct {
+	Scanned int
+	// Expired lists users flagged by this sweep. Users already flagged are
+	// not repeated.
+	Expired []uuid.UUID
+	// Baselined lists users that had no recorded password change time and
+	// were stamped with the sweep time instead of being expired.
+	Baselined []uuid.UUID
+}
+
+// PasswordExpirySweepCommand flags users whose password exceeded the max age
+// with types.PasswordChangeRequiredMetadataKey so sign-in flows can force the
+// change screen.
+type PasswordExpirySweepCommand struct {
+	repo       types.AuthRepository
+	inventory  types.UserInventoryRepository
+	rotation   types.PasswordRotationPolicy
+	userScopes types.ScopeResolver
+	clock      types.Clock
+	sink       types.ActivitySink
+	hooks      types.Hooks
+	logger     types.Logger
+	guard      scope.Guard
+}
+
+// PasswordExpirySweepCommandConfig wires the sweeper.
+type PasswordExpirySweepCommandConfig struct {
+	Repository types.AuthRepository
+	Inventory  types.UserInventoryRepository
+	Rotation   types.PasswordRotationPolicy
+	// UserScopes resolves the scope each swept user belongs to, with the
+	// user as the actor and the sweep scope as the requested scope, so the
+	// max age follows the user's own tenant. When nil the sweep scope is
+	// used for every user.
+	UserScopes types.ScopeResolver
+	Clock      types.Clock
+	Activity   types.ActivitySink
+	Hooks      types.Hooks
+	Logger     types.Logger
+	ScopeGuard scope.Guard
+}
+
+// NewPasswordExpirySweepCommand constructs the sweeper.
+func NewPasswordExpirySweepCommand(cfg PasswordExpirySweepCommandConfig) *PasswordExpirySweepCommand {
+	return &PasswordExpirySweepCommand{
+		repo:       cfg.Repository,
+		inventory:  cfg.Inventory,
+		rotation:   cfg.Rotation,
+		userScopes: cfg.UserScopes,
+		clock:      safeClock(cfg.Clock),
+		sink:       safeActivitySink(cfg.Activity),
+		hooks:      safeHooks(cfg.Hooks),
+		logger:     safeLogger(cfg.Logger),
+		guard:      safeScopeGuard(cfg.ScopeGuard),
+	}
+}
+
+var _ gocommand.Commander[PasswordExpirySweepInput] = (*PasswordExpirySweepCommand)(nil)
+
+// Execute pages through active users and flags expired passwords. Each
+// flagged user emits a user.password.expired activity record and hook.
+// Users without a recorded change time are never expired by the sweep that
+// first sees them: it records the sweep time as their change time, so
+// accounts that predate rotation get a full max age from that point.
+func (c *PasswordExpirySweepCommand) Execute(ctx context.Context, input PasswordExpirySweepInput) error {
+	if c == nil || c.repo == nil {
+		return types.ErrMissingAuthRepository
+	}
+	if c.inventory == nil {
+		return types.ErrMissingInventoryRepository
+	}
+	if c.rotation == nil {
+		return types.ErrMissingPasswordRotation
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionUsersWrite, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	batch := input.BatchSize
+	if batch <= 0 {
+		batch = defaultPasswordExpirySweepBatchSize
+	}
+
+	result := PasswordExpirySweepResult{}
+	offset := 0
+	for {
+		page, err := c.inventory.ListUsers(ctx, types.UserInventoryFilter{
+			Actor:      input.Actor,
+			Scope:      scope,
+			Statuses:   []types.LifecycleState{types.LifecycleStateActive},
+			Pagination: types.Pagination{Limit: batch, Offset: offset},
+		})
+		if err != nil {
+			return err
+		}
+		for i := range page.Users {
+			result.Scanned++
+			if err := c.sweepUser(ctx, &page.Users[i], input.Actor, scope, &result); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore || len(page.Users) == 0 {
+			break
+		}
+		if page.NextOffset > offset {
+			offset = page.NextOffset
+		} else {
+			offset += len(page.Users)
+		}
+	}
+
+	if input.Result != nil {
+		*input.Result = result
+	}
+	return nil
+}
+
+func (c *PasswordExpirySweepCommand) sweepUser(ctx context.Context, user *types.AuthUser, actor types.ActorRef, sweepScope types.ScopeFilter, result *PasswordExpirySweepResult) error {
+	userScope := c.userScope(ctx, user.ID, sweepScope)
+	status := c.rotation.RotationStatus(ctx, user, userScope)
+	switch {
+	case status.Required && status.Reason == types.PasswordRotationExpired:
+		if err := c.updateMetadata(ctx, user.ID, types.MarkPasswordChangeRequiredMetadata); err != nil {
+			return err
+		}
+		c.recordExpired(ctx, user.ID, actor, userScope, status)
+		result.Expired = append(result.Expired, user.ID)
+	case !status.Required && status.MaxAge > 0 && status.ChangedAt.IsZero():
+		changedAt := now(c.clock)
+		if err := c.updateMetadata(ctx, user.ID, func(metadata map[string]any) map[string]any {
+			return types.MarkPasswordChangedMetadata(metadata, changedAt)
+		}); err != nil {
+			return err
+		}
+		result.Baselined = append(result.Baselined, user.ID)
+	}
+	return nil
+}
+
+// userScope returns the scope whose max age applies to the user, falling
+// back to the sweep scope when no resolver is configured or it fails.
+func (c *PasswordExpirySweepCommand) userScope(ctx context.Context, userID uuid.UUID, sweepScope types.ScopeFilter) types.ScopeFilter {
+	if c.userScopes == nil {
+		return sweepScope
+	}
+	resolved, err := c.userScopes.ResolveScope(ctx, types.ActorRef{ID: userID, Type: "user"}, sweepScope)
+	if err != nil {
+		c.logger.Error("password expiry sweep user scope resolution failed", err, "user_id", userID)
+		return sweepScope
+	}
+	return resolved
+}
+
+func (c *PasswordExpirySweepCommand) updateMetadata(ctx context.Context, userID uuid.UUID, mutate func(map[string]any) map[string]any) error {
+	current, err := c.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrUserNotFound
+	}
+	current.Metadata = mutate(current.Metadata)
+	_, err = c.repo.Update(ctx, current)
+	return err
+}
+
+func (c *PasswordExpirySweepCommand) recordExpired(ctx context.Context, userID uuid.UUID, actor types.ActorRef, scope types.ScopeFilter, status types.PasswordRotationStatus) {
+	record := types.ActivityRecord{
+		UserID:     userID,
+		ActorID:    actor.ID,
+		Verb:       "user.password.expired",
+		ObjectType: "user",
+		ObjectID:   userID.String(),
+		Channel:    "password",
+		TenantID:   scope.TenantID,
+		OrgID:      scope.OrgID,
+		Data: map[string]any{
+			"changed_at": status.ChangedAt,
+			"expires_at": status.ExpiresAt,
+			"max_age":    status.MaxAge.String(),
+		},
+		OccurredAt: now(c.clock),
+	}
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
+}
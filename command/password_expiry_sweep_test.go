@@ -0,0 +1,201 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordExpirySweepCommand_FlagsExpiredPasswords(t *testing.T) {
+	repo := newFakeAuthRepo()
+	expiredID := uuid.New()
+	freshID := uuid.New()
+	flaggedID := uuid.New()
+	repo.users[expiredID] = &types.AuthUser{ID: expiredID, Status: types.LifecycleStateActive, Metadata: map[string]any{"plan": "pro"}}
+	repo.users[freshID] = &types.AuthUser{ID: freshID, Status: types.LifecycleStateActive}
+	repo.users[flaggedID] = &types.AuthUser{
+		ID:       flaggedID,
+		Status:   types.LifecycleStateActive,
+		Metadata: types.MarkPasswordChangeRequiredMetadata(nil),
+	}
+	inventory := &pagedInventoryRepo{users: []types.AuthUser{*repo.users[expiredID], *repo.users[freshID], *repo.users[flaggedID]}}
+	rotation := stubRotationPolicy{expired: map[uuid.UUID]bool{expiredID: true}}
+	sink := &recordingActivitySink{}
+	var hooked []types.ActivityRecord
+	cmd := NewPasswordExpirySweepCommand(PasswordExpirySweepCommandConfig{
+		Repository: repo,
+		Inventory:  inventory,
+		Rotation:   rotation,
+		Activity:   sink,
+		Hooks: types.Hooks{AfterActivity: func(_ context.Context, record types.ActivityRecord) {
+			hooked = append(hooked, record)
+		}},
+	})
+
+	var result PasswordExpirySweepResult
+	tenantID := uuid.New()
+	err := cmd.Execute(context.Background(), PasswordExpirySweepInput{
+		Actor:     types.ActorRef{ID: uuid.New(), Type: "system"},
+		Scope:     types.ScopeFilter{TenantID: tenantID},
+		BatchSize: 2,
+		Result:    &result,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Scanned)
+	require.Equal(t, []uuid.UUID{expiredID}, result.Expired)
+	require.True(t, types.PasswordChangeRequired(repo.users[expiredID].Metadata))
+	require.Equal(t, "pro", repo.users[expiredID].Metadata["plan"])
+	require.False(t, types.PasswordChangeRequired(repo.users[freshID].Metadata))
+	require.Len(t, sink.records, 1)
+	require.Equal(t, "user.password.expired", sink.records[0].Verb)
+	require.Equal(t, tenantID, sink.records[0].TenantID)
+	require.Len(t, hooked, 1)
+	require.Equal(t, []int{0, 2}, inventory.offsets)
+	require.Equal(t, []types.LifecycleState{types.LifecycleStateActive}, inventory.lastFilter.Statuses)
+}
+
+func TestPasswordExpirySweepCommand_UsesEachUserScope(t *testing.T) {
+	repo := newFakeAuthRepo()
+	tenantA := uuid.New()
+	tenantB := uuid.New()
+	userA := uuid.New()
+	userB := uuid.New()
+	repo.users[userA] = &types.AuthUser{ID: userA, Status: types.LifecycleStateActive}
+	repo.users[userB] = &types.AuthUser{ID: userB, Status: types.LifecycleStateActive}
+	rotation := stubRotationPolicy{expiredIn: map[uuid.UUID]bool{tenantA: true}}
+	sink := &recordingActivitySink{}
+	cmd := NewPasswordExpirySweepCommand(PasswordExpirySweepCommandConfig{
+		Repository: repo,
+		Inventory:  &pagedInventoryRepo{users: []types.AuthUser{*repo.users[userA], *repo.users[userB]}},
+		Rotation:   rotation,
+		UserScopes: types.ScopeResolverFunc(func(_ context.Context, actor types.ActorRef, requested types.ScopeFilter) (types.ScopeFilter, error) {
+			switch actor.ID {
+			case userA:
+				return types.ScopeFilter{TenantID: tenantA}, nil
+			case userB:
+				return types.ScopeFilter{TenantID: tenantB}, nil
+			}
+			return requested, nil
+		}),
+		Activity: sink,
+	})
+
+	var result PasswordExpirySweepResult
+	require.NoError(t, cmd.Execute(context.Background(), PasswordExpirySweepInput{
+		Actor:  types.ActorRef{ID: uuid.New(), Type: "system"},
+		Result: &result,
+	}))
+	require.Equal(t, []uuid.UUID{userA}, result.Expired)
+	require.Len(t, sink.records, 1)
+	require.Equal(t, tenantA, sink.records[0].TenantID)
+}
+
+func TestPasswordExpirySweepCommand_BaselinesUsersWithoutChangeTime(t *testing.T) {
+	repo := newFakeAuthRepo()
+	legacyID := uuid.New()
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.users[legacyID] = &types.AuthUser{ID: legacyID, Status: types.LifecycleStateActive, CreatedAt: &created}
+	sweptAt := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	cmd := NewPasswordExpirySweepCommand(PasswordExpirySweepCommandConfig{
+		Repository: repo,
+		Inventory:  &pagedInventoryRepo{users: []types.AuthUser{*repo.users[legacyID]}},
+		Rotation:   stubRotationPolicy{maxAge: 30 * 24 * time.Hour},
+		Clock:      fixedClock{t: sweptAt},
+	})
+
+	var result PasswordExpirySweepResult
+	require.NoError(t, cmd.Execute(context.Background(), PasswordExpirySweepInput{
+		Actor:  types.ActorRef{ID: uuid.New(), Type: "system"},
+		Result: &result,
+	}))
+	require.Empty(t, result.Expired)
+	require.Equal(t, []uuid.UUID{legacyID}, result.Baselined)
+	changedAt, ok := types.PasswordChangedAt(repo.users[legacyID].Metadata)
+	require.True(t, ok)
+	require.True(t, sweptAt.Equal(changedAt))
+	require.False(t, types.PasswordChangeRequired(repo.users[legacyID].Metadata))
+}
+
+func TestPasswordExpirySweepCommand_RequiresRotationPolicy(t *testing.T) {
+	cmd := NewPasswordExpirySweepCommand(PasswordExpirySweepCommandConfig{
+		Repository: newFakeAuthRepo(),
+		Inventory:  &pagedInventoryRepo{},
+	})
+	err := cmd.Execute(context.Background(), PasswordExpirySweepInput{Actor: types.ActorRef{ID: uuid.New()}})
+	require.ErrorIs(t, err, types.ErrMissingPasswordRotation)
+}
+
+func TestUserPasswordResetCommand_RecordsPasswordChangeTime(t *testing.T) {
+	userID := uuid.New()
+	repo := &passwordChangeAuthRepo{fakeAuthRepo: newFakeAuthRepo()}
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	changedAt := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	cmd := NewUserPasswordResetCommand(PasswordResetCommandConfig{
+		Repository: repo,
+		Clock:      fixedClock{t: changedAt},
+	})
+
+	err := cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:          userID,
+		NewPasswordHash: "new-hash",
+		Actor:           types.ActorRef{ID: uuid.New()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, changedAt, repo.changedAt[userID])
+}
+
+type passwordChangeAuthRepo struct {
+	*fakeAuthRepo
+	changedAt map[uuid.UUID]time.Time
+}
+
+func (r *passwordChangeAuthRepo) MarkPasswordChanged(_ context.Context, id uuid.UUID, changedAt time.Time) error {
+	if r.changedAt == nil {
+		r.changedAt = make(map[uuid.UUID]time.Time)
+	}
+	r.changedAt[id] = changedAt
+	return nil
+}
+
+type pagedInventoryRepo struct {
+	users      []types.AuthUser
+	offsets    []int
+	lastFilter types.UserInventoryFilter
+}
+
+func (r *pagedInventoryRepo) ListUsers(_ context.Context, filter types.UserInventoryFilter) (types.UserInventoryPage, error) {
+	r.lastFilter = filter
+	r.offsets = append(r.offsets, filter.Pagination.Offset)
+	start := min(filter.Pagination.Offset, len(r.users))
+	end := min(start+filter.Pagination.Limit, len(r.users))
+	return types.UserInventoryPage{
+		Users:      r.users[start:end],
+		Total:      len(r.users),
+		NextOffset: end,
+		HasMore:    end < len(r.users),
+	}, nil
+}
+
+type stubRotationPolicy struct {
+	expired   map[uuid.UUID]bool
+	expiredIn map[uuid.UUID]bool
+	maxAge    time.Duration
+}
+
+func (s stubRotationPolicy) RotationStatus(_ context.Context, user *types.AuthUser, scope types.ScopeFilter) types.PasswordRotationStatus {
+	status := types.PasswordRotationStatus{UserID: user.ID, MaxAge: s.maxAge}
+	status.ChangedAt, _ = types.PasswordChangedAt(user.Metadata)
+	switch {
+	case types.PasswordChangeRequired(user.Metadata):
+		status.Required = true
+		status.Reason = types.PasswordRotationChangeRequired
+	case s.expired[user.ID] || s.expiredIn[scope.TenantID]:
+		status.Required = true
+		status.Reason = types.PasswordRotationExpired
+	}
+	return status
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPasswordResetCommand_RejectsReusedPassword(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	history := &recordingPasswordHistory{reused: "Reused-Password-1"}
+	cmd := NewUserPasswordResetCommand(PasswordResetCommandConfig{
+		Repository: repo,
+		Passwords:  newTestPasswordService(),
+		History:    history,
+	})
+	actor := types.ActorRef{ID: uuid.New()}
+
+	err := cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:      userID,
+		NewPassword: "Reused-Password-1",
+		Actor:       actor,
+	})
+	require.ErrorIs(t, err, types.ErrPasswordReused)
+	require.Empty(t, repo.lastResetHash)
+	require.Empty(t, history.recorded)
+
+	err = cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:      userID,
+		NewPassword: "Fresh-Password-2",
+		Actor:       actor,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{repo.lastResetHash}, history.recorded)
+
+	// Precomputed hashes cannot be compared but are still recorded.
+	err = cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:          userID,
+		NewPasswordHash: "hashed-secret",
+		Actor:           actor,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hashed-secret", history.recorded[1])
+}
+
+func TestUserLifecycleTransitionCommand_ArchivePurgesPasswordHistory(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Status: types.LifecycleStateActive}
+	history := &recordingPasswordHistory{}
+	cmd := NewUserLifecycleTransitionCommand(LifecycleCommandConfig{
+		Repository:      repo,
+		PasswordHistory: history,
+	})
+	actor := types.ActorRef{ID: uuid.New(), Type: "admin"}
+
+	require.NoError(t, cmd.Execute(context.Background(), UserLifecycleTransitionInput{
+		UserID: userID,
+		Target: types.LifecycleStateDisabled,
+		Actor:  actor,
+	}))
+	require.Empty(t, history.purged)
+
+	require.NoError(t, cmd.Execute(context.Background(), UserLifecycleTransitionInput{
+		UserID: userID,
+		Target: types.LifecycleStateArchived,
+		Actor:  actor,
+	}))
+	require.Equal(t, []uuid.UUID{userID}, history.purged)
+}
+
+type recordingPasswordHistory struct {
+	reused   string
+	recorded []string
+	purged   []uuid.UUID
+}
+
+func (h *recordingPasswordHistory) CheckPasswordReuse(_ context.Context, _ uuid.UUID, _ types.ScopeFilter, plaintext string) error {
+	if plaintext == h.reused {
+		return types.ErrPasswordReused
+	}
+	return nil
+}
+
+func (h *recordingPasswordHistory) RecordPasswordHistory(_ context.Context, _ uuid.UUID, _ types.ScopeFilter, passwordHash string) error {
+	h.recorded = append(h.recorded, passwordHash)
+	return nil
+}
+
+func (h *recordingPasswordHistory) PurgePasswordHistory(_ context.Context, userID uuid.UUID) error {
+	h.purged = append(h.purged, userID)
+	return nil
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/password"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestPasswordService() *password.Service {
+	return password.NewService(password.ServiceConfig{
+		Policy: password.DefaultPolicy(),
+		Hasher: password.BcryptHasher{Cost: bcrypt.MinCost},
+	})
+}
+
+func TestUserPasswordResetCommand_HashesPlaintextPassword(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	passwords := newTestPasswordService()
+	cmd := NewUserPasswordResetCommand(PasswordResetCommandConfig{
+		Repository: repo,
+		Passwords:  passwords,
+	})
+
+	err := cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:      userID,
+		NewPassword: "Correct-Horse-42",
+		Actor:       types.ActorRef{ID: uuid.New()},
+	})
+	require.NoError(t, err)
+	ok, err := passwords.Verify(repo.lastResetHash, "Correct-Horse-42")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	repo.lastResetHash = ""
+	err = cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:      userID,
+		NewPassword: "user-Password-1",
+		Actor:       types.ActorRef{ID: uuid.New()},
+	})
+	require.ErrorIs(t, err, types.ErrPasswordPolicy)
+	require.Empty(t, repo.lastResetHash)
+}
+
+func TestUserPasswordResetCommand_PlaintextRequiresPasswordService(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	cmd := NewUserPasswordResetCommand(PasswordResetCommandConfig{Repository: repo})
+
+	err := cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:      userID,
+		NewPassword: "Correct-Horse-42",
+		Actor:       types.ActorRef{ID: uuid.New()},
+	})
+	require.ErrorIs(t, err, types.ErrMissingPasswordService)
+
+	err = cmd.Execute(context.Background(), UserPasswordResetInput{
+		UserID:          userID,
+		NewPassword:     "Correct-Horse-42",
+		NewPasswordHash: "hashed",
+		Actor:           types.ActorRef{ID: uuid.New()},
+	})
+	require.ErrorIs(t, err, ErrPasswordInputConflict)
+}
+
+func TestUserPasswordResetConfirmCommand_PolicyViolationKeepsToken(t *testing.T) {
+	userID := uuid.New()
+	issuedAt := time.Date(2026, 4, 22, 12, 0, 0, 0, time.UTC)
+	repo := newFakeAuthRepo()
+	repo.users[userID] = &types.AuthUser{ID: userID, Email: "user@example.com"}
+	resetRepo := newMemoryResetRepo()
+	_, err := resetRepo.CreateReset(context.Background(), types.PasswordResetRecord{
+		UserID:    userID,
+		Email:     "user@example.com",
+		Status:    types.PasswordResetStatusRequested,
+		JTI:       "reset-jti",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	confirmCmd := NewUserPasswordResetConfirmCommand(PasswordResetConfirmConfig{
+		ResetRepository: resetRepo,
+		SecureLinks: &stubSecureLinkManager{
+			validatePayload: types.SecureLinkPayload{
+				"jti":     "reset-jti",
+				"user_id": userID.String(),
+			},
+		},
+		ResetCommand: NewUserPasswordResetCommand(PasswordResetCommandConfig{
+			Repository: repo,
+			Passwords:  newTestPasswordService(),
+		}),
+		Clock: fixedClock{t: issuedAt},
+	})
+
+	err = confirmCmd.Execute(context.Background(), UserPasswordResetConfirmInput{
+		Token:       "reset-token",
+		NewPassword: "weak",
+	})
+	require.ErrorIs(t, err, types.ErrPasswordPolicy)
+	require.Equal(t, types.PasswordResetStatusRequested, resetRepo.resets["reset-jti"].Status)
+
+	err = confirmCmd.Execute(context.Background(), UserPasswordResetConfirmInput{
+		Token:       "reset-token",
+		NewPassword: "Correct-Horse-42",
+	})
+	require.NoError(t, err)
+	require.Equal(t, types.PasswordResetStatusChanged, resetRepo.resets["reset-jti"].Status)
+	require.Equal(t, userID, repo.lastResetUserID)
+}
+
+func TestUserBootstrapPasswordCommand_PolicyViolationSkipsCreate(t *testing.T) {
+	repo := newFakeAuthRepo()
+	cmd := NewUserBootstrapPasswordCommand(BootstrapPasswordCommandConfig{
+		Repository: repo,
+		Passwords:  newTestPasswordService(),
+	})
+	input := UserBootstrapPasswordInput{
+		User: &types.AuthUser{
+			Email:    "bootstrap@example.com",
+			Username: "bootstrap",
+			Role:     "admin",
+		},
+		Password: "Bootstrap-Admin-1",
+		Actor:    types.ActorRef{ID: uuid.New(), Type: "system"},
+	}
+
+	err := cmd.Execute(context.Background(), input)
+	require.ErrorIs(t, err, types.ErrPasswordPolicy)
+	require.Empty(t, repo.users)
+
+	input.Password = "Correct-Horse-42"
+	result := &UserBootstrapPasswordResult{}
+	input.Result = result
+	require.NoError(t, cmd.Execute(context.Background(), input))
+	require.True(t, result.Created)
+	require.NotEmpty(t, repo.lastResetHash)
+}
@@ -12,10 +12,13 @@ import (
 	"github.com/google/uuid"
 )
 
-// UserPasswordResetInput resets a user's password hash.
+// UserPasswordResetInput resets a user's password. Supply either a
+// precomputed NewPasswordHash or a plaintext NewPassword, which is checked and
+// hashed by the configured password service.
 type UserPasswordResetInput struct {
 	UserID                            uuid.UUID
 	NewPasswordHash                   string
+	NewPassword                       string
 	TokenJTI                          string
 	TokenExpiresAt                    time.Time
 	Actor                             types.ActorRef
@@ -36,8 +39,10 @@ func (input UserPasswordResetInput) Validate() error {
 		return ErrLifecycleUserIDRequired
 	case input.Actor.ID == uuid.Nil:
 		return ErrActorRequired
-	case input.NewPasswordHash == "":
+	case input.NewPasswordHash == "" && input.NewPassword == "":
 		return ErrPasswordHashRequired
+	case input.NewPasswordHash != "" && input.NewPassword != "":
+		return ErrPasswordInputConflict
 	default:
 		return nil
 	}
@@ -50,12 +55,14 @@ type UserPasswordResetResult struct {
 
 // UserPasswordResetCommand wraps the AuthRepository password reset helper.
 type UserPasswordResetCommand struct {
-	repo   types.AuthRepository
-	clock  types.Clock
-	sink   types.ActivitySink
-	hooks  types.Hooks
-	logger types.Logger
-	guard  scope.Guard
+	repo      types.AuthRepository
+	clock     types.Clock
+	sink      types.ActivitySink
+	hooks     types.Hooks
+	logger    types.Logger
+	guard     scope.Guard
+	passwords types.PasswordService
+	history   types.PasswordHistory
 }
 
 // PasswordResetCommandConfig wires the reset handler.
@@ -66,17 +73,25 @@ type PasswordResetCommandConfig struct {
 	Hooks      types.Hooks
 	Logger     types.Logger
 	ScopeGuard scope.Guard
+	// Passwords checks and hashes plaintext NewPassword inputs. Plaintext
+	// resets fail with types.ErrMissingPasswordService when it is nil.
+	Passwords types.PasswordService
+	// History rejects plaintext passwords matching recent ones and records
+	// every applied hash.
+	History types.PasswordHistory
 }
 
 // NewUserPasswordResetCommand builds the handler.
 func NewUserPasswordResetCommand(cfg PasswordResetCommandConfig) *UserPasswordResetCommand {
 	return &UserPasswordResetCommand{
-		repo:   cfg.Repository,
-		clock:  safeClock(cfg.Clock),
-		sink:   safeActivitySink(cfg.Activity),
-		hooks:  safeHooks(cfg.Hooks),
-		logger: safeLogger(cfg.Logger),
-		guard:  safeScopeGuard(cfg.ScopeGuard),
+		repo:      cfg.Repository,
+		clock:     safeClock(cfg.Clock),
+		sink:      safeActivitySink(cfg.Activity),
+		hooks:     safeHooks(cfg.Hooks),
+		logger:    safeLogger(cfg.Logger),
+		guard:     safeScopeGuard(cfg.ScopeGuard),
+		passwords: cfg.Passwords,
+		history:   cfg.History,
 	}
 }
 
@@ -90,6 +105,9 @@ func (c *UserPasswordResetCommand) Execute(ctx context.Context, input UserPasswo
 	if err := input.Validate(); err != nil {
 		return err
 	}
+	if input.NewPassword != "" && c.passwords == nil {
+		return types.ErrMissingPasswordService
+	}
 
 	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionUsersWrite, input.UserID)
 	if err != nil {
@@ -100,10 +118,28 @@ func (c *UserPasswordResetCommand) Execute(ctx context.Context, input UserPasswo
 	if err != nil {
 		return err
 	}
+	passwordHash := input.NewPasswordHash
+	if input.NewPassword != "" {
+		passwordHash, err = c.passwords.HashPassword(ctx, input.NewPassword, user)
+		if err != nil {
+			return err
+		}
+		if c.history != nil {
+			if err := c.history.CheckPasswordReuse(ctx, input.UserID, scope, input.NewPassword); err != nil {
+				return err
+			}
+		}
+	}
 	needsTemporaryCleanup := types.HasTemporaryPasswordMetadata(user.Metadata)
-	if err := c.resetPassword(ctx, input, needsTemporaryCleanup); err != nil {
+	if err := c.resetPassword(ctx, input, passwordHash, needsTemporaryCleanup); err != nil {
 		return err
 	}
+	c.markPasswordChanged(ctx, input.UserID)
+	if c.history != nil {
+		if err := c.history.RecordPasswordHistory(ctx, input.UserID, scope, passwordHash); err != nil {
+			c.logger.Error("password history record failed", err, "user_id", input.UserID)
+		}
+	}
 
 	data := map[string]any{
 		"user_email": user.Email,
@@ -135,16 +171,28 @@ func (c *UserPasswordResetCommand) Execute(ctx context.Context, input UserPasswo
 	return nil
 }
 
-func (c *UserPasswordResetCommand) resetPassword(ctx context.Context, input UserPasswordResetInput, needsTemporaryCleanup bool) error {
+func (c *UserPasswordResetCommand) resetPassword(ctx context.Context, input UserPasswordResetInput, passwordHash string, needsTemporaryCleanup bool) error {
 	if input.PreserveTemporaryPasswordMetadata || !needsTemporaryCleanup {
-		return c.repo.ResetPassword(ctx, input.UserID, input.NewPasswordHash)
+		return c.repo.ResetPassword(ctx, input.UserID, passwordHash)
 	}
 
 	resetRepo, ok := c.repo.(types.TemporaryPasswordResetRepository)
 	if !ok {
 		return types.ErrTemporaryPasswordResetUnsupported
 	}
-	return resetRepo.ResetPasswordAndClearTemporaryPassword(ctx, input.UserID, input.NewPasswordHash)
+	return resetRepo.ResetPasswordAndClearTemporaryPassword(ctx, input.UserID, passwordHash)
+}
+
+// markPasswordChanged records the change time for password age checks when
+// the repository supports it.
+func (c *UserPasswordResetCommand) markPasswordChanged(ctx context.Context, id uuid.UUID) {
+	changes, ok := c.repo.(types.PasswordChangeRepository)
+	if !ok {
+		return
+	}
+	if err := changes.MarkPasswordChanged(ctx, id, now(c.clock)); err != nil {
+		c.logger.Error("password change time record failed", err, "user_id", id)
+	}
 }
 
 func cloneAuthUser(user *types.AuthUser) *types.AuthUser {
@@ -12,10 +12,12 @@ import (
 	"github.com/goliatone/go-users/pkg/types"
 )
 
-// UserPasswordResetConfirmInput validates and consumes a reset token.
+// UserPasswordResetConfirmInput validates and consumes a reset token. Supply
+// either NewPasswordHash or a plaintext NewPassword.
 type UserPasswordResetConfirmInput struct {
 	Token           string
 	NewPasswordHash string
+	NewPassword     string
 	Scope           types.ScopeFilter
 	Result          *UserPasswordResetConfirmResult
 }
@@ -30,8 +32,10 @@ func (input UserPasswordResetConfirmInput) Validate() error {
 	switch {
 	case strings.TrimSpace(input.Token) == "":
 		return ErrTokenRequired
-	case strings.TrimSpace(input.NewPasswordHash) == "":
+	case strings.TrimSpace(input.NewPasswordHash) == "" && input.NewPassword == "":
 		return ErrPasswordHashRequired
+	case strings.TrimSpace(input.NewPasswordHash) != "" && input.NewPassword != "":
+		return ErrPasswordInputConflict
 	default:
 		return nil
 	}
@@ -144,6 +148,7 @@ func (c *UserPasswordResetConfirmCommand) executePasswordReset(ctx context.Conte
 	if err := c.resetCmd.Execute(ctx, UserPasswordResetInput{
 		UserID:          record.UserID,
 		NewPasswordHash: strings.TrimSpace(input.NewPasswordHash),
+		NewPassword:     input.NewPassword,
 		TokenJTI:        jti,
 		TokenExpiresAt:  expiresAt,
 		Actor:           types.ActorRef{ID: record.UserID, Type: "user"},
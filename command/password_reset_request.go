@@ -7,6 +7,7 @@ import (
 
 	gocommand "github.com/goliatone/go-command"
 	featuregate "github.com/goliatone/go-featuregate/gate"
+	"github.com/goliatone/go-users/notification"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/google/uuid"
 )
@@ -56,6 +57,7 @@ type UserPasswordResetRequestCommand struct {
 	tokenTTL    time.Duration
 	featureGate featuregate.FeatureGate
 	route       string
+	notifier    notification.Notifier
 }
 
 // PasswordResetRequestConfig holds dependencies for reset issuance.
@@ -71,6 +73,8 @@ type PasswordResetRequestConfig struct {
 	TokenTTL        time.Duration
 	FeatureGate     featuregate.FeatureGate
 	Route           string
+	// Notifier delivers the reset link when configured.
+	Notifier notification.Notifier
 }
 
 // NewUserPasswordResetRequestCommand constructs the request handler.
@@ -102,6 +106,7 @@ func NewUserPasswordResetRequestCommand(cfg PasswordResetRequestConfig) *UserPas
 		tokenTTL:    ttl,
 		featureGate: cfg.FeatureGate,
 		route:       route,
+		notifier:    cfg.Notifier,
 	}
 }
 
@@ -144,6 +149,7 @@ func (c *UserPasswordResetRequestCommand) Execute(ctx context.Context, input Use
 	}
 
 	c.emitPasswordResetRequestActivity(ctx, user, input, jti, issuedAt, expiresAt)
+	notifyUser(ctx, c.notifier, c.logger, secureLinkNotification(notification.KindPasswordReset, user, input.Actor, input.Scope, token, expiresAt))
 	setPasswordResetRequestResult(input.Result, user, token, expiresAt)
 	return nil
 }
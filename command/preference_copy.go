@@ -0,0 +1,130 @@
+package command
+
+import (
+	"context"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// PreferenceCopyInput copies the tenant- or org-level preferences of Source
+// to Target, for example when an org is cloned.
+type PreferenceCopyInput struct {
+	// Level is the level copied: tenant or org.
+	Level  types.PreferenceLevel
+	Source types.ScopeFilter
+	Target types.ScopeFilter
+	// Keys limits the copy to the listed keys; empty copies every key.
+	Keys []string
+	// Conflict decides what happens to keys that already exist at the target
+	// (defaults to skip).
+	Conflict types.PreferenceConflictPolicy
+	Actor    types.ActorRef
+	Result   *types.PreferenceTransferResult
+}
+
+// Type implements gocommand.Message.
+func (PreferenceCopyInput) Type() string {
+	return "command.preference.copy"
+}
+
+// Validate implements gocommand.Message.
+func (input PreferenceCopyInput) Validate() error {
+	switch input.Level {
+	case types.PreferenceLevelTenant:
+		if input.Source.TenantID == uuid.Nil || input.Target.TenantID == uuid.Nil {
+			return ErrPreferenceCopyScopeRequired
+		}
+		if input.Source.TenantID == input.Target.TenantID {
+			return ErrPreferenceCopySameScope
+		}
+	case types.PreferenceLevelOrg:
+		if input.Source.OrgID == uuid.Nil || input.Target.OrgID == uuid.Nil {
+			return ErrPreferenceCopyScopeRequired
+		}
+		if input.Source.OrgID == input.Target.OrgID && input.Source.TenantID == input.Target.TenantID {
+			return ErrPreferenceCopySameScope
+		}
+	default:
+		return ErrPreferenceCopyLevelInvalid
+	}
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	if _, err := normalizePreferenceConflictPolicy(input.Conflict); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PreferenceCopyCommand copies preferences between tenants or orgs in one
+// transactional bulk write.
+type PreferenceCopyCommand struct {
+	repo   types.PreferenceRepository
+	guard  scope.Guard
+	upsert *PreferenceUpsertManyCommand
+}
+
+// NewPreferenceCopyCommand constructs the handler.
+func NewPreferenceCopyCommand(cfg PreferenceCommandConfig) *PreferenceCopyCommand {
+	return &PreferenceCopyCommand{
+		repo:   cfg.Repository,
+		guard:  safeScopeGuard(cfg.ScopeGuard),
+		upsert: NewPreferenceUpsertManyCommand(cfg),
+	}
+}
+
+var _ gocommand.Commander[PreferenceCopyInput] = (*PreferenceCopyCommand)(nil)
+
+// Execute reads the source preferences and writes them to the target through
+// PreferenceBulkRepository. Either every planned key is written or none is.
+func (c *PreferenceCopyCommand) Execute(ctx context.Context, input PreferenceCopyInput) error {
+	if c.repo == nil {
+		return types.ErrMissingPreferenceRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if _, ok := c.repo.(types.PreferenceBulkRepository); !ok {
+		return types.ErrPreferenceBulkTransactionalUnsupported
+	}
+	policy, _ := normalizePreferenceConflictPolicy(input.Conflict)
+	source, err := c.guard.Enforce(ctx, input.Actor, input.Source, types.PolicyActionPreferencesRead, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	target, err := c.guard.Enforce(ctx, input.Actor, input.Target, types.PolicyActionPreferencesWrite, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	keys := normalizePreferenceKeys(input.Keys)
+	sourceRecords, err := c.repo.ListPreferences(ctx, types.PreferenceFilter{Scope: source, Level: input.Level, Keys: keys})
+	if err != nil {
+		return err
+	}
+	existing, err := c.repo.ListPreferences(ctx, types.PreferenceFilter{Scope: target, Level: input.Level, Keys: keys})
+	if err != nil {
+		return err
+	}
+	_, versioned := c.repo.(types.PreferenceVersionedRepository)
+	records, skipped, err := planPreferenceTransfer(preferenceExportEntries(sourceRecords), existing, policy, versioned, types.PreferenceRecord{
+		Scope:     target,
+		Level:     input.Level,
+		CreatedBy: input.Actor.ID,
+		UpdatedBy: input.Actor.ID,
+	})
+	if err != nil {
+		return err
+	}
+	written, err := writePreferenceTransfer(ctx, c.upsert, uuid.Nil, input.Actor, records, preferenceBulkContext{
+		level: input.Level,
+		mode:  types.PreferenceBulkModeTransactional,
+		scope: target,
+	})
+	if input.Result != nil {
+		*input.Result = types.PreferenceTransferResult{Written: written, Skipped: skipped}
+	}
+	return err
+}
@@ -17,6 +17,8 @@ type PreferenceDeleteInput struct {
 	Level  types.PreferenceLevel
 	Key    string
 	Actor  types.ActorRef
+	// ExpectedVersion deletes only when the stored version matches.
+	ExpectedVersion *int
 }
 
 // Type implements gocommand.Message.
@@ -79,12 +81,14 @@ func (c *PreferenceDeleteCommand) Execute(ctx context.Context, input PreferenceD
 		return err
 	}
 
-	if err := c.repo.DeletePreference(ctx, input.UserID, scope, level, strings.TrimSpace(input.Key)); err != nil {
+	ctx = types.WithPreferenceActor(ctx, input.Actor.ID)
+	if err := c.delete(ctx, input, scope, level); err != nil {
 		return err
 	}
 	emitPreferenceHook(ctx, c.hooks, types.PreferenceEvent{
 		UserID:     input.UserID,
 		Scope:      scope,
+		Level:      level,
 		Key:        strings.TrimSpace(input.Key),
 		Action:     "preference.delete",
 		ActorID:    input.Actor.ID,
@@ -92,3 +96,16 @@ func (c *PreferenceDeleteCommand) Execute(ctx context.Context, input PreferenceD
 	})
 	return nil
 }
+
+func (c *PreferenceDeleteCommand) delete(ctx context.Context, input PreferenceDeleteInput, scope types.ScopeFilter, level types.PreferenceLevel) error {
+	key := strings.TrimSpace(input.Key)
+	if input.ExpectedVersion == nil {
+		return c.repo.DeletePreference(ctx, input.UserID, scope, level, key)
+	}
+	versioned, err := versionedPreferenceRepo(c.repo)
+	if err != nil {
+		return err
+	}
+	expected := map[string]int{key: *input.ExpectedVersion}
+	return versioned.DeletePreferencesIfVersion(ctx, input.UserID, scope, level, []string{key}, expected, types.PreferenceBulkModeBestEffort)
+}
@@ -13,13 +13,16 @@ import (
 
 // PreferenceDeleteManyInput captures a bulk preference delete payload.
 type PreferenceDeleteManyInput struct {
-	UserID  uuid.UUID
-	Scope   types.ScopeFilter
-	Level   types.PreferenceLevel
-	Keys    []string
-	Actor   types.ActorRef
-	Mode    types.PreferenceBulkMode
-	Results *[]types.PreferenceBulkDeleteResult
+	UserID uuid.UUID
+	Scope  types.ScopeFilter
+	Level  types.PreferenceLevel
+	Keys   []string
+	Actor  types.ActorRef
+	Mode   types.PreferenceBulkMode
+	// ExpectedVersions deletes the listed keys only when their stored
+	// versions match. Transactional mode rolls back on any conflict.
+	ExpectedVersions map[string]int
+	Results          *[]types.PreferenceBulkDeleteResult
 }
 
 // Type implements gocommand.Message.
@@ -81,6 +84,12 @@ func (c *PreferenceDeleteManyCommand) Execute(ctx context.Context, input Prefere
 	if err != nil {
 		return err
 	}
+	if len(input.ExpectedVersions) > 0 {
+		if _, err := versionedPreferenceRepo(c.repo); err != nil {
+			return err
+		}
+	}
+	ctx = types.WithPreferenceActor(ctx, input.Actor.ID)
 	var results []types.PreferenceBulkDeleteResult
 	switch bulk.mode {
 	case types.PreferenceBulkModeTransactional:
@@ -116,17 +125,27 @@ func (c *PreferenceDeleteManyCommand) preparePreferenceDeleteMany(ctx context.Co
 }
 
 func (c *PreferenceDeleteManyCommand) deleteManyTransactional(ctx context.Context, input PreferenceDeleteManyInput, bulk preferenceBulkContext) ([]types.PreferenceBulkDeleteResult, error) {
-	bulkRepo, ok := c.repo.(types.PreferenceBulkRepository)
-	if !ok {
-		return nil, types.ErrPreferenceBulkTransactionalUnsupported
-	}
-	if err := bulkRepo.DeleteManyPreferences(ctx, input.UserID, bulk.scope, bulk.level, bulk.keys, types.PreferenceBulkModeTransactional); err != nil {
-		return nil, err
+	if len(input.ExpectedVersions) > 0 {
+		versioned, err := versionedPreferenceRepo(c.repo)
+		if err != nil {
+			return nil, err
+		}
+		if err := versioned.DeletePreferencesIfVersion(ctx, input.UserID, bulk.scope, bulk.level, bulk.keys, c.expectedVersions(input, bulk.keys), types.PreferenceBulkModeTransactional); err != nil {
+			return nil, err
+		}
+	} else {
+		bulkRepo, ok := c.repo.(types.PreferenceBulkRepository)
+		if !ok {
+			return nil, types.ErrPreferenceBulkTransactionalUnsupported
+		}
+		if err := bulkRepo.DeleteManyPreferences(ctx, input.UserID, bulk.scope, bulk.level, bulk.keys, types.PreferenceBulkModeTransactional); err != nil {
+			return nil, err
+		}
 	}
 	results := make([]types.PreferenceBulkDeleteResult, 0, len(bulk.keys))
 	for _, key := range bulk.keys {
 		results = append(results, types.PreferenceBulkDeleteResult{Key: key})
-		c.emitDeleteManyHook(ctx, input, bulk.scope, key)
+		c.emitDeleteManyHook(ctx, input, bulk, key)
 	}
 	return results, nil
 }
@@ -135,23 +154,47 @@ func (c *PreferenceDeleteManyCommand) deleteManyBestEffort(ctx context.Context,
 	results := make([]types.PreferenceBulkDeleteResult, 0, len(bulk.keys))
 	var errs []error
 	for _, key := range bulk.keys {
-		delErr := c.repo.DeletePreference(ctx, input.UserID, bulk.scope, bulk.level, key)
+		delErr := c.deleteKey(ctx, input, bulk, key)
 		result := types.PreferenceBulkDeleteResult{Key: key}
 		if delErr != nil {
 			result.Err = delErr
 			errs = append(errs, fmt.Errorf("preference %q: %w", key, delErr))
 		} else {
-			c.emitDeleteManyHook(ctx, input, bulk.scope, key)
+			c.emitDeleteManyHook(ctx, input, bulk, key)
 		}
 		results = append(results, result)
 	}
 	return results, errors.Join(errs...)
 }
 
-func (c *PreferenceDeleteManyCommand) emitDeleteManyHook(ctx context.Context, input PreferenceDeleteManyInput, scope types.ScopeFilter, key string) {
+func (c *PreferenceDeleteManyCommand) deleteKey(ctx context.Context, input PreferenceDeleteManyInput, bulk preferenceBulkContext, key string) error {
+	expected := expectedPreferenceVersion(input.ExpectedVersions, key)
+	if expected == nil {
+		return c.repo.DeletePreference(ctx, input.UserID, bulk.scope, bulk.level, key)
+	}
+	versioned, err := versionedPreferenceRepo(c.repo)
+	if err != nil {
+		return err
+	}
+	return versioned.DeletePreferencesIfVersion(ctx, input.UserID, bulk.scope, bulk.level, []string{key}, map[string]int{key: *expected}, types.PreferenceBulkModeBestEffort)
+}
+
+// expectedVersions re-keys ExpectedVersions onto the normalized keys.
+func (c *PreferenceDeleteManyCommand) expectedVersions(input PreferenceDeleteManyInput, keys []string) map[string]int {
+	out := make(map[string]int, len(keys))
+	for _, key := range keys {
+		if expected := expectedPreferenceVersion(input.ExpectedVersions, key); expected != nil {
+			out[key] = *expected
+		}
+	}
+	return out
+}
+
+func (c *PreferenceDeleteManyCommand) emitDeleteManyHook(ctx context.Context, input PreferenceDeleteManyInput, bulk preferenceBulkContext, key string) {
 	emitPreferenceHook(ctx, c.hooks, types.PreferenceEvent{
 		UserID:     input.UserID,
-		Scope:      scope,
+		Scope:      bulk.scope,
+		Level:      bulk.level,
 		Key:        key,
 		Action:     "preference.delete",
 		ActorID:    input.Actor.ID,
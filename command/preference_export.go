@@ -0,0 +1,106 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// PreferenceExportInput selects the preferences of one scope and level to
+// export. The document is written to Writer as JSON and/or stored in Result.
+type PreferenceExportInput struct {
+	UserID uuid.UUID
+	Scope  types.ScopeFilter
+	Level  types.PreferenceLevel
+	// Keys limits the export to the listed keys; empty exports every key.
+	Keys   []string
+	Actor  types.ActorRef
+	Writer io.Writer
+	Result *types.PreferenceExport
+}
+
+// Type implements gocommand.Message.
+func (PreferenceExportInput) Type() string {
+	return "command.preference.export"
+}
+
+// Validate implements gocommand.Message.
+func (input PreferenceExportInput) Validate() error {
+	level, err := normalizePreferenceLevel(input.Level)
+	if err != nil {
+		return err
+	}
+	switch {
+	case level == types.PreferenceLevelUser && input.UserID == uuid.Nil:
+		return types.ErrUserIDRequired
+	case input.Actor.ID == uuid.Nil:
+		return ErrActorRequired
+	case input.Writer == nil && input.Result == nil:
+		return ErrPreferenceExportOutputRequired
+	default:
+		return nil
+	}
+}
+
+// PreferenceExportCommand exports scoped preferences as JSON.
+type PreferenceExportCommand struct {
+	repo  types.PreferenceRepository
+	clock types.Clock
+	guard scope.Guard
+}
+
+// NewPreferenceExportCommand constructs the handler.
+func NewPreferenceExportCommand(cfg PreferenceCommandConfig) *PreferenceExportCommand {
+	return &PreferenceExportCommand{
+		repo:  cfg.Repository,
+		clock: safeClock(cfg.Clock),
+		guard: safeScopeGuard(cfg.ScopeGuard),
+	}
+}
+
+var _ gocommand.Commander[PreferenceExportInput] = (*PreferenceExportCommand)(nil)
+
+// Execute lists the selected preferences and writes the export document.
+func (c *PreferenceExportCommand) Execute(ctx context.Context, input PreferenceExportInput) error {
+	if c.repo == nil {
+		return types.ErrMissingPreferenceRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	level, _ := normalizePreferenceLevel(input.Level)
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionPreferencesRead, input.UserID)
+	if err != nil {
+		return err
+	}
+	records, err := c.repo.ListPreferences(ctx, types.PreferenceFilter{
+		UserID: input.UserID,
+		Scope:  scope,
+		Level:  level,
+		Keys:   normalizePreferenceKeys(input.Keys),
+	})
+	if err != nil {
+		return err
+	}
+	export := types.PreferenceExport{
+		Level:       level,
+		ExportedAt:  now(c.clock),
+		Preferences: preferenceExportEntries(records),
+	}
+	if input.Writer != nil {
+		encoder := json.NewEncoder(input.Writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(export); err != nil {
+			return err
+		}
+	}
+	if input.Result != nil {
+		*input.Result = export
+	}
+	return nil
+}
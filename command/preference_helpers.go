@@ -1,9 +1,11 @@
 package command
 
 import (
+	"context"
 	"strings"
 
 	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
 )
 
 func normalizePreferenceLevel(level types.PreferenceLevel) (types.PreferenceLevel, error) {
@@ -11,13 +13,140 @@ func normalizePreferenceLevel(level types.PreferenceLevel) (types.PreferenceLeve
 		return types.PreferenceLevelUser, nil
 	}
 	switch level {
-	case types.PreferenceLevelSystem, types.PreferenceLevelTenant, types.PreferenceLevelOrg, types.PreferenceLevelUser:
+	case types.PreferenceLevelSystem, types.PreferenceLevelTenant, types.PreferenceLevelOrg, types.PreferenceLevelRole, types.PreferenceLevelUser:
 		return level, nil
 	default:
 		return "", types.ErrUnsupportedPreferenceLevel
 	}
 }
 
+func validatePreferenceValue(validator types.PreferenceValidator, key string, level types.PreferenceLevel, value map[string]any) error {
+	if validator == nil {
+		return nil
+	}
+	return validator.ValidatePreference(strings.TrimSpace(key), level, value)
+}
+
+// versionedPreferenceRepo returns the repository's conditional-write extension
+// or ErrPreferenceVersioningUnsupported.
+func versionedPreferenceRepo(repo types.PreferenceRepository) (types.PreferenceVersionedRepository, error) {
+	versioned, ok := repo.(types.PreferenceVersionedRepository)
+	if !ok {
+		return nil, types.ErrPreferenceVersioningUnsupported
+	}
+	return versioned, nil
+}
+
+// expectedPreferenceVersion looks up key in versions case-insensitively.
+func expectedPreferenceVersion(versions map[string]int, key string) *int {
+	key = strings.ToLower(strings.TrimSpace(key))
+	for candidate, version := range versions {
+		if strings.ToLower(strings.TrimSpace(candidate)) == key {
+			return &version
+		}
+	}
+	return nil
+}
+
+func validatePreferenceLock(level types.PreferenceLevel, locked bool) error {
+	if locked && (level == types.PreferenceLevelUser || level == types.PreferenceLevelRole) {
+		return ErrPreferenceLockLevelInvalid
+	}
+	return nil
+}
+
+// storedPreferenceLocks returns the lock flags stored at level for keys,
+// keyed by lower-cased key, so writes that leave Locked nil keep them. It
+// skips the lookup when locked is set or level cannot hold locks.
+func storedPreferenceLocks(ctx context.Context, repo types.PreferenceRepository, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, keys []string, locked *bool) (map[string]bool, error) {
+	if locked != nil || validatePreferenceLock(level, true) != nil {
+		return nil, nil
+	}
+	records, err := repo.ListPreferences(ctx, types.PreferenceFilter{
+		UserID: userID,
+		Scope:  scope,
+		Level:  level,
+		Keys:   keys,
+	})
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(records))
+	for _, record := range records {
+		if record.Locked {
+			stored[strings.ToLower(strings.TrimSpace(record.Key))] = true
+		}
+	}
+	return stored, nil
+}
+
+// preferenceLock returns the lock to write for key: locked when set,
+// otherwise the stored lock.
+func preferenceLock(locked *bool, stored map[string]bool, key string) bool {
+	if locked != nil {
+		return *locked
+	}
+	return stored[strings.ToLower(key)]
+}
+
+// preferenceLocks returns, for each of keys locked at a level outside level,
+// the outermost level holding the lock. Keys are matched case-insensitively.
+func preferenceLocks(ctx context.Context, repo types.PreferenceRepository, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, keys []string) (map[string]types.PreferenceLevel, error) {
+	locks := make(map[string]types.PreferenceLevel)
+	for _, outer := range outerPreferenceLevels(level, scope) {
+		records, err := repo.ListPreferences(ctx, types.PreferenceFilter{
+			UserID: userID,
+			Scope:  scope,
+			Level:  outer,
+			Keys:   keys,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			key := strings.ToLower(strings.TrimSpace(record.Key))
+			if _, held := locks[key]; record.Locked && !held {
+				locks[key] = outer
+			}
+		}
+	}
+	return locks, nil
+}
+
+func ensurePreferenceUnlocked(ctx context.Context, repo types.PreferenceRepository, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, key string) error {
+	locks, err := preferenceLocks(ctx, repo, userID, scope, level, []string{key})
+	if err != nil {
+		return err
+	}
+	if lockedBy, ok := locks[strings.ToLower(key)]; ok {
+		return &types.PreferenceLockedError{Key: key, LockedBy: lockedBy}
+	}
+	return nil
+}
+
+// outerPreferenceLevels lists the levels that take lock precedence over level,
+// outermost first, skipping levels the scope cannot address.
+func outerPreferenceLevels(level types.PreferenceLevel, scope types.ScopeFilter) []types.PreferenceLevel {
+	var outer []types.PreferenceLevel
+	for _, candidate := range []types.PreferenceLevel{types.PreferenceLevelSystem, types.PreferenceLevelTenant, types.PreferenceLevelOrg} {
+		if candidate == level {
+			break
+		}
+		switch candidate {
+		case types.PreferenceLevelTenant:
+			if scope.TenantID == uuid.Nil {
+				continue
+			}
+		case types.PreferenceLevelOrg:
+			if scope.OrgID == uuid.Nil {
+				continue
+			}
+		}
+		outer = append(outer, candidate)
+	}
+	return outer
+}
+
 func normalizePreferenceBulkMode(mode types.PreferenceBulkMode) (types.PreferenceBulkMode, error) {
 	if mode == "" {
 		return types.PreferenceBulkModeBestEffort, nil
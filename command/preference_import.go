@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// PreferenceImportInput imports an export document into one scope and level.
+// The document is read from Reader as JSON, or taken from Document when
+// Reader is nil. Level selects the target level; the document's own level is
+// informational.
+type PreferenceImportInput struct {
+	UserID   uuid.UUID
+	Scope    types.ScopeFilter
+	Level    types.PreferenceLevel
+	Reader   io.Reader
+	Document *types.PreferenceExport
+	// Conflict decides what happens to keys that already exist at the target
+	// (defaults to skip).
+	Conflict types.PreferenceConflictPolicy
+	Mode     types.PreferenceBulkMode
+	Actor    types.ActorRef
+	Result   *types.PreferenceTransferResult
+}
+
+// Type implements gocommand.Message.
+func (PreferenceImportInput) Type() string {
+	return "command.preference.import"
+}
+
+// Validate implements gocommand.Message.
+func (input PreferenceImportInput) Validate() error {
+	level, err := normalizePreferenceLevel(input.Level)
+	if err != nil {
+		return err
+	}
+	if level == types.PreferenceLevelUser && input.UserID == uuid.Nil {
+		return types.ErrUserIDRequired
+	}
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	if input.Reader == nil && input.Document == nil {
+		return ErrPreferenceImportSourceRequired
+	}
+	if _, err := normalizePreferenceConflictPolicy(input.Conflict); err != nil {
+		return err
+	}
+	if _, err := normalizePreferenceBulkMode(input.Mode); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PreferenceImportCommand imports exported preferences with a conflict policy.
+type PreferenceImportCommand struct {
+	repo   types.PreferenceRepository
+	guard  scope.Guard
+	upsert *PreferenceUpsertManyCommand
+}
+
+// NewPreferenceImportCommand constructs the handler.
+func NewPreferenceImportCommand(cfg PreferenceCommandConfig) *PreferenceImportCommand {
+	return &PreferenceImportCommand{
+		repo:   cfg.Repository,
+		guard:  safeScopeGuard(cfg.ScopeGuard),
+		upsert: NewPreferenceUpsertManyCommand(cfg),
+	}
+}
+
+var _ gocommand.Commander[PreferenceImportInput] = (*PreferenceImportCommand)(nil)
+
+// Execute decodes the document, applies the conflict policy against the
+// stored preferences, and writes the remaining entries.
+func (c *PreferenceImportCommand) Execute(ctx context.Context, input PreferenceImportInput) error {
+	if c.repo == nil {
+		return types.ErrMissingPreferenceRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	level, _ := normalizePreferenceLevel(input.Level)
+	policy, _ := normalizePreferenceConflictPolicy(input.Conflict)
+	mode, _ := normalizePreferenceBulkMode(input.Mode)
+	document, err := decodePreferenceImport(input)
+	if err != nil {
+		return err
+	}
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionPreferencesWrite, input.UserID)
+	if err != nil {
+		return err
+	}
+	existing, err := c.repo.ListPreferences(ctx, types.PreferenceFilter{
+		UserID: input.UserID,
+		Scope:  scope,
+		Level:  level,
+	})
+	if err != nil {
+		return err
+	}
+	_, versioned := c.repo.(types.PreferenceVersionedRepository)
+	records, skipped, err := planPreferenceTransfer(document.Preferences, existing, policy, versioned, types.PreferenceRecord{
+		UserID:    input.UserID,
+		Scope:     scope,
+		Level:     level,
+		CreatedBy: input.Actor.ID,
+		UpdatedBy: input.Actor.ID,
+	})
+	if err != nil {
+		return err
+	}
+	written, err := writePreferenceTransfer(ctx, c.upsert, input.UserID, input.Actor, records, preferenceBulkContext{
+		level: level,
+		mode:  mode,
+		scope: scope,
+	})
+	if input.Result != nil {
+		*input.Result = types.PreferenceTransferResult{Written: written, Skipped: skipped}
+	}
+	return err
+}
+
+func decodePreferenceImport(input PreferenceImportInput) (types.PreferenceExport, error) {
+	if input.Reader == nil {
+		return *input.Document, nil
+	}
+	var document types.PreferenceExport
+	if err := json.NewDecoder(input.Reader).Decode(&document); err != nil {
+		return types.PreferenceExport{}, err
+	}
+	return document, nil
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceUpsertCommand_RejectsWritesUnderLock(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	cmd := NewPreferenceUpsertCommand(PreferenceCommandConfig{Repository: repo})
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+	scope := types.ScopeFilter{TenantID: uuid.New()}
+	locked := true
+
+	err := cmd.Execute(ctx, PreferenceUpsertInput{
+		UserID: userID,
+		Key:    "locale",
+		Value:  map[string]any{"value": "en"},
+		Locked: &locked,
+		Actor:  actor,
+	})
+	require.ErrorIs(t, err, ErrPreferenceLockLevelInvalid)
+
+	require.NoError(t, cmd.Execute(ctx, PreferenceUpsertInput{
+		Scope:  scope,
+		Level:  types.PreferenceLevelTenant,
+		Key:    "locale",
+		Value:  map[string]any{"value": "de"},
+		Locked: &locked,
+		Actor:  actor,
+	}))
+
+	err = cmd.Execute(ctx, PreferenceUpsertInput{
+		UserID: userID,
+		Scope:  scope,
+		Key:    "Locale",
+		Value:  map[string]any{"value": "en"},
+		Actor:  actor,
+	})
+	var lockErr *types.PreferenceLockedError
+	require.ErrorAs(t, err, &lockErr)
+	require.Equal(t, types.PreferenceLevelTenant, lockErr.LockedBy)
+
+	// Users outside the tenant are unaffected.
+	require.NoError(t, cmd.Execute(ctx, PreferenceUpsertInput{
+		UserID: userID,
+		Key:    "locale",
+		Value:  map[string]any{"value": "en"},
+		Actor:  actor,
+	}))
+
+	var results []types.PreferenceBulkUpsertResult
+	err = NewPreferenceUpsertManyCommand(PreferenceCommandConfig{Repository: repo}).Execute(ctx, PreferenceUpsertManyInput{
+		UserID:  userID,
+		Scope:   scope,
+		Actor:   actor,
+		Values:  map[string]any{"locale": "en", "theme": "dark"},
+		Results: &results,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceLocked)
+	require.Len(t, results, 2)
+	require.ErrorIs(t, results[0].Err, types.ErrPreferenceLocked)
+	require.NoError(t, results[1].Err)
+}
+
+func TestPreferenceUpsertCommands_KeepStoredLockUnlessSet(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	cfg := PreferenceCommandConfig{Repository: repo}
+	upsert := NewPreferenceUpsertCommand(cfg)
+	upsertMany := NewPreferenceUpsertManyCommand(cfg)
+	actor := types.ActorRef{ID: uuid.New()}
+	scope := types.ScopeFilter{TenantID: uuid.New()}
+	locked, unlocked := true, false
+
+	var record types.PreferenceRecord
+	require.NoError(t, upsert.Execute(ctx, PreferenceUpsertInput{
+		Scope: scope, Level: types.PreferenceLevelTenant, Actor: actor,
+		Key: "locale", Value: map[string]any{"value": "de"}, Locked: &locked,
+	}))
+	require.NoError(t, upsert.Execute(ctx, PreferenceUpsertInput{
+		Scope: scope, Level: types.PreferenceLevelTenant, Actor: actor,
+		Key: "Locale", Value: map[string]any{"value": "fr"}, Result: &record,
+	}))
+	require.True(t, record.Locked)
+
+	var results []types.PreferenceBulkUpsertResult
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		Scope: scope, Level: types.PreferenceLevelTenant, Actor: actor,
+		Values: map[string]any{"locale": "it", "theme": "dark"}, Results: &results,
+	}))
+	require.Len(t, results, 2)
+	require.True(t, results[0].Record.Locked)
+	require.False(t, results[1].Record.Locked)
+
+	require.NoError(t, upsert.Execute(ctx, PreferenceUpsertInput{
+		Scope: scope, Level: types.PreferenceLevelTenant, Actor: actor,
+		Key: "locale", Value: map[string]any{"value": "it"}, Locked: &unlocked, Result: &record,
+	}))
+	require.False(t, record.Locked)
+}
@@ -0,0 +1,152 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// PreferenceRestoreInput restores a preference key to a past version.
+type PreferenceRestoreInput struct {
+	UserID uuid.UUID
+	Scope  types.ScopeFilter
+	Level  types.PreferenceLevel
+	Key    string
+	// Version is the historical version whose value is written back.
+	Version int
+	Actor   types.ActorRef
+	Result  *types.PreferenceRecord
+}
+
+// Type implements gocommand.Message.
+func (PreferenceRestoreInput) Type() string {
+	return "command.preference.restore"
+}
+
+// Validate implements gocommand.Message.
+func (input PreferenceRestoreInput) Validate() error {
+	if strings.TrimSpace(input.Key) == "" {
+		return ErrPreferenceKeyRequired
+	}
+	if input.Version <= 0 {
+		return ErrPreferenceVersionRequired
+	}
+	level, err := normalizePreferenceLevel(input.Level)
+	if err != nil {
+		return err
+	}
+	if level == types.PreferenceLevelUser && input.UserID == uuid.Nil {
+		return types.ErrUserIDRequired
+	}
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	return nil
+}
+
+// PreferenceRestoreCommand writes the value a key held at a past version as a
+// new version, so history stays append-only.
+type PreferenceRestoreCommand struct {
+	repo      types.PreferenceRepository
+	hooks     types.Hooks
+	clock     types.Clock
+	guard     scope.Guard
+	validator types.PreferenceValidator
+}
+
+// NewPreferenceRestoreCommand constructs the restore handler.
+func NewPreferenceRestoreCommand(cfg PreferenceCommandConfig) *PreferenceRestoreCommand {
+	return &PreferenceRestoreCommand{
+		repo:      cfg.Repository,
+		hooks:     safeHooks(cfg.Hooks),
+		clock:     safeClock(cfg.Clock),
+		guard:     safeScopeGuard(cfg.ScopeGuard),
+		validator: cfg.Validator,
+	}
+}
+
+var _ gocommand.Commander[PreferenceRestoreInput] = (*PreferenceRestoreCommand)(nil)
+
+// Execute looks up the historical value and upserts it. The write goes through
+// the same schema and lock checks as a regular upsert.
+func (c *PreferenceRestoreCommand) Execute(ctx context.Context, input PreferenceRestoreInput) error {
+	if c.repo == nil {
+		return types.ErrMissingPreferenceRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	history, ok := c.repo.(types.PreferenceHistoryRepository)
+	if !ok {
+		return types.ErrPreferenceHistoryUnsupported
+	}
+	level, err := normalizePreferenceLevel(input.Level)
+	if err != nil {
+		return err
+	}
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionPreferencesWrite, input.UserID)
+	if err != nil {
+		return err
+	}
+	key := strings.TrimSpace(input.Key)
+	entry, err := findPreferenceVersion(ctx, history, input.UserID, scope, level, key, input.Version)
+	if err != nil {
+		return err
+	}
+	if err := validatePreferenceValue(c.validator, key, level, entry.Value); err != nil {
+		return err
+	}
+	if err := ensurePreferenceUnlocked(ctx, c.repo, input.UserID, scope, level, key); err != nil {
+		return err
+	}
+
+	saved, err := c.repo.UpsertPreference(ctx, types.PreferenceRecord{
+		UserID:    input.UserID,
+		Scope:     scope,
+		Level:     level,
+		Key:       key,
+		Value:     cloneMap(entry.Value),
+		Locked:    entry.Locked && validatePreferenceLock(level, true) == nil,
+		UpdatedBy: input.Actor.ID,
+		CreatedBy: input.Actor.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if input.Result != nil && saved != nil {
+		*input.Result = *saved
+	}
+	emitPreferenceHook(ctx, c.hooks, types.PreferenceEvent{
+		UserID:     input.UserID,
+		Scope:      scope,
+		Level:      level,
+		Key:        key,
+		Action:     "preference.restore",
+		ActorID:    input.Actor.ID,
+		OccurredAt: now(c.clock),
+	})
+	return nil
+}
+
+func findPreferenceVersion(ctx context.Context, history types.PreferenceHistoryRepository, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, key string, version int) (*types.PreferenceHistoryEntry, error) {
+	entries, err := history.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{
+		UserID:  userID,
+		Scope:   scope,
+		Level:   level,
+		Keys:    []string{key},
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Action == types.PreferenceHistoryUpsert {
+			return &entry, nil
+		}
+	}
+	return nil, types.ErrPreferenceHistoryNotFound
+}
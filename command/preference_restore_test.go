@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceRestoreCommand_WritesPastValueAsNewVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	var events []types.PreferenceEvent
+	cfg := PreferenceCommandConfig{Repository: repo, Hooks: types.Hooks{
+		AfterPreferenceChange: func(_ context.Context, e types.PreferenceEvent) {
+			events = append(events, e)
+		},
+	}}
+	upsert := NewPreferenceUpsertCommand(cfg)
+	restore := NewPreferenceRestoreCommand(cfg)
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+
+	for _, theme := range []string{"light", "dark"} {
+		require.NoError(t, upsert.Execute(ctx, PreferenceUpsertInput{
+			UserID: userID,
+			Key:    "theme",
+			Value:  map[string]any{"value": theme},
+			Actor:  actor,
+		}))
+	}
+
+	var restored types.PreferenceRecord
+	require.NoError(t, restore.Execute(ctx, PreferenceRestoreInput{
+		UserID:  userID,
+		Key:     "theme",
+		Version: 1,
+		Actor:   actor,
+		Result:  &restored,
+	}))
+	require.Equal(t, 3, restored.Version)
+	require.Equal(t, "light", restored.Value["value"])
+	require.Equal(t, "preference.restore", events[len(events)-1].Action)
+
+	err := restore.Execute(ctx, PreferenceRestoreInput{UserID: userID, Key: "theme", Version: 9, Actor: actor})
+	require.ErrorIs(t, err, types.ErrPreferenceHistoryNotFound)
+
+	err = NewPreferenceRestoreCommand(PreferenceCommandConfig{Repository: &fakePreferenceRepo{}}).Execute(ctx, PreferenceRestoreInput{
+		UserID:  userID,
+		Key:     "theme",
+		Version: 1,
+		Actor:   actor,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceHistoryUnsupported)
+	require.ErrorIs(t, restore.Execute(ctx, PreferenceRestoreInput{UserID: userID, Key: "theme", Actor: actor}), ErrPreferenceVersionRequired)
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPreferenceSchema() *preferences.SchemaRegistry {
+	return preferences.NewSchemaRegistry().MustRegister(
+		preferences.KeyDefinition{Key: "theme", Type: preferences.ValueTypeString, Enum: []any{"light", "dark"}},
+		preferences.KeyDefinition{
+			Key:    "billing.currency",
+			Type:   preferences.ValueTypeString,
+			Levels: []types.PreferenceLevel{types.PreferenceLevelTenant},
+		},
+	)
+}
+
+func TestPreferenceUpsertCommand_RejectsInvalidValues(t *testing.T) {
+	repo := &fakePreferenceRepo{}
+	cmd := NewPreferenceUpsertCommand(PreferenceCommandConfig{Repository: repo, Validator: newTestPreferenceSchema()})
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+
+	err := cmd.Execute(context.Background(), PreferenceUpsertInput{
+		UserID: userID,
+		Level:  types.PreferenceLevelUser,
+		Key:    "theme",
+		Value:  map[string]any{"value": "neon"},
+		Actor:  actor,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceValueInvalid)
+
+	err = cmd.Execute(context.Background(), PreferenceUpsertInput{
+		UserID: userID,
+		Level:  types.PreferenceLevelUser,
+		Key:    "billing.currency",
+		Value:  map[string]any{"value": "EUR"},
+		Actor:  actor,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceLevelNotAllowed)
+	require.Empty(t, repo.upserts)
+
+	require.NoError(t, cmd.Execute(context.Background(), PreferenceUpsertInput{
+		UserID: userID,
+		Level:  types.PreferenceLevelUser,
+		Key:    "theme",
+		Value:  map[string]any{"value": "dark"},
+		Actor:  actor,
+	}))
+	require.Len(t, repo.upserts, 1)
+}
+
+func TestPreferenceUpsertManyCommand_SchemaValidation(t *testing.T) {
+	actor := types.ActorRef{ID: uuid.New()}
+	values := map[string]any{"theme": "neon", "locale": "en"}
+
+	repo := &bulkRepoStub{}
+	cmd := NewPreferenceUpsertManyCommand(PreferenceCommandConfig{Repository: repo, Validator: newTestPreferenceSchema()})
+	var results []types.PreferenceBulkUpsertResult
+	err := cmd.Execute(context.Background(), PreferenceUpsertManyInput{
+		UserID:  uuid.New(),
+		Level:   types.PreferenceLevelUser,
+		Actor:   actor,
+		Values:  values,
+		Results: &results,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceValueInvalid)
+	require.Len(t, repo.upserted, 1)
+	require.Equal(t, "locale", repo.upserted[0].Key)
+	require.Len(t, results, 2)
+	require.Equal(t, "locale", results[0].Key)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "theme", results[1].Key)
+	require.ErrorIs(t, results[1].Err, types.ErrPreferenceValueInvalid)
+
+	repo = &bulkRepoStub{}
+	cmd = NewPreferenceUpsertManyCommand(PreferenceCommandConfig{Repository: repo, Validator: newTestPreferenceSchema()})
+	err = cmd.Execute(context.Background(), PreferenceUpsertManyInput{
+		UserID: uuid.New(),
+		Level:  types.PreferenceLevelUser,
+		Actor:  actor,
+		Mode:   types.PreferenceBulkModeTransactional,
+		Values: values,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceValueInvalid)
+	require.Empty(t, repo.upserted)
+}
@@ -0,0 +1,114 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+func normalizePreferenceConflictPolicy(policy types.PreferenceConflictPolicy) (types.PreferenceConflictPolicy, error) {
+	switch policy {
+	case "":
+		return types.PreferenceConflictSkip, nil
+	case types.PreferenceConflictSkip, types.PreferenceConflictOverwrite, types.PreferenceConflictKeepHigherVersion:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", types.ErrUnsupportedPreferenceConflictPolicy, policy)
+	}
+}
+
+// planPreferenceTransfer turns incoming entries into the records to write at
+// the target described by base, applying policy to keys that already exist
+// there. Locks are dropped at levels that cannot hold them, and writes are
+// made conditional on the versions read when the repository supports it.
+func planPreferenceTransfer(entries []types.PreferenceExportEntry, existing []types.PreferenceRecord, policy types.PreferenceConflictPolicy, versioned bool, base types.PreferenceRecord) ([]types.PreferenceRecord, []string, error) {
+	current := make(map[string]types.PreferenceRecord, len(existing))
+	for _, record := range existing {
+		current[strings.ToLower(strings.TrimSpace(record.Key))] = record
+	}
+	seen := make(map[string]string, len(entries))
+	records := make([]types.PreferenceRecord, 0, len(entries))
+	var skipped []string
+	for _, entry := range entries {
+		key := strings.TrimSpace(entry.Key)
+		if key == "" {
+			return nil, nil, ErrPreferenceKeyRequired
+		}
+		if entry.Value == nil {
+			return nil, nil, ErrPreferenceValueRequired
+		}
+		lower := strings.ToLower(key)
+		if prior, ok := seen[lower]; ok {
+			return nil, nil, fmt.Errorf("%w: %q conflicts with %q", ErrPreferenceDuplicateKey, key, prior)
+		}
+		seen[lower] = key
+		target, exists := current[lower]
+		if exists && !preferenceTransferOverwrites(policy, entry, target) {
+			skipped = append(skipped, key)
+			continue
+		}
+		record := base
+		record.Key = key
+		record.Value = cloneMap(entry.Value)
+		record.Locked = entry.Locked && validatePreferenceLock(base.Level, true) == nil
+		if versioned {
+			expected := target.Version
+			record.ExpectedVersion = &expected
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Key < records[j].Key })
+	sort.Strings(skipped)
+	return records, skipped, nil
+}
+
+func preferenceTransferOverwrites(policy types.PreferenceConflictPolicy, entry types.PreferenceExportEntry, target types.PreferenceRecord) bool {
+	switch policy {
+	case types.PreferenceConflictOverwrite:
+		return true
+	case types.PreferenceConflictKeepHigherVersion:
+		return entry.Version > target.Version
+	default:
+		return false
+	}
+}
+
+// writePreferenceTransfer validates and persists planned records through the
+// bulk upsert pipeline so imports and copies honor schemas, outer-level locks,
+// and hooks exactly like PreferenceUpsertManyCommand.
+func writePreferenceTransfer(ctx context.Context, cmd *PreferenceUpsertManyCommand, userID uuid.UUID, actor types.ActorRef, records []types.PreferenceRecord, bulk preferenceBulkContext) ([]types.PreferenceBulkUpsertResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	bulk.keys = make([]string, len(records))
+	for i, record := range records {
+		bulk.keys[i] = record.Key
+	}
+	input := PreferenceUpsertManyInput{
+		UserID: userID,
+		Scope:  bulk.scope,
+		Level:  bulk.level,
+		Actor:  actor,
+		Mode:   bulk.mode,
+	}
+	return cmd.write(ctx, input, records, bulk)
+}
+
+// preferenceExportEntries converts stored records into transfer entries.
+func preferenceExportEntries(records []types.PreferenceRecord) []types.PreferenceExportEntry {
+	entries := make([]types.PreferenceExportEntry, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, types.PreferenceExportEntry{
+			Key:     record.Key,
+			Value:   cloneMap(record.Value),
+			Version: record.Version,
+			Locked:  record.Locked,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
@@ -0,0 +1,172 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceExportImport_ConflictPolicies(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	cfg := PreferenceCommandConfig{Repository: repo}
+	upsertMany := NewPreferenceUpsertManyCommand(cfg)
+	actor := types.ActorRef{ID: uuid.New()}
+	source, target := uuid.New(), uuid.New()
+
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		UserID: source, Actor: actor, Values: map[string]any{"theme": "dark", "locale": "fr", "density": "compact"},
+	}))
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		UserID: source, Actor: actor, Values: map[string]any{"theme": "dark"},
+	}))
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		UserID: target, Actor: actor, Values: map[string]any{"theme": "light", "locale": "en"},
+	}))
+
+	var buf bytes.Buffer
+	var export types.PreferenceExport
+	require.NoError(t, NewPreferenceExportCommand(cfg).Execute(ctx, PreferenceExportInput{
+		UserID: source, Actor: actor, Writer: &buf, Result: &export,
+	}))
+	require.Equal(t, types.PreferenceLevelUser, export.Level)
+	require.Len(t, export.Preferences, 3)
+	require.Equal(t, "density", export.Preferences[0].Key)
+	require.Contains(t, buf.String(), `"key": "theme"`)
+
+	cases := []struct {
+		policy  types.PreferenceConflictPolicy
+		written []string
+		skipped []string
+		theme   string
+		locale  string
+	}{
+		{policy: types.PreferenceConflictSkip, written: []string{"density"}, skipped: []string{"locale", "theme"}, theme: "light", locale: "en"},
+		{policy: types.PreferenceConflictKeepHigherVersion, written: []string{"density", "theme"}, skipped: []string{"locale"}, theme: "dark", locale: "en"},
+		{policy: types.PreferenceConflictOverwrite, written: []string{"density", "locale", "theme"}, theme: "dark", locale: "fr"},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			userID := uuid.New()
+			require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+				UserID: userID, Actor: actor, Values: map[string]any{"theme": "light", "locale": "en"},
+			}))
+			var result types.PreferenceTransferResult
+			require.NoError(t, NewPreferenceImportCommand(cfg).Execute(ctx, PreferenceImportInput{
+				UserID:   userID,
+				Reader:   bytes.NewReader(buf.Bytes()),
+				Conflict: tc.policy,
+				Actor:    actor,
+				Result:   &result,
+			}))
+			var written []string
+			for _, res := range result.Written {
+				require.NoError(t, res.Err)
+				written = append(written, res.Key)
+			}
+			require.Equal(t, tc.written, written)
+			require.Equal(t, tc.skipped, result.Skipped)
+
+			values := preferenceValues(t, repo, userID, types.ScopeFilter{}, types.PreferenceLevelUser)
+			require.Equal(t, tc.theme, values["theme"])
+			require.Equal(t, tc.locale, values["locale"])
+			require.Equal(t, "compact", values["density"])
+		})
+	}
+
+	err := NewPreferenceImportCommand(cfg).Execute(ctx, PreferenceImportInput{
+		UserID: target, Document: &export, Conflict: "newest", Actor: actor,
+	})
+	require.ErrorIs(t, err, types.ErrUnsupportedPreferenceConflictPolicy)
+}
+
+func TestPreferenceCopy_OrgToOrgIsTransactional(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	cfg := PreferenceCommandConfig{Repository: repo}
+	upsertMany := NewPreferenceUpsertManyCommand(cfg)
+	actor := types.ActorRef{ID: uuid.New()}
+	tenantID := uuid.New()
+	source := types.ScopeFilter{TenantID: tenantID, OrgID: uuid.New()}
+	target := types.ScopeFilter{TenantID: tenantID, OrgID: uuid.New()}
+	locked := true
+
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		Scope: source, Level: types.PreferenceLevelOrg, Actor: actor, Locked: &locked,
+		Values: map[string]any{"theme": "dark", "locale": "fr"},
+	}))
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		Scope: target, Level: types.PreferenceLevelOrg, Actor: actor,
+		Values: map[string]any{"locale": "en"},
+	}))
+
+	var result types.PreferenceTransferResult
+	require.NoError(t, NewPreferenceCopyCommand(cfg).Execute(ctx, PreferenceCopyInput{
+		Level:  types.PreferenceLevelOrg,
+		Source: source,
+		Target: target,
+		Actor:  actor,
+		Result: &result,
+	}))
+	require.Len(t, result.Written, 1)
+	require.Equal(t, "theme", result.Written[0].Key)
+	require.True(t, result.Written[0].Record.Locked)
+	require.Equal(t, []string{"locale"}, result.Skipped)
+
+	values := preferenceValues(t, repo, uuid.Nil, target, types.PreferenceLevelOrg)
+	require.Equal(t, map[string]any{"theme": "dark", "locale": "en"}, values)
+
+	// A tenant lock on one key rejects the whole copy.
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		Scope: types.ScopeFilter{TenantID: tenantID}, Level: types.PreferenceLevelTenant, Actor: actor, Locked: &locked,
+		Values: map[string]any{"locale": "de"},
+	}))
+	other := types.ScopeFilter{TenantID: tenantID, OrgID: uuid.New()}
+	err := NewPreferenceCopyCommand(cfg).Execute(ctx, PreferenceCopyInput{
+		Level:    types.PreferenceLevelOrg,
+		Source:   source,
+		Target:   other,
+		Conflict: types.PreferenceConflictOverwrite,
+		Actor:    actor,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceLocked)
+	require.Empty(t, preferenceValues(t, repo, uuid.Nil, other, types.PreferenceLevelOrg))
+}
+
+func TestPreferenceCopy_Validation(t *testing.T) {
+	ctx := context.Background()
+	actor := types.ActorRef{ID: uuid.New()}
+	orgA := types.ScopeFilter{OrgID: uuid.New()}
+	orgB := types.ScopeFilter{OrgID: uuid.New()}
+
+	err := NewPreferenceCopyCommand(PreferenceCommandConfig{Repository: newPreferenceTestRepo(t)}).Execute(ctx, PreferenceCopyInput{
+		Level: types.PreferenceLevelUser, Source: orgA, Target: orgB, Actor: actor,
+	})
+	require.ErrorIs(t, err, ErrPreferenceCopyLevelInvalid)
+
+	err = NewPreferenceCopyCommand(PreferenceCommandConfig{Repository: newPreferenceTestRepo(t)}).Execute(ctx, PreferenceCopyInput{
+		Level: types.PreferenceLevelOrg, Source: orgA, Target: orgA, Actor: actor,
+	})
+	require.ErrorIs(t, err, ErrPreferenceCopySameScope)
+
+	plain := struct{ types.PreferenceRepository }{newPreferenceTestRepo(t)}
+	err = NewPreferenceCopyCommand(PreferenceCommandConfig{Repository: plain}).Execute(ctx, PreferenceCopyInput{
+		Level: types.PreferenceLevelOrg, Source: orgA, Target: orgB, Actor: actor,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceBulkTransactionalUnsupported)
+}
+
+func preferenceValues(t *testing.T, repo types.PreferenceRepository, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel) map[string]any {
+	t.Helper()
+	records, err := repo.ListPreferences(context.Background(), types.PreferenceFilter{UserID: userID, Scope: scope, Level: level})
+	require.NoError(t, err)
+	values := make(map[string]any, len(records))
+	for _, record := range records {
+		values[record.Key] = record.Value["value"]
+	}
+	return values
+}
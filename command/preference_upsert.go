@@ -16,6 +16,8 @@ type PreferenceCommandConfig struct {
 	Hooks      types.Hooks
 	Clock      types.Clock
 	ScopeGuard scope.Guard
+	// Validator checks values against the preference schema before writes.
+	Validator types.PreferenceValidator
 }
 
 // PreferenceUpsertInput captures a preference mutation payload.
@@ -26,7 +28,14 @@ type PreferenceUpsertInput struct {
 	Key    string
 	Value  map[string]any
 	Actor  types.ActorRef
-	Result *types.PreferenceRecord
+	// Locked stops inner levels from overriding the key (system, tenant, and
+	// org levels only). Nil keeps the lock already stored for the key.
+	Locked *bool
+	// ExpectedVersion makes the write conditional on the stored version (0
+	// means the key must not exist yet). Conflicts return a
+	// *types.PreferenceVersionConflictError.
+	ExpectedVersion *int
+	Result          *types.PreferenceRecord
 }
 
 // Type implements gocommand.Message.
@@ -49,6 +58,9 @@ func (input PreferenceUpsertInput) Validate() error {
 	if level == types.PreferenceLevelUser && input.UserID == uuid.Nil {
 		return types.ErrUserIDRequired
 	}
+	if err := validatePreferenceLock(level, input.Locked != nil && *input.Locked); err != nil {
+		return err
+	}
 	if input.Actor.ID == uuid.Nil {
 		return ErrActorRequired
 	}
@@ -57,19 +69,21 @@ func (input PreferenceUpsertInput) Validate() error {
 
 // PreferenceUpsertCommand upserts a scoped preference record.
 type PreferenceUpsertCommand struct {
-	repo  types.PreferenceRepository
-	hooks types.Hooks
-	clock types.Clock
-	guard scope.Guard
+	repo      types.PreferenceRepository
+	hooks     types.Hooks
+	clock     types.Clock
+	guard     scope.Guard
+	validator types.PreferenceValidator
 }
 
 // NewPreferenceUpsertCommand constructs the handler.
 func NewPreferenceUpsertCommand(cfg PreferenceCommandConfig) *PreferenceUpsertCommand {
 	return &PreferenceUpsertCommand{
-		repo:  cfg.Repository,
-		hooks: safeHooks(cfg.Hooks),
-		clock: safeClock(cfg.Clock),
-		guard: safeScopeGuard(cfg.ScopeGuard),
+		repo:      cfg.Repository,
+		hooks:     safeHooks(cfg.Hooks),
+		clock:     safeClock(cfg.Clock),
+		guard:     safeScopeGuard(cfg.ScopeGuard),
+		validator: cfg.Validator,
 	}
 }
 
@@ -93,16 +107,37 @@ func (c *PreferenceUpsertCommand) Execute(ctx context.Context, input PreferenceU
 	if err != nil {
 		return err
 	}
+	if err := validatePreferenceValue(c.validator, input.Key, level, input.Value); err != nil {
+		return err
+	}
+	if input.ExpectedVersion != nil {
+		if _, err := versionedPreferenceRepo(c.repo); err != nil {
+			return err
+		}
+	}
+	key := strings.TrimSpace(input.Key)
+	if err := ensurePreferenceUnlocked(ctx, c.repo, input.UserID, scope, level, key); err != nil {
+		return err
+	}
+	stored, err := storedPreferenceLocks(ctx, c.repo, input.UserID, scope, level, []string{key}, input.Locked)
+	if err != nil {
+		return err
+	}
 
 	record := types.PreferenceRecord{
 		UserID:    input.UserID,
 		Scope:     scope,
 		Level:     level,
-		Key:       strings.TrimSpace(input.Key),
+		Key:       key,
 		Value:     cloneMap(input.Value),
+		Locked:    preferenceLock(input.Locked, stored, key),
 		UpdatedBy: input.Actor.ID,
 		CreatedBy: input.Actor.ID,
 	}
+	if input.ExpectedVersion != nil {
+		expected := *input.ExpectedVersion
+		record.ExpectedVersion = &expected
+	}
 	saved, err := c.repo.UpsertPreference(ctx, record)
 	if err != nil {
 		return err
@@ -113,6 +148,7 @@ func (c *PreferenceUpsertCommand) Execute(ctx context.Context, input PreferenceU
 	emitPreferenceHook(ctx, c.hooks, types.PreferenceEvent{
 		UserID:     input.UserID,
 		Scope:      scope,
+		Level:      level,
 		Key:        record.Key,
 		Action:     "preference.upsert",
 		ActorID:    input.Actor.ID,
@@ -15,13 +15,19 @@ import (
 
 // PreferenceUpsertManyInput captures a bulk preference upsert payload.
 type PreferenceUpsertManyInput struct {
-	UserID  uuid.UUID
-	Scope   types.ScopeFilter
-	Level   types.PreferenceLevel
-	Values  map[string]any
-	Actor   types.ActorRef
-	Mode    types.PreferenceBulkMode
-	Results *[]types.PreferenceBulkUpsertResult
+	UserID uuid.UUID
+	Scope  types.ScopeFilter
+	Level  types.PreferenceLevel
+	Values map[string]any
+	Actor  types.ActorRef
+	Mode   types.PreferenceBulkMode
+	// Locked locks or unlocks every written key (system, tenant, and org
+	// levels only). Nil keeps the lock already stored for each key.
+	Locked *bool
+	// ExpectedVersions makes writes for the listed keys conditional on their
+	// stored versions. Transactional mode rolls back on any conflict.
+	ExpectedVersions map[string]int
+	Results          *[]types.PreferenceBulkUpsertResult
 }
 
 // Type implements gocommand.Message.
@@ -38,6 +44,9 @@ func (input PreferenceUpsertManyInput) Validate() error {
 	if level == types.PreferenceLevelUser && input.UserID == uuid.Nil {
 		return types.ErrUserIDRequired
 	}
+	if err := validatePreferenceLock(level, input.Locked != nil && *input.Locked); err != nil {
+		return err
+	}
 	if input.Actor.ID == uuid.Nil {
 		return ErrActorRequired
 	}
@@ -60,19 +69,21 @@ func (input PreferenceUpsertManyInput) Validate() error {
 
 // PreferenceUpsertManyCommand upserts many scoped preference entries.
 type PreferenceUpsertManyCommand struct {
-	repo  types.PreferenceRepository
-	hooks types.Hooks
-	clock types.Clock
-	guard scope.Guard
+	repo      types.PreferenceRepository
+	hooks     types.Hooks
+	clock     types.Clock
+	guard     scope.Guard
+	validator types.PreferenceValidator
 }
 
 // NewPreferenceUpsertManyCommand constructs the handler.
 func NewPreferenceUpsertManyCommand(cfg PreferenceCommandConfig) *PreferenceUpsertManyCommand {
 	return &PreferenceUpsertManyCommand{
-		repo:  cfg.Repository,
-		hooks: safeHooks(cfg.Hooks),
-		clock: safeClock(cfg.Clock),
-		guard: safeScopeGuard(cfg.ScopeGuard),
+		repo:      cfg.Repository,
+		hooks:     safeHooks(cfg.Hooks),
+		clock:     safeClock(cfg.Clock),
+		guard:     safeScopeGuard(cfg.ScopeGuard),
+		validator: cfg.Validator,
 	}
 }
 
@@ -90,6 +101,25 @@ func (c *PreferenceUpsertManyCommand) Execute(ctx context.Context, input Prefere
 	if err != nil {
 		return err
 	}
+	if len(input.ExpectedVersions) > 0 {
+		if _, err := versionedPreferenceRepo(c.repo); err != nil {
+			return err
+		}
+	}
+	results, err := c.write(ctx, input, records, bulk)
+	if input.Results != nil {
+		*input.Results = append((*input.Results)[:0], results...)
+	}
+	return err
+}
+
+// write validates and persists prepared records in the requested bulk mode.
+// Best-effort results include the keys rejected by validation.
+func (c *PreferenceUpsertManyCommand) write(ctx context.Context, input PreferenceUpsertManyInput, records []types.PreferenceRecord, bulk preferenceBulkContext) ([]types.PreferenceBulkUpsertResult, error) {
+	records, rejected, err := c.validateRecords(ctx, input, records, bulk)
+	if err != nil {
+		return nil, err
+	}
 	var results []types.PreferenceBulkUpsertResult
 	switch bulk.mode {
 	case types.PreferenceBulkModeTransactional:
@@ -97,10 +127,16 @@ func (c *PreferenceUpsertManyCommand) Execute(ctx context.Context, input Prefere
 	case types.PreferenceBulkModeBestEffort:
 		results, err = c.executeBestEffortUpserts(ctx, input, records, bulk.scope)
 	}
-	if input.Results != nil {
-		*input.Results = append((*input.Results)[:0], results...)
+	if len(rejected) > 0 {
+		errs := []error{err}
+		for _, result := range rejected {
+			errs = append(errs, fmt.Errorf("preference %q: %w", result.Key, result.Err))
+		}
+		results = append(results, rejected...)
+		sort.Slice(results, func(i, j int) bool { return results[i].Key < results[j].Key })
+		err = errors.Join(errs...)
 	}
-	return err
+	return results, err
 }
 
 func (c *PreferenceUpsertManyCommand) preparePreferenceUpsertMany(ctx context.Context, input PreferenceUpsertManyInput) (preferenceBulkContext, []types.PreferenceRecord, error) {
@@ -120,14 +156,45 @@ func (c *PreferenceUpsertManyCommand) preparePreferenceUpsertMany(ctx context.Co
 	if err != nil {
 		return preferenceBulkContext{}, nil, err
 	}
-	records, err := buildPreferenceRecords(input, scope, level, values, keys)
+	stored, err := storedPreferenceLocks(ctx, c.repo, input.UserID, scope, level, keys, input.Locked)
+	if err != nil {
+		return preferenceBulkContext{}, nil, err
+	}
+	records, err := buildPreferenceRecords(input, scope, level, values, keys, stored)
 	if err != nil {
 		return preferenceBulkContext{}, nil, err
 	}
 	return preferenceBulkContext{level: level, mode: mode, scope: scope, keys: keys}, records, nil
 }
 
-func buildPreferenceRecords(input PreferenceUpsertManyInput, scope types.ScopeFilter, level types.PreferenceLevel, values map[string]any, keys []string) ([]types.PreferenceRecord, error) {
+// validateRecords checks records against the schema and outer-level locks.
+// Transactional writes fail on the first rejected record; best-effort writes
+// skip rejected records and report them as per-key results.
+func (c *PreferenceUpsertManyCommand) validateRecords(ctx context.Context, input PreferenceUpsertManyInput, records []types.PreferenceRecord, bulk preferenceBulkContext) ([]types.PreferenceRecord, []types.PreferenceBulkUpsertResult, error) {
+	locks, err := preferenceLocks(ctx, c.repo, input.UserID, bulk.scope, bulk.level, bulk.keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	valid := make([]types.PreferenceRecord, 0, len(records))
+	var rejected []types.PreferenceBulkUpsertResult
+	for _, record := range records {
+		err := validatePreferenceValue(c.validator, record.Key, record.Level, record.Value)
+		if lockedBy, ok := locks[strings.ToLower(record.Key)]; ok && err == nil {
+			err = &types.PreferenceLockedError{Key: record.Key, LockedBy: lockedBy}
+		}
+		if err == nil {
+			valid = append(valid, record)
+			continue
+		}
+		if bulk.mode == types.PreferenceBulkModeTransactional {
+			return nil, nil, err
+		}
+		rejected = append(rejected, types.PreferenceBulkUpsertResult{Key: record.Key, Err: err})
+	}
+	return valid, rejected, nil
+}
+
+func buildPreferenceRecords(input PreferenceUpsertManyInput, scope types.ScopeFilter, level types.PreferenceLevel, values map[string]any, keys []string, stored map[string]bool) ([]types.PreferenceRecord, error) {
 	records := make([]types.PreferenceRecord, 0, len(keys))
 	for _, key := range keys {
 		payload, err := coercePreferencePayload(values[key])
@@ -135,13 +202,15 @@ func buildPreferenceRecords(input PreferenceUpsertManyInput, scope types.ScopeFi
 			return nil, err
 		}
 		records = append(records, types.PreferenceRecord{
-			UserID:    input.UserID,
-			Scope:     scope,
-			Level:     level,
-			Key:       key,
-			Value:     payload,
-			UpdatedBy: input.Actor.ID,
-			CreatedBy: input.Actor.ID,
+			UserID:          input.UserID,
+			Scope:           scope,
+			Level:           level,
+			Key:             key,
+			Value:           payload,
+			Locked:          preferenceLock(input.Locked, stored, key),
+			UpdatedBy:       input.Actor.ID,
+			CreatedBy:       input.Actor.ID,
+			ExpectedVersion: expectedPreferenceVersion(input.ExpectedVersions, key),
 		})
 	}
 	return records, nil
@@ -161,6 +230,7 @@ func (c *PreferenceUpsertManyCommand) executeBestEffortUpserts(ctx context.Conte
 			emitPreferenceHook(ctx, c.hooks, types.PreferenceEvent{
 				UserID:     input.UserID,
 				Scope:      scope,
+				Level:      record.Level,
 				Key:        record.Key,
 				Action:     "preference.upsert",
 				ActorID:    input.Actor.ID,
@@ -195,6 +265,7 @@ func (c *PreferenceUpsertManyCommand) executeTransactional(ctx context.Context,
 			emitPreferenceHook(ctx, c.hooks, types.PreferenceEvent{
 				UserID:     input.UserID,
 				Scope:      record.Scope,
+				Level:      record.Level,
 				Key:        record.Key,
 				Action:     "preference.upsert",
 				ActorID:    input.Actor.ID,
@@ -0,0 +1,130 @@
+package command
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceCommands_ExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	cfg := PreferenceCommandConfig{Repository: repo}
+	upsert := NewPreferenceUpsertCommand(cfg)
+	deleteCmd := NewPreferenceDeleteCommand(cfg)
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+	version := func(v int) *int { return &v }
+
+	var saved types.PreferenceRecord
+	require.NoError(t, upsert.Execute(ctx, PreferenceUpsertInput{
+		UserID:          userID,
+		Key:             "theme",
+		Value:           map[string]any{"value": "light"},
+		Actor:           actor,
+		ExpectedVersion: version(0),
+		Result:          &saved,
+	}))
+	require.Equal(t, 1, saved.Version)
+
+	// A second tab still holding version 0 loses.
+	err := upsert.Execute(ctx, PreferenceUpsertInput{
+		UserID:          userID,
+		Key:             "theme",
+		Value:           map[string]any{"value": "dark"},
+		Actor:           actor,
+		ExpectedVersion: version(0),
+	})
+	var conflict *types.PreferenceVersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, "light", conflict.Current.Value["value"])
+
+	err = deleteCmd.Execute(ctx, PreferenceDeleteInput{UserID: userID, Key: "theme", Actor: actor, ExpectedVersion: version(3)})
+	require.ErrorIs(t, err, types.ErrPreferenceVersionConflict)
+	require.NoError(t, deleteCmd.Execute(ctx, PreferenceDeleteInput{UserID: userID, Key: "theme", Actor: actor, ExpectedVersion: version(1)}))
+}
+
+func TestPreferenceBulkCommands_ExpectedVersions(t *testing.T) {
+	ctx := context.Background()
+	repo := newPreferenceTestRepo(t)
+	cfg := PreferenceCommandConfig{Repository: repo}
+	upsertMany := NewPreferenceUpsertManyCommand(cfg)
+	deleteMany := NewPreferenceDeleteManyCommand(cfg)
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+
+	require.NoError(t, upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		UserID: userID,
+		Actor:  actor,
+		Values: map[string]any{"theme": "light", "locale": "en"},
+	}))
+
+	err := upsertMany.Execute(ctx, PreferenceUpsertManyInput{
+		UserID:           userID,
+		Actor:            actor,
+		Mode:             types.PreferenceBulkModeTransactional,
+		Values:           map[string]any{"theme": "dark", "locale": "fr"},
+		ExpectedVersions: map[string]int{"theme": 1, "locale": 7},
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceVersionConflict)
+	rows, err := repo.ListPreferences(ctx, types.PreferenceFilter{UserID: userID, Level: types.PreferenceLevelUser})
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.Equal(t, 1, row.Version, "transaction rolled back %s", row.Key)
+	}
+
+	var results []types.PreferenceBulkDeleteResult
+	err = deleteMany.Execute(ctx, PreferenceDeleteManyInput{
+		UserID:           userID,
+		Actor:            actor,
+		Keys:             []string{"theme", "locale"},
+		ExpectedVersions: map[string]int{"Theme": 1, "locale": 2},
+		Results:          &results,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceVersionConflict)
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, types.ErrPreferenceVersionConflict)
+}
+
+func TestPreferenceCommands_ExpectedVersionRequiresVersionedRepository(t *testing.T) {
+	expected := 1
+	cmd := NewPreferenceUpsertCommand(PreferenceCommandConfig{Repository: &fakePreferenceRepo{}})
+	err := cmd.Execute(context.Background(), PreferenceUpsertInput{
+		UserID:          uuid.New(),
+		Key:             "theme",
+		Value:           map[string]any{"value": "dark"},
+		Actor:           types.ActorRef{ID: uuid.New()},
+		ExpectedVersion: &expected,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceVersioningUnsupported)
+}
+
+func newPreferenceTestRepo(t *testing.T) *preferences.Repository {
+	db := newActivityTestDB(t)
+	for _, path := range []string{
+		"../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql",
+		"../data/sql/migrations/sqlite/00012_preference_locks.up.sql",
+		"../data/sql/migrations/sqlite/00013_preference_history.up.sql",
+		"../data/sql/migrations/sqlite/00014_preference_roles.up.sql",
+	} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for _, stmt := range splitSQLStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
+	}
+	repo, err := preferences.NewRepository(preferences.RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	return repo
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// ProfileFieldUpsertInput creates or replaces a custom profile field
+// definition. The definition's tenant comes from the enforced scope; an empty
+// scope declares a global field.
+type ProfileFieldUpsertInput struct {
+	Definition types.ProfileFieldDefinition
+	Scope      types.ScopeFilter
+	Actor      types.ActorRef
+	Result     *types.ProfileFieldDefinition
+}
+
+// Type implements gocommand.Message.
+func (ProfileFieldUpsertInput) Type() string {
+	return "command.profile.field.upsert"
+}
+
+// Validate implements gocommand.Message.
+func (input ProfileFieldUpsertInput) Validate() error {
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	def := input.Definition
+	def.Normalize()
+	return def.Validate()
+}
+
+// ProfileFieldUpsertCommand stores custom profile field definitions.
+type ProfileFieldUpsertCommand struct {
+	repo  types.ProfileFieldRepository
+	guard scope.Guard
+}
+
+// NewProfileFieldUpsertCommand constructs the definition upsert handler.
+func NewProfileFieldUpsertCommand(cfg ProfileCommandConfig) *ProfileFieldUpsertCommand {
+	return &ProfileFieldUpsertCommand{
+		repo:  cfg.Fields,
+		guard: safeScopeGuard(cfg.ScopeGuard),
+	}
+}
+
+var _ gocommand.Commander[ProfileFieldUpsertInput] = (*ProfileFieldUpsertCommand)(nil)
+
+// Execute validates and persists the definition.
+func (c *ProfileFieldUpsertCommand) Execute(ctx context.Context, input ProfileFieldUpsertInput) error {
+	if c.repo == nil {
+		return types.ErrMissingProfileFieldRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionProfileFieldsWrite, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	def := input.Definition
+	def.Normalize()
+	def.TenantID = scope.TenantID
+	stored, err := c.repo.UpsertProfileField(ctx, def)
+	if err != nil {
+		return err
+	}
+	if input.Result != nil && stored != nil {
+		*input.Result = *stored
+	}
+	return nil
+}
+
+// ProfileFieldDeleteInput removes a custom profile field definition from the
+// enforced scope's tenant. Values stored on profiles are kept.
+type ProfileFieldDeleteInput struct {
+	Key   string
+	Scope types.ScopeFilter
+	Actor types.ActorRef
+}
+
+// Type implements gocommand.Message.
+func (ProfileFieldDeleteInput) Type() string {
+	return "command.profile.field.delete"
+}
+
+// Validate implements gocommand.Message.
+func (input ProfileFieldDeleteInput) Validate() error {
+	if strings.TrimSpace(input.Key) == "" {
+		return types.ErrProfileFieldKeyRequired
+	}
+	if input.Actor.ID == uuid.Nil {
+		return ErrActorRequired
+	}
+	return nil
+}
+
+// ProfileFieldDeleteCommand removes custom profile field definitions.
+type ProfileFieldDeleteCommand struct {
+	repo  types.ProfileFieldRepository
+	guard scope.Guard
+}
+
+// NewProfileFieldDeleteCommand constructs the definition delete handler.
+func NewProfileFieldDeleteCommand(cfg ProfileCommandConfig) *ProfileFieldDeleteCommand {
+	return &ProfileFieldDeleteCommand{
+		repo:  cfg.Fields,
+		guard: safeScopeGuard(cfg.ScopeGuard),
+	}
+}
+
+var _ gocommand.Commander[ProfileFieldDeleteInput] = (*ProfileFieldDeleteCommand)(nil)
+
+// Execute removes the definition for the supplied key.
+func (c *ProfileFieldDeleteCommand) Execute(ctx context.Context, input ProfileFieldDeleteInput) error {
+	if c.repo == nil {
+		return types.ErrMissingProfileFieldRepository
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	scope, err := c.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionProfileFieldsWrite, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	return c.repo.DeleteProfileField(ctx, scope.TenantID, strings.TrimSpace(input.Key))
+}
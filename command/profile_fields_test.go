@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileUpsertCommand_ValidatesCustomFields(t *testing.T) {
+	ctx := context.Background()
+	fields := &memoryProfileFields{}
+	repo := &uniqueProfileRepo{taken: map[string]bool{"B-2": true}}
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+	cfg := ProfileCommandConfig{Repository: repo, Fields: fields}
+
+	for _, def := range []types.ProfileFieldDefinition{
+		{Key: "department", Type: types.ProfileFieldTypeEnum, Options: []string{"eng", "ops"}, Required: true},
+		{Key: "badge", Type: types.ProfileFieldTypeString, Unique: true},
+		{Key: "startDate", Type: types.ProfileFieldTypeDate},
+	} {
+		require.NoError(t, NewProfileFieldUpsertCommand(cfg).Execute(ctx, ProfileFieldUpsertInput{Definition: def, Actor: actor}))
+	}
+	upsert := NewProfileUpsertCommand(cfg)
+
+	err := upsert.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Actor:  actor,
+		Patch: types.ProfilePatch{CustomFields: map[string]any{
+			"department": "",
+			"badge":      "B-2",
+			"startDate":  "yesterday",
+			"shoeSize":   42,
+		}},
+	})
+	require.ErrorIs(t, err, types.ErrProfileFieldUnknown)
+	require.ErrorIs(t, err, types.ErrProfileFieldRequired)
+	require.ErrorIs(t, err, types.ErrProfileFieldInvalid)
+	require.ErrorIs(t, err, types.ErrProfileFieldNotUnique)
+	require.Nil(t, repo.stored)
+
+	var result types.UserProfile
+	require.NoError(t, upsert.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Actor:  actor,
+		Patch: types.ProfilePatch{CustomFields: map[string]any{
+			"Department": "eng",
+			"badge":      "A-1",
+			"startdate":  "2026-01-05",
+		}},
+		Result: &result,
+	}))
+	require.Equal(t, map[string]any{"department": "eng", "badge": "A-1", "startDate": "2026-01-05"}, result.CustomFields)
+
+	// Unchanged unique values are not re-checked; nil removes a field.
+	repo.taken["A-1"] = true
+	require.NoError(t, upsert.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Actor:  actor,
+		Patch:  types.ProfilePatch{CustomFields: map[string]any{"startDate": nil}},
+		Result: &result,
+	}))
+	require.Equal(t, map[string]any{"department": "eng", "badge": "A-1"}, result.CustomFields)
+
+	plain := &fakeProfileRepo{}
+	err = NewProfileUpsertCommand(ProfileCommandConfig{Repository: plain, Fields: fields}).Execute(ctx, ProfileUpsertInput{
+		UserID: uuid.New(),
+		Actor:  actor,
+		Patch:  types.ProfilePatch{CustomFields: map[string]any{"department": "ops", "badge": "Z-9"}},
+	})
+	require.ErrorIs(t, err, types.ErrProfileFieldUniquenessUnsupported)
+
+	require.NoError(t, NewProfileFieldDeleteCommand(cfg).Execute(ctx, ProfileFieldDeleteInput{Key: "startDate", Actor: actor}))
+	require.Len(t, fields.defs, 2)
+	err = NewProfileFieldDeleteCommand(ProfileCommandConfig{}).Execute(ctx, ProfileFieldDeleteInput{Key: "badge", Actor: actor})
+	require.ErrorIs(t, err, types.ErrMissingProfileFieldRepository)
+}
+
+func TestProfileUpsertCommand_ValidatesOnlyPatchedCustomFields(t *testing.T) {
+	ctx := context.Background()
+	fields := &memoryProfileFields{}
+	actor := types.ActorRef{ID: uuid.New()}
+	userID := uuid.New()
+	repo := &fakeProfileRepo{stored: &types.UserProfile{
+		UserID:       userID,
+		CustomFields: map[string]any{"legacyCode": "X-1", "team": "core"},
+	}}
+	cfg := ProfileCommandConfig{Repository: repo, Fields: fields}
+	for _, def := range []types.ProfileFieldDefinition{
+		{Key: "team", Type: types.ProfileFieldTypeString},
+		{Key: "costCenter", Type: types.ProfileFieldTypeString, Required: true},
+	} {
+		require.NoError(t, NewProfileFieldUpsertCommand(cfg).Execute(ctx, ProfileFieldUpsertInput{Definition: def, Actor: actor}))
+	}
+	upsert := NewProfileUpsertCommand(cfg)
+
+	// legacyCode has no definition and costCenter is required but unset;
+	// neither blocks a write that does not touch them.
+	display := "Ada"
+	var result types.UserProfile
+	require.NoError(t, upsert.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Actor:  actor,
+		Patch:  types.ProfilePatch{DisplayName: &display},
+		Result: &result,
+	}))
+	require.Equal(t, "Ada", result.DisplayName)
+	require.Equal(t, map[string]any{"legacyCode": "X-1", "team": "core"}, result.CustomFields)
+
+	require.NoError(t, upsert.Execute(ctx, ProfileUpsertInput{
+		UserID: userID,
+		Actor:  actor,
+		Patch:  types.ProfilePatch{CustomFields: map[string]any{"Team": "infra", "legacyCode": nil}},
+		Result: &result,
+	}))
+	require.Equal(t, map[string]any{"team": "infra"}, result.CustomFields)
+}
+
+type uniqueProfileRepo struct {
+	fakeProfileRepo
+	taken map[string]bool
+}
+
+func (r *uniqueProfileRepo) ProfileFieldValueTaken(_ context.Context, _ uuid.UUID, _ string, value any, _ uuid.UUID) (bool, error) {
+	text, _ := value.(string)
+	return r.taken[text], nil
+}
+
+type memoryProfileFields struct {
+	defs []types.ProfileFieldDefinition
+}
+
+func (m *memoryProfileFields) ListProfileFields(context.Context, uuid.UUID) ([]types.ProfileFieldDefinition, error) {
+	return append([]types.ProfileFieldDefinition(nil), m.defs...), nil
+}
+
+func (m *memoryProfileFields) UpsertProfileField(_ context.Context, def types.ProfileFieldDefinition) (*types.ProfileFieldDefinition, error) {
+	m.defs = append(m.defs, def)
+	return &def, nil
+}
+
+func (m *memoryProfileFields) DeleteProfileField(_ context.Context, _ uuid.UUID, key string) error {
+	for i, def := range m.defs {
+		if def.Key == key {
+			m.defs = append(m.defs[:i], m.defs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestProfileFieldCommands_RequireProfileFieldsWrite(t *testing.T) {
+	ctx := context.Background()
+	var actions []types.PolicyAction
+	guard := scope.NewGuard(nil, types.AuthorizationPolicyFunc(func(_ context.Context, check types.PolicyCheck) error {
+		actions = append(actions, check.Action)
+		if check.Action != types.PolicyActionProfileFieldsWrite {
+			return types.ErrUnauthorizedScope
+		}
+		return nil
+	}))
+	cfg := ProfileCommandConfig{Fields: &memoryProfileFields{}, ScopeGuard: guard}
+	actor := types.ActorRef{ID: uuid.New()}
+
+	require.NoError(t, NewProfileFieldUpsertCommand(cfg).Execute(ctx, ProfileFieldUpsertInput{
+		Definition: types.ProfileFieldDefinition{Key: "badge", Type: types.ProfileFieldTypeString},
+		Actor:      actor,
+	}))
+	require.NoError(t, NewProfileFieldDeleteCommand(cfg).Execute(ctx, ProfileFieldDeleteInput{Key: "badge", Actor: actor}))
+	require.Equal(t, []types.PolicyAction{types.PolicyActionProfileFieldsWrite, types.PolicyActionProfileFieldsWrite}, actions)
+}
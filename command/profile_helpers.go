@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// profileDraft is the starting point for a profile write in one scope.
+type profileDraft struct {
+	// profile is the row to write. When the scope has no row yet it starts
+	// empty, so the new row only holds the fields the write sets and the
+	// broader scopes keep supplying the rest.
+	profile *types.UserProfile
+	// stored is the row saved for exactly the scope, or nil.
+	stored *types.UserProfile
+	// inherited is the profile resolved from the scopes broader than scope,
+	// or nil when none of them has a row.
+	inherited *types.UserProfile
+	// effective is the resolved profile before the write, or nil when the
+	// user has no profile in any scope of the chain.
+	effective *types.UserProfile
+}
+
+// loadProfileDraft loads the stored and resolved profiles for scope.
+func loadProfileDraft(ctx context.Context, repo types.ProfileRepository, userID uuid.UUID, scope types.ScopeFilter) (profileDraft, error) {
+	stored, err := repo.GetProfile(ctx, userID, scope)
+	if err != nil {
+		return profileDraft{}, err
+	}
+	draft := profileDraft{
+		profile: &types.UserProfile{UserID: userID, Scope: scope},
+	}
+	if chain := types.ProfileScopeChain(scope); len(chain) > 1 {
+		if draft.inherited, err = types.ResolveProfile(ctx, repo, userID, chain[1]); err != nil {
+			return profileDraft{}, err
+		}
+	}
+	if stored != nil {
+		snapshot := *stored
+		draft.stored = &snapshot
+		*draft.profile = *stored
+		effective := draft.resolve(*stored)
+		draft.effective = &effective
+	} else if draft.inherited != nil {
+		effective := *draft.inherited
+		draft.effective = &effective
+	}
+	return draft, nil
+}
+
+// resolve returns profile, a row of the draft's scope, merged over the
+// inherited profile: what readers of the scope see once the row is saved.
+func (d profileDraft) resolve(profile types.UserProfile) types.UserProfile {
+	if d.inherited == nil {
+		return profile
+	}
+	return types.MergeProfiles(profile, *d.inherited)
+}
+
+// checkVersion compares expected with the version of the profile the caller
+// read (the stored row, or the resolved fallback when the scope has no row
+// yet) and returns the version the write must be conditional on.
+func (d profileDraft) checkVersion(userID uuid.UUID, expected int) (int, error) {
+	read := d.stored
+	if read == nil {
+		read = d.effective
+	}
+	if err := checkProfileVersion(userID, expected, read); err != nil {
+		return 0, err
+	}
+	if d.stored == nil {
+		return 0, nil
+	}
+	return d.stored.Version, nil
+}
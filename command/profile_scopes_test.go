@@ -0,0 +1,128 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/goliatone/go-users/query"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// TestProfileCommands_TenantScopeFallsBackToMigratedGlobalProfile covers
+// profiles that migration 00017 moved to the global scope and that are then
+// read and written through a tenant scope.
+func TestProfileCommands_TenantScopeFallsBackToMigratedGlobalProfile(t *testing.T) {
+	ctx := context.Background()
+	db := newProfileScopesTestDB(t)
+	userID := uuid.New()
+	tenantID := uuid.New()
+	_, err := db.ExecContext(ctx, `INSERT INTO user_profiles (user_id, display_name, contact, custom_fields, tenant_id, created_by, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID.String(), "Legacy", `{"phone":"+15550100"}`, `{"badge":"B-1"}`, tenantID.String(), userID.String(), userID.String())
+	require.NoError(t, err)
+	applyProfileScopesMigrations(t, db, "../data/sql/migrations/sqlite/00017_profile_scopes.up.sql")
+
+	profiles, err := profile.NewRepository(profile.RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	fields, err := profile.NewFieldRepository(profile.FieldRepositoryConfig{DB: db})
+	require.NoError(t, err)
+	_, err = fields.UpsertProfileField(ctx, types.ProfileFieldDefinition{Key: "badge", Type: types.ProfileFieldTypeString, Required: true})
+	require.NoError(t, err)
+
+	actor := types.ActorRef{ID: userID, Type: "user"}
+	tenantScope := types.ScopeFilter{TenantID: tenantID}
+
+	read, err := query.NewProfileQuery(profiles, nil).Query(ctx, query.ProfileQueryInput{UserID: userID, Actor: actor, Scope: tenantScope})
+	require.NoError(t, err)
+	require.NotNil(t, read)
+	require.Equal(t, "Legacy", read.DisplayName)
+
+	sink := &recordingActivitySink{}
+	bio := "Hello"
+	expected := read.Version
+	var result types.UserProfile
+	require.NoError(t, NewProfileUpsertCommand(ProfileCommandConfig{
+		Repository: profiles,
+		Activity:   sink,
+		Fields:     fields,
+	}).Execute(ctx, ProfileUpsertInput{
+		UserID:          userID,
+		Patch:           types.ProfilePatch{Bio: &bio},
+		Scope:           tenantScope,
+		Actor:           actor,
+		ExpectedVersion: &expected,
+		Result:          &result,
+	}))
+	require.Equal(t, "Legacy", result.DisplayName)
+	require.Equal(t, "B-1", result.CustomFields["badge"])
+	require.Equal(t, tenantID, result.Scope.TenantID)
+	require.Len(t, sink.records, 1)
+	require.Equal(t, false, sink.records[0].Data["created"])
+	changes, ok := sink.records[0].Data[activity.DataKeyChanges].([]map[string]any)
+	require.True(t, ok)
+	require.Len(t, changes, 1)
+	require.Equal(t, "bio", changes[0]["field"])
+
+	global, err := profiles.GetProfile(ctx, userID, types.ScopeFilter{})
+	require.NoError(t, err)
+	require.Empty(t, global.Bio)
+
+	// The tenant row only holds what the write set, so later global changes
+	// keep showing through the tenant scope.
+	stored, err := profiles.GetProfile(ctx, userID, tenantScope)
+	require.NoError(t, err)
+	require.Equal(t, "Hello", stored.Bio)
+	require.Empty(t, stored.DisplayName)
+	require.Empty(t, stored.CustomFields)
+	global.DisplayName = "Renamed"
+	_, err = profiles.UpsertProfile(ctx, *global)
+	require.NoError(t, err)
+	read, err = query.NewProfileQuery(profiles, nil).Query(ctx, query.ProfileQueryInput{UserID: userID, Actor: actor, Scope: tenantScope})
+	require.NoError(t, err)
+	require.Equal(t, "Renamed", read.DisplayName)
+	require.Equal(t, "Hello", read.Bio)
+
+	old, err := currentContactValue(ctx, profiles, &types.AuthUser{ID: userID}, types.ContactChannelPhone, types.ScopeFilter{TenantID: uuid.New()})
+	require.NoError(t, err)
+	require.Equal(t, "+15550100", old)
+}
+
+func newProfileScopesTestDB(t *testing.T) *bun.DB {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = sqlDB.Close()
+	})
+	applyProfileScopesMigrations(t, db,
+		"../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql",
+		"../data/sql/migrations/sqlite/00015_profile_custom_fields.up.sql",
+		"../data/sql/migrations/sqlite/00016_profile_versions.up.sql",
+	)
+	return db
+}
+
+func applyProfileScopesMigrations(t *testing.T, db *bun.DB, paths ...string) {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for _, stmt := range splitSQLStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
+	}
+}
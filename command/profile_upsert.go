@@ -2,8 +2,15 @@ package command
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
 
 	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -12,9 +19,18 @@ import (
 // ProfileCommandConfig wires dependencies for profile commands.
 type ProfileCommandConfig struct {
 	Repository types.ProfileRepository
+	Activity   types.ActivitySink
 	Hooks      types.Hooks
 	Clock      types.Clock
 	ScopeGuard scope.Guard
+	// Fields enables validation of ProfilePatch.CustomFields against the
+	// tenant's custom field definitions. Without it custom fields are stored
+	// as given.
+	Fields types.ProfileFieldRepository
+	// RequirePhoneVerification rejects patches that change the contact phone
+	// with ErrContactVerificationRequired so they go through the contact
+	// verification commands instead.
+	RequirePhoneVerification bool
 }
 
 // ProfileUpsertInput captures a profile patch request.
@@ -23,7 +39,13 @@ type ProfileUpsertInput struct {
 	Patch  types.ProfilePatch
 	Scope  types.ScopeFilter
 	Actor  types.ActorRef
-	Result *types.UserProfile
+	// ExpectedVersion makes the write conditional on the version of the
+	// profile the caller read: the one stored for the scope, or the resolved
+	// fallback when the scope has none yet (0 means no profile exists).
+	// Conflicts return a *types.ProfileVersionConflictError carrying the
+	// current profile.
+	ExpectedVersion *int
+	Result          *types.UserProfile
 }
 
 // Type implements gocommand.Message.
@@ -44,25 +66,34 @@ func (input ProfileUpsertInput) Validate() error {
 
 // ProfileUpsertCommand applies profile patches for a user.
 type ProfileUpsertCommand struct {
-	repo  types.ProfileRepository
-	hooks types.Hooks
-	clock types.Clock
-	guard scope.Guard
+	repo                     types.ProfileRepository
+	sink                     types.ActivitySink
+	hooks                    types.Hooks
+	clock                    types.Clock
+	guard                    scope.Guard
+	fields                   types.ProfileFieldRepository
+	requirePhoneVerification bool
 }
 
 // NewProfileUpsertCommand constructs the profile command handler.
 func NewProfileUpsertCommand(cfg ProfileCommandConfig) *ProfileUpsertCommand {
 	return &ProfileUpsertCommand{
-		repo:  cfg.Repository,
-		hooks: safeHooks(cfg.Hooks),
-		clock: safeClock(cfg.Clock),
-		guard: safeScopeGuard(cfg.ScopeGuard),
+		repo:                     cfg.Repository,
+		sink:                     safeActivitySink(cfg.Activity),
+		hooks:                    safeHooks(cfg.Hooks),
+		clock:                    safeClock(cfg.Clock),
+		guard:                    safeScopeGuard(cfg.ScopeGuard),
+		fields:                   cfg.Fields,
+		requirePhoneVerification: cfg.RequirePhoneVerification,
 	}
 }
 
 var _ gocommand.Commander[ProfileUpsertInput] = (*ProfileUpsertCommand)(nil)
 
-// Execute applies the supplied patch creating the profile when necessary.
+// Execute applies the supplied patch to the profile stored for the enforced
+// scope. When that scope has no profile yet, a new one is created from the
+// resolved profile (see types.ResolveProfile) so fallback data is kept;
+// ExpectedVersion is then compared with the resolved profile's version.
 func (c *ProfileUpsertCommand) Execute(ctx context.Context, input ProfileUpsertInput) error {
 	if c.repo == nil {
 		return types.ErrMissingProfileRepository
@@ -76,16 +107,18 @@ func (c *ProfileUpsertCommand) Execute(ctx context.Context, input ProfileUpsertI
 		return err
 	}
 
-	existing, err := c.repo.GetProfile(ctx, input.UserID, scope)
+	draft, err := loadProfileDraft(ctx, c.repo, input.UserID, scope)
 	if err != nil {
 		return err
 	}
-	profile := &types.UserProfile{
-		UserID: input.UserID,
-		Scope:  scope,
-	}
-	if existing != nil {
-		*profile = *existing
+	profile := draft.profile
+	before := draft.effective
+	if input.ExpectedVersion != nil {
+		expected, err := draft.checkVersion(input.UserID, *input.ExpectedVersion)
+		if err != nil {
+			return err
+		}
+		profile.ExpectedVersion = &expected
 	}
 	if profile.CreatedBy == uuid.Nil {
 		profile.CreatedBy = input.Actor.ID
@@ -93,35 +126,87 @@ func (c *ProfileUpsertCommand) Execute(ctx context.Context, input ProfileUpsertI
 	profile.UpdatedBy = input.Actor.ID
 	patch := input.Patch
 	patch.CanonicalizeLocale()
+	if c.fields != nil && len(patch.CustomFields) > 0 {
+		var previous map[string]any
+		if before != nil {
+			previous = before.CustomFields
+		}
+		fields, err := c.validateCustomFields(ctx, input.UserID, scope, patch.CustomFields, previous)
+		if err != nil {
+			return err
+		}
+		patch.CustomFields = fields
+	}
 	applyProfilePatch(profile, patch)
 	profile.CanonicalizeLocale()
+	if c.requirePhoneVerification {
+		after := draft.resolve(*profile)
+		if profilePhone(&after) != profilePhone(before) {
+			return ErrContactVerificationRequired
+		}
+	}
 
 	updated, err := c.repo.UpsertProfile(ctx, *profile)
 	if err != nil {
 		return err
 	}
-	var eventProfile types.UserProfile
-	if updated != nil {
-		eventProfile = *updated
-		if input.Result != nil {
-			*input.Result = *updated
-		}
-	} else {
-		eventProfile = *profile
-		if input.Result != nil {
-			*input.Result = *profile
-		}
+	if updated == nil {
+		updated = profile
 	}
+	eventProfile := draft.resolve(*updated)
+	if input.Result != nil {
+		*input.Result = eventProfile
+	}
+	eventTime := now(c.clock)
 	emitProfileHook(ctx, c.hooks, types.ProfileEvent{
 		UserID:     input.UserID,
 		Scope:      scope,
 		ActorID:    input.Actor.ID,
-		OccurredAt: now(c.clock),
+		OccurredAt: eventTime,
 		Profile:    eventProfile,
 	})
+
+	changes := activity.DiffUserProfile(before, &eventProfile)
+	if len(changes) == 0 {
+		return nil
+	}
+	record := types.ActivityRecord{
+		UserID:     input.UserID,
+		ActorID:    input.Actor.ID,
+		Verb:       "profile.updated",
+		ObjectType: "profile",
+		ObjectID:   input.UserID.String(),
+		Channel:    "profile",
+		TenantID:   scope.TenantID,
+		OrgID:      scope.OrgID,
+		Data:       map[string]any{"created": before == nil},
+		OccurredAt: eventTime,
+	}
+	record = activity.AttachChanges(record, nil, changes)
+	logActivity(ctx, c.sink, record)
+	emitActivityHook(ctx, c.hooks, record)
 	return nil
 }
 
+// checkProfileVersion fails fast when the loaded profile already disagrees
+// with expected. Repositories that support versioning repeat the check
+// atomically on write.
+func checkProfileVersion(userID uuid.UUID, expected int, existing *types.UserProfile) error {
+	current := 0
+	if existing != nil {
+		current = existing.Version
+	}
+	if current == expected {
+		return nil
+	}
+	conflict := &types.ProfileVersionConflictError{UserID: userID, ExpectedVersion: expected}
+	if existing != nil {
+		snapshot := *existing
+		conflict.Current = &snapshot
+	}
+	return conflict
+}
+
 func applyProfilePatch(profile *types.UserProfile, patch types.ProfilePatch) {
 	if profile == nil {
 		return
@@ -147,4 +232,79 @@ func applyProfilePatch(profile *types.UserProfile, patch types.ProfilePatch) {
 	if patch.Metadata != nil {
 		profile.Metadata = cloneMap(patch.Metadata)
 	}
+	if patch.CustomFields != nil {
+		fields := cloneMap(profile.CustomFields)
+		if fields == nil {
+			fields = make(map[string]any, len(patch.CustomFields))
+		}
+		for key, value := range patch.CustomFields {
+			if value == nil {
+				delete(fields, key)
+				continue
+			}
+			fields[key] = value
+		}
+		profile.CustomFields = fields
+	}
+}
+
+// validateCustomFields checks the patched values against the tenant's
+// definitions and returns them keyed by each definition's canonical key.
+// Stored values outside the patch are left alone, so deleting a definition or
+// adding a required one does not block unrelated writes. A nil value clears
+// the field; clearing a key without a definition is allowed so orphaned
+// values can be removed.
+func (c *ProfileUpsertCommand) validateCustomFields(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, values, previous map[string]any) (map[string]any, error) {
+	defs, err := c.fields.ListProfileFields(ctx, scope.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]types.ProfileFieldDefinition, len(defs))
+	for _, def := range defs {
+		byKey[strings.ToLower(def.Key)] = def
+	}
+	canonical := make(map[string]any, len(values))
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		def, ok := byKey[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			if value == nil {
+				canonical[key] = nil
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%w: %q", types.ErrProfileFieldUnknown, key))
+			continue
+		}
+		canonical[def.Key] = value
+		if types.ProfileFieldEmpty(value) {
+			if def.Required {
+				errs = append(errs, fmt.Errorf("%w: %q", types.ErrProfileFieldRequired, def.Key))
+			}
+			continue
+		}
+		if err := def.ValidateValue(value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !def.Unique || reflect.DeepEqual(previous[def.Key], value) {
+			continue
+		}
+		checker, ok := c.repo.(types.ProfileFieldUniquenessChecker)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %q", types.ErrProfileFieldUniquenessUnsupported, def.Key))
+			continue
+		}
+		taken, err := checker.ProfileFieldValueTaken(ctx, scope.TenantID, def.Key, value, userID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			errs = append(errs, fmt.Errorf("%w: %q", types.ErrProfileFieldNotUnique, def.Key))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return canonical, nil
 }
@@ -7,6 +7,7 @@ import (
 
 	gocommand "github.com/goliatone/go-command"
 	featuregate "github.com/goliatone/go-featuregate/gate"
+	"github.com/goliatone/go-users/notification"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -63,6 +64,7 @@ type UserRegistrationRequestCommand struct {
 	guard       scope.Guard
 	featureGate featuregate.FeatureGate
 	route       string
+	notifier    notification.Notifier
 }
 
 // RegistrationRequestConfig holds dependencies for the registration flow.
@@ -79,6 +81,8 @@ type RegistrationRequestConfig struct {
 	ScopeGuard      scope.Guard
 	FeatureGate     featuregate.FeatureGate
 	Route           string
+	// Notifier delivers the registration link when configured.
+	Notifier notification.Notifier
 }
 
 // NewUserRegistrationRequestCommand constructs the registration handler.
@@ -100,6 +104,7 @@ func NewUserRegistrationRequestCommand(cfg RegistrationRequestConfig) *UserRegis
 		repo:        cfg.Repository,
 		tokens:      cfg.TokenRepository,
 		featureGate: cfg.FeatureGate,
+		notifier:    cfg.Notifier,
 	}
 	cmd.applyRuntime(runtime)
 	return cmd
@@ -144,6 +149,7 @@ func (c *UserRegistrationRequestCommand) Execute(ctx context.Context, input User
 		return err
 	}
 	c.emitRegistrationActivity(ctx, created, input.Actor, scope, jti, issuedAt, expiresAt)
+	notifyUser(ctx, c.notifier, c.logger, secureLinkNotification(notification.KindRegistration, created, input.Actor, scope, token, expiresAt))
 	setRegistrationResult(input.Result, created, token, expiresAt)
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -61,13 +62,15 @@ func (input UnassignRoleInput) Validate() error {
 type AssignRoleCommand struct {
 	registry types.RoleRegistry
 	guard    scope.Guard
+	cfg      roleCommandConfig
 }
 
 // NewAssignRoleCommand constructs the handler.
-func NewAssignRoleCommand(registry types.RoleRegistry, guard scope.Guard) *AssignRoleCommand {
+func NewAssignRoleCommand(registry types.RoleRegistry, guard scope.Guard, opts ...RoleCommandOption) *AssignRoleCommand {
 	return &AssignRoleCommand{
 		registry: registry,
 		guard:    safeScopeGuard(guard),
+		cfg:      applyRoleCommandOptions(opts),
 	}
 }
 
@@ -82,20 +85,31 @@ func (c *AssignRoleCommand) Execute(ctx context.Context, input AssignRoleInput)
 	if err != nil {
 		return err
 	}
-	return c.registry.AssignRole(ctx, input.UserID, input.RoleID, scope, input.Actor.ID)
+	if err := c.registry.AssignRole(ctx, input.UserID, input.RoleID, scope, input.Actor.ID); err != nil {
+		return err
+	}
+	c.cfg.invalidateRoleChange(ctx, "role.assigned", input.Actor, scope, input.UserID, input.RoleID)
+	c.cfg.logRoleActivity(ctx, "role.assigned", input.Actor, scope, input.UserID, input.RoleID, []activity.FieldChange{{
+		Field: "roles",
+		After: input.RoleID.String(),
+	}})
+	c.cfg.notifyRoleGranted(ctx, c.registry, input.Actor, scope, input.UserID, input.RoleID)
+	return nil
 }
 
 // UnassignRoleCommand removes assignments.
 type UnassignRoleCommand struct {
 	registry types.RoleRegistry
 	guard    scope.Guard
+	cfg      roleCommandConfig
 }
 
 // NewUnassignRoleCommand constructs the handler.
-func NewUnassignRoleCommand(registry types.RoleRegistry, guard scope.Guard) *UnassignRoleCommand {
+func NewUnassignRoleCommand(registry types.RoleRegistry, guard scope.Guard, opts ...RoleCommandOption) *UnassignRoleCommand {
 	return &UnassignRoleCommand{
 		registry: registry,
 		guard:    safeScopeGuard(guard),
+		cfg:      applyRoleCommandOptions(opts),
 	}
 }
 
@@ -110,5 +124,13 @@ func (c *UnassignRoleCommand) Execute(ctx context.Context, input UnassignRoleInp
 	if err != nil {
 		return err
 	}
-	return c.registry.UnassignRole(ctx, input.UserID, input.RoleID, scope, input.Actor.ID)
+	if err := c.registry.UnassignRole(ctx, input.UserID, input.RoleID, scope, input.Actor.ID); err != nil {
+		return err
+	}
+	c.cfg.invalidateRoleChange(ctx, "role.unassigned", input.Actor, scope, input.UserID, input.RoleID)
+	c.cfg.logRoleActivity(ctx, "role.unassigned", input.Actor, scope, input.UserID, input.RoleID, []activity.FieldChange{{
+		Field:  "roles",
+		Before: input.RoleID.String(),
+	}})
+	return nil
 }
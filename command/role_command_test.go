@@ -3,7 +3,9 @@ package command
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/goliatone/go-users/activity"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -38,7 +40,85 @@ func TestAssignRoleCommand_ValidatesUser(t *testing.T) {
 	require.ErrorIs(t, err, ErrUserIDRequired)
 }
 
+func TestAssignRoleCommand_LogsActivity(t *testing.T) {
+	reg := &fakeRoleRegistry{}
+	sink := &recordingActivitySink{}
+	cmd := NewAssignRoleCommand(reg, scope.NopGuard(), WithRoleActivity(sink, types.Hooks{}))
+	userID := uuid.New()
+	roleID := uuid.New()
+
+	err := cmd.Execute(context.Background(), AssignRoleInput{
+		UserID: userID,
+		RoleID: roleID,
+		Actor:  types.ActorRef{ID: uuid.New()},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, sink.records, 1)
+	require.Equal(t, "role.assigned", sink.records[0].Verb)
+	require.Equal(t, userID, sink.records[0].UserID)
+	changes := activity.ChangesFromData(sink.records[0].Data)
+	require.Len(t, changes, 1)
+	require.Equal(t, roleID.String(), changes[0].After)
+}
+
+func TestUpdateRoleCommand_DiffsAgainstCurrentRoleWithHooksOnly(t *testing.T) {
+	roleID := uuid.New()
+	reg := &fakeRoleRegistry{current: &types.RoleDefinition{ID: roleID, Name: "Editors"}}
+	var records []types.ActivityRecord
+	hooks := types.Hooks{AfterActivity: func(_ context.Context, record types.ActivityRecord) {
+		records = append(records, record)
+	}}
+	cmd := NewUpdateRoleCommand(reg, scope.NopGuard(), WithRoleActivity(nil, hooks))
+
+	require.NoError(t, cmd.Execute(context.Background(), UpdateRoleInput{
+		RoleID: roleID,
+		Name:   "Publishers",
+		Actor:  types.ActorRef{ID: uuid.New()},
+	}))
+	require.Len(t, records, 1)
+	require.Equal(t, "role.updated", records[0].Verb)
+	changes := activity.ChangesFromData(records[0].Data)
+	require.Len(t, changes, 1)
+	require.Equal(t, "name", changes[0].Field)
+	require.Equal(t, "Editors", changes[0].Before)
+	require.Equal(t, "Publishers", changes[0].After)
+}
+
+func TestAssignRoleCommand_TriggersOffHoursEscalationRule(t *testing.T) {
+	sink := &recordingActivitySink{}
+	var alerts []activity.AnomalyAlert
+	anomalies := activity.NewAnomalySink(sink, activity.DefaultAnomalyRules(),
+		activity.WithAnomalyAlertHook(func(_ context.Context, alert activity.AnomalyAlert) {
+			alerts = append(alerts, alert)
+		}),
+	)
+	actor := types.ActorRef{ID: uuid.New()}
+	assign := func(at time.Time) {
+		cmd := NewAssignRoleCommand(&fakeRoleRegistry{}, scope.NopGuard(),
+			WithRoleActivity(anomalies, types.Hooks{}),
+			WithRoleClock(fixedClock{t: at}),
+		)
+		require.NoError(t, cmd.Execute(context.Background(), AssignRoleInput{
+			UserID: uuid.New(),
+			RoleID: uuid.New(),
+			Actor:  actor,
+		}))
+	}
+
+	assign(time.Date(2026, 6, 3, 11, 0, 0, 0, time.UTC))
+	require.Empty(t, alerts)
+
+	assign(time.Date(2026, 6, 3, 2, 30, 0, 0, time.UTC))
+	require.Len(t, alerts, 1)
+	require.Equal(t, "role.escalation.off_hours", alerts[0].Rule)
+	require.Equal(t, actor.ID.String(), alerts[0].Key)
+	require.Equal(t, activity.AnomalyAlertVerb, sink.records[len(sink.records)-1].Verb)
+}
+
 type fakeRoleRegistry struct {
+	// current is returned by GetRole and renamed by UpdateRole when set.
+	current      *types.RoleDefinition
 	lastMutation types.RoleMutation
 	lastAssign   struct {
 		UserID uuid.UUID
@@ -54,8 +134,15 @@ func (f *fakeRoleRegistry) CreateRole(ctx context.Context, input types.RoleMutat
 	}, nil
 }
 
-func (f *fakeRoleRegistry) UpdateRole(context.Context, uuid.UUID, types.RoleMutation) (*types.RoleDefinition, error) {
-	return nil, nil
+func (f *fakeRoleRegistry) UpdateRole(_ context.Context, _ uuid.UUID, input types.RoleMutation) (*types.RoleDefinition, error) {
+	f.lastMutation = input
+	if f.current == nil {
+		return nil, nil
+	}
+	updated := *f.current
+	updated.Name = input.Name
+	f.current = &updated
+	return &updated, nil
 }
 
 func (f *fakeRoleRegistry) DeleteRole(context.Context, uuid.UUID, types.ScopeFilter, uuid.UUID) error {
@@ -77,7 +164,11 @@ func (f *fakeRoleRegistry) ListRoles(context.Context, types.RoleFilter) (types.R
 }
 
 func (f *fakeRoleRegistry) GetRole(context.Context, uuid.UUID, types.ScopeFilter) (*types.RoleDefinition, error) {
-	return nil, nil
+	if f.current == nil {
+		return nil, nil
+	}
+	role := *f.current
+	return &role, nil
 }
 
 func (f *fakeRoleRegistry) ListAssignments(context.Context, types.RoleAssignmentFilter) ([]types.RoleAssignment, error) {
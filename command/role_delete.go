@@ -30,13 +30,15 @@ func (input DeleteRoleInput) Validate() error {
 type DeleteRoleCommand struct {
 	registry types.RoleRegistry
 	guard    scope.Guard
+	cfg      roleCommandConfig
 }
 
 // NewDeleteRoleCommand constructs the handler.
-func NewDeleteRoleCommand(registry types.RoleRegistry, guard scope.Guard) *DeleteRoleCommand {
+func NewDeleteRoleCommand(registry types.RoleRegistry, guard scope.Guard, opts ...RoleCommandOption) *DeleteRoleCommand {
 	return &DeleteRoleCommand{
 		registry: registry,
 		guard:    safeScopeGuard(guard),
+		cfg:      applyRoleCommandOptions(opts),
 	}
 }
 
@@ -51,5 +53,9 @@ func (c *DeleteRoleCommand) Execute(ctx context.Context, input DeleteRoleInput)
 	if err != nil {
 		return err
 	}
-	return c.registry.DeleteRole(ctx, input.RoleID, scope, input.Actor.ID)
+	if err := c.registry.DeleteRole(ctx, input.RoleID, scope, input.Actor.ID); err != nil {
+		return err
+	}
+	c.cfg.invalidateRoleChange(ctx, "role.deleted", input.Actor, scope, uuid.Nil, input.RoleID)
+	return nil
 }
@@ -1,8 +1,11 @@
 package command
 
 import (
+	"context"
 	"strings"
 
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/notification"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/google/uuid"
 )
@@ -26,3 +29,138 @@ func validateRoleTarget(roleID uuid.UUID, actor types.ActorRef) error {
 	}
 	return nil
 }
+
+// RoleCommandOption customizes role command handlers.
+type RoleCommandOption func(*roleCommandConfig)
+
+type roleCommandConfig struct {
+	sink     types.ActivitySink
+	hooks    types.Hooks
+	clock    types.Clock
+	notifier notification.Notifier
+	logger   types.Logger
+	caches   []types.RoleChangeInvalidator
+}
+
+// WithRoleActivity records role mutations (with field diffs) on the sink and
+// forwards the records to the AfterActivity hook.
+func WithRoleActivity(sink types.ActivitySink, hooks types.Hooks) RoleCommandOption {
+	return func(cfg *roleCommandConfig) {
+		if cfg == nil {
+			return
+		}
+		cfg.sink = sink
+		cfg.hooks = hooks
+	}
+}
+
+// WithRoleClock overrides the clock used to timestamp role activity.
+func WithRoleClock(clock types.Clock) RoleCommandOption {
+	return func(cfg *roleCommandConfig) {
+		if cfg == nil {
+			return
+		}
+		cfg.clock = clock
+	}
+}
+
+// WithRoleNotifier notifies users when a role is granted to them.
+func WithRoleNotifier(notifier notification.Notifier, logger types.Logger) RoleCommandOption {
+	return func(cfg *roleCommandConfig) {
+		if cfg == nil {
+			return
+		}
+		cfg.notifier = notifier
+		cfg.logger = logger
+	}
+}
+
+// WithRoleInvalidator drops cached data (for example resolved preference
+// snapshots) after a role is updated, deleted, assigned, or unassigned. Role
+// registries only fire their own hooks, so caches wired to the service hooks
+// need this to see changes made through the commands.
+func WithRoleInvalidator(cache types.RoleChangeInvalidator) RoleCommandOption {
+	return func(cfg *roleCommandConfig) {
+		if cfg == nil || cache == nil {
+			return
+		}
+		cfg.caches = append(cfg.caches, cache)
+	}
+}
+
+func applyRoleCommandOptions(opts []RoleCommandOption) roleCommandConfig {
+	cfg := roleCommandConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	cfg.sink = safeActivitySink(cfg.sink)
+	cfg.hooks = safeHooks(cfg.hooks)
+	cfg.clock = safeClock(cfg.clock)
+	cfg.logger = safeLogger(cfg.logger)
+	return cfg
+}
+
+// emitsActivity reports whether role activity reaches the sink or the
+// AfterActivity hook.
+func (cfg roleCommandConfig) emitsActivity() bool {
+	return cfg.sink != nil || cfg.hooks.AfterActivity != nil
+}
+
+func (cfg roleCommandConfig) logRoleActivity(ctx context.Context, verb string, actor types.ActorRef, scope types.ScopeFilter, userID, roleID uuid.UUID, changes []activity.FieldChange) {
+	if !cfg.emitsActivity() {
+		return
+	}
+	record := types.ActivityRecord{
+		UserID:     userID,
+		ActorID:    actor.ID,
+		Verb:       verb,
+		ObjectType: "role",
+		ObjectID:   roleID.String(),
+		Channel:    "roles",
+		TenantID:   scope.TenantID,
+		OrgID:      scope.OrgID,
+		Data:       map[string]any{"role_id": roleID.String()},
+		OccurredAt: now(cfg.clock),
+	}
+	record = activity.AttachChanges(record, nil, changes)
+	logActivity(ctx, cfg.sink, record)
+	emitActivityHook(ctx, cfg.hooks, record)
+}
+
+func (cfg roleCommandConfig) invalidateRoleChange(ctx context.Context, action string, actor types.ActorRef, scope types.ScopeFilter, userID, roleID uuid.UUID) {
+	if len(cfg.caches) == 0 {
+		return
+	}
+	event := types.RoleEvent{
+		RoleID:     roleID,
+		UserID:     userID,
+		Action:     action,
+		ActorID:    actor.ID,
+		Scope:      scope,
+		OccurredAt: now(cfg.clock),
+	}
+	for _, cache := range cfg.caches {
+		cache.InvalidateRoleChange(ctx, event)
+	}
+}
+
+func (cfg roleCommandConfig) notifyRoleGranted(ctx context.Context, registry types.RoleRegistry, actor types.ActorRef, scope types.ScopeFilter, userID, roleID uuid.UUID) {
+	if cfg.notifier == nil {
+		return
+	}
+	data := map[string]any{"role_id": roleID.String()}
+	if registry != nil {
+		if role, err := registry.GetRole(ctx, roleID, scope); err == nil && role != nil {
+			data["role_name"] = role.Name
+		}
+	}
+	notifyUser(ctx, cfg.notifier, cfg.logger, notification.Notification{
+		Kind:    notification.KindRoleGranted,
+		UserID:  userID,
+		ActorID: actor.ID,
+		Scope:   scope,
+		Data:    data,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -45,13 +46,15 @@ func (input UpdateRoleInput) Validate() error {
 type UpdateRoleCommand struct {
 	registry types.RoleRegistry
 	guard    scope.Guard
+	cfg      roleCommandConfig
 }
 
 // NewUpdateRoleCommand constructs the command handler.
-func NewUpdateRoleCommand(registry types.RoleRegistry, guard scope.Guard) *UpdateRoleCommand {
+func NewUpdateRoleCommand(registry types.RoleRegistry, guard scope.Guard, opts ...RoleCommandOption) *UpdateRoleCommand {
 	return &UpdateRoleCommand{
 		registry: registry,
 		guard:    safeScopeGuard(guard),
+		cfg:      applyRoleCommandOptions(opts),
 	}
 }
 
@@ -66,6 +69,13 @@ func (c *UpdateRoleCommand) Execute(ctx context.Context, input UpdateRoleInput)
 	if err != nil {
 		return err
 	}
+	var before *types.RoleDefinition
+	if c.cfg.emitsActivity() {
+		if current, getErr := c.registry.GetRole(ctx, input.RoleID, scope); getErr == nil && current != nil {
+			snapshot := *current
+			before = &snapshot
+		}
+	}
 	role, err := c.registry.UpdateRole(ctx, input.RoleID, types.RoleMutation{
 		Name:        strings.TrimSpace(input.Name),
 		Order:       input.Order,
@@ -80,6 +90,10 @@ func (c *UpdateRoleCommand) Execute(ctx context.Context, input UpdateRoleInput)
 	if err != nil {
 		return err
 	}
+	c.cfg.invalidateRoleChange(ctx, "role.updated", input.Actor, scope, uuid.Nil, input.RoleID)
+	if role != nil {
+		c.cfg.logRoleActivity(ctx, "role.updated", input.Actor, scope, uuid.Nil, input.RoleID, activity.DiffRoleDefinition(before, role))
+	}
 	if input.Result != nil && role != nil {
 		*input.Result = *role
 	}
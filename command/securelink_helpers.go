@@ -10,15 +10,17 @@ import (
 )
 
 const (
-	SecureLinkActionInvite        = "invite"
-	SecureLinkActionRegister      = "register"
-	SecureLinkActionPasswordReset = "password_reset"
+	SecureLinkActionInvite              = "invite"
+	SecureLinkActionRegister            = "register"
+	SecureLinkActionPasswordReset       = "password_reset"
+	SecureLinkActionContactVerification = "contact_verification"
 )
 
 const (
-	SecureLinkRouteInviteAccept  = "invite_accept"
-	SecureLinkRouteRegister      = "register"
-	SecureLinkRoutePasswordReset = "password_reset"
+	SecureLinkRouteInviteAccept        = "invite_accept"
+	SecureLinkRouteRegister            = "register"
+	SecureLinkRoutePasswordReset       = "password_reset"
+	SecureLinkRouteContactVerification = "contact_verification"
 )
 
 const secureLinkSourceDefault = "go-users"
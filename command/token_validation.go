@@ -258,7 +258,7 @@ func (c *UserTokenConsumeCommand) Execute(ctx context.Context, input UserTokenCo
 
 	usedAt := now(c.clock)
 	if err := c.tokens.UpdateTokenStatus(ctx, input.TokenType, record.JTI, types.UserTokenStatusUsed, usedAt); err != nil {
-		return c.tokenConsumeError(ctx, input.TokenType, record.JTI, usedAt, err)
+		return tokenConsumeError(ctx, c.tokens, input.TokenType, record.JTI, usedAt, err)
 	}
 	record.Status = types.UserTokenStatusUsed
 	record.UsedAt = usedAt
@@ -292,14 +292,16 @@ func (c *UserTokenConsumeCommand) Execute(ctx context.Context, input UserTokenCo
 	return nil
 }
 
-func (c *UserTokenConsumeCommand) tokenConsumeError(ctx context.Context, tokenType types.UserTokenType, jti string, usedAt time.Time, err error) error {
+// tokenConsumeError maps a failed UpdateTokenStatus call to the token error
+// explaining why the token could not be consumed.
+func tokenConsumeError(ctx context.Context, tokens types.UserTokenRepository, tokenType types.UserTokenType, jti string, usedAt time.Time, err error) error {
 	if repository.IsRecordNotFound(err) {
 		return ErrTokenNotFound
 	}
 	if !repository.IsSQLExpectedCountViolation(err) {
 		return err
 	}
-	latest, lookupErr := c.tokens.GetTokenByJTI(ctx, tokenType, jti)
+	latest, lookupErr := tokens.GetTokenByJTI(ctx, tokenType, jti)
 	if lookupErr != nil {
 		return ErrTokenAlreadyUsed
 	}
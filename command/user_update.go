@@ -4,6 +4,7 @@ import (
 	"context"
 
 	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
@@ -38,13 +39,14 @@ func (input UserUpdateInput) Validate() error {
 
 // UserUpdateCommand updates existing users while enforcing scopes.
 type UserUpdateCommand struct {
-	repo   types.AuthRepository
-	policy types.TransitionPolicy
-	clock  types.Clock
-	sink   types.ActivitySink
-	hooks  types.Hooks
-	logger types.Logger
-	guard  scope.Guard
+	repo                     types.AuthRepository
+	policy                   types.TransitionPolicy
+	clock                    types.Clock
+	sink                     types.ActivitySink
+	hooks                    types.Hooks
+	logger                   types.Logger
+	guard                    scope.Guard
+	requireEmailVerification bool
 }
 
 // UserUpdateCommandConfig wires dependencies for the update command.
@@ -56,6 +58,10 @@ type UserUpdateCommandConfig struct {
 	Hooks      types.Hooks
 	Logger     types.Logger
 	ScopeGuard scope.Guard
+	// RequireEmailVerification rejects email changes with
+	// ErrContactVerificationRequired so they go through the contact
+	// verification commands instead.
+	RequireEmailVerification bool
 }
 
 // NewUserUpdateCommand constructs the update handler.
@@ -65,13 +71,14 @@ func NewUserUpdateCommand(cfg UserUpdateCommandConfig) *UserUpdateCommand {
 		policy = types.DefaultTransitionPolicy()
 	}
 	return &UserUpdateCommand{
-		repo:   cfg.Repository,
-		policy: policy,
-		clock:  safeClock(cfg.Clock),
-		sink:   safeActivitySink(cfg.Activity),
-		hooks:  safeHooks(cfg.Hooks),
-		logger: safeLogger(cfg.Logger),
-		guard:  safeScopeGuard(cfg.ScopeGuard),
+		repo:                     cfg.Repository,
+		policy:                   policy,
+		clock:                    safeClock(cfg.Clock),
+		sink:                     safeActivitySink(cfg.Activity),
+		hooks:                    safeHooks(cfg.Hooks),
+		logger:                   safeLogger(cfg.Logger),
+		guard:                    safeScopeGuard(cfg.ScopeGuard),
+		requireEmailVerification: cfg.RequireEmailVerification,
 	}
 }
 
@@ -92,17 +99,19 @@ func (c *UserUpdateCommand) Execute(ctx context.Context, input UserUpdateInput)
 	}
 
 	user := normalizeAuthUser(input.User)
-	if user != nil && user.Status != "" {
-		current, currentErr := c.repo.GetByID(ctx, user.ID)
-		if currentErr != nil {
-			return currentErr
-		}
-		if current != nil && current.Status != user.Status && c.policy != nil {
-			if policyErr := c.policy.Validate(current.Status, user.Status); policyErr != nil {
-				return policyErr
-			}
+	current, err := c.repo.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if current != nil && user.Status != "" && current.Status != user.Status && c.policy != nil {
+		if policyErr := c.policy.Validate(current.Status, user.Status); policyErr != nil {
+			return policyErr
 		}
 	}
+	if c.requireEmailVerification && current != nil && user.Email != current.Email {
+		return ErrContactVerificationRequired
+	}
+	before := cloneAuthUser(current)
 	updated, err := c.repo.Update(ctx, user)
 	if err != nil {
 		return err
@@ -124,6 +133,7 @@ func (c *UserUpdateCommand) Execute(ctx context.Context, input UserUpdateInput)
 		},
 		OccurredAt: now(c.clock),
 	}
+	record = activity.AttachChanges(record, nil, activity.DiffAuthUser(before, updated))
 	logActivity(ctx, c.sink, record)
 	emitActivityHook(ctx, c.hooks, record)
 
@@ -0,0 +1,254 @@
+package contactverification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// RepositoryConfig wires the Bun-backed contact change repository.
+type RepositoryConfig struct {
+	DB         *bun.DB
+	Repository repository.Repository[*Record]
+	Clock      types.Clock
+}
+
+// Repository implements types.ContactChangeRepository using Bun.
+type Repository struct {
+	store repository.Repository[*Record]
+	clock types.Clock
+	db    *bun.DB
+}
+
+// NewRepository constructs the default contact change repository.
+func NewRepository(cfg RepositoryConfig) (*Repository, error) {
+	if cfg.Repository == nil && cfg.DB == nil {
+		return nil, errors.New("contactverification: db or repository required")
+	}
+	repo := cfg.Repository
+	if repo == nil {
+		repo = repository.NewRepository(cfg.DB, repository.ModelHandlers[*Record]{
+			NewRecord: func() *Record { return &Record{} },
+			GetID: func(rec *Record) uuid.UUID {
+				if rec == nil {
+					return uuid.Nil
+				}
+				return rec.ID
+			},
+			SetID: func(rec *Record, id uuid.UUID) {
+				if rec != nil {
+					rec.ID = id
+				}
+			},
+		})
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	db := cfg.DB
+	if db == nil {
+		if withDB, ok := repo.(interface{ DB() *bun.DB }); ok {
+			db = withDB.DB()
+		}
+	}
+	return &Repository{store: repo, clock: clock, db: db}, nil
+}
+
+var _ types.ContactChangeRepository = (*Repository)(nil)
+
+// CreateContactChange persists a pending change, superseding any pending
+// change for the same user and channel in the same transaction.
+func (r *Repository) CreateContactChange(ctx context.Context, change types.ContactChange) (*types.ContactChange, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("contactverification: db required for create")
+	}
+	if change.UserID == uuid.Nil {
+		return nil, types.ErrUserIDRequired
+	}
+	rec := fromDomain(change)
+	if rec.ID == uuid.Nil {
+		rec.ID = uuid.New()
+	}
+	now := r.clock.Now()
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = now
+	}
+	if rec.UpdatedAt.IsZero() {
+		rec.UpdatedAt = now
+	}
+	if rec.IssuedAt == nil {
+		rec.IssuedAt = timePtr(now)
+	}
+	if strings.TrimSpace(rec.Status) == "" {
+		rec.Status = string(types.ContactChangeStatusPending)
+	}
+	var created *Record
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model((*Record)(nil)).
+			Set("status = ?", string(types.ContactChangeStatusSuperseded)).
+			Set("updated_at = ?", now).
+			Where("user_id = ?", rec.UserID).
+			Where("channel = ?", rec.Channel).
+			Where("status = ?", string(types.ContactChangeStatusPending)).
+			Exec(ctx)
+		if err != nil {
+			return repository.MapDatabaseError(err, repository.DetectDriver(r.db))
+		}
+		created, err = r.store.CreateTx(ctx, tx, rec)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomain(created), nil
+}
+
+// GetContactChangeByJTI returns the change sharing the token JTI.
+func (r *Repository) GetContactChangeByJTI(ctx context.Context, jti string) (*types.ContactChange, error) {
+	rec, err := r.store.Get(ctx, repository.SelectBy("jti", "=", strings.TrimSpace(jti)))
+	if err != nil {
+		if repository.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toDomain(rec), nil
+}
+
+// RecordContactChangeAttempt increments the failed attempt counter of a
+// pending change and returns the new total.
+func (r *Repository) RecordContactChangeAttempt(ctx context.Context, jti string) (int, error) {
+	if r == nil || r.db == nil {
+		return 0, errors.New("contactverification: db required for updates")
+	}
+	normalized := strings.TrimSpace(jti)
+	if normalized == "" {
+		return 0, errors.New("contactverification: jti required")
+	}
+	var attempts int
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().Model((*Record)(nil)).
+			Set("attempts = attempts + 1").
+			Set("updated_at = ?", r.clock.Now()).
+			Where("jti = ?", normalized).
+			Where("status = ?", string(types.ContactChangeStatusPending)).
+			Exec(ctx)
+		if err != nil {
+			return repository.MapDatabaseError(err, repository.DetectDriver(r.db))
+		}
+		if err := repository.SQLExpectedCount(res, 1); err != nil {
+			return err
+		}
+		return tx.NewSelect().Model((*Record)(nil)).
+			Column("attempts").
+			Where("jti = ?", normalized).
+			Scan(ctx, &attempts)
+	})
+	return attempts, err
+}
+
+// UpdateContactChangeStatus moves a pending change to status, stamping
+// confirmed_at when the change is confirmed.
+func (r *Repository) UpdateContactChangeStatus(ctx context.Context, jti string, status types.ContactChangeStatus, at time.Time) error {
+	if r == nil || r.db == nil {
+		return errors.New("contactverification: db required for updates")
+	}
+	normalized := strings.TrimSpace(jti)
+	if normalized == "" {
+		return errors.New("contactverification: jti required")
+	}
+	if at.IsZero() {
+		at = r.clock.Now()
+	}
+	rec := &Record{
+		Status:    string(status),
+		UpdatedAt: r.clock.Now(),
+	}
+	columns := []string{"status", "updated_at"}
+	if status == types.ContactChangeStatusConfirmed {
+		rec.ConfirmedAt = timePtr(at)
+		columns = append(columns, "confirmed_at")
+	}
+	res, err := r.db.NewUpdate().Model(rec).
+		Column(columns...).
+		Where("jti = ?", normalized).
+		Where("status = ?", string(types.ContactChangeStatusPending)).
+		Exec(ctx)
+	if err != nil {
+		return repository.MapDatabaseError(err, repository.DetectDriver(r.db))
+	}
+	return repository.SQLExpectedCount(res, 1)
+}
+
+func fromDomain(change types.ContactChange) *Record {
+	return &Record{
+		ID:          change.ID,
+		UserID:      change.UserID,
+		Channel:     string(change.Channel),
+		Method:      string(change.Method),
+		OldValue:    change.OldValue,
+		NewValue:    change.NewValue,
+		JTI:         strings.TrimSpace(change.JTI),
+		CodeHash:    change.CodeHash,
+		Attempts:    change.Attempts,
+		Status:      string(change.Status),
+		TenantID:    change.Scope.TenantID,
+		OrgID:       change.Scope.OrgID,
+		RequestedBy: change.RequestedBy,
+		IssuedAt:    timePtr(change.IssuedAt),
+		ExpiresAt:   timePtr(change.ExpiresAt),
+		ConfirmedAt: timePtr(change.ConfirmedAt),
+		CreatedAt:   change.CreatedAt,
+		UpdatedAt:   change.UpdatedAt,
+	}
+}
+
+func toDomain(rec *Record) *types.ContactChange {
+	if rec == nil {
+		return nil
+	}
+	return &types.ContactChange{
+		ID:       rec.ID,
+		UserID:   rec.UserID,
+		Channel:  types.ContactChannel(rec.Channel),
+		Method:   types.ContactVerificationMethod(rec.Method),
+		OldValue: rec.OldValue,
+		NewValue: rec.NewValue,
+		JTI:      rec.JTI,
+		CodeHash: rec.CodeHash,
+		Attempts: rec.Attempts,
+		Status:   types.ContactChangeStatus(rec.Status),
+		Scope: types.ScopeFilter{
+			TenantID: rec.TenantID,
+			OrgID:    rec.OrgID,
+		},
+		RequestedBy: rec.RequestedBy,
+		IssuedAt:    timeFromPtr(rec.IssuedAt),
+		ExpiresAt:   timeFromPtr(rec.ExpiresAt),
+		ConfirmedAt: timeFromPtr(rec.ConfirmedAt),
+		CreatedAt:   rec.CreatedAt,
+		UpdatedAt:   rec.UpdatedAt,
+	}
+}
+
+func timePtr(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	copy := value
+	return &copy
+}
+
+func timeFromPtr(value *time.Time) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	return *value
+}
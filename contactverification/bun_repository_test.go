@@ -0,0 +1,193 @@
+package contactverification
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestRepositoryContactChangeLifecycle(t *testing.T) {
+	ctx := context.Background()
+	db := newContactChangeTestDB(t)
+	applyContactChangeDDL(t, db)
+
+	issuedAt := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	repo, err := NewRepository(RepositoryConfig{DB: db, Clock: fixedRepositoryClock{t: issuedAt}})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	tenantID := uuid.New()
+	insertContactChangeUser(t, db, userID)
+
+	first, err := repo.CreateContactChange(ctx, types.ContactChange{
+		UserID:    userID,
+		Channel:   types.ContactChannelEmail,
+		Method:    types.ContactVerificationLink,
+		OldValue:  "user@example.com",
+		NewValue:  "first@example.com",
+		JTI:       "change-1",
+		Scope:     types.ScopeFilter{TenantID: tenantID},
+		ExpiresAt: issuedAt.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, types.ContactChangeStatusPending, first.Status)
+
+	second, err := repo.CreateContactChange(ctx, types.ContactChange{
+		UserID:    userID,
+		Channel:   types.ContactChannelEmail,
+		Method:    types.ContactVerificationOTP,
+		OldValue:  "user@example.com",
+		NewValue:  "second@example.com",
+		JTI:       "change-2",
+		CodeHash:  "hash",
+		ExpiresAt: issuedAt.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.GetContactChangeByJTI(ctx, first.JTI)
+	require.NoError(t, err)
+	require.Equal(t, types.ContactChangeStatusSuperseded, stored.Status)
+	require.Equal(t, tenantID, stored.Scope.TenantID)
+
+	attempts, err := repo.RecordContactChangeAttempt(ctx, second.JTI)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+	attempts, err = repo.RecordContactChangeAttempt(ctx, second.JTI)
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+
+	confirmedAt := issuedAt.Add(5 * time.Minute)
+	require.NoError(t, repo.UpdateContactChangeStatus(ctx, second.JTI, types.ContactChangeStatusConfirmed, confirmedAt))
+
+	stored, err = repo.GetContactChangeByJTI(ctx, second.JTI)
+	require.NoError(t, err)
+	require.Equal(t, types.ContactChangeStatusConfirmed, stored.Status)
+	require.Equal(t, confirmedAt, stored.ConfirmedAt)
+	require.Equal(t, "hash", stored.CodeHash)
+
+	err = repo.UpdateContactChangeStatus(ctx, second.JTI, types.ContactChangeStatusExpired, confirmedAt)
+	require.True(t, repository.IsSQLExpectedCountViolation(err))
+	_, err = repo.RecordContactChangeAttempt(ctx, first.JTI)
+	require.True(t, repository.IsSQLExpectedCountViolation(err))
+
+	missing, err := repo.GetContactChangeByJTI(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+func TestContactVerificationMigration_AllowsTokenType(t *testing.T) {
+	db := newContactChangeTestDB(t)
+	applyContactChangeDDL(t, db, "../data/sql/migrations/sqlite/00018_contact_verification.up.sql")
+
+	userID := uuid.New()
+	insertContactChangeUser(t, db, userID)
+	insert := `INSERT INTO user_tokens (id, user_id, token_type, jti) VALUES (?, ?, ?, ?)`
+
+	_, err := db.Exec(insert, uuid.NewString(), userID.String(), "invite", "invite-jti")
+	require.NoError(t, err)
+	_, err = db.Exec(insert, uuid.NewString(), userID.String(), "unknown", "unknown-jti")
+	require.Error(t, err)
+
+	applyMigrations(t, db, "../data/sql/migrations/sqlite/00018_contact_verification.up.sql")
+
+	_, err = db.Exec(insert, uuid.NewString(), userID.String(), string(types.UserTokenContactVerification), "contact-jti")
+	require.NoError(t, err)
+	_, err = db.Exec(insert, uuid.NewString(), userID.String(), "unknown", "unknown-jti")
+	require.Error(t, err)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM user_tokens WHERE jti = 'invite-jti'`).Scan(&count))
+	require.Equal(t, 1, count)
+}
+
+func newContactChangeTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = sqldb.Close()
+	})
+	return db
+}
+
+// applyContactChangeDDL applies the schema up to the contact change table,
+// leaving out any path listed in skip.
+func applyContactChangeDDL(t *testing.T, db *bun.DB, skip ...string) {
+	for _, path := range []string{
+		"../data/sql/migrations/auth/sqlite/00001_users.up.sql",
+		"../data/sql/migrations/sqlite/00008_user_tokens.up.sql",
+		"../data/sql/migrations/sqlite/00018_contact_verification.up.sql",
+	} {
+		if !slices.Contains(skip, path) {
+			applyMigrations(t, db, path)
+		}
+	}
+}
+
+func applyMigrations(t *testing.T, db *bun.DB, paths ...string) {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for _, stmt := range splitStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
+	}
+}
+
+func insertContactChangeUser(t *testing.T, db *bun.DB, userID uuid.UUID) {
+	_, err := db.Exec(`
+		INSERT INTO users (
+			id, user_role, first_name, last_name, username, email, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, userID.String(), "member", "Test", "User", userID.String(), "user@example.com", "{}")
+	require.NoError(t, err)
+}
+
+func splitStatements(sql string) []string {
+	lines := strings.Split(sql, "\n")
+	var builder strings.Builder
+	var statements []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") || line == "---bun:split" {
+			continue
+		}
+		builder.WriteString(line)
+		if strings.HasSuffix(line, ";") {
+			statements = append(statements, strings.TrimSuffix(builder.String(), ";"))
+			builder.Reset()
+		} else {
+			builder.WriteString(" ")
+		}
+	}
+	if builder.Len() > 0 {
+		statements = append(statements, builder.String())
+	}
+	return statements
+}
+
+type fixedRepositoryClock struct {
+	t time.Time
+}
+
+func (f fixedRepositoryClock) Now() time.Time {
+	return f.t
+}
@@ -0,0 +1,32 @@
+package contactverification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Record models the persisted user_contact_changes row.
+type Record struct {
+	bun.BaseModel `bun:"table:user_contact_changes"`
+
+	ID          uuid.UUID  `bun:"id,pk,type:uuid"`
+	UserID      uuid.UUID  `bun:"user_id,notnull,type:uuid"`
+	Channel     string     `bun:"channel,notnull"`
+	Method      string     `bun:"method,notnull"`
+	OldValue    string     `bun:"old_value"`
+	NewValue    string     `bun:"new_value,notnull"`
+	JTI         string     `bun:"jti,notnull"`
+	CodeHash    string     `bun:"code_hash"`
+	Attempts    int        `bun:"attempts,notnull"`
+	Status      string     `bun:"status,notnull"`
+	TenantID    uuid.UUID  `bun:"tenant_id,type:uuid,nullzero"`
+	OrgID       uuid.UUID  `bun:"org_id,type:uuid,nullzero"`
+	RequestedBy uuid.UUID  `bun:"requested_by,type:uuid,nullzero"`
+	IssuedAt    *time.Time `bun:"issued_at,nullzero"`
+	ExpiresAt   *time.Time `bun:"expires_at,nullzero"`
+	ConfirmedAt *time.Time `bun:"confirmed_at,nullzero"`
+	CreatedAt   time.Time  `bun:"created_at"`
+	UpdatedAt   time.Time  `bun:"updated_at"`
+}
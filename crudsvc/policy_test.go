@@ -12,6 +12,7 @@ import (
 	"github.com/goliatone/go-users/crudguard"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/preferences"
+	"github.com/goliatone/go-users/query"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -144,8 +145,48 @@ func TestActivityServiceMasksIPForTenantAdmin(t *testing.T) {
 	require.Empty(t, records[0].IP)
 }
 
+func TestProfileServiceAppliesAccessPolicy(t *testing.T) {
+	ownerID := uuid.New()
+	detail := &stubProfileQuery{profile: &types.UserProfile{
+		UserID:      ownerID,
+		DisplayName: "Owner",
+		Contact:     map[string]any{"email": "owner@example.com"},
+	}}
+	newService := func(actor types.ActorRef) *ProfileService {
+		return NewProfileService(ProfileServiceConfig{
+			Guard:  &stubGuardAdapter{result: crudguard.GuardResult{Actor: actor}},
+			Detail: detail,
+		})
+	}
+	ctx := newTestCrudContext(context.Background())
+
+	record, err := newService(types.ActorRef{ID: uuid.New(), Type: types.ActorRoleSupport}).Show(ctx, ownerID.String(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "Owner", record.DisplayName)
+	require.Nil(t, record.Contact)
+
+	record, err = newService(types.ActorRef{ID: ownerID}).Show(ctx, ownerID.String(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "owner@example.com", record.Contact["email"])
+
+	_, _, err = newService(types.ActorRef{ID: ownerID}).Index(ctx, nil)
+	require.Error(t, err)
+}
+
 // ----- test stubs -----
 
+type stubProfileQuery struct {
+	profile *types.UserProfile
+}
+
+func (s *stubProfileQuery) Query(context.Context, query.ProfileQueryInput) (*types.UserProfile, error) {
+	if s.profile == nil {
+		return nil, nil
+	}
+	clone := *s.profile
+	return &clone, nil
+}
+
 type stubGuardAdapter struct {
 	result    crudguard.GuardResult
 	err       error
@@ -0,0 +1,195 @@
+package crudsvc
+
+import (
+	"context"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-crud"
+	goerrors "github.com/goliatone/go-errors"
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/crudguard"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/goliatone/go-users/query"
+	"github.com/google/uuid"
+)
+
+// ProfileServiceConfig wires dependencies for the profile CRUD adapter.
+type ProfileServiceConfig struct {
+	Guard  GuardAdapter
+	Detail gocommand.Querier[query.ProfileQueryInput, *types.UserProfile]
+	Upsert gocommand.Commander[command.ProfileUpsertInput]
+	Policy profile.ProfileAccessPolicy
+}
+
+// ProfileService routes go-crud operations through the profile query and
+// upsert command, applying the profile access policy to every record it
+// returns.
+type ProfileService struct {
+	guard   GuardAdapter
+	detail  gocommand.Querier[query.ProfileQueryInput, *types.UserProfile]
+	upsert  gocommand.Commander[command.ProfileUpsertInput]
+	policy  profile.ProfileAccessPolicy
+	emitter ActivityEmitter
+	logger  types.Logger
+}
+
+// NewProfileService constructs the adapter.
+func NewProfileService(cfg ProfileServiceConfig, opts ...ServiceOption) *ProfileService {
+	options := applyOptions(opts)
+	policy := cfg.Policy
+	if policy == nil {
+		policy = profile.NewDefaultAccessPolicy()
+	}
+	return &ProfileService{
+		guard:   cfg.Guard,
+		detail:  cfg.Detail,
+		upsert:  cfg.Upsert,
+		policy:  policy,
+		emitter: options.emitter,
+		logger:  options.logger,
+	}
+}
+
+func (s *ProfileService) Create(ctx crud.Context, record *profile.Record) (*profile.Record, error) {
+	return s.upsertRecord(ctx, crud.OpCreate, record)
+}
+
+func (s *ProfileService) CreateBatch(ctx crud.Context, records []*profile.Record) ([]*profile.Record, error) {
+	created := make([]*profile.Record, 0, len(records))
+	for _, record := range records {
+		rec, err := s.upsertRecord(ctx, crud.OpCreateBatch, record)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, rec)
+	}
+	return created, nil
+}
+
+func (s *ProfileService) Update(ctx crud.Context, record *profile.Record) (*profile.Record, error) {
+	return s.upsertRecord(ctx, crud.OpUpdate, record)
+}
+
+func (s *ProfileService) UpdateBatch(ctx crud.Context, records []*profile.Record) ([]*profile.Record, error) {
+	updated := make([]*profile.Record, 0, len(records))
+	for _, record := range records {
+		rec, err := s.upsertRecord(ctx, crud.OpUpdateBatch, record)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, rec)
+	}
+	return updated, nil
+}
+
+func (s *ProfileService) Delete(crud.Context, *profile.Record) error {
+	return notSupported(crud.OpDelete)
+}
+
+func (s *ProfileService) DeleteBatch(crud.Context, []*profile.Record) error {
+	return notSupported(crud.OpDeleteBatch)
+}
+
+func (s *ProfileService) Index(crud.Context, []repository.SelectCriteria) ([]*profile.Record, int, error) {
+	return nil, 0, notSupported(crud.OpList)
+}
+
+func (s *ProfileService) Show(ctx crud.Context, id string, _ []repository.SelectCriteria) (*profile.Record, error) {
+	if s.detail == nil {
+		return nil, goerrors.New("profile query unavailable", goerrors.CategoryInternal).WithCode(goerrors.CodeInternal)
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, goerrors.New("invalid user id", goerrors.CategoryValidation).WithCode(goerrors.CodeBadRequest)
+	}
+	res, err := s.guard.Enforce(crudguard.GuardInput{
+		Context:   ctx,
+		Operation: crud.OpRead,
+		TargetID:  userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	found, err := s.detail.Query(ctx.UserContext(), query.ProfileQueryInput{
+		UserID: userID,
+		Scope:  res.Scope,
+		Actor:  res.Actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, goerrors.New("profile not found", goerrors.CategoryNotFound).WithCode(goerrors.CodeNotFound)
+	}
+	return s.sanitize(ctx.UserContext(), res.Actor, *found)
+}
+
+func (s *ProfileService) upsertRecord(ctx crud.Context, op crud.CrudOperation, record *profile.Record) (*profile.Record, error) {
+	if s.upsert == nil {
+		return nil, goerrors.New("profile upsert command missing", goerrors.CategoryInternal).WithCode(goerrors.CodeInternal)
+	}
+	if record == nil {
+		return nil, goerrors.New("profile payload required", goerrors.CategoryValidation).WithCode(goerrors.CodeBadRequest)
+	}
+	domain := profile.ToUserProfile(record)
+	res, err := s.guard.Enforce(crudguard.GuardInput{
+		Context:   ctx,
+		Operation: op,
+		Scope:     domain.Scope,
+		TargetID:  domain.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result types.UserProfile
+	input := command.ProfileUpsertInput{
+		UserID: domain.UserID,
+		Patch: types.ProfilePatch{
+			DisplayName:  &domain.DisplayName,
+			AvatarURL:    &domain.AvatarURL,
+			Locale:       &domain.Locale,
+			Timezone:     &domain.Timezone,
+			Bio:          &domain.Bio,
+			Contact:      domain.Contact,
+			Metadata:     domain.Metadata,
+			CustomFields: domain.CustomFields,
+		},
+		Scope:  res.Scope,
+		Actor:  res.Actor,
+		Result: &result,
+	}
+	if err := s.upsert.Execute(ctx.UserContext(), input); err != nil {
+		return nil, err
+	}
+	s.emit(ctx.UserContext(), res, domain.UserID)
+	return s.sanitize(ctx.UserContext(), res.Actor, result)
+}
+
+func (s *ProfileService) sanitize(ctx context.Context, actor types.ActorRef, found types.UserProfile) (*profile.Record, error) {
+	visible, err := s.policy.Sanitize(ctx, actor, found)
+	if err != nil {
+		return nil, err
+	}
+	return profile.FromUserProfile(visible), nil
+}
+
+func (s *ProfileService) emit(ctx context.Context, guardResult crudguard.GuardResult, userID uuid.UUID) {
+	if s.emitter == nil {
+		return
+	}
+	record := types.ActivityRecord{
+		UserID:     userID,
+		ActorID:    guardResult.Actor.ID,
+		TenantID:   guardResult.Scope.TenantID,
+		OrgID:      guardResult.Scope.OrgID,
+		Verb:       "profile.upsert",
+		ObjectType: "profile",
+		ObjectID:   userID.String(),
+		Channel:    "profile",
+	}
+	if err := s.emitter.Emit(ctx, record); err != nil && s.logger != nil {
+		s.logger.Error("profile activity emit failed", err)
+	}
+}
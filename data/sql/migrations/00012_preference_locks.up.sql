@@ -0,0 +1,5 @@
+-- 00012_preference_locks.up.sql
+-- Lets system, tenant, and org preferences lock a key against inner overrides.
+
+ALTER TABLE user_preferences
+	ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- 00013_preference_history.down.sql
+-- Drops the preference history log.
+
+DROP INDEX IF EXISTS user_preference_history_scope_key_idx;
+DROP TABLE IF EXISTS user_preference_history;
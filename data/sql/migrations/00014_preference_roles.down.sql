@@ -0,0 +1,22 @@
+-- 00014_preference_roles.down.sql
+-- Removes role preference rows and restores the original key indexes.
+
+DELETE FROM user_preferences WHERE scope_level = 'role';
+
+DELETE FROM user_preference_history WHERE scope_level = 'role';
+
+DROP INDEX IF EXISTS user_preferences_scope_key_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_preferences_scope_key_idx
+    ON user_preferences (user_id, tenant_id, org_id, lower(key));
+
+DROP INDEX IF EXISTS user_preference_history_scope_key_idx;
+
+CREATE INDEX IF NOT EXISTS user_preference_history_scope_key_idx
+    ON user_preference_history (scope_level, user_id, tenant_id, org_id, lower(key), changed_at);
+
+ALTER TABLE user_preference_history
+	DROP COLUMN IF EXISTS role_id;
+
+ALTER TABLE user_preferences
+	DROP COLUMN IF EXISTS role_id;
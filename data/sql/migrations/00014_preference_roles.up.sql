@@ -0,0 +1,19 @@
+-- 00014_preference_roles.up.sql
+-- Adds the role preference level: role rows carry the role id and the key
+-- indexes are widened so role rows do not collide with tenant or org rows.
+
+ALTER TABLE user_preferences
+	ADD COLUMN IF NOT EXISTS role_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE user_preference_history
+	ADD COLUMN IF NOT EXISTS role_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+DROP INDEX IF EXISTS user_preferences_scope_key_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_preferences_scope_key_idx
+    ON user_preferences (user_id, tenant_id, org_id, role_id, lower(key));
+
+DROP INDEX IF EXISTS user_preference_history_scope_key_idx;
+
+CREATE INDEX IF NOT EXISTS user_preference_history_scope_key_idx
+    ON user_preference_history (scope_level, user_id, tenant_id, org_id, role_id, lower(key), changed_at);
@@ -0,0 +1,9 @@
+-- 00015_profile_custom_fields.down.sql
+-- Drops custom profile field definitions and values.
+
+DROP INDEX IF EXISTS user_profile_fields_tenant_key_idx;
+
+DROP TABLE IF EXISTS user_profile_fields;
+
+ALTER TABLE user_profiles
+	DROP COLUMN IF EXISTS custom_fields;
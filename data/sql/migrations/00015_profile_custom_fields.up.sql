@@ -0,0 +1,25 @@
+-- 00015_profile_custom_fields.up.sql
+-- Adds per-tenant custom profile field definitions and the profile column
+-- holding their values.
+
+ALTER TABLE user_profiles
+	ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS user_profile_fields (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    key TEXT NOT NULL,
+    label TEXT,
+    description TEXT,
+    field_type TEXT NOT NULL,
+    options JSONB NOT NULL DEFAULT '[]',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    is_unique BOOLEAN NOT NULL DEFAULT FALSE,
+    visibility TEXT NOT NULL DEFAULT 'public',
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_profile_fields_tenant_key_idx
+    ON user_profile_fields (tenant_id, lower(key));
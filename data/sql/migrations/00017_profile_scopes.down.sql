@@ -0,0 +1,17 @@
+-- 00017_profile_scopes.down.sql
+-- Restores one profile per user. Scoped profiles and their history are
+-- deleted; global profiles are kept.
+
+DELETE FROM user_profiles_history
+    WHERE tenant_id <> '00000000-0000-0000-0000-000000000000'
+       OR org_id <> '00000000-0000-0000-0000-000000000000';
+
+DELETE FROM user_profiles
+    WHERE tenant_id <> '00000000-0000-0000-0000-000000000000'
+       OR org_id <> '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE user_profiles
+    DROP CONSTRAINT IF EXISTS user_profiles_pkey;
+
+ALTER TABLE user_profiles
+    ADD PRIMARY KEY (user_id);
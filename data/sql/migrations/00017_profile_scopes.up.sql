@@ -0,0 +1,17 @@
+-- 00017_profile_scopes.up.sql
+-- Allows one profile per user and tenant/org scope. Existing profiles become
+-- the user's global profile, which scoped profiles fall back to.
+
+UPDATE user_profiles
+    SET tenant_id = '00000000-0000-0000-0000-000000000000',
+        org_id = '00000000-0000-0000-0000-000000000000';
+
+UPDATE user_profiles_history
+    SET tenant_id = '00000000-0000-0000-0000-000000000000',
+        org_id = '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE user_profiles
+    DROP CONSTRAINT IF EXISTS user_profiles_pkey;
+
+ALTER TABLE user_profiles
+    ADD PRIMARY KEY (user_id, tenant_id, org_id);
@@ -0,0 +1,16 @@
+-- 00018_contact_verification.down.sql
+-- Drops pending contact changes and the contact_verification token type.
+
+DROP INDEX IF EXISTS user_contact_changes_user_status_index;
+DROP INDEX IF EXISTS user_contact_changes_jti_unique;
+DROP TABLE IF EXISTS user_contact_changes;
+
+DELETE FROM user_tokens WHERE token_type = 'contact_verification';
+
+ALTER TABLE user_tokens
+	DROP CONSTRAINT IF EXISTS user_tokens_token_type_check;
+
+ALTER TABLE user_tokens
+	ADD CONSTRAINT user_tokens_token_type_check CHECK (
+		token_type IN ('invite', 'register', 'password_reset')
+	);
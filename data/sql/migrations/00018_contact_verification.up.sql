@@ -0,0 +1,38 @@
+-- 00018_contact_verification.up.sql
+-- Adds the contact_verification token type and the table that holds email and
+-- phone changes until the new address is verified.
+
+ALTER TABLE user_tokens
+	DROP CONSTRAINT IF EXISTS user_tokens_token_type_check;
+
+ALTER TABLE user_tokens
+	ADD CONSTRAINT user_tokens_token_type_check CHECK (
+		token_type IN ('invite', 'register', 'password_reset', 'contact_verification')
+	);
+
+CREATE TABLE IF NOT EXISTS user_contact_changes (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'phone')),
+    method TEXT NOT NULL CHECK (method IN ('link', 'otp')),
+    old_value TEXT,
+    new_value TEXT NOT NULL,
+    jti TEXT NOT NULL,
+    code_hash TEXT,
+    attempts INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'confirmed', 'expired', 'superseded')
+    ),
+    tenant_id TEXT,
+    org_id TEXT,
+    requested_by TEXT,
+    issued_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_contact_changes_jti_unique ON user_contact_changes (jti);
+CREATE INDEX IF NOT EXISTS user_contact_changes_user_status_index
+    ON user_contact_changes (user_id, channel, status);
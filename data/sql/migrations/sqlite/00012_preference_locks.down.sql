@@ -0,0 +1,5 @@
+-- 00012_preference_locks.down.sql
+-- Removes preference lock support.
+
+ALTER TABLE user_preferences
+	DROP COLUMN locked;
@@ -0,0 +1,21 @@
+-- 00013_preference_history.up.sql
+-- Append-only log of preference writes used for auditing and point-in-time resolution.
+
+CREATE TABLE IF NOT EXISTS user_preference_history (
+    id TEXT PRIMARY KEY,
+    preference_id TEXT NOT NULL,
+    user_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    org_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    scope_level TEXT NOT NULL DEFAULT 'user',
+    key TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '{}',
+    version INTEGER NOT NULL DEFAULT 1,
+    locked BOOLEAN NOT NULL DEFAULT 0,
+    action TEXT NOT NULL CHECK (action IN ('upsert', 'delete')),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    changed_by TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000'
+);
+
+CREATE INDEX IF NOT EXISTS user_preference_history_scope_key_idx
+    ON user_preference_history (scope_level, user_id, tenant_id, org_id, lower(key), changed_at);
@@ -0,0 +1,9 @@
+-- 00016_profile_versions.down.sql
+-- Drops the profile revision log and version column.
+
+DROP INDEX IF EXISTS user_profiles_history_user_idx;
+
+DROP TABLE IF EXISTS user_profiles_history;
+
+ALTER TABLE user_profiles
+	DROP COLUMN version;
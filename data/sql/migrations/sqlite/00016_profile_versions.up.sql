@@ -0,0 +1,27 @@
+-- 00016_profile_versions.up.sql
+-- Adds the profile version used for optimistic locking and an append-only log
+-- of profile revisions.
+
+ALTER TABLE user_profiles
+	ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS user_profiles_history (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    org_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    version INTEGER NOT NULL DEFAULT 1,
+    display_name TEXT,
+    avatar_url TEXT,
+    locale TEXT,
+    timezone TEXT,
+    bio TEXT,
+    contact TEXT NOT NULL DEFAULT '{}',
+    metadata TEXT NOT NULL DEFAULT '{}',
+    custom_fields TEXT NOT NULL DEFAULT '{}',
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    changed_by TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000'
+);
+
+CREATE INDEX IF NOT EXISTS user_profiles_history_user_idx
+    ON user_profiles_history (user_id, tenant_id, org_id, version);
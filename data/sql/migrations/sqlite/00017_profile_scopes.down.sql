@@ -0,0 +1,46 @@
+-- 00017_profile_scopes.down.sql
+-- Restores one profile per user. Scoped profiles and their history are
+-- deleted; global profiles are kept.
+
+DELETE FROM user_profiles_history
+    WHERE tenant_id <> '00000000-0000-0000-0000-000000000000'
+       OR org_id <> '00000000-0000-0000-0000-000000000000';
+
+DELETE FROM user_profiles
+    WHERE tenant_id <> '00000000-0000-0000-0000-000000000000'
+       OR org_id <> '00000000-0000-0000-0000-000000000000';
+
+CREATE TABLE user_profiles_single (
+    user_id TEXT PRIMARY KEY,
+    display_name TEXT,
+    avatar_url TEXT,
+    locale TEXT,
+    timezone TEXT,
+    bio TEXT,
+    contact TEXT NOT NULL DEFAULT '{}',
+    metadata TEXT NOT NULL DEFAULT '{}',
+    custom_fields TEXT NOT NULL DEFAULT '{}',
+    tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    org_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by TEXT NOT NULL
+);
+
+INSERT INTO user_profiles_single (
+    user_id, display_name, avatar_url, locale, timezone, bio, contact, metadata,
+    custom_fields, tenant_id, org_id, version, created_at, created_by, updated_at, updated_by
+)
+SELECT
+    user_id, display_name, avatar_url, locale, timezone, bio, contact, metadata,
+    custom_fields, tenant_id, org_id, version, created_at, created_by, updated_at, updated_by
+FROM user_profiles;
+
+DROP TABLE user_profiles;
+
+ALTER TABLE user_profiles_single RENAME TO user_profiles;
+
+CREATE INDEX IF NOT EXISTS user_profiles_scope_idx
+    ON user_profiles (tenant_id, org_id);
@@ -0,0 +1,48 @@
+-- 00017_profile_scopes.up.sql
+-- Allows one profile per user and tenant/org scope. Existing profiles become
+-- the user's global profile, which scoped profiles fall back to. SQLite cannot
+-- change a primary key in place, so the table is rebuilt.
+
+UPDATE user_profiles
+    SET tenant_id = '00000000-0000-0000-0000-000000000000',
+        org_id = '00000000-0000-0000-0000-000000000000';
+
+UPDATE user_profiles_history
+    SET tenant_id = '00000000-0000-0000-0000-000000000000',
+        org_id = '00000000-0000-0000-0000-000000000000';
+
+CREATE TABLE user_profiles_scoped (
+    user_id TEXT NOT NULL,
+    display_name TEXT,
+    avatar_url TEXT,
+    locale TEXT,
+    timezone TEXT,
+    bio TEXT,
+    contact TEXT NOT NULL DEFAULT '{}',
+    metadata TEXT NOT NULL DEFAULT '{}',
+    custom_fields TEXT NOT NULL DEFAULT '{}',
+    tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    org_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by TEXT NOT NULL,
+    PRIMARY KEY (user_id, tenant_id, org_id)
+);
+
+INSERT INTO user_profiles_scoped (
+    user_id, display_name, avatar_url, locale, timezone, bio, contact, metadata,
+    custom_fields, tenant_id, org_id, version, created_at, created_by, updated_at, updated_by
+)
+SELECT
+    user_id, display_name, avatar_url, locale, timezone, bio, contact, metadata,
+    custom_fields, tenant_id, org_id, version, created_at, created_by, updated_at, updated_by
+FROM user_profiles;
+
+DROP TABLE user_profiles;
+
+ALTER TABLE user_profiles_scoped RENAME TO user_profiles;
+
+CREATE INDEX IF NOT EXISTS user_profiles_scope_idx
+    ON user_profiles (tenant_id, org_id);
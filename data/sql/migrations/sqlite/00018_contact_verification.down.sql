@@ -0,0 +1,45 @@
+-- 00018_contact_verification.down.sql
+-- Drops pending contact changes and the contact_verification token type.
+
+DROP INDEX IF EXISTS user_contact_changes_user_status_index;
+DROP INDEX IF EXISTS user_contact_changes_jti_unique;
+DROP TABLE IF EXISTS user_contact_changes;
+
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE user_tokens_untyped (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    token_type TEXT NOT NULL CHECK (
+        token_type IN ('invite', 'register', 'password_reset')
+    ),
+    jti TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'issued' CHECK (
+        status IN ('issued', 'used', 'expired')
+    ),
+    issued_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+        ON DELETE CASCADE
+);
+
+INSERT INTO user_tokens_untyped (
+    id, user_id, token_type, jti, status, issued_at, expires_at, used_at, created_at, updated_at
+)
+SELECT
+    id, user_id, token_type, jti, status, issued_at, expires_at, used_at, created_at, updated_at
+FROM user_tokens
+WHERE token_type <> 'contact_verification';
+
+DROP TABLE user_tokens;
+
+ALTER TABLE user_tokens_untyped RENAME TO user_tokens;
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_index ON user_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS user_tokens_jti_unique ON user_tokens (jti);
+CREATE INDEX IF NOT EXISTS user_tokens_expiry_status_index ON user_tokens (status, expires_at);
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,71 @@
+-- 00018_contact_verification.up.sql
+-- Adds the contact_verification token type and the table that holds email and
+-- phone changes until the new address is verified. SQLite cannot alter a CHECK
+-- constraint in place, so user_tokens is rebuilt.
+
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE user_tokens_typed (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    token_type TEXT NOT NULL CHECK (
+        token_type IN ('invite', 'register', 'password_reset', 'contact_verification')
+    ),
+    jti TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'issued' CHECK (
+        status IN ('issued', 'used', 'expired')
+    ),
+    issued_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+        ON DELETE CASCADE
+);
+
+INSERT INTO user_tokens_typed (
+    id, user_id, token_type, jti, status, issued_at, expires_at, used_at, created_at, updated_at
+)
+SELECT
+    id, user_id, token_type, jti, status, issued_at, expires_at, used_at, created_at, updated_at
+FROM user_tokens;
+
+DROP TABLE user_tokens;
+
+ALTER TABLE user_tokens_typed RENAME TO user_tokens;
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_index ON user_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS user_tokens_jti_unique ON user_tokens (jti);
+CREATE INDEX IF NOT EXISTS user_tokens_expiry_status_index ON user_tokens (status, expires_at);
+
+PRAGMA foreign_keys = ON;
+
+CREATE TABLE IF NOT EXISTS user_contact_changes (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'phone')),
+    method TEXT NOT NULL CHECK (method IN ('link', 'otp')),
+    old_value TEXT,
+    new_value TEXT NOT NULL,
+    jti TEXT NOT NULL,
+    code_hash TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'confirmed', 'expired', 'superseded')
+    ),
+    tenant_id TEXT,
+    org_id TEXT,
+    requested_by TEXT,
+    issued_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_contact_changes_jti_unique ON user_contact_changes (jti);
+CREATE INDEX IF NOT EXISTS user_contact_changes_user_status_index
+    ON user_contact_changes (user_id, channel, status);
@@ -0,0 +1,4 @@
+-- 00019_password_history.down.sql
+
+DROP INDEX IF EXISTS user_password_history_user_created_index;
+DROP TABLE IF EXISTS user_password_history;
@@ -0,0 +1,17 @@
+-- 00019_password_history.up.sql
+-- Stores previous password hashes so reset flows can reject reuse of recent
+-- passwords.
+
+CREATE TABLE IF NOT EXISTS user_password_history (
+    id TEXT NOT NULL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    tenant_id TEXT,
+    org_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS user_password_history_user_created_index
+    ON user_password_history (user_id, created_at);
@@ -0,0 +1,4 @@
+-- 00020_login_failures.down.sql
+
+DROP INDEX IF EXISTS user_login_failures_key_occurred_index;
+DROP TABLE IF EXISTS user_login_failures;
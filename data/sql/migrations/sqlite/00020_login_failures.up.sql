@@ -0,0 +1,12 @@
+-- 00020_login_failures.up.sql
+-- Stores failed sign-in timestamps per identifier or IP key for lockout
+-- sliding windows.
+
+CREATE TABLE IF NOT EXISTS user_login_failures (
+    id TEXT NOT NULL PRIMARY KEY,
+    attempt_key TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_login_failures_key_occurred_index
+    ON user_login_failures (attempt_key, occurred_at);
@@ -0,0 +1,98 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// BunStoreConfig wires the Bun-backed attempt store.
+type BunStoreConfig struct {
+	DB *bun.DB
+}
+
+// BunStore implements types.LoginAttemptStore on the user_login_failures
+// table so counters are shared across instances.
+type BunStore struct {
+	db *bun.DB
+}
+
+// NewBunStore constructs the Bun attempt store.
+func NewBunStore(cfg BunStoreConfig) (*BunStore, error) {
+	if cfg.DB == nil {
+		return nil, errors.New("lockout: db required")
+	}
+	return &BunStore{db: cfg.DB}, nil
+}
+
+var _ types.LoginAttemptStore = (*BunStore)(nil)
+
+// RecordFailure implements types.LoginAttemptStore. Failures that slid out of
+// the window are deleted in the same transaction.
+func (s *BunStore) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	var count int
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		rec := &Record{ID: uuid.New(), AttemptKey: key, OccurredAt: at}
+		if _, err := tx.NewInsert().Model(rec).Exec(ctx); err != nil {
+			return err
+		}
+		from := at.Add(-window)
+		if _, err := tx.NewDelete().Model((*Record)(nil)).
+			Where("attempt_key = ?", key).
+			Where("occurred_at < ?", from).
+			Exec(ctx); err != nil {
+			return err
+		}
+		var err error
+		count, err = tx.NewSelect().Model((*Record)(nil)).
+			Where("attempt_key = ?", key).
+			Where("occurred_at >= ?", from).
+			Count(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, repository.MapDatabaseError(err, repository.DetectDriver(s.db))
+	}
+	return count, nil
+}
+
+// CountFailures implements types.LoginAttemptStore.
+func (s *BunStore) CountFailures(ctx context.Context, key string, from time.Time) (int, error) {
+	count, err := s.db.NewSelect().Model((*Record)(nil)).
+		Where("attempt_key = ?", key).
+		Where("occurred_at >= ?", from).
+		Count(ctx)
+	if err != nil {
+		return 0, repository.MapDatabaseError(err, repository.DetectDriver(s.db))
+	}
+	return count, nil
+}
+
+// ResetFailures implements types.LoginAttemptStore.
+func (s *BunStore) ResetFailures(ctx context.Context, key string) error {
+	_, err := s.db.NewDelete().Model((*Record)(nil)).
+		Where("attempt_key = ?", key).
+		Exec(ctx)
+	if err != nil {
+		return repository.MapDatabaseError(err, repository.DetectDriver(s.db))
+	}
+	return nil
+}
+
+// Prune deletes failures older than the given time for every key. Run it
+// periodically so keys that stop failing do not accumulate rows.
+func (s *BunStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.db.NewDelete().Model((*Record)(nil)).
+		Where("occurred_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, repository.MapDatabaseError(err, repository.DetectDriver(s.db))
+	}
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
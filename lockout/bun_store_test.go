@@ -0,0 +1,101 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestBunStoreSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewBunStore(BunStoreConfig{DB: newLockoutTestDB(t)})
+	require.NoError(t, err)
+
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	window := 10 * time.Minute
+	for i, offset := range []time.Duration{0, 4 * time.Minute, 8 * time.Minute} {
+		count, err := store.RecordFailure(ctx, "identifier:ada@example.com", base.Add(offset), window)
+		require.NoError(t, err)
+		require.Equal(t, i+1, count)
+	}
+	count, err := store.RecordFailure(ctx, "identifier:ada@example.com", base.Add(12*time.Minute), window)
+	require.NoError(t, err)
+	require.Equal(t, 3, count, "the first failure slid out of the window")
+
+	_, err = store.RecordFailure(ctx, "ip:10.0.0.1", base, window)
+	require.NoError(t, err)
+
+	count, err = store.CountFailures(ctx, "identifier:ada@example.com", base.Add(5*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	require.NoError(t, store.ResetFailures(ctx, "identifier:ada@example.com"))
+	count, err = store.CountFailures(ctx, "identifier:ada@example.com", base)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	pruned, err := store.Prune(ctx, base.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+	count, err = store.CountFailures(ctx, "ip:10.0.0.1", base)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func newLockoutTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = sqldb.Close()
+	})
+	applyMigrations(t, db, "../data/sql/migrations/sqlite/00020_login_failures.up.sql")
+	return db
+}
+
+func applyMigrations(t *testing.T, db *bun.DB, paths ...string) {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for _, stmt := range splitStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
+	}
+}
+
+func splitStatements(sql string) []string {
+	lines := strings.Split(sql, "\n")
+	var builder strings.Builder
+	var statements []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") || line == "---bun:split" {
+			continue
+		}
+		builder.WriteString(line)
+		if strings.HasSuffix(line, ";") {
+			statements = append(statements, strings.TrimSuffix(builder.String(), ";"))
+			builder.Reset()
+		} else {
+			builder.WriteString(" ")
+		}
+	}
+	if builder.Len() > 0 {
+		statements = append(statements, builder.String())
+	}
+	return statements
+}
@@ -0,0 +1,6 @@
+// Package lockout counts failed sign-ins per identifier and per IP over
+// sliding windows and locks accounts through the lifecycle command once a
+// threshold is reached. Locks lift after a cool-down or through
+// command.UserUnlockCommand. MemoryStore and BunStore implement
+// types.LoginAttemptStore.
+package lockout
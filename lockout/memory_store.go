@@ -0,0 +1,77 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// MemoryStore keeps failures in process memory. It suits tests and single
+// instance deployments.
+type MemoryStore struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+// NewMemoryStore constructs an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{failures: make(map[string][]time.Time)}
+}
+
+var _ types.LoginAttemptStore = (*MemoryStore)(nil)
+
+// RecordFailure implements types.LoginAttemptStore.
+func (s *MemoryStore) RecordFailure(_ context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := since(s.failures[key], at.Add(-window))
+	kept = append(kept, at)
+	s.failures[key] = kept
+	return len(kept), nil
+}
+
+// CountFailures implements types.LoginAttemptStore.
+func (s *MemoryStore) CountFailures(_ context.Context, key string, from time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(since(s.failures[key], from)), nil
+}
+
+// ResetFailures implements types.LoginAttemptStore.
+func (s *MemoryStore) ResetFailures(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, key)
+	return nil
+}
+
+// Prune deletes failures older than the given time for every key and drops
+// keys left empty. Run it periodically so keys that stop failing do not
+// accumulate in memory.
+func (s *MemoryStore) Prune(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := 0
+	for key, failures := range s.failures {
+		kept := since(failures, before)
+		pruned += len(failures) - len(kept)
+		if len(kept) == 0 {
+			delete(s.failures, key)
+			continue
+		}
+		s.failures[key] = kept
+	}
+	return pruned, nil
+}
+
+func since(failures []time.Time, from time.Time) []time.Time {
+	kept := make([]time.Time, 0, len(failures)+1)
+	for _, at := range failures {
+		if !at.Before(from) {
+			kept = append(kept, at)
+		}
+	}
+	return kept
+}
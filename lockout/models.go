@@ -0,0 +1,17 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Record models the persisted user_login_failures row.
+type Record struct {
+	bun.BaseModel `bun:"table:user_login_failures"`
+
+	ID         uuid.UUID `bun:"id,pk,type:uuid"`
+	AttemptKey string    `bun:"attempt_key,notnull"`
+	OccurredAt time.Time `bun:"occurred_at,notnull"`
+}
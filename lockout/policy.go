@@ -0,0 +1,56 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+const (
+	// DefaultIdentifierThreshold is the failures per identifier that lock the
+	// account when Policy.IdentifierThreshold is zero.
+	DefaultIdentifierThreshold = 5
+	// DefaultIPThreshold is the failures per IP that throttle the address
+	// when Policy.IPThreshold is zero.
+	DefaultIPThreshold = 20
+	// DefaultWindow is the sliding window used when a window is zero.
+	DefaultWindow = 15 * time.Minute
+	// DefaultCoolDown is how long a lock lasts when Policy.CoolDown is zero.
+	DefaultCoolDown = 30 * time.Minute
+)
+
+// Policy sets the lockout thresholds. Zero values use the package defaults;
+// a negative threshold disables that counter.
+type Policy struct {
+	IdentifierThreshold int
+	IdentifierWindow    time.Duration
+	IPThreshold         int
+	IPWindow            time.Duration
+	CoolDown            time.Duration
+	// LockState is the lifecycle state applied to locked accounts. It must be
+	// able to transition back to active; defaults to suspended because the
+	// users table has no dedicated locked status.
+	LockState types.LifecycleState
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.IdentifierThreshold == 0 {
+		p.IdentifierThreshold = DefaultIdentifierThreshold
+	}
+	if p.IdentifierWindow <= 0 {
+		p.IdentifierWindow = DefaultWindow
+	}
+	if p.IPThreshold == 0 {
+		p.IPThreshold = DefaultIPThreshold
+	}
+	if p.IPWindow <= 0 {
+		p.IPWindow = DefaultWindow
+	}
+	if p.CoolDown <= 0 {
+		p.CoolDown = DefaultCoolDown
+	}
+	if p.LockState == "" {
+		p.LockState = types.LifecycleStateSuspended
+	}
+	return p
+}
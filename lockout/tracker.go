@@ -0,0 +1,355 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// LoginAttempt identifies a sign-in attempt.
+type LoginAttempt struct {
+	// Identifier is the email or username the caller tried to sign in with.
+	Identifier string
+	IP         string
+	Scope      types.ScopeFilter
+}
+
+// AttemptResult reports the counters after a failure was recorded.
+type AttemptResult struct {
+	IdentifierFailures int
+	IPFailures         int
+	// Locked is true when this failure locked the account.
+	Locked      bool
+	LockedUntil time.Time
+}
+
+// Config wires the tracker.
+type Config struct {
+	Store      types.LoginAttemptStore
+	Repository types.AuthRepository
+	// Lifecycle applies Policy.LockState. Required to lock accounts.
+	Lifecycle *command.UserLifecycleTransitionCommand
+	// Unlock lifts locks whose cool-down elapsed. When nil one is built from
+	// Repository, Lifecycle, and Store.
+	Unlock *command.UserUnlockCommand
+	Policy Policy
+	// Actor is recorded on automatic lock and unlock transitions. Defaults to
+	// a system actor carrying the user's ID.
+	Actor    types.ActorRef
+	Clock    types.Clock
+	Activity types.ActivitySink
+	Hooks    types.Hooks
+	Logger   types.Logger
+}
+
+// Tracker counts failed sign-ins and locks accounts that exceed the policy.
+type Tracker struct {
+	store     types.LoginAttemptStore
+	repo      types.AuthRepository
+	lifecycle *command.UserLifecycleTransitionCommand
+	unlock    *command.UserUnlockCommand
+	policy    Policy
+	actor     types.ActorRef
+	clock     types.Clock
+	sink      types.ActivitySink
+	hooks     types.Hooks
+	logger    types.Logger
+}
+
+// NewTracker constructs the login attempt tracker.
+func NewTracker(cfg Config) (*Tracker, error) {
+	if cfg.Store == nil {
+		return nil, types.ErrMissingLoginAttemptStore
+	}
+	if cfg.Repository == nil {
+		return nil, types.ErrMissingAuthRepository
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = types.NopLogger{}
+	}
+	unlock := cfg.Unlock
+	if unlock == nil {
+		unlock = command.NewUserUnlockCommand(command.UserUnlockCommandConfig{
+			Repository: cfg.Repository,
+			Lifecycle:  cfg.Lifecycle,
+			Attempts:   cfg.Store,
+			Clock:      clock,
+			Activity:   cfg.Activity,
+			Hooks:      cfg.Hooks,
+			Logger:     logger,
+		})
+	}
+	return &Tracker{
+		store:     cfg.Store,
+		repo:      cfg.Repository,
+		lifecycle: cfg.Lifecycle,
+		unlock:    unlock,
+		policy:    cfg.Policy.withDefaults(),
+		actor:     cfg.Actor,
+		clock:     clock,
+		sink:      cfg.Activity,
+		hooks:     cfg.Hooks,
+		logger:    logger,
+	}, nil
+}
+
+// Policy returns the effective policy with defaults applied.
+func (t *Tracker) Policy() Policy {
+	return t.policy
+}
+
+// Check runs before credentials are verified. It returns
+// types.ErrTooManyLoginAttempts when the IP or an unknown identifier is
+// throttled and types.ErrAccountLocked while the account is locked. Locks
+// whose cool-down elapsed are lifted.
+func (t *Tracker) Check(ctx context.Context, attempt LoginAttempt) error {
+	current := t.clock.Now()
+	if throttled, err := t.exceeded(ctx, attempt.IP, types.LoginAttemptIPKey, t.policy.IPThreshold, t.policy.IPWindow, current); err != nil || throttled {
+		if err != nil {
+			return err
+		}
+		return types.ErrTooManyLoginAttempts
+	}
+	user, err := t.lookup(ctx, attempt.Identifier)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		// Unknown identifiers are throttled like known ones so responses do
+		// not reveal which accounts exist.
+		throttled, err := t.exceeded(ctx, attempt.Identifier, types.LoginAttemptIdentifierKey, t.policy.IdentifierThreshold, t.policy.IdentifierWindow, current)
+		if err != nil {
+			return err
+		}
+		if throttled {
+			return types.ErrTooManyLoginAttempts
+		}
+		return nil
+	}
+	until, locked := types.AccountLockedUntil(user.Metadata)
+	if !locked {
+		return nil
+	}
+	if until.IsZero() || current.Before(until) {
+		return lockedError(until)
+	}
+	return t.unlock.Execute(ctx, command.UserUnlockInput{
+		UserID: user.ID,
+		Actor:  t.actorFor(user),
+		Scope:  attempt.Scope,
+		Reason: "cool_down_elapsed",
+	})
+}
+
+// RecordFailure records a failed sign-in for the identifier and IP and locks
+// the account once the identifier threshold is reached.
+func (t *Tracker) RecordFailure(ctx context.Context, attempt LoginAttempt) (AttemptResult, error) {
+	var result AttemptResult
+	current := t.clock.Now()
+	if strings.TrimSpace(attempt.IP) != "" {
+		count, err := t.store.RecordFailure(ctx, types.LoginAttemptIPKey(attempt.IP), current, t.policy.IPWindow)
+		if err != nil {
+			return result, err
+		}
+		result.IPFailures = count
+	}
+	if strings.TrimSpace(attempt.Identifier) == "" {
+		return result, nil
+	}
+	count, err := t.store.RecordFailure(ctx, types.LoginAttemptIdentifierKey(attempt.Identifier), current, t.policy.IdentifierWindow)
+	if err != nil {
+		return result, err
+	}
+	result.IdentifierFailures = count
+
+	user, err := t.lookup(ctx, attempt.Identifier)
+	if err != nil {
+		return result, err
+	}
+	var userID uuid.UUID
+	if user != nil {
+		userID = user.ID
+	}
+	t.record(ctx, types.ActivityRecord{
+		UserID:     userID,
+		ActorID:    userID,
+		Verb:       "auth.login.failed",
+		ObjectType: "user",
+		ObjectID:   objectID(userID),
+		Channel:    "auth",
+		IP:         attempt.IP,
+		TenantID:   attempt.Scope.TenantID,
+		OrgID:      attempt.Scope.OrgID,
+		Data: map[string]any{
+			"identifier_failures": result.IdentifierFailures,
+			"ip_failures":         result.IPFailures,
+		},
+		OccurredAt: current,
+	})
+
+	if user == nil || user.Status != types.LifecycleStateActive {
+		return result, nil
+	}
+	if t.policy.IdentifierThreshold < 0 || count < t.policy.IdentifierThreshold {
+		return result, nil
+	}
+	until, err := t.lock(ctx, user, attempt, count, current)
+	if err != nil {
+		return result, err
+	}
+	result.Locked = true
+	result.LockedUntil = until
+	return result, nil
+}
+
+// RecordSuccess clears the identifier's failures after a successful sign-in.
+// IP counters are left to expire so one valid account cannot reset them.
+func (t *Tracker) RecordSuccess(ctx context.Context, attempt LoginAttempt) error {
+	if strings.TrimSpace(attempt.Identifier) == "" {
+		return nil
+	}
+	return t.store.ResetFailures(ctx, types.LoginAttemptIdentifierKey(attempt.Identifier))
+}
+
+func (t *Tracker) lock(ctx context.Context, user *types.AuthUser, attempt LoginAttempt, failures int, current time.Time) (time.Time, error) {
+	if t.lifecycle == nil {
+		return time.Time{}, errors.New("lockout: lifecycle command required to lock accounts")
+	}
+	until := current.Add(t.policy.CoolDown)
+	actor := t.actorFor(user)
+	previous := user.Status
+	if err := t.lifecycle.Execute(ctx, command.UserLifecycleTransitionInput{
+		UserID: user.ID,
+		Target: t.policy.LockState,
+		Actor:  actor,
+		Reason: "account_locked",
+		Metadata: map[string]any{
+			"failures":     failures,
+			"locked_until": until,
+		},
+		Scope: attempt.Scope,
+	}); err != nil {
+		return time.Time{}, err
+	}
+	if err := t.markLocked(ctx, user.ID, current, until); err != nil {
+		return time.Time{}, t.revertLock(ctx, user.ID, previous, actor, attempt.Scope, err)
+	}
+	t.record(ctx, types.ActivityRecord{
+		UserID:     user.ID,
+		ActorID:    actor.ID,
+		Verb:       "user.account.locked",
+		ObjectType: "user",
+		ObjectID:   user.ID.String(),
+		Channel:    "auth",
+		IP:         attempt.IP,
+		TenantID:   attempt.Scope.TenantID,
+		OrgID:      attempt.Scope.OrgID,
+		Data: map[string]any{
+			"failures":     failures,
+			"locked_until": until,
+			"state":        string(t.policy.LockState),
+		},
+		OccurredAt: current,
+	})
+	return until, nil
+}
+
+func (t *Tracker) markLocked(ctx context.Context, id uuid.UUID, current, until time.Time) error {
+	locked, err := t.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if locked == nil {
+		return command.ErrUserNotFound
+	}
+	locked.Metadata = types.MarkAccountLockedMetadata(locked.Metadata, current, until)
+	_, err = t.repo.Update(ctx, locked)
+	return err
+}
+
+// revertLock returns the account to its previous state when the lock
+// metadata could not be written. Without the metadata Check would treat the
+// account as suspended for good instead of lifting the lock after the
+// cool-down.
+func (t *Tracker) revertLock(ctx context.Context, id uuid.UUID, previous types.LifecycleState, actor types.ActorRef, scope types.ScopeFilter, cause error) error {
+	if err := t.lifecycle.Execute(ctx, command.UserLifecycleTransitionInput{
+		UserID: id,
+		Target: previous,
+		Actor:  actor,
+		Reason: "account_lock_failed",
+		Scope:  scope,
+	}); err != nil {
+		t.logger.Error("lockout lock revert failed", err, "user_id", id)
+		return errors.Join(cause, err)
+	}
+	return cause
+}
+
+func (t *Tracker) exceeded(ctx context.Context, value string, key func(string) string, threshold int, window time.Duration, current time.Time) (bool, error) {
+	if threshold < 0 || strings.TrimSpace(value) == "" {
+		return false, nil
+	}
+	count, err := t.store.CountFailures(ctx, key(value), current.Add(-window))
+	if err != nil {
+		return false, err
+	}
+	return count >= threshold, nil
+}
+
+func (t *Tracker) lookup(ctx context.Context, identifier string) (*types.AuthUser, error) {
+	if strings.TrimSpace(identifier) == "" {
+		return nil, nil
+	}
+	user, err := t.repo.GetByIdentifier(ctx, identifier)
+	if err != nil {
+		if repository.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (t *Tracker) actorFor(user *types.AuthUser) types.ActorRef {
+	if t.actor.ID != uuid.Nil {
+		return t.actor
+	}
+	return types.ActorRef{ID: user.ID, Type: "system"}
+}
+
+func (t *Tracker) record(ctx context.Context, record types.ActivityRecord) {
+	if t.sink != nil {
+		if err := t.sink.Log(ctx, record); err != nil {
+			t.logger.Error("lockout activity log failed", err, "verb", record.Verb)
+		}
+	}
+	if t.hooks.AfterActivity != nil {
+		t.hooks.AfterActivity(ctx, record)
+	}
+}
+
+func lockedError(until time.Time) error {
+	if until.IsZero() {
+		return types.ErrAccountLocked
+	}
+	return fmt.Errorf("%w until %s", types.ErrAccountLocked, until.UTC().Format(time.RFC3339))
+}
+
+func objectID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
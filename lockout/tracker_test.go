@@ -0,0 +1,269 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerLocksAfterThresholdAndUnlocksAfterCoolDown(t *testing.T) {
+	ctx := context.Background()
+	repo := newLockoutAuthRepo()
+	user := repo.seed("ada@example.com", types.LifecycleStateActive)
+	clock := &steppingClock{at: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)}
+	sink := &recordingSink{}
+	tracker := newTestTracker(t, repo, clock, sink, Policy{
+		IdentifierThreshold: 3,
+		CoolDown:            10 * time.Minute,
+	})
+
+	attempt := LoginAttempt{Identifier: "Ada@Example.com", IP: "10.0.0.1"}
+	for i := 1; i < 3; i++ {
+		result, err := tracker.RecordFailure(ctx, attempt)
+		require.NoError(t, err)
+		require.Equal(t, i, result.IdentifierFailures)
+		require.False(t, result.Locked)
+		clock.advance(time.Minute)
+	}
+	result, err := tracker.RecordFailure(ctx, attempt)
+	require.NoError(t, err)
+	require.True(t, result.Locked)
+	require.Equal(t, clock.Now().Add(10*time.Minute), result.LockedUntil)
+	require.Equal(t, types.LifecycleStateSuspended, repo.users[user].Status)
+	_, locked := types.AccountLockedUntil(repo.users[user].Metadata)
+	require.True(t, locked)
+	require.Contains(t, sink.verbs(), "user.account.locked")
+
+	err = tracker.Check(ctx, attempt)
+	require.ErrorIs(t, err, types.ErrAccountLocked)
+
+	clock.advance(11 * time.Minute)
+	require.NoError(t, tracker.Check(ctx, attempt))
+	require.Equal(t, types.LifecycleStateActive, repo.users[user].Status)
+	_, locked = types.AccountLockedUntil(repo.users[user].Metadata)
+	require.False(t, locked)
+	require.Contains(t, sink.verbs(), "user.account.unlocked")
+
+	count, err := tracker.store.CountFailures(ctx, types.LoginAttemptIdentifierKey("ada@example.com"), time.Time{})
+	require.NoError(t, err)
+	require.Zero(t, count, "unlock resets the identifier counter")
+}
+
+func TestTrackerRevertsLockWhenMetadataWriteFails(t *testing.T) {
+	ctx := context.Background()
+	repo := newLockoutAuthRepo()
+	user := repo.seed("ada@example.com", types.LifecycleStateActive)
+	clock := &steppingClock{at: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)}
+	sink := &recordingSink{}
+	tracker := newTestTracker(t, repo, clock, sink, Policy{IdentifierThreshold: 1})
+	repo.updateErr = errors.New("metadata write failed")
+
+	result, err := tracker.RecordFailure(ctx, LoginAttempt{Identifier: "ada@example.com"})
+	require.ErrorIs(t, err, repo.updateErr)
+	require.False(t, result.Locked)
+	require.Equal(t, types.LifecycleStateActive, repo.users[user].Status)
+	require.NotContains(t, sink.verbs(), "user.account.locked")
+}
+
+func TestTrackerThrottlesIPAndUnknownIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	repo := newLockoutAuthRepo()
+	clock := &steppingClock{at: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)}
+	tracker := newTestTracker(t, repo, clock, nil, Policy{
+		IdentifierThreshold: 2,
+		IPThreshold:         3,
+	})
+
+	unknown := LoginAttempt{Identifier: "ghost@example.com", IP: "10.0.0.2"}
+	for range 2 {
+		_, err := tracker.RecordFailure(ctx, unknown)
+		require.NoError(t, err)
+	}
+	require.ErrorIs(t, tracker.Check(ctx, unknown), types.ErrTooManyLoginAttempts)
+
+	other := LoginAttempt{Identifier: "someone@example.com", IP: "10.0.0.2"}
+	require.NoError(t, tracker.Check(ctx, other))
+	_, err := tracker.RecordFailure(ctx, other)
+	require.NoError(t, err)
+	require.ErrorIs(t, tracker.Check(ctx, other), types.ErrTooManyLoginAttempts)
+
+	clock.advance(DefaultWindow + time.Second)
+	require.NoError(t, tracker.Check(ctx, other))
+}
+
+func TestTrackerRecordSuccessResetsIdentifier(t *testing.T) {
+	ctx := context.Background()
+	repo := newLockoutAuthRepo()
+	repo.seed("ada@example.com", types.LifecycleStateActive)
+	clock := &steppingClock{at: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)}
+	tracker := newTestTracker(t, repo, clock, nil, Policy{IdentifierThreshold: 2})
+
+	attempt := LoginAttempt{Identifier: "ada@example.com"}
+	_, err := tracker.RecordFailure(ctx, attempt)
+	require.NoError(t, err)
+	require.NoError(t, tracker.RecordSuccess(ctx, attempt))
+
+	result, err := tracker.RecordFailure(ctx, attempt)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.IdentifierFailures)
+	require.False(t, result.Locked)
+}
+
+func TestMemoryStoreSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	for _, offset := range []time.Duration{0, 5 * time.Minute} {
+		_, err := store.RecordFailure(ctx, "ip:10.0.0.1", base.Add(offset), 10*time.Minute)
+		require.NoError(t, err)
+	}
+	count, err := store.RecordFailure(ctx, "ip:10.0.0.1", base.Add(11*time.Minute), 10*time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	count, err = store.CountFailures(ctx, "ip:10.0.0.1", base.Add(6*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	require.NoError(t, store.ResetFailures(ctx, "ip:10.0.0.1"))
+	count, err = store.CountFailures(ctx, "ip:10.0.0.1", base)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	_, err := store.RecordFailure(ctx, "ip:10.0.0.1", base, time.Hour)
+	require.NoError(t, err)
+	_, err = store.RecordFailure(ctx, "ip:10.0.0.2", base, time.Hour)
+	require.NoError(t, err)
+	_, err = store.RecordFailure(ctx, "ip:10.0.0.2", base.Add(2*time.Minute), time.Hour)
+	require.NoError(t, err)
+
+	pruned, err := store.Prune(ctx, base.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+	require.NotContains(t, store.failures, "ip:10.0.0.1")
+	count, err := store.CountFailures(ctx, "ip:10.0.0.2", base)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func newTestTracker(t *testing.T, repo *lockoutAuthRepo, clock types.Clock, sink types.ActivitySink, policy Policy) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker(Config{
+		Store:      NewMemoryStore(),
+		Repository: repo,
+		Lifecycle: command.NewUserLifecycleTransitionCommand(command.LifecycleCommandConfig{
+			Repository: repo,
+			Clock:      clock,
+		}),
+		Policy:   policy,
+		Clock:    clock,
+		Activity: sink,
+	})
+	require.NoError(t, err)
+	return tracker
+}
+
+type steppingClock struct {
+	at time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	return c.at
+}
+
+func (c *steppingClock) advance(d time.Duration) {
+	c.at = c.at.Add(d)
+}
+
+type recordingSink struct {
+	records []types.ActivityRecord
+}
+
+func (s *recordingSink) Log(_ context.Context, record types.ActivityRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *recordingSink) verbs() []string {
+	out := make([]string, 0, len(s.records))
+	for _, record := range s.records {
+		out = append(out, record.Verb)
+	}
+	return out
+}
+
+type lockoutAuthRepo struct {
+	users     map[uuid.UUID]*types.AuthUser
+	updateErr error
+}
+
+func newLockoutAuthRepo() *lockoutAuthRepo {
+	return &lockoutAuthRepo{users: make(map[uuid.UUID]*types.AuthUser)}
+}
+
+func (r *lockoutAuthRepo) seed(email string, status types.LifecycleState) uuid.UUID {
+	id := uuid.New()
+	r.users[id] = &types.AuthUser{ID: id, Email: email, Status: status}
+	return id
+}
+
+func (r *lockoutAuthRepo) GetByID(_ context.Context, id uuid.UUID) (*types.AuthUser, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.NewRecordNotFound()
+	}
+	return user, nil
+}
+
+func (r *lockoutAuthRepo) GetByIdentifier(_ context.Context, identifier string) (*types.AuthUser, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, strings.TrimSpace(identifier)) {
+			return user, nil
+		}
+	}
+	return nil, repository.NewRecordNotFound()
+}
+
+func (r *lockoutAuthRepo) Create(_ context.Context, input *types.AuthUser) (*types.AuthUser, error) {
+	r.users[input.ID] = input
+	return input, nil
+}
+
+func (r *lockoutAuthRepo) Update(_ context.Context, input *types.AuthUser) (*types.AuthUser, error) {
+	if r.updateErr != nil {
+		return nil, r.updateErr
+	}
+	r.users[input.ID] = input
+	return input, nil
+}
+
+func (r *lockoutAuthRepo) UpdateStatus(_ context.Context, _ types.ActorRef, id uuid.UUID, next types.LifecycleState, _ ...types.TransitionOption) (*types.AuthUser, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.NewRecordNotFound()
+	}
+	user.Status = next
+	return user, nil
+}
+
+func (r *lockoutAuthRepo) AllowedTransitions(context.Context, uuid.UUID) ([]types.LifecycleTransition, error) {
+	return nil, nil
+}
+
+func (r *lockoutAuthRepo) ResetPassword(context.Context, uuid.UUID, string) error {
+	return nil
+}
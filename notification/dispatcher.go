@@ -0,0 +1,359 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/google/uuid"
+)
+
+// Preference keys consulted when selecting channels. Values are a channel
+// name or a list of channel names; an empty list opts the user out.
+const (
+	PreferenceKeyPrefix  = "notifications."
+	PreferenceKeyDefault = PreferenceKeyPrefix + "default"
+)
+
+// PreferenceKey returns the per-kind channel preference key
+// (e.g. notifications.password_reset).
+func PreferenceKey(kind Kind) string {
+	return PreferenceKeyPrefix + strings.ToLower(strings.TrimSpace(string(kind)))
+}
+
+// PreferenceResolver resolves effective preferences for channel selection.
+type PreferenceResolver interface {
+	Resolve(ctx context.Context, input preferences.ResolveInput) (types.PreferenceSnapshot, error)
+}
+
+// Config wires the dispatcher dependencies.
+type Config struct {
+	Resolver PreferenceResolver
+	// Users fills in recipient details when a notification carries only a user id.
+	Users types.AuthRepository
+	// Profiles supplies the recipient phone from the profile contact resolved
+	// for the notification scope.
+	Profiles   types.ProfileRepository
+	Transports map[Channel]Transport
+	Templates  *TemplateSet
+	Activity   types.ActivitySink
+	Hooks      types.Hooks
+	Clock      types.Clock
+	IDGen      types.IDGenerator
+	Logger     types.Logger
+	// DefaultChannels apply when no preference is set (defaults to email).
+	DefaultChannels []Channel
+	// RequiredKinds ignore opt-outs because the user cannot act without them
+	// (defaults to invite, registration, and password reset).
+	RequiredKinds []Kind
+}
+
+// Dispatcher renders notifications and delivers them over the channels the
+// recipient prefers, recording each attempt as activity.
+type Dispatcher struct {
+	resolver        PreferenceResolver
+	users           types.AuthRepository
+	profiles        types.ProfileRepository
+	templates       *TemplateSet
+	sink            types.ActivitySink
+	hooks           types.Hooks
+	clock           types.Clock
+	idGen           types.IDGenerator
+	logger          types.Logger
+	defaultChannels []Channel
+	required        map[Kind]bool
+
+	mu         sync.RWMutex
+	transports map[Channel]Transport
+}
+
+var _ Notifier = (*Dispatcher)(nil)
+
+// NewDispatcher constructs a dispatcher.
+func NewDispatcher(cfg Config) *Dispatcher {
+	templates := cfg.Templates
+	if templates == nil {
+		templates = DefaultTemplates()
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	idGen := cfg.IDGen
+	if idGen == nil {
+		idGen = types.UUIDGenerator{}
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = types.NopLogger{}
+	}
+	defaults := normalizeChannels(cfg.DefaultChannels)
+	if len(defaults) == 0 {
+		defaults = []Channel{ChannelEmail}
+	}
+	requiredKinds := cfg.RequiredKinds
+	if requiredKinds == nil {
+		requiredKinds = []Kind{KindInvite, KindRegistration, KindPasswordReset}
+	}
+	required := make(map[Kind]bool, len(requiredKinds))
+	for _, kind := range requiredKinds {
+		required[kind] = true
+	}
+	transports := make(map[Channel]Transport, len(cfg.Transports))
+	maps.Copy(transports, cfg.Transports)
+	return &Dispatcher{
+		resolver:        cfg.Resolver,
+		users:           cfg.Users,
+		profiles:        cfg.Profiles,
+		templates:       templates,
+		sink:            cfg.Activity,
+		hooks:           cfg.Hooks,
+		clock:           clock,
+		idGen:           idGen,
+		logger:          logger,
+		defaultChannels: defaults,
+		required:        required,
+		transports:      transports,
+	}
+}
+
+// RegisterTransport adds or replaces the transport for channel.
+func (d *Dispatcher) RegisterTransport(channel Channel, transport Transport) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.transports[channel] = transport
+}
+
+// Notify implements Notifier.
+func (d *Dispatcher) Notify(ctx context.Context, n Notification) error {
+	_, err := d.Dispatch(ctx, n)
+	return err
+}
+
+// Dispatch delivers n and returns the per-channel outcome. The returned error
+// joins every failed delivery.
+func (d *Dispatcher) Dispatch(ctx context.Context, n Notification) ([]Delivery, error) {
+	if d == nil {
+		return nil, nil
+	}
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	n.Kind = Kind(strings.ToLower(strings.TrimSpace(string(n.Kind))))
+	n.Recipient = d.resolveRecipient(ctx, n)
+
+	channels := d.selectChannels(ctx, n)
+	if len(channels) == 0 {
+		d.record(ctx, n, Delivery{Status: DeliveryStatusSkipped})
+		return []Delivery{{Status: DeliveryStatusSkipped}}, nil
+	}
+
+	deliveries := make([]Delivery, 0, len(channels))
+	var errs []error
+	for _, channel := range channels {
+		delivery := d.deliver(ctx, n, channel)
+		d.record(ctx, n, delivery)
+		if delivery.Err != nil {
+			errs = append(errs, delivery.Err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, n Notification, channel Channel) Delivery {
+	delivery := Delivery{MessageID: d.idGen.UUID(), Channel: channel}
+	fail := func(err error) Delivery {
+		delivery.Status = DeliveryStatusFailed
+		delivery.Err = fmt.Errorf("notification %s via %s: %w", n.Kind, channel, err)
+		return delivery
+	}
+
+	d.mu.RLock()
+	transport := d.transports[channel]
+	d.mu.RUnlock()
+	if transport == nil {
+		return fail(ErrTransportNotFound)
+	}
+	to := n.Recipient.Address(channel, n.UserID)
+	if to == "" {
+		return fail(ErrRecipientAddressMissing)
+	}
+	subject, body, err := d.templates.Render(n.Kind, channel, TemplateData{
+		Kind:      n.Kind,
+		Channel:   channel,
+		Recipient: n.Recipient,
+		Data:      n.Data,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	err = transport.Send(ctx, Message{
+		ID:        delivery.MessageID,
+		Kind:      n.Kind,
+		Channel:   channel,
+		UserID:    n.UserID,
+		To:        to,
+		Subject:   subject,
+		Body:      body,
+		Data:      maps.Clone(n.Data),
+		CreatedAt: d.clock.Now(),
+	})
+	if err != nil {
+		return fail(err)
+	}
+	delivery.Status = DeliveryStatusSent
+	return delivery
+}
+
+func (d *Dispatcher) resolveRecipient(ctx context.Context, n Notification) Recipient {
+	recipient := n.Recipient
+	if d.users != nil && (recipient.Email == "" || recipient.Name == "") {
+		user, err := d.users.GetByID(ctx, n.UserID)
+		if err != nil {
+			d.logger.Debug("notification recipient lookup failed", "user_id", n.UserID, "error", err)
+		}
+		if user != nil {
+			if recipient.Email == "" {
+				recipient.Email = user.Email
+			}
+			if recipient.Name == "" {
+				recipient.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+			}
+		}
+	}
+	if d.profiles != nil && recipient.Phone == "" {
+		profile, err := types.ResolveProfile(ctx, d.profiles, n.UserID, n.Scope)
+		if err != nil {
+			d.logger.Debug("notification recipient profile lookup failed", "user_id", n.UserID, "error", err)
+		}
+		if profile != nil {
+			if phone, ok := profile.Contact[types.ContactProfileKeyPhone].(string); ok {
+				recipient.Phone = phone
+			}
+		}
+	}
+	return recipient
+}
+
+// selectChannels returns explicit channels, then the per-kind preference, then
+// the default preference, then the configured defaults.
+func (d *Dispatcher) selectChannels(ctx context.Context, n Notification) []Channel {
+	if len(n.Channels) > 0 {
+		return normalizeChannels(n.Channels)
+	}
+	if d.resolver == nil {
+		return d.defaultChannels
+	}
+	kindKey := PreferenceKey(n.Kind)
+	snapshot, err := d.resolver.Resolve(ctx, preferences.ResolveInput{
+		UserID:     n.UserID,
+		Scope:      n.Scope,
+		Keys:       []string{kindKey, PreferenceKeyDefault},
+		OutputMode: types.PreferenceOutputRawValue,
+	})
+	if err != nil {
+		d.logger.Error("notification preference resolution failed", err, "user_id", n.UserID, "kind", n.Kind)
+		return d.defaultChannels
+	}
+	for _, key := range []string{kindKey, PreferenceKeyDefault} {
+		raw, ok := snapshot.Effective[key]
+		if !ok || raw == nil {
+			continue
+		}
+		channels := channelsFromPreference(raw)
+		if len(channels) == 0 && d.required[n.Kind] {
+			return d.defaultChannels
+		}
+		return channels
+	}
+	return d.defaultChannels
+}
+
+func (d *Dispatcher) record(ctx context.Context, n Notification, delivery Delivery) {
+	verb := VerbSent
+	switch delivery.Status {
+	case DeliveryStatusFailed:
+		verb = VerbFailed
+	case DeliveryStatusSkipped:
+		verb = VerbSkipped
+	}
+	data := map[string]any{
+		"kind":   string(n.Kind),
+		"status": string(delivery.Status),
+	}
+	if delivery.Channel != "" {
+		data["channel"] = string(delivery.Channel)
+	}
+	if delivery.Err != nil {
+		data["error"] = delivery.Err.Error()
+	}
+	record := types.ActivityRecord{
+		UserID:     n.UserID,
+		ActorID:    n.ActorID,
+		Verb:       verb,
+		ObjectType: activityObjectType,
+		ObjectID:   string(n.Kind),
+		Channel:    activityChannel,
+		TenantID:   n.Scope.TenantID,
+		OrgID:      n.Scope.OrgID,
+		Data:       data,
+		OccurredAt: d.clock.Now(),
+	}
+	if delivery.MessageID != uuid.Nil {
+		record.ObjectID = delivery.MessageID.String()
+		data["message_id"] = delivery.MessageID.String()
+	}
+	if d.sink != nil {
+		if err := d.sink.Log(ctx, record); err != nil {
+			d.logger.Error("notification activity log failed", err, "user_id", n.UserID, "kind", n.Kind)
+		}
+	}
+	if d.hooks.AfterActivity != nil {
+		d.hooks.AfterActivity(ctx, record)
+	}
+}
+
+func channelsFromPreference(raw any) []Channel {
+	switch value := raw.(type) {
+	case string:
+		return normalizeChannels([]Channel{Channel(value)})
+	case []string:
+		out := make([]Channel, 0, len(value))
+		for _, item := range value {
+			out = append(out, Channel(item))
+		}
+		return normalizeChannels(out)
+	case []any:
+		out := make([]Channel, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				out = append(out, Channel(str))
+			}
+		}
+		return normalizeChannels(out)
+	case map[string]any:
+		if channels, ok := value["channels"]; ok {
+			return channelsFromPreference(channels)
+		}
+	}
+	return nil
+}
+
+func normalizeChannels(channels []Channel) []Channel {
+	out := make([]Channel, 0, len(channels))
+	for _, channel := range channels {
+		normalized := Channel(strings.ToLower(strings.TrimSpace(string(channel))))
+		if normalized == "" || slices.Contains(out, normalized) {
+			continue
+		}
+		out = append(out, normalized)
+	}
+	return out
+}
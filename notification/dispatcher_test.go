@@ -0,0 +1,295 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_UsesPreferredChannelsAndRecordsActivity(t *testing.T) {
+	userID := uuid.New()
+	prefs := newMemoryPreferenceRepo()
+	prefs.set(userID, types.PreferenceLevelUser, PreferenceKey(KindPasswordReset), []any{"sms", "email"})
+	resolver, err := preferences.NewResolver(preferences.ResolverConfig{Repository: prefs})
+	require.NoError(t, err)
+
+	email := NewMemoryTransport()
+	sms := NewMemoryTransport()
+	sink := &recordingSink{}
+	dispatcher := NewDispatcher(Config{
+		Resolver:   resolver,
+		Transports: map[Channel]Transport{ChannelEmail: email, ChannelSMS: sms},
+		Activity:   sink,
+		Clock:      fixedClock{at: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	})
+
+	deliveries, err := dispatcher.Dispatch(context.Background(), Notification{
+		Kind:      KindPasswordReset,
+		UserID:    userID,
+		Recipient: Recipient{Name: "Ada", Email: "ada@example.com", Phone: "+15550100"},
+		Data:      map[string]any{"token": "tok-123"},
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	require.Equal(t, ChannelSMS, deliveries[0].Channel)
+
+	require.Len(t, sms.Messages(), 1)
+	require.Equal(t, "+15550100", sms.Messages()[0].To)
+	msgs := email.Messages()
+	require.Len(t, msgs, 1)
+	require.Equal(t, "ada@example.com", msgs[0].To)
+	require.Equal(t, "Reset your password", msgs[0].Subject)
+	require.Contains(t, msgs[0].Body, "Hello Ada,")
+	require.Contains(t, msgs[0].Body, "tok-123")
+
+	require.Len(t, sink.records, 2)
+	for _, record := range sink.records {
+		require.Equal(t, VerbSent, record.Verb)
+		require.Equal(t, "password_reset", record.Data["kind"])
+		require.NotContains(t, record.Data, "token")
+	}
+}
+
+func TestDispatcher_FallsBackToDefaultPreferenceAndDefaults(t *testing.T) {
+	userID := uuid.New()
+	prefs := newMemoryPreferenceRepo()
+	resolver, err := preferences.NewResolver(preferences.ResolverConfig{Repository: prefs})
+	require.NoError(t, err)
+	inApp := NewMemoryTransport()
+	email := NewMemoryTransport()
+	dispatcher := NewDispatcher(Config{
+		Resolver:   resolver,
+		Transports: map[Channel]Transport{ChannelEmail: email, ChannelInApp: inApp},
+	})
+
+	n := Notification{Kind: KindRoleGranted, UserID: userID, Recipient: Recipient{Email: "a@example.com"}, Data: map[string]any{"role_name": "Editor"}}
+	require.NoError(t, dispatcher.Notify(context.Background(), n))
+	require.Len(t, email.Messages(), 1, "configured default channel")
+	require.Contains(t, email.Messages()[0].Body, "Editor role")
+
+	prefs.set(userID, types.PreferenceLevelUser, PreferenceKeyDefault, "in_app")
+	require.NoError(t, dispatcher.Notify(context.Background(), n))
+	require.Len(t, inApp.Messages(), 1)
+	require.Equal(t, userID.String(), inApp.Messages()[0].To)
+}
+
+func TestDispatcher_OptOutSkipsUnlessRequired(t *testing.T) {
+	userID := uuid.New()
+	prefs := newMemoryPreferenceRepo()
+	prefs.set(userID, types.PreferenceLevelUser, PreferenceKeyDefault, []any{})
+	resolver, err := preferences.NewResolver(preferences.ResolverConfig{Repository: prefs})
+	require.NoError(t, err)
+	email := NewMemoryTransport()
+	sink := &recordingSink{}
+	dispatcher := NewDispatcher(Config{
+		Resolver:   resolver,
+		Transports: map[Channel]Transport{ChannelEmail: email},
+		Activity:   sink,
+	})
+
+	deliveries, err := dispatcher.Dispatch(context.Background(), Notification{
+		Kind:      KindLifecycleChanged,
+		UserID:    userID,
+		Recipient: Recipient{Email: "a@example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DeliveryStatusSkipped, deliveries[0].Status)
+	require.Empty(t, email.Messages())
+	require.Equal(t, VerbSkipped, sink.records[0].Verb)
+
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{
+		Kind:      KindInvite,
+		UserID:    userID,
+		Recipient: Recipient{Email: "a@example.com"},
+	}))
+	require.Len(t, email.Messages(), 1)
+}
+
+func TestDispatcher_RecordsFailures(t *testing.T) {
+	sink := &recordingSink{}
+	boom := errors.New("smtp down")
+	dispatcher := NewDispatcher(Config{
+		Transports: map[Channel]Transport{
+			ChannelEmail: TransportFunc(func(context.Context, Message) error { return boom }),
+		},
+		Activity: sink,
+	})
+	userID := uuid.New()
+
+	err := dispatcher.Notify(context.Background(), Notification{Kind: KindInvite, UserID: userID, Recipient: Recipient{Email: "a@example.com"}})
+	require.ErrorIs(t, err, boom)
+	require.Equal(t, VerbFailed, sink.records[0].Verb)
+	require.Contains(t, sink.records[0].Data["error"], "smtp down")
+
+	err = dispatcher.Notify(context.Background(), Notification{Kind: KindInvite, UserID: userID, Channels: []Channel{ChannelSMS}})
+	require.ErrorIs(t, err, ErrTransportNotFound)
+
+	dispatcher.RegisterTransport(ChannelSMS, NewMemoryTransport())
+	err = dispatcher.Notify(context.Background(), Notification{Kind: KindInvite, UserID: userID, Channels: []Channel{ChannelSMS}})
+	require.ErrorIs(t, err, ErrRecipientAddressMissing)
+
+	err = dispatcher.Notify(context.Background(), Notification{Kind: "unknown", UserID: userID, Recipient: Recipient{Email: "a@example.com"}})
+	require.ErrorIs(t, err, ErrTemplateNotFound)
+
+	require.ErrorIs(t, dispatcher.Notify(context.Background(), Notification{UserID: userID}), ErrKindRequired)
+	require.ErrorIs(t, dispatcher.Notify(context.Background(), Notification{Kind: KindInvite}), ErrUserRequired)
+}
+
+func TestTemplateSet_ChannelSpecificOverride(t *testing.T) {
+	set := DefaultTemplates()
+	require.NoError(t, set.Register(KindInvite, ChannelSMS, Template{Body: "Invite code {{.Data.token}}"}))
+
+	_, body, err := set.Render(KindInvite, ChannelSMS, TemplateData{Data: map[string]any{"token": "abc"}})
+	require.NoError(t, err)
+	require.Equal(t, "Invite code abc", body)
+
+	subject, _, err := set.Render(KindInvite, ChannelEmail, TemplateData{})
+	require.NoError(t, err)
+	require.Equal(t, "You have been invited", subject)
+
+	require.Error(t, set.Register(KindInvite, "", Template{Body: "{{.Broken"}))
+}
+
+func TestFileTransport_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	transport, err := NewFileTransport(path)
+	require.NoError(t, err)
+	for i := range 2 {
+		require.NoError(t, transport.Send(context.Background(), Message{ID: uuid.New(), Kind: KindInvite, Body: strings.Repeat("x", i+1)}))
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	count := 0
+	for scanner.Scan() {
+		var msg Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		require.Equal(t, KindInvite, msg.Kind)
+		count++
+	}
+	require.Equal(t, 2, count)
+}
+
+func TestDispatcher_ResolvesPhoneFromProfileContact(t *testing.T) {
+	userID := uuid.New()
+	tenantID := uuid.New()
+	profiles := profileRepo{
+		{UserID: userID, Contact: map[string]any{types.ContactProfileKeyPhone: "+15550100"}},
+		{UserID: userID, Scope: types.ScopeFilter{TenantID: tenantID}, DisplayName: "Ada"},
+	}
+	sms := NewMemoryTransport()
+	dispatcher := NewDispatcher(Config{
+		Profiles:   profiles,
+		Transports: map[Channel]Transport{ChannelSMS: sms},
+	})
+
+	// Email and name are already known, so only the phone is looked up.
+	require.NoError(t, dispatcher.Notify(context.Background(), Notification{
+		Kind:      KindInvite,
+		UserID:    userID,
+		Scope:     types.ScopeFilter{TenantID: tenantID},
+		Recipient: Recipient{Name: "Ada", Email: "ada@example.com"},
+		Channels:  []Channel{ChannelSMS},
+	}))
+	require.Len(t, sms.Messages(), 1)
+	require.Equal(t, "+15550100", sms.Messages()[0].To)
+}
+
+type profileRepo []types.UserProfile
+
+func (r profileRepo) GetProfile(_ context.Context, userID uuid.UUID, scope types.ScopeFilter) (*types.UserProfile, error) {
+	for _, profile := range r {
+		if profile.UserID == userID && profile.Scope.TenantID == scope.TenantID && profile.Scope.OrgID == scope.OrgID {
+			return &profile, nil
+		}
+	}
+	return nil, nil
+}
+
+func (profileRepo) UpsertProfile(_ context.Context, profile types.UserProfile) (*types.UserProfile, error) {
+	return &profile, nil
+}
+
+type recordingSink struct {
+	records []types.ActivityRecord
+}
+
+func (r *recordingSink) Log(_ context.Context, record types.ActivityRecord) error {
+	r.records = append(r.records, record)
+	return nil
+}
+
+type fixedClock struct {
+	at time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.at
+}
+
+// memoryPreferenceRepo stores raw values wrapped in the {"value": ...} envelope.
+type memoryPreferenceRepo struct {
+	mu      sync.Mutex
+	records []types.PreferenceRecord
+}
+
+func newMemoryPreferenceRepo() *memoryPreferenceRepo {
+	return &memoryPreferenceRepo{}
+}
+
+func (m *memoryPreferenceRepo) set(userID uuid.UUID, level types.PreferenceLevel, key string, value any) {
+	_, _ = m.UpsertPreference(context.Background(), types.PreferenceRecord{
+		UserID: userID,
+		Level:  level,
+		Key:    key,
+		Value:  map[string]any{"value": value},
+	})
+}
+
+func (m *memoryPreferenceRepo) ListPreferences(_ context.Context, filter types.PreferenceFilter) ([]types.PreferenceRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []types.PreferenceRecord
+	for _, record := range m.records {
+		if record.Level != filter.Level {
+			continue
+		}
+		if filter.Level == types.PreferenceLevelUser && record.UserID != filter.UserID {
+			continue
+		}
+		out = append(out, record)
+	}
+	return out, nil
+}
+
+func (m *memoryPreferenceRepo) UpsertPreference(_ context.Context, record types.PreferenceRecord) (*types.PreferenceRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.records {
+		if existing.UserID == record.UserID && existing.Level == record.Level && existing.Key == record.Key {
+			m.records[i] = record
+			return &record, nil
+		}
+	}
+	record.ID = uuid.New()
+	m.records = append(m.records, record)
+	return &record, nil
+}
+
+func (m *memoryPreferenceRepo) DeletePreference(context.Context, uuid.UUID, types.ScopeFilter, types.PreferenceLevel, string) error {
+	return nil
+}
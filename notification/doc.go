@@ -0,0 +1,4 @@
+// Package notification delivers templated user notifications (invites,
+// password resets, lifecycle changes, role grants) over channels selected from
+// the recipient's preferences, recording delivery status as activity.
+package notification
@@ -0,0 +1,155 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Kind identifies the notification template family.
+type Kind string
+
+const (
+	KindInvite           Kind = "invite"
+	KindRegistration     Kind = "registration"
+	KindPasswordReset    Kind = "password_reset"
+	KindLifecycleChanged Kind = "lifecycle_changed"
+	KindRoleGranted      Kind = "role_granted"
+	// KindContactVerification carries the link or code sent to a new email
+	// address or phone number.
+	KindContactVerification Kind = "contact_verification"
+	// KindContactChanged tells the previous address that it was replaced.
+	KindContactChanged Kind = "contact_changed"
+)
+
+// Channel identifies a delivery transport (email, sms, in-app, ...).
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+	ChannelInApp Channel = "in_app"
+)
+
+// Activity verbs recorded for each delivery attempt.
+const (
+	VerbSent    = "notification.sent"
+	VerbFailed  = "notification.failed"
+	VerbSkipped = "notification.skipped"
+
+	activityObjectType = "notification"
+	activityChannel    = "notifications"
+)
+
+var (
+	// ErrKindRequired indicates a notification without a kind.
+	ErrKindRequired = errors.New("go-users: notification kind required")
+	// ErrUserRequired indicates a notification without a target user.
+	ErrUserRequired = errors.New("go-users: notification user required")
+	// ErrTemplateNotFound indicates no template is registered for the kind/channel.
+	ErrTemplateNotFound = errors.New("go-users: notification template not found")
+	// ErrTransportNotFound indicates no transport is registered for the channel.
+	ErrTransportNotFound = errors.New("go-users: notification transport not found")
+	// ErrRecipientAddressMissing indicates the recipient has no address for the channel.
+	ErrRecipientAddressMissing = errors.New("go-users: notification recipient address missing")
+)
+
+// Recipient describes who receives the notification.
+type Recipient struct {
+	Name  string
+	Email string
+	Phone string
+}
+
+// Address returns the recipient address used by channel. In-app delivery is
+// addressed by user id.
+func (r Recipient) Address(channel Channel, userID uuid.UUID) string {
+	switch channel {
+	case ChannelEmail:
+		return strings.TrimSpace(r.Email)
+	case ChannelSMS:
+		return strings.TrimSpace(r.Phone)
+	default:
+		if userID == uuid.Nil {
+			return ""
+		}
+		return userID.String()
+	}
+}
+
+// Notification is the dispatch request produced by commands.
+type Notification struct {
+	Kind      Kind
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	Scope     types.ScopeFilter
+	Recipient Recipient
+	// Data is exposed to templates as .Data.
+	Data map[string]any
+	// Channels overrides preference-based channel selection when set.
+	Channels []Channel
+}
+
+// Validate reports missing required fields.
+func (n Notification) Validate() error {
+	switch {
+	case strings.TrimSpace(string(n.Kind)) == "":
+		return ErrKindRequired
+	case n.UserID == uuid.Nil:
+		return ErrUserRequired
+	default:
+		return nil
+	}
+}
+
+// Message is the rendered payload handed to a transport.
+type Message struct {
+	ID        uuid.UUID      `json:"id"`
+	Kind      Kind           `json:"kind"`
+	Channel   Channel        `json:"channel"`
+	UserID    uuid.UUID      `json:"user_id"`
+	To        string         `json:"to"`
+	Subject   string         `json:"subject,omitempty"`
+	Body      string         `json:"body"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Transport delivers rendered messages for a single channel.
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// TransportFunc adapts a function into a Transport.
+type TransportFunc func(ctx context.Context, msg Message) error
+
+// Send implements Transport.
+func (fn TransportFunc) Send(ctx context.Context, msg Message) error {
+	return fn(ctx, msg)
+}
+
+// Notifier is the contract commands depend on to deliver notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// DeliveryStatus captures the outcome of a delivery attempt.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+	DeliveryStatusSkipped DeliveryStatus = "skipped"
+)
+
+// Delivery reports the result for one channel.
+type Delivery struct {
+	MessageID uuid.UUID
+	Channel   Channel
+	Status    DeliveryStatus
+	Err       error
+}
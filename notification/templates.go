@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Template holds the subject/body sources for a kind (and optionally channel).
+// Both are parsed with text/template and receive TemplateData.
+type Template struct {
+	Subject string
+	Body    string
+}
+
+// TemplateData is the value templates are executed against.
+type TemplateData struct {
+	Kind      Kind
+	Channel   Channel
+	Recipient Recipient
+	Data      map[string]any
+}
+
+type templateKey struct {
+	kind    Kind
+	channel Channel
+}
+
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// TemplateSet stores compiled templates keyed by kind and channel. Templates
+// registered without a channel apply to every channel of that kind.
+type TemplateSet struct {
+	mu        sync.RWMutex
+	templates map[templateKey]compiledTemplate
+}
+
+// NewTemplateSet returns an empty template set.
+func NewTemplateSet() *TemplateSet {
+	return &TemplateSet{templates: make(map[templateKey]compiledTemplate)}
+}
+
+// Register compiles and stores tpl for kind/channel. Pass an empty channel to
+// register the fallback template for the kind.
+func (s *TemplateSet) Register(kind Kind, channel Channel, tpl Template) error {
+	name := string(kind) + ":" + string(channel)
+	subject, err := template.New(name + ":subject").Option("missingkey=zero").Parse(tpl.Subject)
+	if err != nil {
+		return fmt.Errorf("notification: parse %s subject: %w", name, err)
+	}
+	body, err := template.New(name + ":body").Option("missingkey=zero").Parse(tpl.Body)
+	if err != nil {
+		return fmt.Errorf("notification: parse %s body: %w", name, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates[templateKey{kind: kind, channel: channel}] = compiledTemplate{subject: subject, body: body}
+	return nil
+}
+
+// MustRegister is Register that panics on parse errors; intended for init code.
+func (s *TemplateSet) MustRegister(kind Kind, channel Channel, tpl Template) *TemplateSet {
+	if err := s.Register(kind, channel, tpl); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Render executes the template for kind/channel, falling back to the
+// channel-agnostic template.
+func (s *TemplateSet) Render(kind Kind, channel Channel, data TemplateData) (string, string, error) {
+	if s == nil {
+		return "", "", ErrTemplateNotFound
+	}
+	s.mu.RLock()
+	tpl, ok := s.templates[templateKey{kind: kind, channel: channel}]
+	if !ok {
+		tpl, ok = s.templates[templateKey{kind: kind}]
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrTemplateNotFound, kind)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+const greeting = "Hello{{with .Recipient.Name}} {{.}}{{end}},\n\n"
+
+// DefaultTemplates returns plain-text templates for the built-in kinds.
+// Hosts typically replace them with branded copies via Register.
+func DefaultTemplates() *TemplateSet {
+	return NewTemplateSet().
+		MustRegister(KindInvite, "", Template{
+			Subject: "You have been invited",
+			Body:    greeting + "You have been invited to join. Accept the invitation with this token: {{.Data.token}}\n{{with .Data.expires_at}}The invitation expires at {{.}}.\n{{end}}",
+		}).
+		MustRegister(KindRegistration, "", Template{
+			Subject: "Complete your registration",
+			Body:    greeting + "Complete your registration with this token: {{.Data.token}}\n{{with .Data.expires_at}}The link expires at {{.}}.\n{{end}}",
+		}).
+		MustRegister(KindPasswordReset, "", Template{
+			Subject: "Reset your password",
+			Body:    greeting + "A password reset was requested for your account. Use this token to choose a new password: {{.Data.token}}\n{{with .Data.expires_at}}The token expires at {{.}}.\n{{end}}If you did not request a reset you can ignore this message.\n",
+		}).
+		MustRegister(KindLifecycleChanged, "", Template{
+			Subject: "Your account status changed",
+			Body:    greeting + "Your account status changed from {{.Data.from_state}} to {{.Data.to_state}}.\n{{with .Data.reason}}Reason: {{.}}\n{{end}}",
+		}).
+		MustRegister(KindRoleGranted, "", Template{
+			Subject: "You have a new role",
+			Body:    greeting + "You have been granted the {{or .Data.role_name .Data.role_id}} role.\n",
+		}).
+		MustRegister(KindContactVerification, "", Template{
+			Subject: "Confirm your new {{.Data.channel}}",
+			Body:    greeting + "Confirm this {{.Data.channel}} for your account{{with .Data.code}} with this code: {{.}}{{end}}{{with .Data.token}} with this token: {{.}}{{end}}\n{{with .Data.expires_at}}It expires at {{.}}.\n{{end}}If you did not request this change you can ignore this message.\n",
+		}).
+		MustRegister(KindContactChanged, "", Template{
+			Subject: "Your {{.Data.channel}} was changed",
+			Body:    greeting + "The {{.Data.channel}} on your account was changed to {{.Data.new_value}}. If you did not make this change, contact support immediately.\n",
+		})
+}
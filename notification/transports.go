@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// MemoryTransport keeps delivered messages in memory. Useful for tests.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryTransport returns an empty in-memory transport.
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+// Send implements Transport.
+func (t *MemoryTransport) Send(_ context.Context, msg Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of the delivered messages.
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Message(nil), t.messages...)
+}
+
+// Reset discards delivered messages.
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
+
+// FileTransport appends each message as a JSON line to a local file. It is
+// meant for local development; message bodies include secure-link tokens.
+type FileTransport struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileTransport writes messages to path, creating it when missing.
+func NewFileTransport(path string) (*FileTransport, error) {
+	if path == "" {
+		return nil, errors.New("notification: file transport path required")
+	}
+	return &FileTransport{path: path}, nil
+}
+
+// Send implements Transport.
+func (t *FileTransport) Send(_ context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	file, err := os.OpenFile(t.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(payload, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,4 @@
+// Package password checks plaintext passwords against a configurable policy
+// and hashes them with bcrypt or argon2id. Service implements
+// types.PasswordService for the reset, confirm, and bootstrap commands.
+package password
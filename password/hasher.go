@@ -0,0 +1,173 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrHashFormat indicates a stored hash cannot be parsed by the hasher.
+var ErrHashFormat = errors.New("password: unrecognized hash format")
+
+// BcryptMaxBytes is the longest password, in bytes, bcrypt accepts.
+const BcryptMaxBytes = 72
+
+// byteLimitedHasher is implemented by hashers that only accept passwords up
+// to a number of bytes. Service reports longer passwords as
+// types.PasswordTooLong.
+type byteLimitedHasher interface {
+	MaxPasswordBytes() int
+}
+
+// BcryptHasher hashes passwords with bcrypt. A zero Cost uses
+// bcrypt.DefaultCost. bcrypt rejects passwords longer than BcryptMaxBytes.
+type BcryptHasher struct {
+	Cost int
+}
+
+var (
+	_ types.PasswordHasher = BcryptHasher{}
+	_ byteLimitedHasher    = BcryptHasher{}
+)
+
+// MaxPasswordBytes returns BcryptMaxBytes.
+func (BcryptHasher) MaxPasswordBytes() int {
+	return BcryptMaxBytes
+}
+
+// Hash implements types.PasswordHasher.
+func (h BcryptHasher) Hash(plaintext string) (string, error) {
+	cost := h.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", &types.PasswordPolicyError{Violations: []types.PasswordPolicyViolation{types.PasswordTooLong}}
+	}
+	if err != nil {
+		return "", fmt.Errorf("password: bcrypt: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify implements types.PasswordHasher.
+func (h BcryptHasher) Verify(hash, plaintext string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plaintext))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %v", ErrHashFormat, err)
+	}
+}
+
+// Argon2idHasher hashes passwords with argon2id and encodes them in the PHC
+// string format ($argon2id$v=19$m=...,t=...,p=...$salt$key). Zero fields use
+// the RFC 9106 second recommended option: 64 MiB, 3 passes, 4 lanes, a 16
+// byte salt, and a 32 byte key.
+type Argon2idHasher struct {
+	// Memory is measured in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+var _ types.PasswordHasher = Argon2idHasher{}
+
+// Hash implements types.PasswordHasher.
+func (h Argon2idHasher) Hash(plaintext string) (string, error) {
+	h = h.withDefaults()
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(plaintext), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify implements types.PasswordHasher. The parameters encoded in hash are
+// used, so hashes written with other settings still verify.
+func (h Argon2idHasher) Verify(hash, plaintext string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrHashFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrHashFormat
+	}
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, ErrHashFormat
+	}
+	if !validArgon2Params(memory, iterations, parallelism) {
+		return false, ErrHashFormat
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, ErrHashFormat
+	}
+	candidate := argon2.IDKey([]byte(plaintext), salt, iterations, memory, parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+// Bounds applied to parameters read from stored argon2id hashes. argon2
+// panics on zero passes or lanes, and oversized values would let a crafted
+// hash exhaust memory or CPU.
+const (
+	argon2MaxMemory     = 4 * 1024 * 1024 // 4 GiB in KiB
+	argon2MaxIterations = 64
+)
+
+func validArgon2Params(memory, iterations uint32, parallelism uint8) bool {
+	switch {
+	case iterations == 0 || iterations > argon2MaxIterations:
+		return false
+	case parallelism == 0:
+		return false
+	case memory == 0 || memory > argon2MaxMemory:
+		return false
+	default:
+		return true
+	}
+}
+
+func (h Argon2idHasher) withDefaults() Argon2idHasher {
+	if h.Memory == 0 {
+		h.Memory = 64 * 1024
+	}
+	if h.Iterations == 0 {
+		h.Iterations = 3
+	}
+	if h.Parallelism == 0 {
+		h.Parallelism = 4
+	}
+	if h.SaltLength == 0 {
+		h.SaltLength = 16
+	}
+	if h.KeyLength == 0 {
+		h.KeyLength = 32
+	}
+	return h
+}
@@ -0,0 +1,93 @@
+package password
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashers_RoundTrip(t *testing.T) {
+	hashers := map[string]types.PasswordHasher{
+		"bcrypt":   BcryptHasher{Cost: bcrypt.MinCost},
+		"argon2id": Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1},
+	}
+	for name, hasher := range hashers {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Hash("Correct-Horse-42")
+			require.NoError(t, err)
+			require.NotContains(t, hash, "Correct-Horse-42")
+
+			ok, err := hasher.Verify(hash, "Correct-Horse-42")
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = hasher.Verify(hash, "wrong")
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			_, err = hasher.Verify("not-a-hash", "Correct-Horse-42")
+			require.ErrorIs(t, err, ErrHashFormat)
+		})
+	}
+}
+
+func TestArgon2idHasher_EncodesParameters(t *testing.T) {
+	hash, err := Argon2idHasher{Memory: 1024, Iterations: 2, Parallelism: 1}.Hash("secret")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=2,p=1$"))
+
+	// Verification reads the parameters from the hash, not the hasher.
+	ok, err := Argon2idHasher{}.Verify(hash, "secret")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestArgon2idHasher_RejectsInvalidParameters(t *testing.T) {
+	hash, err := Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}.Hash("secret")
+	require.NoError(t, err)
+	tail := hash[strings.LastIndex(hash[:strings.LastIndex(hash, "$")], "$"):]
+
+	for _, params := range []string{"m=1024,t=0,p=1", "m=1024,t=1,p=0", "m=0,t=1,p=1", "m=1024,t=1000000,p=1", "m=4294967295,t=1,p=1"} {
+		ok, err := Argon2idHasher{}.Verify("$argon2id$v=19$"+params+tail, "secret")
+		require.ErrorIs(t, err, ErrHashFormat, params)
+		require.False(t, ok)
+	}
+}
+
+func TestService_RejectsPasswordsBeyondBcryptLimit(t *testing.T) {
+	svc := NewService(ServiceConfig{
+		Policy: DefaultPolicy(),
+		Hasher: BcryptHasher{Cost: bcrypt.MinCost},
+	})
+	long := "Aa1" + strings.Repeat("é", 40)
+	require.LessOrEqual(t, len([]rune(long)), DefaultMaxLength)
+
+	_, err := svc.HashPassword(context.Background(), long, nil)
+	var policyErr *types.PasswordPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	require.Equal(t, []types.PasswordPolicyViolation{types.PasswordTooLong}, policyErr.Violations)
+
+	_, err = BcryptHasher{Cost: bcrypt.MinCost}.Hash(long)
+	require.ErrorIs(t, err, types.ErrPasswordPolicy)
+}
+
+func TestService_HashPasswordChecksPolicy(t *testing.T) {
+	svc := NewService(ServiceConfig{
+		Policy: DefaultPolicy(),
+		Hasher: BcryptHasher{Cost: bcrypt.MinCost},
+	})
+	user := &types.AuthUser{Email: "jane@example.com"}
+
+	_, err := svc.HashPassword(context.Background(), "jane-Password-1", user)
+	require.ErrorIs(t, err, types.ErrPasswordPolicy)
+
+	hash, err := svc.HashPassword(context.Background(), "Correct-Horse-42", user)
+	require.NoError(t, err)
+	ok, err := svc.Verify(hash, "Correct-Horse-42")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
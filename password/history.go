@@ -0,0 +1,137 @@
+package password
+
+import (
+	"context"
+	"errors"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/google/uuid"
+)
+
+// DefaultHistoryRetention is the number of previous passwords checked when
+// HistoryConfig.Retention is zero and no preference overrides it.
+const DefaultHistoryRetention = 5
+
+// PreferenceResolver resolves scoped settings such as
+// types.PreferenceKeyPasswordHistory.
+type PreferenceResolver interface {
+	Resolve(ctx context.Context, input preferences.ResolveInput) (types.PreferenceSnapshot, error)
+}
+
+// HistoryConfig wires the password history checker.
+type HistoryConfig struct {
+	Repository types.PasswordHistoryRepository
+	// Hasher verifies plaintext against stored hashes and must match the
+	// hasher used to write them. Defaults to BcryptHasher.
+	Hasher types.PasswordHasher
+	// Retention is the number of previous passwords that cannot be reused.
+	// Defaults to DefaultHistoryRetention; negative disables the check.
+	Retention int
+	// Preferences, when set, lets system, tenant, and org preferences
+	// override Retention via types.PreferenceKeyPasswordHistory.
+	Preferences PreferenceResolver
+	Clock       types.Clock
+	Logger      types.Logger
+}
+
+// History implements types.PasswordHistory.
+type History struct {
+	repo      types.PasswordHistoryRepository
+	hasher    types.PasswordHasher
+	retention int
+	prefs     PreferenceResolver
+	clock     types.Clock
+	logger    types.Logger
+}
+
+// NewHistory constructs the password history checker.
+func NewHistory(cfg HistoryConfig) *History {
+	hasher := cfg.Hasher
+	if hasher == nil {
+		hasher = BcryptHasher{}
+	}
+	retention := cfg.Retention
+	if retention == 0 {
+		retention = DefaultHistoryRetention
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = types.NopLogger{}
+	}
+	return &History{
+		repo:      cfg.Repository,
+		hasher:    hasher,
+		retention: retention,
+		prefs:     cfg.Preferences,
+		clock:     clock,
+		logger:    logger,
+	}
+}
+
+var _ types.PasswordHistory = (*History)(nil)
+
+// Retention returns the number of previous passwords checked in scope.
+func (h *History) Retention(ctx context.Context, scope types.ScopeFilter) int {
+	retention := h.retention
+	if value, ok := resolveIntSetting(ctx, h.prefs, h.logger, scope, types.PreferenceKeyPasswordHistory); ok {
+		retention = value
+	}
+	return max(retention, 0)
+}
+
+// CheckPasswordReuse implements types.PasswordHistory.
+func (h *History) CheckPasswordReuse(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, plaintext string) error {
+	if h.repo == nil {
+		return types.ErrMissingPasswordHistoryRepository
+	}
+	retention := h.Retention(ctx, scope)
+	if retention == 0 {
+		return nil
+	}
+	entries, err := h.repo.ListPasswordHistory(ctx, userID, retention)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		match, err := h.hasher.Verify(entry.PasswordHash, plaintext)
+		if err != nil {
+			// Hashes written by another algorithm cannot be compared.
+			if errors.Is(err, ErrHashFormat) {
+				continue
+			}
+			return err
+		}
+		if match {
+			return types.ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// RecordPasswordHistory implements types.PasswordHistory. Entries beyond the
+// scope's retention are pruned; at least one entry is kept so a later
+// retention increase has history to check.
+func (h *History) RecordPasswordHistory(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, passwordHash string) error {
+	if h.repo == nil {
+		return types.ErrMissingPasswordHistoryRepository
+	}
+	return h.repo.AppendPasswordHistory(ctx, types.PasswordHistoryEntry{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		Scope:        scope,
+		CreatedAt:    h.clock.Now(),
+	}, max(h.Retention(ctx, scope), 1))
+}
+
+// PurgePasswordHistory implements types.PasswordHistory.
+func (h *History) PurgePasswordHistory(ctx context.Context, userID uuid.UUID) error {
+	if h.repo == nil {
+		return types.ErrMissingPasswordHistoryRepository
+	}
+	return h.repo.DeletePasswordHistory(ctx, userID)
+}
@@ -0,0 +1,112 @@
+package password
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHistory_RejectsRecentPasswords(t *testing.T) {
+	ctx := context.Background()
+	hasher := BcryptHasher{Cost: bcrypt.MinCost}
+	repo := newMemoryHistoryRepo()
+	history := NewHistory(HistoryConfig{Repository: repo, Hasher: hasher, Retention: 2})
+	userID := uuid.New()
+
+	for _, plaintext := range []string{"First-Password-1", "Second-Password-2", "Third-Password-3"} {
+		hash, err := hasher.Hash(plaintext)
+		require.NoError(t, err)
+		require.NoError(t, history.RecordPasswordHistory(ctx, userID, types.ScopeFilter{}, hash))
+	}
+	require.Len(t, repo.entries[userID], 2)
+
+	require.ErrorIs(t, history.CheckPasswordReuse(ctx, userID, types.ScopeFilter{}, "Third-Password-3"), types.ErrPasswordReused)
+	require.ErrorIs(t, history.CheckPasswordReuse(ctx, userID, types.ScopeFilter{}, "Second-Password-2"), types.ErrPasswordReused)
+	require.NoError(t, history.CheckPasswordReuse(ctx, userID, types.ScopeFilter{}, "First-Password-1"))
+	require.NoError(t, history.CheckPasswordReuse(ctx, uuid.New(), types.ScopeFilter{}, "Third-Password-3"))
+
+	require.NoError(t, history.PurgePasswordHistory(ctx, userID))
+	require.NoError(t, history.CheckPasswordReuse(ctx, userID, types.ScopeFilter{}, "Third-Password-3"))
+}
+
+func TestHistory_TenantRetentionPreference(t *testing.T) {
+	ctx := context.Background()
+	hasher := BcryptHasher{Cost: bcrypt.MinCost}
+	tenantID := uuid.New()
+	resolver := &stubPreferenceResolver{values: map[uuid.UUID]any{tenantID: float64(0)}}
+	history := NewHistory(HistoryConfig{
+		Repository:  newMemoryHistoryRepo(),
+		Hasher:      hasher,
+		Preferences: resolver,
+	})
+	userID := uuid.New()
+	hash, err := hasher.Hash("Reused-Password-1")
+	require.NoError(t, err)
+	require.NoError(t, history.RecordPasswordHistory(ctx, userID, types.ScopeFilter{}, hash))
+
+	require.Equal(t, DefaultHistoryRetention, history.Retention(ctx, types.ScopeFilter{}))
+	require.ErrorIs(t, history.CheckPasswordReuse(ctx, userID, types.ScopeFilter{}, "Reused-Password-1"), types.ErrPasswordReused)
+
+	tenantScope := types.ScopeFilter{TenantID: tenantID}
+	require.Equal(t, 0, history.Retention(ctx, tenantScope))
+	require.NoError(t, history.CheckPasswordReuse(ctx, userID, tenantScope, "Reused-Password-1"))
+	require.Equal(t, []types.PreferenceLevel{
+		types.PreferenceLevelSystem,
+		types.PreferenceLevelTenant,
+		types.PreferenceLevelOrg,
+	}, resolver.lastInput.Levels)
+}
+
+type memoryHistoryRepo struct {
+	entries map[uuid.UUID][]types.PasswordHistoryEntry
+}
+
+func newMemoryHistoryRepo() *memoryHistoryRepo {
+	return &memoryHistoryRepo{entries: make(map[uuid.UUID][]types.PasswordHistoryEntry)}
+}
+
+func (r *memoryHistoryRepo) AppendPasswordHistory(_ context.Context, entry types.PasswordHistoryEntry, keep int) error {
+	entries := append([]types.PasswordHistoryEntry{entry}, r.entries[entry.UserID]...)
+	if keep > 0 && len(entries) > keep {
+		entries = entries[:keep]
+	}
+	r.entries[entry.UserID] = entries
+	return nil
+}
+
+func (r *memoryHistoryRepo) ListPasswordHistory(_ context.Context, userID uuid.UUID, limit int) ([]types.PasswordHistoryEntry, error) {
+	entries := slices.Clone(r.entries[userID])
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (r *memoryHistoryRepo) DeletePasswordHistory(_ context.Context, userID uuid.UUID) error {
+	delete(r.entries, userID)
+	return nil
+}
+
+// stubPreferenceResolver returns the value configured for the scope's tenant
+// under every requested key.
+type stubPreferenceResolver struct {
+	values    map[uuid.UUID]any
+	lastInput preferences.ResolveInput
+}
+
+func (s *stubPreferenceResolver) Resolve(_ context.Context, input preferences.ResolveInput) (types.PreferenceSnapshot, error) {
+	s.lastInput = input
+	effective := map[string]any{}
+	if value, ok := s.values[input.Scope.TenantID]; ok {
+		for _, key := range input.Keys {
+			effective[key] = value
+		}
+	}
+	return types.PreferenceSnapshot{Effective: effective}, nil
+}
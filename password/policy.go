@@ -0,0 +1,143 @@
+package password
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// DefaultMinLength is the minimum length enforced when Policy.MinLength is
+// zero.
+const DefaultMinLength = 12
+
+// DefaultMaxLength caps password length when Policy.MaxLength is zero.
+// Service also rejects passwords longer than its hasher accepts, such as
+// BcryptMaxBytes for bcrypt.
+const DefaultMaxLength = 128
+
+// minIdentityLength keeps short usernames and email local parts from
+// rejecting unrelated passwords.
+const minIdentityLength = 3
+
+// Policy describes the passwords accepted by Service. Lengths count runes.
+// Denylist entries match the whole password, ignoring case.
+type Policy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Denylist      []string
+	// RejectIdentity rejects passwords containing the user's email, email
+	// local part, or username.
+	RejectIdentity bool
+}
+
+// DefaultPolicy returns a policy requiring DefaultMinLength characters with
+// upper case, lower case, and digit characters, and rejecting passwords that
+// contain the user's identity.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:      DefaultMinLength,
+		MaxLength:      DefaultMaxLength,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireDigit:   true,
+		RejectIdentity: true,
+	}
+}
+
+// Check validates plaintext for user, which may be nil. It returns a
+// *types.PasswordPolicyError listing every failed rule.
+func (p Policy) Check(plaintext string, user *types.AuthUser) error {
+	var violations []types.PasswordPolicyViolation
+	length := utf8.RuneCountInString(plaintext)
+	if length < p.minLength() {
+		violations = append(violations, types.PasswordTooShort)
+	}
+	if length > p.maxLength() {
+		violations = append(violations, types.PasswordTooLong)
+	}
+	violations = append(violations, p.checkClasses(plaintext)...)
+	if p.denylisted(plaintext) {
+		violations = append(violations, types.PasswordDenylisted)
+	}
+	if p.RejectIdentity && containsIdentity(plaintext, user) {
+		violations = append(violations, types.PasswordContainsIdentity)
+	}
+	if len(violations) > 0 {
+		return &types.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p Policy) checkClasses(plaintext string) []types.PasswordPolicyViolation {
+	var upper, lower, digit, symbol bool
+	for _, r := range plaintext {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	var violations []types.PasswordPolicyViolation
+	if p.RequireUpper && !upper {
+		violations = append(violations, types.PasswordMissingUpper)
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, types.PasswordMissingLower)
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, types.PasswordMissingDigit)
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, types.PasswordMissingSymbol)
+	}
+	return violations
+}
+
+func (p Policy) denylisted(plaintext string) bool {
+	for _, entry := range p.Denylist {
+		if strings.EqualFold(strings.TrimSpace(entry), plaintext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) minLength() int {
+	if p.MinLength <= 0 {
+		return DefaultMinLength
+	}
+	return p.MinLength
+}
+
+func (p Policy) maxLength() int {
+	if p.MaxLength <= 0 {
+		return DefaultMaxLength
+	}
+	return p.MaxLength
+}
+
+func containsIdentity(plaintext string, user *types.AuthUser) bool {
+	if user == nil {
+		return false
+	}
+	lowered := strings.ToLower(plaintext)
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+	local, _, _ := strings.Cut(email, "@")
+	for _, identity := range []string{email, local, strings.ToLower(strings.TrimSpace(user.Username))} {
+		if utf8.RuneCountInString(identity) >= minIdentityLength && strings.Contains(lowered, identity) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	user := &types.AuthUser{Email: "jane.doe@example.com", Username: "jdoe"}
+	cases := []struct {
+		name       string
+		policy     Policy
+		password   string
+		violations []types.PasswordPolicyViolation
+	}{
+		{
+			name:     "accepts strong password",
+			policy:   DefaultPolicy(),
+			password: "Correct-Horse-42",
+		},
+		{
+			name:       "defaults minimum length",
+			policy:     Policy{},
+			password:   "short",
+			violations: []types.PasswordPolicyViolation{types.PasswordTooShort},
+		},
+		{
+			name:       "enforces maximum length",
+			policy:     Policy{MinLength: 1, MaxLength: 4},
+			password:   "toolong",
+			violations: []types.PasswordPolicyViolation{types.PasswordTooLong},
+		},
+		{
+			name:     "requires character classes",
+			policy:   Policy{MinLength: 1, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true},
+			password: "lowercase",
+			violations: []types.PasswordPolicyViolation{
+				types.PasswordMissingUpper,
+				types.PasswordMissingDigit,
+				types.PasswordMissingSymbol,
+			},
+		},
+		{
+			name:       "rejects denylisted passwords ignoring case",
+			policy:     Policy{MinLength: 1, Denylist: []string{"Password123"}},
+			password:   "PASSWORD123",
+			violations: []types.PasswordPolicyViolation{types.PasswordDenylisted},
+		},
+		{
+			name:       "rejects email local part",
+			policy:     Policy{MinLength: 1, RejectIdentity: true},
+			password:   "xxJane.Doexx",
+			violations: []types.PasswordPolicyViolation{types.PasswordContainsIdentity},
+		},
+		{
+			name:       "rejects username",
+			policy:     Policy{MinLength: 1, RejectIdentity: true},
+			password:   "my-JDOE-secret",
+			violations: []types.PasswordPolicyViolation{types.PasswordContainsIdentity},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Check(tc.password, user)
+			if tc.violations == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, types.ErrPasswordPolicy)
+			var policyErr *types.PasswordPolicyError
+			require.True(t, errors.As(err, &policyErr))
+			require.Equal(t, tc.violations, policyErr.Violations)
+		})
+	}
+}
+
+func TestPolicyCheck_IgnoresShortIdentities(t *testing.T) {
+	err := Policy{MinLength: 1, RejectIdentity: true}.Check("Bob-is-fine-1", &types.AuthUser{Username: "bo"})
+	require.NoError(t, err)
+	require.NoError(t, Policy{MinLength: 1, RejectIdentity: true}.Check("anything", nil))
+}
@@ -0,0 +1,55 @@
+package password
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+)
+
+// settingLevels are the preference levels allowed to set password rules;
+// users and roles cannot relax them.
+var settingLevels = []types.PreferenceLevel{
+	types.PreferenceLevelSystem,
+	types.PreferenceLevelTenant,
+	types.PreferenceLevelOrg,
+}
+
+// resolveIntSetting reads an integer setting for scope. It reports false when
+// no resolver is configured, resolution fails, or the value is not a number.
+func resolveIntSetting(ctx context.Context, resolver PreferenceResolver, logger types.Logger, scope types.ScopeFilter, key string) (int, bool) {
+	if resolver == nil {
+		return 0, false
+	}
+	snapshot, err := resolver.Resolve(ctx, preferences.ResolveInput{
+		Scope:      scope,
+		Levels:     settingLevels,
+		Keys:       []string{key},
+		OutputMode: types.PreferenceOutputRawValue,
+	})
+	if err != nil {
+		logger.Error("password setting resolution failed", err, "key", key)
+		return 0, false
+	}
+	switch value := snapshot.Effective[key].(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, false
+		}
+		return int(value), true
+	case json.Number:
+		parsed, err := value.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return int(parsed), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,88 @@
+package password
+
+import (
+	"context"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// RotationConfig wires the password age checker.
+type RotationConfig struct {
+	// MaxAge is how long a password stays valid. Zero disables expiry unless
+	// a preference sets it.
+	MaxAge time.Duration
+	// Preferences, when set, lets system, tenant, and org preferences
+	// override MaxAge in days via types.PreferenceKeyPasswordMaxAge.
+	Preferences PreferenceResolver
+	Clock       types.Clock
+	Logger      types.Logger
+}
+
+// Rotation implements types.PasswordRotationPolicy.
+type Rotation struct {
+	maxAge time.Duration
+	prefs  PreferenceResolver
+	clock  types.Clock
+	logger types.Logger
+}
+
+// NewRotation constructs the password age checker.
+func NewRotation(cfg RotationConfig) *Rotation {
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = types.NopLogger{}
+	}
+	return &Rotation{
+		maxAge: cfg.MaxAge,
+		prefs:  cfg.Preferences,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+var _ types.PasswordRotationPolicy = (*Rotation)(nil)
+
+// MaxAge returns the maximum password age in scope; zero means passwords do
+// not expire.
+func (r *Rotation) MaxAge(ctx context.Context, scope types.ScopeFilter) time.Duration {
+	maxAge := r.maxAge
+	if days, ok := resolveIntSetting(ctx, r.prefs, r.logger, scope, types.PreferenceKeyPasswordMaxAge); ok {
+		maxAge = time.Duration(days) * 24 * time.Hour
+	}
+	return max(maxAge, 0)
+}
+
+// RotationStatus implements types.PasswordRotationPolicy. Users without a
+// recorded change time have no expiry: their creation time says nothing
+// about when the current password was set, and measuring from it would
+// expire every account that predates rotation the moment MaxAge is enabled.
+// The expiry sweep records a baseline change time for such users.
+func (r *Rotation) RotationStatus(ctx context.Context, user *types.AuthUser, scope types.ScopeFilter) types.PasswordRotationStatus {
+	if user == nil {
+		return types.PasswordRotationStatus{}
+	}
+	status := types.PasswordRotationStatus{
+		UserID: user.ID,
+		MaxAge: r.MaxAge(ctx, scope),
+	}
+	if changedAt, ok := types.PasswordChangedAt(user.Metadata); ok {
+		status.ChangedAt = changedAt
+	}
+	if status.MaxAge > 0 && !status.ChangedAt.IsZero() {
+		status.ExpiresAt = status.ChangedAt.Add(status.MaxAge)
+	}
+	switch {
+	case types.PasswordChangeRequired(user.Metadata):
+		status.Required = true
+		status.Reason = types.PasswordRotationChangeRequired
+	case !status.ExpiresAt.IsZero() && !r.clock.Now().Before(status.ExpiresAt):
+		status.Required = true
+		status.Reason = types.PasswordRotationExpired
+	}
+	return status
+}
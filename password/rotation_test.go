@@ -0,0 +1,85 @@
+package password
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotation_ExpiresPasswordsOlderThanMaxAge(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	rotation := NewRotation(RotationConfig{MaxAge: 30 * 24 * time.Hour, Clock: fixedRotationClock{at: now}})
+
+	fresh := &types.AuthUser{
+		ID:       uuid.New(),
+		Metadata: types.MarkPasswordChangedMetadata(nil, now.Add(-10*24*time.Hour)),
+	}
+	status := rotation.RotationStatus(ctx, fresh, types.ScopeFilter{})
+	require.False(t, status.Required)
+	require.Equal(t, now.Add(20*24*time.Hour), status.ExpiresAt)
+
+	changed := now.Add(-31 * 24 * time.Hour)
+	stale := &types.AuthUser{ID: uuid.New(), Metadata: types.MarkPasswordChangedMetadata(nil, changed)}
+	status = rotation.RotationStatus(ctx, stale, types.ScopeFilter{})
+	require.True(t, status.Required)
+	require.Equal(t, types.PasswordRotationExpired, status.Reason)
+	require.Equal(t, changed, status.ChangedAt)
+
+	created := now.Add(-365 * 24 * time.Hour)
+	legacy := &types.AuthUser{ID: uuid.New(), CreatedAt: &created}
+	status = rotation.RotationStatus(ctx, legacy, types.ScopeFilter{})
+	require.False(t, status.Required)
+	require.True(t, status.ChangedAt.IsZero())
+	require.True(t, status.ExpiresAt.IsZero())
+
+	flagged := &types.AuthUser{
+		ID:       uuid.New(),
+		Metadata: types.MarkPasswordChangeRequiredMetadata(types.MarkPasswordChangedMetadata(nil, now)),
+	}
+	status = rotation.RotationStatus(ctx, flagged, types.ScopeFilter{})
+	require.True(t, status.Required)
+	require.Equal(t, types.PasswordRotationChangeRequired, status.Reason)
+}
+
+func TestRotation_TenantMaxAgePreference(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	tenantID := uuid.New()
+	disabledTenantID := uuid.New()
+	rotation := NewRotation(RotationConfig{
+		MaxAge: 90 * 24 * time.Hour,
+		Preferences: &stubPreferenceResolver{values: map[uuid.UUID]any{
+			tenantID:         float64(7),
+			disabledTenantID: float64(0),
+		}},
+		Clock: fixedRotationClock{at: now},
+	})
+	user := &types.AuthUser{
+		ID:       uuid.New(),
+		Metadata: types.MarkPasswordChangedMetadata(nil, now.Add(-10*24*time.Hour)),
+	}
+
+	require.Equal(t, 90*24*time.Hour, rotation.MaxAge(ctx, types.ScopeFilter{}))
+	require.False(t, rotation.RotationStatus(ctx, user, types.ScopeFilter{}).Required)
+
+	status := rotation.RotationStatus(ctx, user, types.ScopeFilter{TenantID: tenantID})
+	require.True(t, status.Required)
+	require.Equal(t, 7*24*time.Hour, status.MaxAge)
+
+	status = rotation.RotationStatus(ctx, user, types.ScopeFilter{TenantID: disabledTenantID})
+	require.False(t, status.Required)
+	require.True(t, status.ExpiresAt.IsZero())
+}
+
+type fixedRotationClock struct {
+	at time.Time
+}
+
+func (c fixedRotationClock) Now() time.Time {
+	return c.at
+}
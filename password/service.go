@@ -0,0 +1,74 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// ServiceConfig wires the password service.
+type ServiceConfig struct {
+	Policy Policy
+	// Hasher defaults to BcryptHasher with bcrypt.DefaultCost.
+	Hasher types.PasswordHasher
+}
+
+// Service checks passwords against Policy and hashes accepted passwords.
+type Service struct {
+	policy Policy
+	hasher types.PasswordHasher
+}
+
+// NewService constructs the password service.
+func NewService(cfg ServiceConfig) *Service {
+	hasher := cfg.Hasher
+	if hasher == nil {
+		hasher = BcryptHasher{}
+	}
+	return &Service{policy: cfg.Policy, hasher: hasher}
+}
+
+var (
+	_ types.PasswordService        = (*Service)(nil)
+	_ types.PasswordHasherProvider = (*Service)(nil)
+)
+
+// PasswordHasher implements types.PasswordHasherProvider.
+func (s *Service) PasswordHasher() types.PasswordHasher {
+	return s.hasher
+}
+
+// CheckPassword implements types.PasswordService. Passwords longer than the
+// hasher accepts (72 bytes for bcrypt) fail with types.PasswordTooLong even
+// when they are within Policy.MaxLength.
+func (s *Service) CheckPassword(_ context.Context, plaintext string, user *types.AuthUser) error {
+	err := s.policy.Check(plaintext, user)
+	limited, ok := s.hasher.(byteLimitedHasher)
+	if !ok || len(plaintext) <= limited.MaxPasswordBytes() {
+		return err
+	}
+	var policyErr *types.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		if !slices.Contains(policyErr.Violations, types.PasswordTooLong) {
+			policyErr.Violations = append(policyErr.Violations, types.PasswordTooLong)
+		}
+		return policyErr
+	}
+	return &types.PasswordPolicyError{Violations: []types.PasswordPolicyViolation{types.PasswordTooLong}}
+}
+
+// HashPassword implements types.PasswordService. The password is checked
+// before it is hashed.
+func (s *Service) HashPassword(ctx context.Context, plaintext string, user *types.AuthUser) (string, error) {
+	if err := s.CheckPassword(ctx, plaintext, user); err != nil {
+		return "", err
+	}
+	return s.hasher.Hash(plaintext)
+}
+
+// Verify reports whether plaintext matches hash using the configured hasher.
+func (s *Service) Verify(hash, plaintext string) (bool, error) {
+	return s.hasher.Verify(hash, plaintext)
+}
@@ -0,0 +1,164 @@
+package passwordhistory
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// RepositoryConfig wires the Bun-backed password history repository.
+type RepositoryConfig struct {
+	DB         *bun.DB
+	Repository repository.Repository[*Record]
+	Clock      types.Clock
+}
+
+// Repository implements types.PasswordHistoryRepository using Bun.
+type Repository struct {
+	store repository.Repository[*Record]
+	clock types.Clock
+	db    *bun.DB
+}
+
+// NewRepository constructs the default password history repository.
+func NewRepository(cfg RepositoryConfig) (*Repository, error) {
+	if cfg.Repository == nil && cfg.DB == nil {
+		return nil, errors.New("passwordhistory: db or repository required")
+	}
+	repo := cfg.Repository
+	if repo == nil {
+		repo = repository.NewRepository(cfg.DB, repository.ModelHandlers[*Record]{
+			NewRecord: func() *Record { return &Record{} },
+			GetID: func(rec *Record) uuid.UUID {
+				if rec == nil {
+					return uuid.Nil
+				}
+				return rec.ID
+			},
+			SetID: func(rec *Record, id uuid.UUID) {
+				if rec != nil {
+					rec.ID = id
+				}
+			},
+		})
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	db := cfg.DB
+	if db == nil {
+		if withDB, ok := repo.(interface{ DB() *bun.DB }); ok {
+			db = withDB.DB()
+		}
+	}
+	return &Repository{store: repo, clock: clock, db: db}, nil
+}
+
+var _ types.PasswordHistoryRepository = (*Repository)(nil)
+
+// AppendPasswordHistory stores entry and prunes the user's older entries in
+// the same transaction.
+func (r *Repository) AppendPasswordHistory(ctx context.Context, entry types.PasswordHistoryEntry, keep int) error {
+	if r == nil || r.db == nil {
+		return errors.New("passwordhistory: db required for append")
+	}
+	if entry.UserID == uuid.Nil {
+		return types.ErrUserIDRequired
+	}
+	if strings.TrimSpace(entry.PasswordHash) == "" {
+		return errors.New("passwordhistory: password hash required")
+	}
+	rec := &Record{
+		ID:           entry.ID,
+		UserID:       entry.UserID,
+		PasswordHash: entry.PasswordHash,
+		TenantID:     entry.Scope.TenantID,
+		OrgID:        entry.Scope.OrgID,
+		CreatedAt:    entry.CreatedAt,
+	}
+	if rec.ID == uuid.Nil {
+		rec.ID = uuid.New()
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = r.clock.Now()
+	}
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := r.store.CreateTx(ctx, tx, rec); err != nil {
+			return err
+		}
+		if keep <= 0 {
+			return nil
+		}
+		newest := tx.NewSelect().Model((*Record)(nil)).
+			Column("id").
+			Where("user_id = ?", rec.UserID).
+			OrderExpr("created_at DESC, id DESC").
+			Limit(keep)
+		_, err := tx.NewDelete().Model((*Record)(nil)).
+			Where("user_id = ?", rec.UserID).
+			Where("id NOT IN (?)", newest).
+			Exec(ctx)
+		if err != nil {
+			return repository.MapDatabaseError(err, repository.DetectDriver(r.db))
+		}
+		return nil
+	})
+}
+
+// ListPasswordHistory returns up to limit entries for the user, newest
+// first. limit <= 0 returns every entry.
+func (r *Repository) ListPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]types.PasswordHistoryEntry, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("passwordhistory: db required for list")
+	}
+	if userID == uuid.Nil {
+		return nil, types.ErrUserIDRequired
+	}
+	var records []Record
+	query := r.db.NewSelect().Model(&records).
+		Where("user_id = ?", userID).
+		OrderExpr("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, repository.MapDatabaseError(err, repository.DetectDriver(r.db))
+	}
+	entries := make([]types.PasswordHistoryEntry, 0, len(records))
+	for _, rec := range records {
+		entries = append(entries, types.PasswordHistoryEntry{
+			ID:           rec.ID,
+			UserID:       rec.UserID,
+			PasswordHash: rec.PasswordHash,
+			Scope: types.ScopeFilter{
+				TenantID: rec.TenantID,
+				OrgID:    rec.OrgID,
+			},
+			CreatedAt: rec.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// DeletePasswordHistory removes every entry for the user.
+func (r *Repository) DeletePasswordHistory(ctx context.Context, userID uuid.UUID) error {
+	if r == nil || r.db == nil {
+		return errors.New("passwordhistory: db required for delete")
+	}
+	if userID == uuid.Nil {
+		return types.ErrUserIDRequired
+	}
+	_, err := r.db.NewDelete().Model((*Record)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return repository.MapDatabaseError(err, repository.DetectDriver(r.db))
+	}
+	return nil
+}
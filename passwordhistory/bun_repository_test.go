@@ -0,0 +1,134 @@
+package passwordhistory
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestRepositoryPasswordHistoryLifecycle(t *testing.T) {
+	ctx := context.Background()
+	db := newPasswordHistoryTestDB(t)
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	otherID := uuid.New()
+	insertPasswordHistoryUser(t, db, userID)
+	insertPasswordHistoryUser(t, db, otherID)
+
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	tenantID := uuid.New()
+	for i, hash := range []string{"hash-1", "hash-2", "hash-3", "hash-4"} {
+		require.NoError(t, repo.AppendPasswordHistory(ctx, types.PasswordHistoryEntry{
+			UserID:       userID,
+			PasswordHash: hash,
+			Scope:        types.ScopeFilter{TenantID: tenantID},
+			CreatedAt:    base.Add(time.Duration(i) * time.Hour),
+		}, 3))
+	}
+	require.NoError(t, repo.AppendPasswordHistory(ctx, types.PasswordHistoryEntry{
+		UserID:       otherID,
+		PasswordHash: "other-hash",
+		CreatedAt:    base,
+	}, 3))
+
+	entries, err := repo.ListPasswordHistory(ctx, userID, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, []string{"hash-4", "hash-3", "hash-2"}, hashes(entries))
+	require.Equal(t, tenantID, entries[0].Scope.TenantID)
+
+	entries, err = repo.ListPasswordHistory(ctx, userID, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"hash-4", "hash-3"}, hashes(entries))
+
+	require.NoError(t, repo.DeletePasswordHistory(ctx, userID))
+	entries, err = repo.ListPasswordHistory(ctx, userID, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	entries, err = repo.ListPasswordHistory(ctx, otherID, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"other-hash"}, hashes(entries))
+}
+
+func hashes(entries []types.PasswordHistoryEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.PasswordHash)
+	}
+	return out
+}
+
+func newPasswordHistoryTestDB(t *testing.T) *bun.DB {
+	sqldb, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = sqldb.Close()
+	})
+	applyMigrations(t, db,
+		"../data/sql/migrations/auth/sqlite/00001_users.up.sql",
+		"../data/sql/migrations/sqlite/00019_password_history.up.sql",
+	)
+	return db
+}
+
+func applyMigrations(t *testing.T, db *bun.DB, paths ...string) {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for _, stmt := range splitStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
+	}
+}
+
+func splitStatements(sql string) []string {
+	lines := strings.Split(sql, "\n")
+	var builder strings.Builder
+	var statements []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "--") || line == "---bun:split" {
+			continue
+		}
+		builder.WriteString(line)
+		if strings.HasSuffix(line, ";") {
+			statements = append(statements, strings.TrimSuffix(builder.String(), ";"))
+			builder.Reset()
+		} else {
+			builder.WriteString(" ")
+		}
+	}
+	if builder.Len() > 0 {
+		statements = append(statements, builder.String())
+	}
+	return statements
+}
+
+func insertPasswordHistoryUser(t *testing.T, db *bun.DB, userID uuid.UUID) {
+	_, err := db.Exec(`
+		INSERT INTO users (
+			id, user_role, first_name, last_name, username, email, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, userID.String(), "member", "Test", "User", userID.String(), userID.String()+"@example.com", "{}")
+	require.NoError(t, err)
+}
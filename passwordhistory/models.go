@@ -0,0 +1,20 @@
+package passwordhistory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Record models the persisted user_password_history row.
+type Record struct {
+	bun.BaseModel `bun:"table:user_password_history"`
+
+	ID           uuid.UUID `bun:"id,pk,type:uuid"`
+	UserID       uuid.UUID `bun:"user_id,notnull,type:uuid"`
+	PasswordHash string    `bun:"password_hash,notnull"`
+	TenantID     uuid.UUID `bun:"tenant_id,type:uuid,nullzero"`
+	OrgID        uuid.UUID `bun:"org_id,type:uuid,nullzero"`
+	CreatedAt    time.Time `bun:"created_at,notnull"`
+}
@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"net/http"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-users/preferences"
+)
+
+// PreferenceDefinitionProvider exposes preference key definitions, typically a
+// *preferences.SchemaRegistry.
+type PreferenceDefinitionProvider interface {
+	Definitions() []preferences.KeyDefinition
+}
+
+// PreferenceDocument renders the registered preference keys as a JSON Schema
+// object so admin UIs can build typed preference editors. It returns nil when
+// no keys are registered.
+func PreferenceDocument(provider PreferenceDefinitionProvider) map[string]any {
+	if provider == nil {
+		return nil
+	}
+	defs := provider.Definitions()
+	if len(defs) == 0 {
+		return nil
+	}
+	properties := make(map[string]any, len(defs))
+	for _, def := range defs {
+		properties[def.Key] = def.JSONSchema()
+	}
+	return map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "Preferences",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": true,
+	}
+}
+
+// PreferenceHandler serves PreferenceDocument, responding with 204 when no keys
+// are registered.
+func PreferenceHandler(provider PreferenceDefinitionProvider) router.HandlerFunc {
+	return func(ctx router.Context) error {
+		doc := PreferenceDocument(provider)
+		if len(doc) == 0 {
+			return ctx.NoContent(http.StatusNoContent)
+		}
+		return ctx.JSON(http.StatusOK, doc)
+	}
+}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceDocumentDescribesKeys(t *testing.T) {
+	reg := preferences.NewSchemaRegistry().MustRegister(
+		preferences.KeyDefinition{
+			Key:     "ui.theme",
+			Type:    preferences.ValueTypeString,
+			Enum:    []any{"light", "dark"},
+			Default: "light",
+			Levels:  []types.PreferenceLevel{types.PreferenceLevelUser},
+		},
+	)
+
+	doc := PreferenceDocument(reg)
+	require.Equal(t, "object", doc["type"])
+	props := doc["properties"].(map[string]any)
+	theme := props["ui.theme"].(map[string]any)
+	require.Equal(t, "string", theme["type"])
+	require.Equal(t, []any{"light", "dark"}, theme["enum"])
+	require.Equal(t, []string{"user"}, theme["x-levels"])
+}
+
+func TestPreferenceHandlerEmitsNoContentWhenEmpty(t *testing.T) {
+	ctx := router.NewMockContext()
+	ctx.On("NoContent", http.StatusNoContent).Return(nil)
+
+	require.NoError(t, PreferenceHandler(preferences.NewSchemaRegistry())(ctx))
+	ctx.AssertCalled(t, "NoContent", http.StatusNoContent)
+}
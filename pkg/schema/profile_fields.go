@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"net/http"
+
+	"github.com/goliatone/go-router"
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// ProfileFieldDocument renders custom profile field definitions as a JSON
+// Schema object so admin UIs can build profile forms. Definitions keep their
+// order in x-order; visibility, uniqueness, and position are exposed as
+// x-visibility, x-unique, and x-position. It returns nil when defs is empty.
+func ProfileFieldDocument(defs []types.ProfileFieldDefinition) map[string]any {
+	if len(defs) == 0 {
+		return nil
+	}
+	properties := make(map[string]any, len(defs))
+	order := make([]string, 0, len(defs))
+	required := []string{}
+	for _, def := range defs {
+		properties[def.Key] = profileFieldSchema(def)
+		order = append(order, def.Key)
+		if def.Required {
+			required = append(required, def.Key)
+		}
+	}
+	return map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "Profile Custom Fields",
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+		"x-order":              order,
+	}
+}
+
+// ProfileFieldHandler serves ProfileFieldDocument for the definitions returned
+// by list, responding with 204 when there are none.
+func ProfileFieldHandler(list func(router.Context) ([]types.ProfileFieldDefinition, error)) router.HandlerFunc {
+	return func(ctx router.Context) error {
+		defs, err := list(ctx)
+		if err != nil {
+			return err
+		}
+		doc := ProfileFieldDocument(defs)
+		if len(doc) == 0 {
+			return ctx.NoContent(http.StatusNoContent)
+		}
+		return ctx.JSON(http.StatusOK, doc)
+	}
+}
+
+// ProfileFieldProvider adapts definitions to a router.MetadataProvider so they
+// can be published through Registry.Register under name (for example
+// "profile_fields" or a tenant-qualified name).
+func ProfileFieldProvider(name string, defs []types.ProfileFieldDefinition) router.MetadataProvider {
+	properties := make(map[string]router.PropertyInfo, len(defs))
+	required := []string{}
+	for _, def := range defs {
+		info := router.PropertyInfo{
+			Type:         profileFieldJSONType(def.Type),
+			Description:  def.Description,
+			Required:     def.Required,
+			OriginalName: def.Key,
+			CustomTagData: map[string]any{
+				"label":      def.Label,
+				"unique":     def.Unique,
+				"visibility": string(def.Visibility),
+				"position":   def.Position,
+			},
+		}
+		if def.Type == types.ProfileFieldTypeDate {
+			info.Format = "date"
+		}
+		if len(def.Options) > 0 {
+			info.CustomTagData["enum"] = append([]string(nil), def.Options...)
+		}
+		properties[def.Key] = info
+		if def.Required {
+			required = append(required, def.Key)
+		}
+	}
+	return staticMetadataProvider{metadata: router.ResourceMetadata{
+		Name:        name,
+		Description: "Custom profile fields",
+		Schema: router.SchemaMetadata{
+			Name:       name,
+			Required:   required,
+			Properties: properties,
+		},
+	}}
+}
+
+func profileFieldSchema(def types.ProfileFieldDefinition) map[string]any {
+	out := map[string]any{
+		"type":         profileFieldJSONType(def.Type),
+		"x-unique":     def.Unique,
+		"x-visibility": string(def.Visibility),
+		"x-position":   def.Position,
+	}
+	if def.Label != "" {
+		out["title"] = def.Label
+	}
+	if def.Description != "" {
+		out["description"] = def.Description
+	}
+	switch def.Type {
+	case types.ProfileFieldTypeDate:
+		out["format"] = "date"
+	case types.ProfileFieldTypeEnum:
+		enum := make([]any, 0, len(def.Options))
+		for _, option := range def.Options {
+			enum = append(enum, option)
+		}
+		out["enum"] = enum
+	}
+	return out
+}
+
+func profileFieldJSONType(fieldType types.ProfileFieldType) string {
+	switch fieldType {
+	case types.ProfileFieldTypeNumber, types.ProfileFieldTypeInteger, types.ProfileFieldTypeBoolean:
+		return string(fieldType)
+	default:
+		return "string"
+	}
+}
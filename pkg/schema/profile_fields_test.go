@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileFieldDocumentDescribesFields(t *testing.T) {
+	defs := []types.ProfileFieldDefinition{
+		{Key: "department", Label: "Department", Type: types.ProfileFieldTypeEnum, Options: []string{"eng", "ops"}, Required: true, Visibility: types.ProfileFieldVisibilityPublic},
+		{Key: "startDate", Type: types.ProfileFieldTypeDate, Visibility: types.ProfileFieldVisibilityAdmin, Position: 1},
+		{Key: "employeeNumber", Type: types.ProfileFieldTypeInteger, Unique: true, Position: 2},
+	}
+
+	doc := ProfileFieldDocument(defs)
+	require.Equal(t, []string{"department"}, doc["required"])
+	require.Equal(t, []string{"department", "startDate", "employeeNumber"}, doc["x-order"])
+	props := doc["properties"].(map[string]any)
+	department := props["department"].(map[string]any)
+	require.Equal(t, "string", department["type"])
+	require.Equal(t, "Department", department["title"])
+	require.Equal(t, []any{"eng", "ops"}, department["enum"])
+	start := props["startDate"].(map[string]any)
+	require.Equal(t, "date", start["format"])
+	require.Equal(t, "admin", start["x-visibility"])
+	require.Equal(t, true, props["employeeNumber"].(map[string]any)["x-unique"])
+	require.Nil(t, ProfileFieldDocument(nil))
+
+	reg := NewRegistry()
+	reg.Register(ProfileFieldProvider("profile_fields", defs))
+	require.Equal(t, []string{"profile_fields"}, reg.Resources())
+	meta := ProfileFieldProvider("profile_fields", defs).GetMetadata()
+	require.Equal(t, []string{"department"}, meta.Schema.Required)
+	require.Equal(t, "integer", meta.Schema.Properties["employeeNumber"].Type)
+	require.Equal(t, "date", meta.Schema.Properties["startDate"].Format)
+}
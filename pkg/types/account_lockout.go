@@ -0,0 +1,99 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	AccountLockedMetadataKey      = "account_locked"
+	AccountLockedAtMetadataKey    = "account_locked_at"
+	AccountLockedUntilMetadataKey = "account_locked_until"
+)
+
+var (
+	// ErrAccountLocked indicates sign-in was refused because the account is
+	// locked after repeated failures.
+	ErrAccountLocked = errors.New("go-users: account locked")
+	// ErrTooManyLoginAttempts indicates sign-in was refused because the
+	// identifier or IP exceeded its failure threshold.
+	ErrTooManyLoginAttempts = errors.New("go-users: too many login attempts")
+	// ErrMissingLoginAttemptStore indicates lockout was used without an
+	// attempt store.
+	ErrMissingLoginAttemptStore = errors.New("go-users: missing login attempt store")
+)
+
+// LoginAttemptStore keeps failed sign-in timestamps per key for sliding
+// window counting. Keys come from LoginAttemptIdentifierKey and
+// LoginAttemptIPKey.
+type LoginAttemptStore interface {
+	// RecordFailure stores a failure for key at the given time, drops
+	// failures older than window, and returns the failures left in the
+	// window.
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error)
+	// CountFailures returns the failures recorded for key since the given
+	// time.
+	CountFailures(ctx context.Context, key string, since time.Time) (int, error)
+	// ResetFailures removes every failure recorded for key.
+	ResetFailures(ctx context.Context, key string) error
+}
+
+// LoginAttemptIdentifierKey returns the attempt store key for a sign-in
+// identifier (email or username), ignoring case.
+func LoginAttemptIdentifierKey(identifier string) string {
+	return "identifier:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// LoginAttemptIPKey returns the attempt store key for a client IP.
+func LoginAttemptIPKey(ip string) string {
+	return "ip:" + strings.TrimSpace(ip)
+}
+
+// MarkAccountLockedMetadata returns a cloned metadata map recording a lock
+// that lasts until the given time.
+func MarkAccountLockedMetadata(metadata map[string]any, lockedAt, until time.Time) map[string]any {
+	out := cloneMetadata(metadata)
+	if out == nil {
+		out = map[string]any{}
+	}
+	out[AccountLockedMetadataKey] = true
+	if !lockedAt.IsZero() {
+		out[AccountLockedAtMetadataKey] = lockedAt.UTC().Format(time.RFC3339Nano)
+	}
+	if !until.IsZero() {
+		out[AccountLockedUntilMetadataKey] = until.UTC().Format(time.RFC3339Nano)
+	}
+	return out
+}
+
+// ClearAccountLockedMetadata removes lock state from the metadata.
+func ClearAccountLockedMetadata(metadata map[string]any) map[string]any {
+	out := cloneMetadata(metadata)
+	if out == nil {
+		return nil
+	}
+	delete(out, AccountLockedMetadataKey)
+	delete(out, AccountLockedAtMetadataKey)
+	delete(out, AccountLockedUntilMetadataKey)
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// AccountLockedUntil reports whether the metadata records a lock and when it
+// ends. A lock without a readable end time returns a zero time and only ends
+// through an explicit unlock.
+func AccountLockedUntil(metadata map[string]any) (time.Time, bool) {
+	if locked, _ := metadata[AccountLockedMetadataKey].(bool); !locked {
+		return time.Time{}, false
+	}
+	raw, _ := metadata[AccountLockedUntilMetadataKey].(string)
+	until, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, true
+	}
+	return until, true
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// BlobStorage persists binary objects such as avatar images under
+// caller-chosen keys.
+type BlobStorage interface {
+	// Put stores the contents of r under key, replacing any existing blob.
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+	// Open returns the blob stored under key and its content type, or
+	// ErrBlobNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, string, error)
+	// Delete removes the blob stored under key. Missing blobs are not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the address clients use to fetch the blob under key.
+	URL(key string) string
+	// KeyForURL maps a URL returned by URL back to its key. It reports false
+	// for URLs the storage did not produce.
+	KeyForURL(url string) (string, bool)
+}
+
+var (
+	// ErrMissingAvatarStorage indicates avatar uploads were attempted without blob storage.
+	ErrMissingAvatarStorage = errors.New("go-users: missing avatar storage")
+	// ErrBlobNotFound indicates no blob is stored under the requested key.
+	ErrBlobNotFound = errors.New("go-users: blob not found")
+	// ErrAvatarRequired indicates an avatar upload carried no image data.
+	ErrAvatarRequired = errors.New("go-users: avatar image required")
+	// ErrAvatarTooLarge indicates an avatar exceeds the configured size limit.
+	ErrAvatarTooLarge = errors.New("go-users: avatar image too large")
+	// ErrAvatarContentType indicates an avatar is not an allowed image type.
+	ErrAvatarContentType = errors.New("go-users: avatar content type not allowed")
+	// ErrAvatarDimensions indicates an avatar's width or height is out of bounds.
+	ErrAvatarDimensions = errors.New("go-users: avatar dimensions out of bounds")
+)
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContactChannel identifies the contact detail a verification flow changes.
+type ContactChannel string
+
+const (
+	// ContactChannelEmail changes AuthUser.Email.
+	ContactChannelEmail ContactChannel = "email"
+	// ContactChannelPhone changes the "phone" entry of the profile contact map.
+	ContactChannelPhone ContactChannel = "phone"
+)
+
+// ContactProfileKeyPhone is the UserProfile.Contact key holding the phone
+// number managed by ContactChannelPhone.
+const ContactProfileKeyPhone = "phone"
+
+// ContactVerificationMethod selects how the new address proves ownership.
+type ContactVerificationMethod string
+
+const (
+	// ContactVerificationLink sends a securelink token to the new address.
+	ContactVerificationLink ContactVerificationMethod = "link"
+	// ContactVerificationOTP sends a short numeric code to the new address.
+	ContactVerificationOTP ContactVerificationMethod = "otp"
+)
+
+// ContactChangeStatus tracks the lifecycle of a pending contact change.
+type ContactChangeStatus string
+
+const (
+	ContactChangeStatusPending    ContactChangeStatus = "pending"
+	ContactChangeStatusConfirmed  ContactChangeStatus = "confirmed"
+	ContactChangeStatusExpired    ContactChangeStatus = "expired"
+	ContactChangeStatusSuperseded ContactChangeStatus = "superseded"
+)
+
+// ContactChangeStage identifies which step of the verification flow emitted a
+// ContactChangeEvent.
+type ContactChangeStage string
+
+const (
+	ContactChangeStageStarted   ContactChangeStage = "started"
+	ContactChangeStageConfirmed ContactChangeStage = "confirmed"
+)
+
+var (
+	// ErrContactChannelInvalid indicates an unsupported contact channel.
+	ErrContactChannelInvalid = errors.New("go-users: contact channel must be email or phone")
+	// ErrContactValueRequired indicates the new contact value was empty.
+	ErrContactValueRequired = errors.New("go-users: contact value required")
+	// ErrContactValueUnchanged indicates the new contact value matches the current one.
+	ErrContactValueUnchanged = errors.New("go-users: contact value unchanged")
+	// ErrContactVerificationMethodInvalid indicates an unsupported verification method.
+	ErrContactVerificationMethodInvalid = errors.New("go-users: contact verification method must be link or otp")
+)
+
+// ContactChange is a requested email/phone change held until the new address
+// is verified. It shares its JTI with the contact_verification user token.
+type ContactChange struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	Channel  ContactChannel
+	Method   ContactVerificationMethod
+	OldValue string
+	NewValue string
+	JTI      string
+	// CodeHash is the hashed OTP for ContactVerificationOTP changes.
+	CodeHash    string
+	Attempts    int
+	Status      ContactChangeStatus
+	Scope       ScopeFilter
+	RequestedBy uuid.UUID
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	ConfirmedAt time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ContactChangeRepository persists pending contact changes.
+type ContactChangeRepository interface {
+	// CreateContactChange stores a pending change and marks any other pending
+	// change for the same user and channel as superseded.
+	CreateContactChange(ctx context.Context, change ContactChange) (*ContactChange, error)
+	GetContactChangeByJTI(ctx context.Context, jti string) (*ContactChange, error)
+	// RecordContactChangeAttempt counts a failed code entry against a pending
+	// change and returns the new total.
+	RecordContactChangeAttempt(ctx context.Context, jti string) (int, error)
+	// UpdateContactChangeStatus moves a pending change to status. Changes that
+	// are no longer pending report an expected-count violation.
+	UpdateContactChangeStatus(ctx context.Context, jti string, status ContactChangeStatus, at time.Time) error
+}
+
+// ContactChangeEvent signals a step of the contact verification flow. OldValue
+// lets hosts warn the previous address that its replacement was requested or
+// confirmed.
+type ContactChangeEvent struct {
+	UserID     uuid.UUID
+	ActorID    uuid.UUID
+	Scope      ScopeFilter
+	Channel    ContactChannel
+	Stage      ContactChangeStage
+	OldValue   string
+	NewValue   string
+	ExpiresAt  time.Time
+	OccurredAt time.Time
+}
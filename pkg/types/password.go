@@ -0,0 +1,78 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PasswordHasher turns plaintext passwords into stored hashes and verifies
+// plaintext against them.
+type PasswordHasher interface {
+	Hash(plaintext string) (string, error)
+	// Verify reports whether plaintext matches hash. A mismatch is not an
+	// error.
+	Verify(hash, plaintext string) (bool, error)
+}
+
+// PasswordService checks plaintext passwords against a policy and hashes
+// them. user supplies the identity (email, username) the policy compares
+// against and may be nil.
+type PasswordService interface {
+	CheckPassword(ctx context.Context, plaintext string, user *AuthUser) error
+	HashPassword(ctx context.Context, plaintext string, user *AuthUser) (string, error)
+}
+
+// PasswordHasherProvider is implemented by password services that expose the
+// hasher they write hashes with, so stored hashes can be verified with the
+// same algorithm.
+type PasswordHasherProvider interface {
+	PasswordHasher() PasswordHasher
+}
+
+// PasswordPolicyViolation names a rule a password failed.
+type PasswordPolicyViolation string
+
+const (
+	PasswordTooShort         PasswordPolicyViolation = "too_short"
+	PasswordTooLong          PasswordPolicyViolation = "too_long"
+	PasswordMissingUpper     PasswordPolicyViolation = "missing_uppercase"
+	PasswordMissingLower     PasswordPolicyViolation = "missing_lowercase"
+	PasswordMissingDigit     PasswordPolicyViolation = "missing_digit"
+	PasswordMissingSymbol    PasswordPolicyViolation = "missing_symbol"
+	PasswordDenylisted       PasswordPolicyViolation = "denylisted"
+	PasswordContainsIdentity PasswordPolicyViolation = "contains_identity"
+)
+
+var (
+	// ErrMissingPasswordService indicates a plaintext password was supplied
+	// without a password service to check and hash it.
+	ErrMissingPasswordService = errors.New("go-users: missing password service")
+	// ErrPasswordPolicy indicates a password does not satisfy the policy.
+	ErrPasswordPolicy = errors.New("go-users: password does not satisfy policy")
+	// ErrPasswordHasherUnavailable indicates stored hashes cannot be verified
+	// because the password service does not implement PasswordHasherProvider.
+	ErrPasswordHasherUnavailable = errors.New("go-users: password service does not expose its hasher")
+)
+
+// PasswordPolicyError lists every rule a password failed.
+type PasswordPolicyError struct {
+	Violations []PasswordPolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	if e == nil {
+		return ""
+	}
+	names := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		names = append(names, string(violation))
+	}
+	return fmt.Sprintf("%s: %s", ErrPasswordPolicy.Error(), strings.Join(names, ", "))
+}
+
+// Is reports ErrPasswordPolicy so callers can use errors.Is.
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordPolicy
+}
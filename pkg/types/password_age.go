@@ -0,0 +1,104 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PasswordChangedAtMetadataKey records when the user's password was last
+	// set.
+	PasswordChangedAtMetadataKey = "password_changed_at"
+	// PreferenceKeyPasswordMaxAge holds the maximum password age in days. It
+	// is resolved at the system, tenant, and org levels so each tenant can set
+	// its own rotation period; zero disables expiry.
+	PreferenceKeyPasswordMaxAge = "security.password_max_age_days"
+)
+
+// PasswordRotationReason explains why a password must be changed.
+type PasswordRotationReason string
+
+const (
+	// PasswordRotationExpired means the password is older than the max age.
+	PasswordRotationExpired PasswordRotationReason = "expired"
+	// PasswordRotationChangeRequired means the user metadata already carries
+	// PasswordChangeRequiredMetadataKey (temporary or expired passwords).
+	PasswordRotationChangeRequired PasswordRotationReason = "change_required"
+)
+
+// ErrMissingPasswordRotation indicates password age was queried without a
+// rotation policy.
+var ErrMissingPasswordRotation = errors.New("go-users: missing password rotation policy")
+
+// PasswordRotationStatus reports whether a user must rotate their password.
+type PasswordRotationStatus struct {
+	UserID uuid.UUID
+	// Required is true when the user must change their password now.
+	Required bool
+	Reason   PasswordRotationReason
+	// ChangedAt is when the password was last set and is zero when no change
+	// time has been recorded.
+	ChangedAt time.Time
+	// ExpiresAt is zero when expiry is disabled or ChangedAt is unknown.
+	ExpiresAt time.Time
+	MaxAge    time.Duration
+}
+
+// PasswordRotationPolicy evaluates password age for a user in scope.
+type PasswordRotationPolicy interface {
+	RotationStatus(ctx context.Context, user *AuthUser, scope ScopeFilter) PasswordRotationStatus
+}
+
+// PasswordChangeRepository is an optional AuthRepository extension for stores
+// that record when a password was last changed.
+type PasswordChangeRepository interface {
+	MarkPasswordChanged(ctx context.Context, id uuid.UUID, changedAt time.Time) error
+}
+
+// MarkPasswordChangedMetadata returns a cloned metadata map recording the
+// password change time.
+func MarkPasswordChangedMetadata(metadata map[string]any, changedAt time.Time) map[string]any {
+	out := cloneMetadata(metadata)
+	if out == nil {
+		out = map[string]any{}
+	}
+	out[PasswordChangedAtMetadataKey] = changedAt.UTC().Format(time.RFC3339Nano)
+	return out
+}
+
+// MarkPasswordChangeRequiredMetadata returns a cloned metadata map flagging
+// that the user must change their password before continuing.
+func MarkPasswordChangeRequiredMetadata(metadata map[string]any) map[string]any {
+	out := cloneMetadata(metadata)
+	if out == nil {
+		out = map[string]any{}
+	}
+	out[PasswordChangeRequiredMetadataKey] = true
+	return out
+}
+
+// PasswordChangeRequired reports whether the metadata flags a required
+// password change.
+func PasswordChangeRequired(metadata map[string]any) bool {
+	required, _ := metadata[PasswordChangeRequiredMetadataKey].(bool)
+	return required
+}
+
+// PasswordChangedAt returns the recorded password change time.
+func PasswordChangedAt(metadata map[string]any) (time.Time, bool) {
+	switch value := metadata[PasswordChangedAtMetadataKey].(type) {
+	case time.Time:
+		return value, !value.IsZero()
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return parsed, true
+	default:
+		return time.Time{}, false
+	}
+}
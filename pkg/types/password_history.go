@@ -0,0 +1,52 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PreferenceKeyPasswordHistory holds the number of previous passwords that
+// cannot be reused. It is resolved at the system, tenant, and org levels so
+// each tenant can set its own retention; zero disables the check.
+const PreferenceKeyPasswordHistory = "security.password_history"
+
+var (
+	// ErrPasswordReused indicates a new password matches one of the user's
+	// recent passwords.
+	ErrPasswordReused = errors.New("go-users: password was used recently")
+	// ErrMissingPasswordHistoryRepository indicates password history was used
+	// without a repository.
+	ErrMissingPasswordHistoryRepository = errors.New("go-users: missing password history repository")
+)
+
+// PasswordHistoryEntry is a previous password hash.
+type PasswordHistoryEntry struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	PasswordHash string
+	Scope        ScopeFilter
+	CreatedAt    time.Time
+}
+
+// PasswordHistoryRepository stores previous password hashes per user.
+type PasswordHistoryRepository interface {
+	// AppendPasswordHistory stores entry and removes all but the newest keep
+	// entries for the user. keep <= 0 removes nothing.
+	AppendPasswordHistory(ctx context.Context, entry PasswordHistoryEntry, keep int) error
+	// ListPasswordHistory returns up to limit entries, newest first.
+	ListPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]PasswordHistoryEntry, error)
+	DeletePasswordHistory(ctx context.Context, userID uuid.UUID) error
+}
+
+// PasswordHistory checks new passwords against the user's recent passwords
+// and records applied ones.
+type PasswordHistory interface {
+	// CheckPasswordReuse returns ErrPasswordReused when plaintext matches a
+	// password within the retention configured for scope.
+	CheckPasswordReuse(ctx context.Context, userID uuid.UUID, scope ScopeFilter, plaintext string) error
+	RecordPasswordHistory(ctx context.Context, userID uuid.UUID, scope ScopeFilter, passwordHash string) error
+	PurgePasswordHistory(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,212 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileFieldType is the value type of a custom profile field.
+type ProfileFieldType string
+
+const (
+	ProfileFieldTypeString  ProfileFieldType = "string"
+	ProfileFieldTypeNumber  ProfileFieldType = "number"
+	ProfileFieldTypeInteger ProfileFieldType = "integer"
+	ProfileFieldTypeBoolean ProfileFieldType = "boolean"
+	// ProfileFieldTypeDate holds calendar dates formatted as YYYY-MM-DD.
+	ProfileFieldTypeDate ProfileFieldType = "date"
+	// ProfileFieldTypeEnum holds one of the definition's Options.
+	ProfileFieldTypeEnum ProfileFieldType = "enum"
+)
+
+// ProfileFieldVisibility controls who may read a custom profile field.
+type ProfileFieldVisibility string
+
+const (
+	// ProfileFieldVisibilityPublic exposes the field to anyone who can read
+	// the profile (default).
+	ProfileFieldVisibilityPublic ProfileFieldVisibility = "public"
+	// ProfileFieldVisibilitySelf limits the field to the profile owner and
+	// admins.
+	ProfileFieldVisibilitySelf ProfileFieldVisibility = "self"
+	// ProfileFieldVisibilityAdmin limits the field to admins.
+	ProfileFieldVisibilityAdmin ProfileFieldVisibility = "admin"
+)
+
+// ProfileFieldDefinition declares a custom profile field. Definitions with a
+// nil TenantID apply to every tenant; a tenant definition with the same key
+// replaces the global one for that tenant.
+type ProfileFieldDefinition struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Key         string
+	Label       string
+	Description string
+	Type        ProfileFieldType
+	// Options lists the allowed values of enum fields.
+	Options  []string
+	Required bool
+	// Unique rejects values already stored on another profile in the tenant.
+	Unique     bool
+	Visibility ProfileFieldVisibility
+	// Position orders fields in forms (ascending, then by key).
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Normalize trims the key and defaults the visibility.
+func (d *ProfileFieldDefinition) Normalize() {
+	if d == nil {
+		return
+	}
+	d.Key = strings.TrimSpace(d.Key)
+	if d.Visibility == "" {
+		d.Visibility = ProfileFieldVisibilityPublic
+	}
+}
+
+// Validate checks the definition itself.
+func (d ProfileFieldDefinition) Validate() error {
+	if strings.TrimSpace(d.Key) == "" {
+		return ErrProfileFieldKeyRequired
+	}
+	if !profileFieldKeyPattern.MatchString(strings.TrimSpace(d.Key)) {
+		return fmt.Errorf("%w: %q: keys must start with a letter and contain only letters, digits, and underscores", ErrProfileFieldDefinitionInvalid, d.Key)
+	}
+	switch d.Type {
+	case ProfileFieldTypeString, ProfileFieldTypeNumber, ProfileFieldTypeInteger, ProfileFieldTypeDate:
+	case ProfileFieldTypeBoolean:
+		if d.Unique {
+			return fmt.Errorf("%w: %q: boolean fields cannot be unique", ErrProfileFieldDefinitionInvalid, d.Key)
+		}
+	case ProfileFieldTypeEnum:
+		if len(d.Options) == 0 {
+			return fmt.Errorf("%w: %q: enum fields require options", ErrProfileFieldDefinitionInvalid, d.Key)
+		}
+	default:
+		return fmt.Errorf("%w: %q: unsupported type %q", ErrProfileFieldDefinitionInvalid, d.Key, d.Type)
+	}
+	switch d.Visibility {
+	case "", ProfileFieldVisibilityPublic, ProfileFieldVisibilitySelf, ProfileFieldVisibilityAdmin:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q: unsupported visibility %q", ErrProfileFieldDefinitionInvalid, d.Key, d.Visibility)
+	}
+}
+
+// ValidateValue checks that value matches the field type. Callers handle
+// missing values through Required.
+func (d ProfileFieldDefinition) ValidateValue(value any) error {
+	ok := false
+	switch d.Type {
+	case ProfileFieldTypeString:
+		_, ok = value.(string)
+	case ProfileFieldTypeNumber:
+		_, ok = profileFieldNumber(value)
+	case ProfileFieldTypeInteger:
+		num, isNum := profileFieldNumber(value)
+		ok = isNum && num == math.Trunc(num)
+	case ProfileFieldTypeBoolean:
+		_, ok = value.(bool)
+	case ProfileFieldTypeDate:
+		text, isText := value.(string)
+		if isText {
+			_, err := time.Parse(time.DateOnly, text)
+			ok = err == nil
+		}
+	case ProfileFieldTypeEnum:
+		text, isText := value.(string)
+		ok = isText && slices.Contains(d.Options, text)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q: %v is not a valid %s", ErrProfileFieldInvalid, d.Key, value, d.Type)
+	}
+	return nil
+}
+
+// ProfileFieldEmpty reports whether value counts as missing for required
+// fields: nil or a blank string.
+func ProfileFieldEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+	text, ok := value.(string)
+	return ok && strings.TrimSpace(text) == ""
+}
+
+// ProfileFieldRepository persists custom profile field definitions.
+type ProfileFieldRepository interface {
+	// ListProfileFields returns the definitions in effect for tenantID: the
+	// global definitions merged with the tenant's own, ordered by Position
+	// then Key.
+	ListProfileFields(ctx context.Context, tenantID uuid.UUID) ([]ProfileFieldDefinition, error)
+	UpsertProfileField(ctx context.Context, def ProfileFieldDefinition) (*ProfileFieldDefinition, error)
+	DeleteProfileField(ctx context.Context, tenantID uuid.UUID, key string) error
+}
+
+// ProfileFieldUniquenessChecker is implemented by profile repositories that
+// can enforce unique custom field values.
+type ProfileFieldUniquenessChecker interface {
+	// ProfileFieldValueTaken reports whether another profile in tenantID
+	// stores value under key.
+	ProfileFieldValueTaken(ctx context.Context, tenantID uuid.UUID, key string, value any, excludeUserID uuid.UUID) (bool, error)
+}
+
+var (
+	// ErrMissingProfileFieldRepository indicates profile field definitions were requested without a repository.
+	ErrMissingProfileFieldRepository = errors.New("go-users: missing profile field repository")
+	// ErrProfileFieldKeyRequired indicates a profile field definition lacks a key.
+	ErrProfileFieldKeyRequired = errors.New("go-users: profile field key required")
+	// ErrProfileFieldDefinitionInvalid indicates a profile field definition is malformed.
+	ErrProfileFieldDefinitionInvalid = errors.New("go-users: profile field definition invalid")
+	// ErrProfileFieldUnknown indicates a custom field value has no definition in the tenant.
+	ErrProfileFieldUnknown = errors.New("go-users: unknown profile field")
+	// ErrProfileFieldRequired indicates a required custom field is missing.
+	ErrProfileFieldRequired = errors.New("go-users: profile field required")
+	// ErrProfileFieldInvalid indicates a custom field value does not match its definition.
+	ErrProfileFieldInvalid = errors.New("go-users: profile field value invalid")
+	// ErrProfileFieldNotUnique indicates a unique custom field value is already used in the tenant.
+	ErrProfileFieldNotUnique = errors.New("go-users: profile field value already in use")
+	// ErrProfileFieldUniquenessUnsupported indicates the profile repository cannot check unique fields.
+	ErrProfileFieldUniquenessUnsupported = errors.New("go-users: profile repository cannot check unique fields")
+	// ErrProfileFieldNotReadable indicates the actor may not read a custom field of other users.
+	ErrProfileFieldNotReadable = errors.New("go-users: profile field not readable")
+)
+
+var profileFieldKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func profileFieldNumber(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case float32:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case int32:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint:
+		return float64(typed), true
+	case uint32:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case json.Number:
+		num, err := typed.Float64()
+		return num, err == nil
+	default:
+		return 0, false
+	}
+}
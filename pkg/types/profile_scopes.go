@@ -0,0 +1,146 @@
+package types
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ProfileScopeChain returns the scopes consulted when resolving a profile
+// for scope, most specific first: the org profile, the tenant profile, then
+// the global profile. Scopes without an org or tenant are skipped.
+func ProfileScopeChain(scope ScopeFilter) []ScopeFilter {
+	chain := make([]ScopeFilter, 0, 3)
+	if scope.OrgID != uuid.Nil {
+		chain = append(chain, ScopeFilter{TenantID: scope.TenantID, OrgID: scope.OrgID})
+	}
+	if scope.TenantID != uuid.Nil {
+		chain = append(chain, ScopeFilter{TenantID: scope.TenantID})
+	}
+	return append(chain, ScopeFilter{})
+}
+
+// ResolveProfile loads the profiles along ProfileScopeChain(scope) and merges
+// them with MergeProfiles. It returns nil when the user has no profile in any
+// of those scopes.
+func ResolveProfile(ctx context.Context, repo ProfileRepository, userID uuid.UUID, scope ScopeFilter) (*UserProfile, error) {
+	if repo == nil {
+		return nil, ErrMissingProfileRepository
+	}
+	var layers []UserProfile
+	for _, candidate := range ProfileScopeChain(scope) {
+		profile, err := repo.GetProfile(ctx, userID, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if profile != nil {
+			layers = append(layers, *profile)
+		}
+	}
+	if len(layers) == 0 {
+		return nil, nil
+	}
+	merged := MergeProfiles(layers...)
+	return &merged, nil
+}
+
+// ResolveProfiles resolves the profiles of several users like
+// ResolveProfile. Repositories implementing ProfileBatchRepository are read
+// with one query per scope in the chain; others are read user by user. Users
+// without a profile are absent from the result.
+func ResolveProfiles(ctx context.Context, repo ProfileRepository, userIDs []uuid.UUID, scope ScopeFilter) (map[uuid.UUID]*UserProfile, error) {
+	if repo == nil {
+		return nil, ErrMissingProfileRepository
+	}
+	out := make(map[uuid.UUID]*UserProfile, len(userIDs))
+	if len(userIDs) == 0 {
+		return out, nil
+	}
+	batch, ok := repo.(ProfileBatchRepository)
+	if !ok {
+		for _, userID := range userIDs {
+			profile, err := ResolveProfile(ctx, repo, userID, scope)
+			if err != nil {
+				return nil, err
+			}
+			if profile != nil {
+				out[userID] = profile
+			}
+		}
+		return out, nil
+	}
+	layers := make(map[uuid.UUID][]UserProfile, len(userIDs))
+	for _, candidate := range ProfileScopeChain(scope) {
+		profiles, err := batch.GetProfiles(ctx, userIDs, candidate)
+		if err != nil {
+			return nil, err
+		}
+		for userID, profile := range profiles {
+			if profile != nil {
+				layers[userID] = append(layers[userID], *profile)
+			}
+		}
+	}
+	for userID, userLayers := range layers {
+		merged := MergeProfiles(userLayers...)
+		out[userID] = &merged
+	}
+	return out, nil
+}
+
+// MergeProfiles combines profiles ordered most specific first. Non-empty
+// scalar fields of a more specific profile win, and the contact, metadata,
+// and custom field maps are merged key by key. Scope, version, and audit
+// fields come from the most specific profile, so writes made with its
+// Version as ExpectedVersion target that scope.
+func MergeProfiles(layers ...UserProfile) UserProfile {
+	if len(layers) == 0 {
+		return UserProfile{}
+	}
+	out := layers[0]
+	out.Contact = cloneProfileMap(out.Contact)
+	out.Metadata = cloneProfileMap(out.Metadata)
+	out.CustomFields = cloneProfileMap(out.CustomFields)
+	for _, layer := range layers[1:] {
+		out.DisplayName = fallbackString(out.DisplayName, layer.DisplayName)
+		out.AvatarURL = fallbackString(out.AvatarURL, layer.AvatarURL)
+		out.Locale = fallbackString(out.Locale, layer.Locale)
+		out.Timezone = fallbackString(out.Timezone, layer.Timezone)
+		out.Bio = fallbackString(out.Bio, layer.Bio)
+		out.Contact = fallbackMap(out.Contact, layer.Contact)
+		out.Metadata = fallbackMap(out.Metadata, layer.Metadata)
+		out.CustomFields = fallbackMap(out.CustomFields, layer.CustomFields)
+	}
+	return out
+}
+
+func fallbackString(value, fallback string) string {
+	if strings.TrimSpace(value) != "" {
+		return value
+	}
+	return fallback
+}
+
+func fallbackMap(values, fallback map[string]any) map[string]any {
+	if len(fallback) == 0 {
+		return values
+	}
+	if values == nil {
+		values = make(map[string]any, len(fallback))
+	}
+	for key, value := range fallback {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+func cloneProfileMap(origin map[string]any) map[string]any {
+	if len(origin) == 0 {
+		return nil
+	}
+	return maps.Clone(origin)
+}
@@ -23,6 +23,10 @@ const (
 	PolicyActionPreferencesWrite PolicyAction = "preferences:write"
 	PolicyActionProfilesRead     PolicyAction = "profiles:read"
 	PolicyActionProfilesWrite    PolicyAction = "profiles:write"
+	// PolicyActionProfileFieldsWrite manages the custom profile field
+	// definitions of a tenant. It is an administrative action, separate from
+	// editing individual profiles.
+	PolicyActionProfileFieldsWrite PolicyAction = "profile_fields:write"
 )
 
 // PolicyCheck captures the authorization context for a single command/query.
@@ -12,4 +12,7 @@ var (
 	// ErrMissingPasswordResetLifecycleRepository occurs when reset confirmation
 	// requires safe claim/finalize support that the repository does not expose.
 	ErrMissingPasswordResetLifecycleRepository = errors.New("go-users: missing password reset lifecycle repository")
+	// ErrMissingContactChangeRepository occurs when pending contact change
+	// persistence is unavailable.
+	ErrMissingContactChangeRepository = errors.New("go-users: missing contact change repository")
 )
@@ -14,6 +14,9 @@ const (
 	UserTokenInvite        UserTokenType = "invite"
 	UserTokenRegistration  UserTokenType = "register"
 	UserTokenPasswordReset UserTokenType = "password_reset"
+	// UserTokenContactVerification backs pending email/phone changes
+	// (requires migration 00018_contact_verification).
+	UserTokenContactVerification UserTokenType = "contact_verification"
 )
 
 // UserTokenStatus tracks lifecycle state for user_tokens records.
@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"errors"
+	"fmt"
 	"maps"
 	"strings"
 	"time"
@@ -100,6 +101,7 @@ type RoleEvent struct {
 type PreferenceEvent struct {
 	UserID     uuid.UUID
 	Scope      ScopeFilter
+	Level      PreferenceLevel
 	Key        string
 	Action     string
 	ActorID    uuid.UUID
@@ -121,6 +123,7 @@ type Hooks struct {
 	AfterRoleChange       func(context.Context, RoleEvent)
 	AfterPreferenceChange func(context.Context, PreferenceEvent)
 	AfterProfileChange    func(context.Context, ProfileEvent)
+	AfterContactChange    func(context.Context, ContactChangeEvent)
 	AfterActivity         func(context.Context, ActivityRecord)
 }
 
@@ -196,6 +199,11 @@ type UserInventoryFilter struct {
 	Keyword    string
 	Pagination Pagination
 	UserIDs    []uuid.UUID
+	// ProfileFields keeps users whose custom profile field equals the value.
+	ProfileFields map[string]any
+	// SortProfileField orders users by a custom profile field.
+	SortProfileField string
+	SortDescending   bool
 }
 
 // Type implements gocommand.Message for query inputs.
@@ -324,11 +332,20 @@ type UserProfile struct {
 	Bio         string
 	Contact     map[string]any
 	Metadata    map[string]any
-	Scope       ScopeFilter
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	CreatedBy   uuid.UUID
-	UpdatedBy   uuid.UUID
+	// CustomFields holds values for the tenant's ProfileFieldDefinitions.
+	CustomFields map[string]any
+	Scope        ScopeFilter
+	// Version starts at 1 and increments on every write. Repositories that do
+	// not track versions leave it at 0.
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uuid.UUID
+	UpdatedBy uuid.UUID
+	// ExpectedVersion, when set on a write, makes the write conditional on the
+	// stored version (0 means the profile must not exist yet). It is not
+	// persisted.
+	ExpectedVersion *int
 }
 
 // CanonicalizeLocale normalizes the profile locale into the shared canonical form.
@@ -339,6 +356,18 @@ func (p *UserProfile) CanonicalizeLocale() {
 	p.Locale = NormalizeLocale(p.Locale)
 }
 
+// PublicProfile is the projection of a profile that is safe to show to other
+// users: contact data, metadata, scope, and audit fields are never included.
+type PublicProfile struct {
+	UserID       uuid.UUID      `json:"user_id"`
+	DisplayName  string         `json:"display_name,omitempty"`
+	AvatarURL    string         `json:"avatar_url,omitempty"`
+	Locale       string         `json:"locale,omitempty"`
+	Timezone     string         `json:"timezone,omitempty"`
+	Bio          string         `json:"bio,omitempty"`
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+}
+
 // ProfilePatch represents partial updates applied to a user profile.
 type ProfilePatch struct {
 	DisplayName *string
@@ -348,6 +377,9 @@ type ProfilePatch struct {
 	Bio         *string
 	Contact     map[string]any
 	Metadata    map[string]any
+	// CustomFields is merged into the stored custom fields; a nil value
+	// removes the field.
+	CustomFields map[string]any
 }
 
 // CanonicalizeLocale normalizes the locale patch value in-place when present.
@@ -370,6 +402,66 @@ type ProfileRepository interface {
 	UpsertProfile(ctx context.Context, profile UserProfile) (*UserProfile, error)
 }
 
+// ProfileBatchRepository is an optional extension for repositories that can
+// load the profiles of many users stored for exactly one scope in a single
+// query. Users without a profile in scope are absent from the result.
+type ProfileBatchRepository interface {
+	GetProfiles(ctx context.Context, userIDs []uuid.UUID, scope ScopeFilter) (map[uuid.UUID]*UserProfile, error)
+}
+
+// ProfileHistoryEntry is an append-only snapshot of a profile taken when it
+// was written. Profile.Version identifies the revision.
+type ProfileHistoryEntry struct {
+	ID        uuid.UUID
+	Profile   UserProfile
+	ChangedAt time.Time
+	ChangedBy uuid.UUID
+}
+
+// ProfileHistoryFilter narrows profile history listings. Entries are returned
+// newest first.
+type ProfileHistoryFilter struct {
+	UserID uuid.UUID
+	Scope  ScopeFilter
+	// BeforeVersion returns revisions older than the given version when
+	// non-zero, for paging.
+	BeforeVersion int
+	Since         *time.Time
+	Until         *time.Time
+	Limit         int
+}
+
+// ProfileHistoryRepository is an optional extension for profile repositories
+// that keep an append-only revision log.
+type ProfileHistoryRepository interface {
+	ListProfileHistory(ctx context.Context, filter ProfileHistoryFilter) ([]ProfileHistoryEntry, error)
+}
+
+// ProfileVersionConflictError reports a failed optimistic concurrency check
+// on a profile write. Current holds the stored profile, or nil when it does
+// not exist.
+type ProfileVersionConflictError struct {
+	UserID          uuid.UUID
+	ExpectedVersion int
+	Current         *UserProfile
+}
+
+func (e *ProfileVersionConflictError) Error() string {
+	if e == nil {
+		return ""
+	}
+	current := 0
+	if e.Current != nil {
+		current = e.Current.Version
+	}
+	return fmt.Sprintf("%s: user %s expected version %d, current version %d", ErrProfileVersionConflict.Error(), e.UserID, e.ExpectedVersion, current)
+}
+
+// Is reports ErrProfileVersionConflict so callers can use errors.Is.
+func (e *ProfileVersionConflictError) Is(target error) bool {
+	return target == ErrProfileVersionConflict
+}
+
 // PreferenceLevel identifies the precedence layer for a stored preference.
 type PreferenceLevel string
 
@@ -377,9 +469,22 @@ const (
 	PreferenceLevelSystem PreferenceLevel = "system"
 	PreferenceLevelTenant PreferenceLevel = "tenant"
 	PreferenceLevelOrg    PreferenceLevel = "org"
-	PreferenceLevelUser   PreferenceLevel = "user"
+	// PreferenceLevelRole holds defaults for everyone assigned a custom role.
+	// It resolves between org and user; the role is read from the scope label
+	// PreferenceScopeLabelRole.
+	PreferenceLevelRole PreferenceLevel = "role"
+	PreferenceLevelUser PreferenceLevel = "user"
 )
 
+// PreferenceScopeLabelRole is the ScopeFilter label carrying the role id for
+// role-level preferences.
+const PreferenceScopeLabelRole = "role"
+
+// PreferenceValidator checks preference payloads before they are persisted.
+type PreferenceValidator interface {
+	ValidatePreference(key string, level PreferenceLevel, value map[string]any) error
+}
+
 // PreferenceOutputMode controls how resolved effective values are shaped.
 type PreferenceOutputMode string
 
@@ -413,6 +518,12 @@ type PreferenceRecord struct {
 	UpdatedAt time.Time
 	CreatedBy uuid.UUID
 	UpdatedBy uuid.UUID
+	// Locked stops inner levels (org, user) from overriding the key. It is
+	// honored on system, tenant, and org records only.
+	Locked bool
+	// ExpectedVersion, when set on a write, makes the write conditional on the
+	// stored version (0 means the key must not exist yet). It is not persisted.
+	ExpectedVersion *int
 }
 
 // PreferenceFilter narrows preference listing queries.
@@ -437,6 +548,148 @@ type PreferenceBulkRepository interface {
 	DeleteManyPreferences(ctx context.Context, userID uuid.UUID, scope ScopeFilter, level PreferenceLevel, keys []string, mode PreferenceBulkMode) error
 }
 
+// PreferenceVersionedRepository is an optional extension for repositories that
+// enforce PreferenceRecord.ExpectedVersion on upserts and support conditional
+// deletes. Keys missing from expected are deleted unconditionally.
+type PreferenceVersionedRepository interface {
+	DeletePreferencesIfVersion(ctx context.Context, userID uuid.UUID, scope ScopeFilter, level PreferenceLevel, keys []string, expected map[string]int, mode PreferenceBulkMode) error
+}
+
+// PreferenceHistoryAction names the write captured by a history entry.
+type PreferenceHistoryAction string
+
+const (
+	PreferenceHistoryUpsert PreferenceHistoryAction = "upsert"
+	PreferenceHistoryDelete PreferenceHistoryAction = "delete"
+)
+
+// PreferenceHistoryEntry is an append-only snapshot of a preference record
+// taken when it was written or deleted.
+type PreferenceHistoryEntry struct {
+	ID           uuid.UUID
+	PreferenceID uuid.UUID
+	UserID       uuid.UUID
+	Scope        ScopeFilter
+	Level        PreferenceLevel
+	Key          string
+	Value        map[string]any
+	Version      int
+	Locked       bool
+	Action       PreferenceHistoryAction
+	ChangedAt    time.Time
+	ChangedBy    uuid.UUID
+}
+
+// PreferenceHistoryFilter narrows history listings. Entries are returned
+// newest first.
+type PreferenceHistoryFilter struct {
+	UserID uuid.UUID
+	Scope  ScopeFilter
+	Level  PreferenceLevel
+	Keys   []string
+	// Version matches a single record version when non-zero.
+	Version int
+	Since   *time.Time
+	Until   *time.Time
+	Limit   int
+}
+
+// PreferenceHistoryRepository is an optional extension for repositories that
+// keep an append-only preference history.
+type PreferenceHistoryRepository interface {
+	ListPreferenceHistory(ctx context.Context, filter PreferenceHistoryFilter) ([]PreferenceHistoryEntry, error)
+}
+
+// PreferenceSubscriptionFilter narrows the preference events delivered to a
+// subscriber. Zero fields match everything. Events from outer levels (system,
+// tenant, org, role) match every user inside their scope.
+type PreferenceSubscriptionFilter struct {
+	UserID uuid.UUID
+	Scope  ScopeFilter
+	Keys   []string
+}
+
+// PreferenceEventHandler receives published preference events.
+type PreferenceEventHandler func(context.Context, PreferenceEvent)
+
+// PreferenceEventBus fans preference changes out to subscribers. Implementations
+// backed by an external broker deliver events published by other processes.
+type PreferenceEventBus interface {
+	Publish(ctx context.Context, event PreferenceEvent) error
+	Subscribe(filter PreferenceSubscriptionFilter, handler PreferenceEventHandler) (unsubscribe func(), err error)
+}
+
+// PreferenceInvalidator is implemented by caches that hold preference data and
+// can drop the entries a change affects.
+type PreferenceInvalidator interface {
+	InvalidatePreferences(ctx context.Context, event PreferenceEvent) error
+}
+
+// RoleChangeInvalidator is implemented by caches that depend on role
+// definitions or assignments and can drop the entries a role change affects.
+type RoleChangeInvalidator interface {
+	InvalidateRoleChange(ctx context.Context, event RoleEvent)
+}
+
+type preferenceActorKey struct{}
+
+// WithPreferenceActor attaches the acting user to ctx so repositories can
+// attribute writes whose signatures carry no actor (e.g. deletes).
+func WithPreferenceActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, preferenceActorKey{}, actorID)
+}
+
+// PreferenceActorFromContext returns the actor set by WithPreferenceActor.
+func PreferenceActorFromContext(ctx context.Context) uuid.UUID {
+	if ctx == nil {
+		return uuid.Nil
+	}
+	actorID, _ := ctx.Value(preferenceActorKey{}).(uuid.UUID)
+	return actorID
+}
+
+// PreferenceVersionConflictError reports a failed optimistic concurrency check.
+// Current holds the stored record, or nil when the key does not exist.
+type PreferenceVersionConflictError struct {
+	Key             string
+	ExpectedVersion int
+	Current         *PreferenceRecord
+}
+
+func (e *PreferenceVersionConflictError) Error() string {
+	if e == nil {
+		return ""
+	}
+	current := 0
+	if e.Current != nil {
+		current = e.Current.Version
+	}
+	return fmt.Sprintf("%s: %q expected version %d, current version %d", ErrPreferenceVersionConflict.Error(), e.Key, e.ExpectedVersion, current)
+}
+
+// Is reports ErrPreferenceVersionConflict so callers can use errors.Is.
+func (e *PreferenceVersionConflictError) Is(target error) bool {
+	return target == ErrPreferenceVersionConflict
+}
+
+// PreferenceLockedError reports a write to a key that an outer level locks.
+type PreferenceLockedError struct {
+	Key      string
+	LockedBy PreferenceLevel
+}
+
+func (e *PreferenceLockedError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %q is locked at %s level", ErrPreferenceLocked.Error(), e.Key, e.LockedBy)
+}
+
+// Is reports ErrPreferenceLocked so callers can use errors.Is.
+func (e *PreferenceLockedError) Is(target error) bool {
+	return target == ErrPreferenceLocked
+}
+
 // PreferenceBulkUpsertResult captures a per-key bulk upsert result.
 type PreferenceBulkUpsertResult struct {
 	Key    string
@@ -450,6 +703,44 @@ type PreferenceBulkDeleteResult struct {
 	Err error
 }
 
+// PreferenceConflictPolicy decides how preference imports and copies treat keys
+// that already exist at the target.
+type PreferenceConflictPolicy string
+
+const (
+	// PreferenceConflictSkip keeps existing target values (default).
+	PreferenceConflictSkip PreferenceConflictPolicy = "skip"
+	// PreferenceConflictOverwrite replaces existing target values.
+	PreferenceConflictOverwrite PreferenceConflictPolicy = "overwrite"
+	// PreferenceConflictKeepHigherVersion replaces an existing target value only
+	// when the incoming entry carries a higher version.
+	PreferenceConflictKeepHigherVersion PreferenceConflictPolicy = "keep_higher_version"
+)
+
+// PreferenceExport is the JSON document produced by preference exports and
+// consumed by imports.
+type PreferenceExport struct {
+	Level       PreferenceLevel         `json:"level"`
+	ExportedAt  time.Time               `json:"exported_at"`
+	Preferences []PreferenceExportEntry `json:"preferences"`
+}
+
+// PreferenceExportEntry is one exported preference.
+type PreferenceExportEntry struct {
+	Key     string         `json:"key"`
+	Value   map[string]any `json:"value"`
+	Version int            `json:"version"`
+	Locked  bool           `json:"locked,omitempty"`
+}
+
+// PreferenceTransferResult summarizes a preference import or copy.
+type PreferenceTransferResult struct {
+	// Written lists the per-key results of the keys the transfer wrote.
+	Written []PreferenceBulkUpsertResult
+	// Skipped lists keys left untouched by the conflict policy.
+	Skipped []string
+}
+
 // PreferenceSnapshot depicts the effective settings plus provenance per key.
 type PreferenceSnapshot struct {
 	Effective         map[string]any
@@ -457,10 +748,44 @@ type PreferenceSnapshot struct {
 	Traces            []PreferenceTrace
 }
 
+// PreferenceMergeStrategy controls how a key's values combine across levels.
+type PreferenceMergeStrategy string
+
+const (
+	// PreferenceMergeDeep merges nested maps recursively; arrays and scalars
+	// from inner levels replace outer ones. It is the default.
+	PreferenceMergeDeep PreferenceMergeStrategy = "deep"
+	// PreferenceMergeReplace uses the innermost value as a whole.
+	PreferenceMergeReplace PreferenceMergeStrategy = "replace"
+	// PreferenceMergeAppendUnique concatenates arrays from outer to inner
+	// levels, dropping duplicates and keeping first-seen order.
+	PreferenceMergeAppendUnique PreferenceMergeStrategy = "append_unique"
+	// PreferenceMergeUnion treats arrays as sets and returns their sorted union.
+	PreferenceMergeUnion PreferenceMergeStrategy = "union"
+)
+
 // PreferenceTrace captures how each scope contributed to a key.
 type PreferenceTrace struct {
-	Key    string
-	Layers []PreferenceTraceLayer
+	Key string
+	// LockedBy is the outermost level that locks the key, if any.
+	LockedBy PreferenceLevel
+	// Strategy is the merge strategy applied to the key.
+	Strategy PreferenceMergeStrategy
+	Layers   []PreferenceTraceLayer
+	// Paths lists the level that supplied each leaf of the effective value,
+	// sorted by path.
+	Paths []PreferencePathTrace
+}
+
+// PreferencePathTrace records which level supplied a nested value. Paths use
+// dots for map keys and [i] for array items merged item by item (e.g.
+// "value.channels[1]"). In raw-value output the "value" envelope segment is
+// dropped.
+type PreferencePathTrace struct {
+	Path  string
+	Level PreferenceLevel
+	// RoleID identifies the role layer when Level is PreferenceLevelRole.
+	RoleID uuid.UUID
 }
 
 // PreferenceTraceLayer captures a single scope contribution.
@@ -472,6 +797,14 @@ type PreferenceTraceLayer struct {
 	Value      any
 	Version    int
 	Found      bool
+	// Locked reports that this layer locks the key.
+	Locked bool
+	// Suppressed reports a stored value ignored because an outer layer locks
+	// the key; Value carries it and Found is false.
+	Suppressed bool
+	// RoleID and RoleName identify the role for role-level layers.
+	RoleID   uuid.UUID
+	RoleName string
 }
 
 // ActivityFilter narrows activity feed queries.
@@ -601,6 +934,10 @@ var (
 	ErrMissingActivityRepository = errors.New("go-users: missing activity repository")
 	// ErrMissingProfileRepository occurs when profile commands lack a storage backend.
 	ErrMissingProfileRepository = errors.New("go-users: missing profile repository")
+	// ErrProfileVersionConflict occurs when a profile write's expected version does not match the stored version.
+	ErrProfileVersionConflict = errors.New("go-users: profile version conflict")
+	// ErrProfileHistoryUnsupported indicates the repository does not keep profile history.
+	ErrProfileHistoryUnsupported = errors.New("go-users: profile history is not supported")
 	// ErrMissingPreferenceRepository occurs when preference commands or queries lack storage.
 	ErrMissingPreferenceRepository = errors.New("go-users: missing preference repository")
 	// ErrMissingPreferenceResolver occurs when preference queries lack a resolver.
@@ -613,8 +950,32 @@ var (
 	ErrPreferenceTenantScopeRequired = errors.New("go-users: tenant scope required")
 	// ErrPreferenceOrgScopeRequired occurs when org-level preference calls omit org scope.
 	ErrPreferenceOrgScopeRequired = errors.New("go-users: org scope required")
+	// ErrPreferenceRoleScopeRequired occurs when role-level preference calls omit the role label.
+	ErrPreferenceRoleScopeRequired = errors.New("go-users: role scope required")
 	// ErrUnsupportedPreferenceBulkMode occurs when callers request an unknown bulk mode.
 	ErrUnsupportedPreferenceBulkMode = errors.New("go-users: unsupported preference bulk mode")
 	// ErrPreferenceBulkTransactionalUnsupported indicates the repository cannot guarantee transactional bulk writes.
 	ErrPreferenceBulkTransactionalUnsupported = errors.New("go-users: transactional preference bulk writes are not supported")
+	// ErrUnsupportedPreferenceConflictPolicy occurs when callers request an unknown import or copy conflict policy.
+	ErrUnsupportedPreferenceConflictPolicy = errors.New("go-users: unsupported preference conflict policy")
+	// ErrPreferenceKeyUnknown occurs when a strict preference schema has no definition for the key.
+	ErrPreferenceKeyUnknown = errors.New("go-users: unknown preference key")
+	// ErrPreferenceValueInvalid occurs when a preference value does not satisfy its schema definition.
+	ErrPreferenceValueInvalid = errors.New("go-users: invalid preference value")
+	// ErrPreferenceLevelNotAllowed occurs when a preference is written at a level its definition does not allow.
+	ErrPreferenceLevelNotAllowed = errors.New("go-users: preference level not allowed")
+	// ErrPreferenceVersionConflict occurs when a preference write's expected version does not match the stored version.
+	ErrPreferenceVersionConflict = errors.New("go-users: preference version conflict")
+	// ErrPreferenceLocked occurs when a preference write targets a key locked at an outer level.
+	ErrPreferenceLocked = errors.New("go-users: preference is locked")
+	// ErrPreferenceHistoryUnsupported indicates the repository does not keep preference history.
+	ErrPreferenceHistoryUnsupported = errors.New("go-users: preference history is not supported")
+	// ErrPreferenceHistoryNotFound occurs when no history entry matches a restore request.
+	ErrPreferenceHistoryNotFound = errors.New("go-users: preference history entry not found")
+	// ErrUnsupportedPreferenceMergeStrategy indicates an unknown merge strategy.
+	ErrUnsupportedPreferenceMergeStrategy = errors.New("go-users: unsupported preference merge strategy")
+	// ErrPreferenceVersioningUnsupported indicates the repository cannot enforce expected versions.
+	ErrPreferenceVersioningUnsupported = errors.New("go-users: preference version checks are not supported")
+	// ErrPreferenceCacheTagsUnsupported indicates the preference cache cannot invalidate entries by tag.
+	ErrPreferenceCacheTagsUnsupported = errors.New("go-users: preference cache does not support tag invalidation")
 )
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"maps"
 	"strings"
+	"time"
 
 	repository "github.com/goliatone/go-repository-bun"
 	"github.com/goliatone/go-repository-cache/cache"
@@ -19,8 +20,11 @@ import (
 type RepositoryConfig struct {
 	DB         *bun.DB
 	Repository repository.Repository[*Record]
-	Clock      types.Clock
-	IDGen      types.IDGenerator
+	// HistoryRepository stores the append-only change log. It defaults to a
+	// store built from DB; history is disabled when neither is set.
+	HistoryRepository repository.Repository[*HistoryRecord]
+	Clock             types.Clock
+	IDGen             types.IDGenerator
 }
 
 type preferenceStore interface {
@@ -30,8 +34,14 @@ type preferenceStore interface {
 // Repository implements types.PreferenceRepository.
 type Repository struct {
 	preferenceStore
-	clock types.Clock
-	idGen types.IDGenerator
+	db      *bun.DB
+	history repository.Repository[*HistoryRecord]
+	clock   types.Clock
+	idGen   types.IDGenerator
+	// cached reports a cache decorator; cacheTags is set when its cache
+	// service can invalidate entries by tag.
+	cached    bool
+	cacheTags cache.TagRegistry
 }
 
 // NewRepository constructs the default preference repository.
@@ -57,12 +67,31 @@ func NewRepository(cfg RepositoryConfig, opts ...RepositoryOption) (*Repository,
 			},
 		})
 	}
+	var cacheService cache.CacheService
 	if options.CacheEnabled {
-		cached, err := wrapCachedRepository(repo, options)
+		cached, service, err := wrapCachedRepository(repo, options)
 		if err != nil {
 			return nil, err
 		}
-		repo = cached
+		repo, cacheService = cached, service
+	}
+	cacheTags, _ := cacheService.(cache.TagRegistry)
+	history := cfg.HistoryRepository
+	if history == nil && cfg.DB != nil {
+		history = repository.NewRepository(cfg.DB, repository.ModelHandlers[*HistoryRecord]{
+			NewRecord: func() *HistoryRecord { return &HistoryRecord{} },
+			GetID: func(rec *HistoryRecord) uuid.UUID {
+				if rec == nil {
+					return uuid.Nil
+				}
+				return rec.ID
+			},
+			SetID: func(rec *HistoryRecord, id uuid.UUID) {
+				if rec != nil {
+					rec.ID = id
+				}
+			},
+		})
 	}
 	clock := cfg.Clock
 	if clock == nil {
@@ -75,23 +104,32 @@ func NewRepository(cfg RepositoryConfig, opts ...RepositoryOption) (*Repository,
 
 	return &Repository{
 		preferenceStore: repo,
+		db:              cfg.DB,
+		history:         history,
 		clock:           clock,
 		idGen:           idGen,
+		cached:          isCachedRepository(repo),
+		cacheTags:       cacheTags,
 	}, nil
 }
 
 var (
-	_ repository.Repository[*Record] = (*Repository)(nil)
-	_ types.PreferenceRepository     = (*Repository)(nil)
-	_ types.PreferenceBulkRepository = (*Repository)(nil)
+	_ repository.Repository[*Record]      = (*Repository)(nil)
+	_ types.PreferenceRepository          = (*Repository)(nil)
+	_ types.PreferenceBulkRepository      = (*Repository)(nil)
+	_ types.PreferenceVersionedRepository = (*Repository)(nil)
+	_ types.PreferenceHistoryRepository   = (*Repository)(nil)
+	_ types.PreferenceInvalidator         = (*Repository)(nil)
 )
 
-func wrapCachedRepository(repo repository.Repository[*Record], options RepositoryOptions) (repository.Repository[*Record], error) {
+// wrapCachedRepository decorates repo with the cache and returns the cache
+// service in use (nil for a pre-wrapped repository without WithCacheService).
+func wrapCachedRepository(repo repository.Repository[*Record], options RepositoryOptions) (repository.Repository[*Record], cache.CacheService, error) {
 	if repo == nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 	if isCachedRepository(repo) {
-		return repo, nil
+		return repo, options.CacheService, nil
 	}
 	cacheService := options.CacheService
 	if cacheService == nil {
@@ -102,7 +140,7 @@ func wrapCachedRepository(repo repository.Repository[*Record], options Repositor
 		var err error
 		cacheService, err = cache.NewCacheService(cacheConfig)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	keySerializer := options.CacheKeySerializer
@@ -110,9 +148,9 @@ func wrapCachedRepository(repo repository.Repository[*Record], options Repositor
 		keySerializer = cache.NewDefaultKeySerializer()
 	}
 	if len(options.CacheIdentifierFields) > 0 {
-		return repositorycache.NewWithIdentifierFields(repo, cacheService, keySerializer, options.CacheIdentifierFields...), nil
+		return repositorycache.NewWithIdentifierFields(repo, cacheService, keySerializer, options.CacheIdentifierFields...), cacheService, nil
 	}
-	return repositorycache.New(repo, cacheService, keySerializer), nil
+	return repositorycache.New(repo, cacheService, keySerializer), cacheService, nil
 }
 
 func isCachedRepository(repo repository.Repository[*Record]) bool {
@@ -129,12 +167,16 @@ func (r *Repository) ListPreferences(ctx context.Context, filter types.Preferenc
 	}
 	keys := normalizePreferenceKeys(filter.Keys)
 	ctx = withPreferenceScopeData(ctx, level, ids, keys)
+	if r.cached {
+		ctx = repositorycache.WithCacheTags(ctx, preferenceCacheTag(level, ids))
+	}
 	criteria := []repository.SelectCriteria{
 		func(q *bun.SelectQuery) *bun.SelectQuery {
 			q = q.Where("scope_level = ?", string(level)).
 				Where("user_id = ?", ids.user).
 				Where("tenant_id = ?", ids.tenant).
 				Where("org_id = ?", ids.org).
+				Where("role_id = ?", ids.role).
 				OrderExpr("key ASC")
 			if len(keys) > 0 {
 				q = q.Where("lower(key) IN (?)", bun.List(keys))
@@ -154,8 +196,53 @@ func (r *Repository) ListPreferences(ctx context.Context, filter types.Preferenc
 	return result, nil
 }
 
-// UpsertPreference inserts or updates a scoped preference entry.
+// InvalidatePreferences drops cached list results for the scope the event
+// changed. Writes through this repository already invalidate its cache; use it
+// to apply changes published by other processes.
+func (r *Repository) InvalidatePreferences(ctx context.Context, event types.PreferenceEvent) error {
+	if !r.cached {
+		return nil
+	}
+	if r.cacheTags == nil {
+		return types.ErrPreferenceCacheTagsUnsupported
+	}
+	level := coalesceLevel(event.Level)
+	ids, err := scopeIDs(level, event.UserID, event.Scope)
+	if err != nil {
+		return err
+	}
+	return r.cacheTags.InvalidateTags(ctx, []string{preferenceCacheTag(level, ids)})
+}
+
+func preferenceCacheTag(level types.PreferenceLevel, ids scopeValues) string {
+	return strings.Join([]string{
+		"preferences",
+		string(level),
+		ids.user.String(),
+		ids.tenant.String(),
+		ids.org.String(),
+		ids.role.String(),
+	}, cache.KeySeparator)
+}
+
+// UpsertPreference inserts or updates a scoped preference entry. When
+// record.ExpectedVersion is set the write is applied with a conditional UPDATE
+// and fails with a *types.PreferenceVersionConflictError on mismatch. When
+// history is enabled the write and its history entry share a transaction.
 func (r *Repository) UpsertPreference(ctx context.Context, record types.PreferenceRecord) (*types.PreferenceRecord, error) {
+	var saved *types.PreferenceRecord
+	err := r.inTx(ctx, func(ctx context.Context, tx bun.IDB) error {
+		var err error
+		saved, err = r.upsert(ctx, tx, record)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (r *Repository) upsert(ctx context.Context, tx bun.IDB, record types.PreferenceRecord) (*types.PreferenceRecord, error) {
 	level := coalesceLevel(record.Level)
 	ids, err := scopeIDs(level, record.UserID, record.Scope)
 	if err != nil {
@@ -164,47 +251,99 @@ func (r *Repository) UpsertPreference(ctx context.Context, record types.Preferen
 	keys := normalizePreferenceKeys([]string{record.Key})
 	readCtx := withPreferenceScopeData(ctx, level, ids, keys)
 	writeCtx := withPreferenceScopeData(ctx, level, ids, nil)
+	readTx := tx
+	if record.ExpectedVersion != nil {
+		readTx = r.freshReader(tx)
+	}
 	now := r.clock.Now()
 	payload := fromDomain(record)
 	payload.ScopeLevel = string(level)
 	payload.UserID = ids.user
 	payload.TenantID = ids.tenant
 	payload.OrgID = ids.org
+	payload.RoleID = ids.role
 	payload.Value = cloneMap(payload.Value)
 
-	existing, err := r.findExisting(readCtx, level, ids, record.Key)
-	switch {
-	case err == nil && existing != nil:
+	existing, err := r.findExisting(readCtx, readTx, level, ids, record.Key)
+	if err != nil && !repository.IsRecordNotFound(err) {
+		return nil, err
+	}
+	if record.ExpectedVersion != nil {
+		if conflict := versionConflict(record.Key, *record.ExpectedVersion, existing); conflict != nil {
+			return nil, conflict
+		}
+	}
+	if existing != nil {
 		payload.ID = existing.ID
 		payload.CreatedAt = existing.CreatedAt
 		payload.CreatedBy = existing.CreatedBy
 		payload.Version = existing.Version + 1
 		payload.UpdatedAt = now
-		updated, updateErr := r.Update(writeCtx, payload)
+		var criteria []repository.UpdateCriteria
+		if record.ExpectedVersion != nil {
+			criteria = append(criteria, whereVersion(existing.Version))
+		}
+		updated, updateErr := r.update(writeCtx, tx, payload, criteria...)
 		if updateErr != nil {
+			if record.ExpectedVersion != nil && repository.IsSQLExpectedCountViolation(updateErr) {
+				return nil, r.currentConflict(readCtx, readTx, level, ids, record.Key, *record.ExpectedVersion)
+			}
 			return nil, updateErr
 		}
-		return toDomainPtr(updated), nil
-	case repository.IsRecordNotFound(err):
-		payload.ID = r.idGen.UUID()
-		payload.Version = max(record.Version, 1)
-		payload.CreatedAt = now
-		payload.UpdatedAt = now
-		if payload.CreatedBy == uuid.Nil {
-			payload.CreatedBy = payload.UpdatedBy
+		if err := r.appendHistory(ctx, tx, updated, types.PreferenceHistoryUpsert, now, updated.UpdatedBy); err != nil {
+			return nil, err
 		}
-		created, createErr := r.Create(writeCtx, payload)
-		if createErr != nil {
-			return nil, createErr
+		return toDomainPtr(updated), nil
+	}
+	previous, err := r.latestHistoryVersion(ctx, tx, level, ids, record.Key)
+	if err != nil {
+		return nil, err
+	}
+	payload.ID = r.idGen.UUID()
+	payload.Version = max(record.Version, previous+1)
+	payload.CreatedAt = now
+	payload.UpdatedAt = now
+	if payload.CreatedBy == uuid.Nil {
+		payload.CreatedBy = payload.UpdatedBy
+	}
+	created, createErr := r.create(writeCtx, tx, payload)
+	if createErr != nil {
+		if record.ExpectedVersion != nil && repository.IsDuplicatedKey(createErr) {
+			return nil, r.currentConflict(readCtx, readTx, level, ids, record.Key, *record.ExpectedVersion)
 		}
-		return toDomainPtr(created), nil
-	default:
+		return nil, createErr
+	}
+	if err := r.appendHistory(ctx, tx, created, types.PreferenceHistoryUpsert, now, created.UpdatedBy); err != nil {
 		return nil, err
 	}
+	return toDomainPtr(created), nil
 }
 
-// DeletePreference removes a scoped preference entry.
+// DeletePreference removes a scoped preference entry. The delete is recorded
+// in history, attributed to the actor from types.WithPreferenceActor.
 func (r *Repository) DeletePreference(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, key string) error {
+	return r.inTx(ctx, func(ctx context.Context, tx bun.IDB) error {
+		return r.deleteKey(ctx, tx, userID, scope, level, key, nil)
+	})
+}
+
+// DeletePreferencesIfVersion removes preference entries whose stored version
+// matches expected[key]. The version check is a conditional UPDATE that claims
+// the row before it is deleted. Keys missing from expected are deleted
+// unconditionally.
+func (r *Repository) DeletePreferencesIfVersion(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, keys []string, expected map[string]int, mode types.PreferenceBulkMode) error {
+	mode = coalesceBulkMode(mode)
+	if err := validateBulkMode(mode); err != nil {
+		return err
+	}
+	expectedByKey := make(map[string]int, len(expected))
+	for key, version := range expected {
+		expectedByKey[strings.ToLower(strings.TrimSpace(key))] = version
+	}
+	return r.deleteMany(ctx, userID, scope, level, keys, expectedByKey, mode)
+}
+
+func (r *Repository) deleteKey(ctx context.Context, tx bun.IDB, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, key string, expected *int) error {
 	level = coalesceLevel(level)
 	ids, err := scopeIDs(level, userID, scope)
 	if err != nil {
@@ -213,16 +352,49 @@ func (r *Repository) DeletePreference(ctx context.Context, userID uuid.UUID, sco
 	keys := normalizePreferenceKeys([]string{key})
 	readCtx := withPreferenceScopeData(ctx, level, ids, keys)
 	writeCtx := withPreferenceScopeData(ctx, level, ids, nil)
-	existing, err := r.findExisting(readCtx, level, ids, key)
+	readTx := tx
+	if expected != nil {
+		readTx = r.freshReader(tx)
+	}
+	existing, err := r.findExisting(readCtx, readTx, level, ids, key)
+	if err != nil && !repository.IsRecordNotFound(err) {
+		return err
+	}
+	if expected != nil {
+		if conflict := versionConflict(key, *expected, existing); conflict != nil {
+			return conflict
+		}
+	}
+	if existing == nil {
+		return err
+	}
+	if expected != nil {
+		claim := *existing
+		claim.Version = existing.Version + 1
+		if _, err := r.update(writeCtx, tx, &claim, whereVersion(existing.Version)); err != nil {
+			if repository.IsSQLExpectedCountViolation(err) {
+				return r.currentConflict(readCtx, readTx, level, ids, key, *expected)
+			}
+			return err
+		}
+		existing = &claim
+	}
+	if tx != nil {
+		err = r.DeleteTx(writeCtx, tx, existing)
+	} else {
+		err = r.Delete(writeCtx, existing)
+	}
 	if err != nil {
 		return err
 	}
-	return r.Delete(writeCtx, existing)
+	return r.appendHistory(ctx, tx, existing, types.PreferenceHistoryDelete, r.clock.Now(), types.PreferenceActorFromContext(ctx))
 }
 
 // UpsertManyPreferences inserts or updates multiple scoped preference entries.
 // Best-effort mode applies each record independently and returns aggregated errors.
-// Transactional mode is currently unsupported by this repository.
+// Transactional mode runs every write in one database transaction and rolls
+// back on the first failure, including version conflicts; it requires
+// RepositoryConfig.DB.
 func (r *Repository) UpsertManyPreferences(ctx context.Context, records []types.PreferenceRecord, mode types.PreferenceBulkMode) ([]types.PreferenceRecord, error) {
 	mode = coalesceBulkMode(mode)
 	if err := validateBulkMode(mode); err != nil {
@@ -232,7 +404,25 @@ func (r *Repository) UpsertManyPreferences(ctx context.Context, records []types.
 		return nil, nil
 	}
 	if mode == types.PreferenceBulkModeTransactional {
-		return nil, types.ErrPreferenceBulkTransactionalUnsupported
+		if r.db == nil {
+			return nil, types.ErrPreferenceBulkTransactionalUnsupported
+		}
+		var saved []types.PreferenceRecord
+		err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			saved = make([]types.PreferenceRecord, 0, len(records))
+			for _, record := range records {
+				row, err := r.upsert(ctx, tx, record)
+				if err != nil {
+					return fmt.Errorf("preference %q: %w", record.Key, err)
+				}
+				saved = append(saved, *row)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return saved, nil
 	}
 	saved := make([]types.PreferenceRecord, 0, len(records))
 	var errs []error
@@ -252,22 +442,47 @@ func (r *Repository) UpsertManyPreferences(ctx context.Context, records []types.
 
 // DeleteManyPreferences removes many scoped preference entries.
 // Best-effort mode applies each key independently and returns aggregated errors.
-// Transactional mode is currently unsupported by this repository.
+// Transactional mode deletes every key in one database transaction and
+// requires RepositoryConfig.DB.
 func (r *Repository) DeleteManyPreferences(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, keys []string, mode types.PreferenceBulkMode) error {
 	mode = coalesceBulkMode(mode)
 	if err := validateBulkMode(mode); err != nil {
 		return err
 	}
+	return r.deleteMany(ctx, userID, scope, level, keys, nil, mode)
+}
+
+func (r *Repository) deleteMany(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter, level types.PreferenceLevel, keys []string, expected map[string]int, mode types.PreferenceBulkMode) error {
 	keys = normalizePreferenceKeys(keys)
 	if len(keys) == 0 {
 		return nil
 	}
+	expectedFor := func(key string) *int {
+		version, ok := expected[key]
+		if !ok {
+			return nil
+		}
+		return &version
+	}
 	if mode == types.PreferenceBulkModeTransactional {
-		return types.ErrPreferenceBulkTransactionalUnsupported
+		if r.db == nil {
+			return types.ErrPreferenceBulkTransactionalUnsupported
+		}
+		return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			for _, key := range keys {
+				if err := r.deleteKey(ctx, tx, userID, scope, level, key, expectedFor(key)); err != nil {
+					return fmt.Errorf("preference %q: %w", key, err)
+				}
+			}
+			return nil
+		})
 	}
 	var errs []error
 	for _, key := range keys {
-		if err := r.DeletePreference(ctx, userID, scope, level, key); err != nil {
+		err := r.inTx(ctx, func(ctx context.Context, tx bun.IDB) error {
+			return r.deleteKey(ctx, tx, userID, scope, level, key, expectedFor(key))
+		})
+		if err != nil {
 			errs = append(errs, fmt.Errorf("preference %q: %w", key, err))
 		}
 	}
@@ -277,7 +492,137 @@ func (r *Repository) DeleteManyPreferences(ctx context.Context, userID uuid.UUID
 	return nil
 }
 
-func (r *Repository) findExisting(ctx context.Context, level types.PreferenceLevel, ids scopeValues, key string) (*Record, error) {
+// ListPreferenceHistory returns history entries for one scope level, newest
+// first. It reports types.ErrPreferenceHistoryUnsupported when history is
+// disabled.
+func (r *Repository) ListPreferenceHistory(ctx context.Context, filter types.PreferenceHistoryFilter) ([]types.PreferenceHistoryEntry, error) {
+	if r.history == nil {
+		return nil, types.ErrPreferenceHistoryUnsupported
+	}
+	level := coalesceLevel(filter.Level)
+	ids, err := scopeIDs(level, filter.UserID, filter.Scope)
+	if err != nil {
+		return nil, err
+	}
+	keys := normalizePreferenceKeys(filter.Keys)
+	criteria := []repository.SelectCriteria{
+		func(q *bun.SelectQuery) *bun.SelectQuery {
+			q = q.Where("scope_level = ?", string(level)).
+				Where("user_id = ?", ids.user).
+				Where("tenant_id = ?", ids.tenant).
+				Where("org_id = ?", ids.org).
+				Where("role_id = ?", ids.role).
+				OrderExpr("changed_at DESC, version DESC, action ASC")
+			if len(keys) > 0 {
+				q = q.Where("lower(key) IN (?)", bun.List(keys))
+			}
+			if filter.Version > 0 {
+				q = q.Where("version = ?", filter.Version)
+			}
+			if filter.Since != nil {
+				q = q.Where("changed_at >= ?", *filter.Since)
+			}
+			if filter.Until != nil {
+				q = q.Where("changed_at <= ?", *filter.Until)
+			}
+			if filter.Limit > 0 {
+				q = q.Limit(filter.Limit)
+			}
+			return q
+		},
+	}
+	rows, _, err := r.history.List(ctx, criteria...)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]types.PreferenceHistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, historyToDomain(row))
+	}
+	return entries, nil
+}
+
+// inTx runs fn in a transaction when history is enabled so a write and its
+// history entry commit together. Otherwise fn runs without a transaction.
+func (r *Repository) inTx(ctx context.Context, fn func(ctx context.Context, tx bun.IDB) error) error {
+	if r.history == nil || r.db == nil {
+		return fn(ctx, nil)
+	}
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(ctx, tx)
+	})
+}
+
+func (r *Repository) appendHistory(ctx context.Context, tx bun.IDB, record *Record, action types.PreferenceHistoryAction, changedAt time.Time, changedBy uuid.UUID) error {
+	if r.history == nil || record == nil {
+		return nil
+	}
+	entry := &HistoryRecord{
+		ID:           r.idGen.UUID(),
+		PreferenceID: record.ID,
+		UserID:       record.UserID,
+		TenantID:     record.TenantID,
+		OrgID:        record.OrgID,
+		RoleID:       record.RoleID,
+		ScopeLevel:   record.ScopeLevel,
+		Key:          record.Key,
+		Value:        cloneMap(record.Value),
+		Version:      record.Version,
+		Locked:       record.Locked,
+		Action:       string(action),
+		ChangedAt:    changedAt,
+		ChangedBy:    changedBy,
+	}
+	if entry.Value == nil {
+		entry.Value = map[string]any{}
+	}
+	var err error
+	if tx != nil {
+		_, err = r.history.CreateTx(ctx, tx, entry)
+	} else {
+		_, err = r.history.Create(ctx, entry)
+	}
+	return err
+}
+
+// latestHistoryVersion returns the highest version recorded in history for
+// the key, or zero when history is disabled or the key has none. A key
+// recreated after a delete continues from it so every version in history
+// names exactly one value and restores cannot pick an earlier incarnation.
+func (r *Repository) latestHistoryVersion(ctx context.Context, tx bun.IDB, level types.PreferenceLevel, ids scopeValues, key string) (int, error) {
+	if r.history == nil {
+		return 0, nil
+	}
+	lowerKey := strings.ToLower(strings.TrimSpace(key))
+	criteria := []repository.SelectCriteria{
+		func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("scope_level = ?", string(level)).
+				Where("user_id = ?", ids.user).
+				Where("tenant_id = ?", ids.tenant).
+				Where("org_id = ?", ids.org).
+				Where("role_id = ?", ids.role).
+				Where("lower(key) = ?", lowerKey).
+				OrderExpr("version DESC").
+				Limit(1)
+		},
+	}
+	var (
+		rows []*HistoryRecord
+		err  error
+	)
+	if tx != nil {
+		rows, _, err = r.history.ListTx(ctx, tx, criteria...)
+	} else {
+		rows, _, err = r.history.List(ctx, criteria...)
+	}
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	return rows[0].Version, nil
+}
+
+func (r *Repository) findExisting(ctx context.Context, tx bun.IDB, level types.PreferenceLevel, ids scopeValues, key string) (*Record, error) {
 	lowerKey := strings.ToLower(strings.TrimSpace(key))
 	if lowerKey == "" {
 		return nil, errors.New("preferences: key required")
@@ -290,11 +635,20 @@ func (r *Repository) findExisting(ctx context.Context, level types.PreferenceLev
 				Where("user_id = ?", ids.user).
 				Where("tenant_id = ?", ids.tenant).
 				Where("org_id = ?", ids.org).
+				Where("role_id = ?", ids.role).
 				Where("lower(key) = ?", lowerKey).
 				Limit(1)
 		},
 	}
-	rows, _, err := r.List(ctx, criteria...)
+	var (
+		rows []*Record
+		err  error
+	)
+	if tx != nil {
+		rows, _, err = r.ListTx(ctx, tx, criteria...)
+	} else {
+		rows, _, err = r.List(ctx, criteria...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -304,10 +658,66 @@ func (r *Repository) findExisting(ctx context.Context, level types.PreferenceLev
 	return rows[0], nil
 }
 
+func (r *Repository) update(ctx context.Context, tx bun.IDB, record *Record, criteria ...repository.UpdateCriteria) (*Record, error) {
+	if tx != nil {
+		return r.UpdateTx(ctx, tx, record, criteria...)
+	}
+	return r.Update(ctx, record, criteria...)
+}
+
+func (r *Repository) create(ctx context.Context, tx bun.IDB, record *Record) (*Record, error) {
+	if tx != nil {
+		return r.CreateTx(ctx, tx, record)
+	}
+	return r.Create(ctx, record)
+}
+
+// freshReader returns the handle used for version checks. Reads go straight
+// to the database when possible so a cached row cannot mask a newer version.
+func (r *Repository) freshReader(tx bun.IDB) bun.IDB {
+	if tx != nil || r.db == nil {
+		return tx
+	}
+	return r.db
+}
+
+func (r *Repository) currentConflict(ctx context.Context, tx bun.IDB, level types.PreferenceLevel, ids scopeValues, key string, expected int) error {
+	// A failed re-read (e.g. an aborted transaction) still reports the conflict,
+	// just without the current record.
+	current, _ := r.findExisting(ctx, tx, level, ids, key)
+	conflict := &types.PreferenceVersionConflictError{Key: strings.TrimSpace(key), ExpectedVersion: expected}
+	if current != nil {
+		conflict.Current = toDomainPtr(current)
+	}
+	return conflict
+}
+
+func versionConflict(key string, expected int, existing *Record) error {
+	current := 0
+	if existing != nil {
+		current = existing.Version
+	}
+	if current == expected {
+		return nil
+	}
+	conflict := &types.PreferenceVersionConflictError{Key: strings.TrimSpace(key), ExpectedVersion: expected}
+	if existing != nil {
+		conflict.Current = toDomainPtr(existing)
+	}
+	return conflict
+}
+
+func whereVersion(version int) repository.UpdateCriteria {
+	return func(q *bun.UpdateQuery) *bun.UpdateQuery {
+		return q.Where("version = ?", version)
+	}
+}
+
 type scopeValues struct {
 	user   uuid.UUID
 	tenant uuid.UUID
 	org    uuid.UUID
+	role   uuid.UUID
 }
 
 func scopeIDs(level types.PreferenceLevel, userID uuid.UUID, scope types.ScopeFilter) (scopeValues, error) {
@@ -321,6 +731,16 @@ func scopeIDs(level types.PreferenceLevel, userID uuid.UUID, scope types.ScopeFi
 			tenant: scopeUUID(scope.TenantID),
 			org:    scopeUUID(scope.OrgID),
 		}, nil
+	case types.PreferenceLevelRole:
+		role := scope.Label(types.PreferenceScopeLabelRole)
+		if role == uuid.Nil {
+			return scopeValues{}, types.ErrPreferenceRoleScopeRequired
+		}
+		return scopeValues{
+			tenant: scopeUUID(scope.TenantID),
+			org:    scopeUUID(scope.OrgID),
+			role:   role,
+		}, nil
 	case types.PreferenceLevelOrg:
 		if scope.OrgID == uuid.Nil {
 			return scopeValues{}, types.ErrPreferenceOrgScopeRequired
@@ -389,6 +809,7 @@ func withPreferenceScopeData(ctx context.Context, level types.PreferenceLevel, i
 	ctx = repository.WithScopeData(ctx, "preferences.user_id", ids.user)
 	ctx = repository.WithScopeData(ctx, "preferences.tenant_id", ids.tenant)
 	ctx = repository.WithScopeData(ctx, "preferences.org_id", ids.org)
+	ctx = repository.WithScopeData(ctx, "preferences.role_id", ids.role)
 	if len(keys) > 0 {
 		ctx = repository.WithScopeData(ctx, "preferences.keys", keys)
 	}
@@ -401,10 +822,12 @@ func fromDomain(record types.PreferenceRecord) *Record {
 		UserID:     record.UserID,
 		TenantID:   scopeUUID(record.Scope.TenantID),
 		OrgID:      scopeUUID(record.Scope.OrgID),
+		RoleID:     record.Scope.Label(types.PreferenceScopeLabelRole),
 		ScopeLevel: string(record.Level),
 		Key:        strings.TrimSpace(record.Key),
 		Value:      cloneMap(record.Value),
 		Version:    record.Version,
+		Locked:     record.Locked && lockableLevel(record.Level),
 		CreatedAt:  record.CreatedAt,
 		CreatedBy:  record.CreatedBy,
 		UpdatedAt:  record.UpdatedAt,
@@ -417,16 +840,14 @@ func toDomain(record *Record) types.PreferenceRecord {
 		return types.PreferenceRecord{}
 	}
 	return types.PreferenceRecord{
-		ID:     record.ID,
-		UserID: record.UserID,
-		Scope: types.ScopeFilter{
-			TenantID: record.TenantID,
-			OrgID:    record.OrgID,
-		},
+		ID:        record.ID,
+		UserID:    record.UserID,
+		Scope:     recordScope(record.TenantID, record.OrgID, record.RoleID),
 		Level:     types.PreferenceLevel(record.ScopeLevel),
 		Key:       record.Key,
 		Value:     cloneMap(record.Value),
 		Version:   record.Version,
+		Locked:    record.Locked,
 		CreatedAt: record.CreatedAt,
 		CreatedBy: record.CreatedBy,
 		UpdatedAt: record.UpdatedAt,
@@ -434,11 +855,49 @@ func toDomain(record *Record) types.PreferenceRecord {
 	}
 }
 
+func recordScope(tenantID, orgID, roleID uuid.UUID) types.ScopeFilter {
+	scope := types.ScopeFilter{TenantID: tenantID, OrgID: orgID}
+	if roleID != uuid.Nil {
+		scope = scope.WithLabel(types.PreferenceScopeLabelRole, roleID)
+	}
+	return scope
+}
+
+// lockableLevel reports whether records at level may lock keys.
+func lockableLevel(level types.PreferenceLevel) bool {
+	switch level {
+	case types.PreferenceLevelSystem, types.PreferenceLevelTenant, types.PreferenceLevelOrg:
+		return true
+	default:
+		return false
+	}
+}
+
 func toDomainPtr(record *Record) *types.PreferenceRecord {
 	rec := toDomain(record)
 	return &rec
 }
 
+func historyToDomain(record *HistoryRecord) types.PreferenceHistoryEntry {
+	if record == nil {
+		return types.PreferenceHistoryEntry{}
+	}
+	return types.PreferenceHistoryEntry{
+		ID:           record.ID,
+		PreferenceID: record.PreferenceID,
+		UserID:       record.UserID,
+		Scope:        recordScope(record.TenantID, record.OrgID, record.RoleID),
+		Level:        types.PreferenceLevel(record.ScopeLevel),
+		Key:          record.Key,
+		Value:        cloneMap(record.Value),
+		Version:      record.Version,
+		Locked:       record.Locked,
+		Action:       types.PreferenceHistoryAction(record.Action),
+		ChangedAt:    record.ChangedAt,
+		ChangedBy:    record.ChangedBy,
+	}
+}
+
 // FromPreferenceRecord converts a domain preference record into the Bun model.
 func FromPreferenceRecord(record types.PreferenceRecord) *Record {
 	return fromDomain(record)
@@ -6,7 +6,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	repository "github.com/goliatone/go-repository-bun"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
@@ -167,12 +169,17 @@ func TestPreferenceRepository_BulkBestEffort(t *testing.T) {
 	require.Len(t, afterDelete, 0)
 }
 
-func TestPreferenceRepository_BulkTransactionalUnsupported(t *testing.T) {
+func TestPreferenceRepository_BulkTransactionalRequiresDB(t *testing.T) {
 	ctx := context.Background()
 	db := newTestDB(t)
 	applyDDL(t, db)
 
-	repo, err := NewRepository(RepositoryConfig{DB: db})
+	store := repository.NewRepository(db, repository.ModelHandlers[*Record]{
+		NewRecord: func() *Record { return &Record{} },
+		GetID:     func(rec *Record) uuid.UUID { return rec.ID },
+		SetID:     func(rec *Record, id uuid.UUID) { rec.ID = id },
+	})
+	repo, err := NewRepository(RepositoryConfig{Repository: store})
 	require.NoError(t, err)
 
 	_, err = repo.UpsertManyPreferences(ctx, []types.PreferenceRecord{{
@@ -187,6 +194,95 @@ func TestPreferenceRepository_BulkTransactionalUnsupported(t *testing.T) {
 	require.ErrorIs(t, err, types.ErrPreferenceBulkTransactionalUnsupported)
 }
 
+func TestPreferenceRepository_ExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	userID := uuid.New()
+	record := func(value string, expected int) types.PreferenceRecord {
+		return types.PreferenceRecord{
+			UserID:          userID,
+			Level:           types.PreferenceLevelUser,
+			Key:             "theme",
+			Value:           map[string]any{"value": value},
+			ExpectedVersion: &expected,
+		}
+	}
+
+	created, err := repo.UpsertPreference(ctx, record("light", 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, created.Version)
+
+	_, err = repo.UpsertPreference(ctx, record("dark", 0))
+	var conflict *types.PreferenceVersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.ErrorIs(t, err, types.ErrPreferenceVersionConflict)
+
+	updated, err := repo.UpsertPreference(ctx, record("dark", 1))
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.Version)
+
+	_, err = repo.UpsertPreference(ctx, record("stale", 1))
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, 1, conflict.ExpectedVersion)
+	require.NotNil(t, conflict.Current)
+	require.Equal(t, 2, conflict.Current.Version)
+	require.Equal(t, "dark", conflict.Current.Value["value"])
+
+	err = repo.DeletePreferencesIfVersion(ctx, userID, types.ScopeFilter{}, types.PreferenceLevelUser, []string{"theme"}, map[string]int{"theme": 1}, types.PreferenceBulkModeBestEffort)
+	require.ErrorIs(t, err, types.ErrPreferenceVersionConflict)
+
+	err = repo.DeletePreferencesIfVersion(ctx, userID, types.ScopeFilter{}, types.PreferenceLevelUser, []string{"theme"}, map[string]int{"theme": 2}, types.PreferenceBulkModeBestEffort)
+	require.NoError(t, err)
+	rows, err := repo.ListPreferences(ctx, types.PreferenceFilter{UserID: userID, Level: types.PreferenceLevelUser})
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+func TestPreferenceRepository_TransactionalRollsBackOnConflict(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	userID := uuid.New()
+	_, err = repo.UpsertPreference(ctx, types.PreferenceRecord{
+		UserID: userID,
+		Level:  types.PreferenceLevelUser,
+		Key:    "locale",
+		Value:  map[string]any{"value": "en"},
+	})
+	require.NoError(t, err)
+
+	stale := 5
+	_, err = repo.UpsertManyPreferences(ctx, []types.PreferenceRecord{
+		{UserID: userID, Level: types.PreferenceLevelUser, Key: "theme", Value: map[string]any{"value": "dark"}},
+		{UserID: userID, Level: types.PreferenceLevelUser, Key: "locale", Value: map[string]any{"value": "fr"}, ExpectedVersion: &stale},
+	}, types.PreferenceBulkModeTransactional)
+	require.ErrorIs(t, err, types.ErrPreferenceVersionConflict)
+
+	rows, err := repo.ListPreferences(ctx, types.PreferenceFilter{UserID: userID, Level: types.PreferenceLevelUser})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "en", rows[0].Value["value"])
+
+	saved, err := repo.UpsertManyPreferences(ctx, []types.PreferenceRecord{
+		{UserID: userID, Level: types.PreferenceLevelUser, Key: "theme", Value: map[string]any{"value": "dark"}},
+	}, types.PreferenceBulkModeTransactional)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+
+	err = repo.DeleteManyPreferences(ctx, userID, types.ScopeFilter{}, types.PreferenceLevelUser, []string{"theme", "locale"}, types.PreferenceBulkModeTransactional)
+	require.NoError(t, err)
+	rows, err = repo.ListPreferences(ctx, types.PreferenceFilter{UserID: userID, Level: types.PreferenceLevelUser})
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
 func TestPreferenceRepository_ScopeAndLevelValidationUsesTypedSentinels(t *testing.T) {
 	ctx := context.Background()
 	db := newTestDB(t)
@@ -205,12 +301,156 @@ func TestPreferenceRepository_ScopeAndLevelValidationUsesTypedSentinels(t *testi
 	})
 	require.ErrorIs(t, err, types.ErrPreferenceOrgScopeRequired)
 
+	_, err = repo.ListPreferences(ctx, types.PreferenceFilter{
+		Level: types.PreferenceLevelRole,
+	})
+	require.ErrorIs(t, err, types.ErrPreferenceRoleScopeRequired)
+
 	_, err = repo.ListPreferences(ctx, types.PreferenceFilter{
 		Level: types.PreferenceLevel("invalid"),
 	})
 	require.ErrorIs(t, err, types.ErrUnsupportedPreferenceLevel)
 }
 
+func TestPreferenceRepository_RoleLevelIsKeyedByRole(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+
+	tenantScope := types.ScopeFilter{TenantID: uuid.New()}
+	editorScope := tenantScope.WithLabel(types.PreferenceScopeLabelRole, uuid.New())
+	viewerScope := tenantScope.WithLabel(types.PreferenceScopeLabelRole, uuid.New())
+
+	for _, scope := range []types.ScopeFilter{editorScope, viewerScope} {
+		_, err := repo.UpsertPreference(ctx, types.PreferenceRecord{
+			Scope: scope,
+			Level: types.PreferenceLevelRole,
+			Key:   "dashboard.layout",
+			Value: map[string]any{"role": scope.Label(types.PreferenceScopeLabelRole).String()},
+		})
+		require.NoError(t, err)
+	}
+
+	records, err := repo.ListPreferences(ctx, types.PreferenceFilter{
+		Scope: editorScope,
+		Level: types.PreferenceLevelRole,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, types.PreferenceLevelRole, records[0].Level)
+	require.Equal(t, editorScope.Label(types.PreferenceScopeLabelRole), records[0].Scope.Label(types.PreferenceScopeLabelRole))
+	require.Equal(t, editorScope.Label(types.PreferenceScopeLabelRole).String(), records[0].Value["role"])
+
+	require.NoError(t, repo.DeletePreference(ctx, uuid.Nil, viewerScope, types.PreferenceLevelRole, "dashboard.layout"))
+	records, err = repo.ListPreferences(ctx, types.PreferenceFilter{
+		Scope: editorScope,
+		Level: types.PreferenceLevelRole,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1, "deleting one role's value leaves other roles intact")
+}
+
+func TestPreferenceRepository_HistoryRecordsWritesAndDeletes(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+	clock := &stepClock{at: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+	repo, err := NewRepository(RepositoryConfig{DB: db, Clock: clock})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	actor := uuid.New()
+	for _, theme := range []string{"light", "dark"} {
+		_, err := repo.UpsertPreference(ctx, types.PreferenceRecord{
+			UserID:    userID,
+			Level:     types.PreferenceLevelUser,
+			Key:       "theme",
+			Value:     map[string]any{"value": theme},
+			UpdatedBy: actor,
+		})
+		require.NoError(t, err)
+	}
+	deleter := uuid.New()
+	require.NoError(t, repo.DeletePreference(types.WithPreferenceActor(ctx, deleter), userID, types.ScopeFilter{}, types.PreferenceLevelUser, "Theme"))
+
+	entries, err := repo.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{UserID: userID, Keys: []string{"THEME"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, types.PreferenceHistoryDelete, entries[0].Action)
+	require.Equal(t, deleter, entries[0].ChangedBy)
+	require.Equal(t, "dark", entries[1].Value["value"])
+	require.Equal(t, 2, entries[1].Version)
+	require.Equal(t, actor, entries[2].ChangedBy)
+	require.Equal(t, entries[0].PreferenceID, entries[2].PreferenceID)
+
+	entries, err = repo.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{UserID: userID, Keys: []string{"theme"}, Version: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "light", entries[0].Value["value"])
+
+	until := entries[0].ChangedAt
+	entries, err = repo.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{UserID: userID, Until: &until})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestPreferenceRepository_RecreatedKeyContinuesHistoryVersion(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+	clock := &stepClock{at: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+	repo, err := NewRepository(RepositoryConfig{DB: db, Clock: clock})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	upsert := func(theme string) *types.PreferenceRecord {
+		saved, err := repo.UpsertPreference(ctx, types.PreferenceRecord{
+			UserID: userID,
+			Level:  types.PreferenceLevelUser,
+			Key:    "theme",
+			Value:  map[string]any{"value": theme},
+		})
+		require.NoError(t, err)
+		return saved
+	}
+	upsert("light")
+	upsert("dark")
+	require.NoError(t, repo.DeletePreference(ctx, userID, types.ScopeFilter{}, types.PreferenceLevelUser, "theme"))
+
+	recreated := upsert("blue")
+	require.Equal(t, 3, recreated.Version)
+
+	entries, err := repo.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{UserID: userID, Keys: []string{"theme"}, Version: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "light", entries[0].Value["value"])
+}
+
+func TestPreferenceRepository_HistoryRequiresStore(t *testing.T) {
+	store := repository.NewRepository(newTestDB(t), repository.ModelHandlers[*Record]{
+		NewRecord: func() *Record { return &Record{} },
+		GetID:     func(rec *Record) uuid.UUID { return rec.ID },
+		SetID:     func(rec *Record, id uuid.UUID) { rec.ID = id },
+	})
+	repo, err := NewRepository(RepositoryConfig{Repository: store})
+	require.NoError(t, err)
+	_, err = repo.ListPreferenceHistory(context.Background(), types.PreferenceHistoryFilter{UserID: uuid.New()})
+	require.ErrorIs(t, err, types.ErrPreferenceHistoryUnsupported)
+}
+
+// stepClock advances one minute per reading so history entries sort by time.
+type stepClock struct {
+	at time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	c.at = c.at.Add(time.Minute)
+	return c.at
+}
+
 func newTestDB(t *testing.T) *bun.DB {
 	sqldb, err := sql.Open("sqlite3", ":memory:?cache=shared")
 	require.NoError(t, err)
@@ -224,14 +464,21 @@ func newTestDB(t *testing.T) *bun.DB {
 }
 
 func applyDDL(t *testing.T, db *bun.DB) {
-	content, err := os.ReadFile("../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql")
-	require.NoError(t, err)
-	for _, stmt := range splitStatements(string(content)) {
-		if strings.TrimSpace(stmt) == "" {
-			continue
-		}
-		_, err := db.Exec(stmt)
+	for _, path := range []string{
+		"../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql",
+		"../data/sql/migrations/sqlite/00012_preference_locks.up.sql",
+		"../data/sql/migrations/sqlite/00013_preference_history.up.sql",
+		"../data/sql/migrations/sqlite/00014_preference_roles.up.sql",
+	} {
+		content, err := os.ReadFile(path)
 		require.NoError(t, err)
+		for _, stmt := range splitStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
 	}
 }
 
@@ -184,6 +184,64 @@ func TestPreferenceRepository_DeleteInvalidatesCache(t *testing.T) {
 	require.Equal(t, 1, spy.listCalls)
 }
 
+func TestPreferenceRepository_InvalidateOnEventsDropsRemoteCache(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	// Two repositories with their own caches stand in for two processes.
+	writer, err := NewRepository(RepositoryConfig{DB: db}, WithCache(true))
+	require.NoError(t, err)
+	spy := &spyRecordRepository{Repository: newBaseRecordRepository(db)}
+	reader, err := NewRepository(RepositoryConfig{Repository: spy}, WithCache(true))
+	require.NoError(t, err)
+
+	bus := NewEventBus()
+	unsubscribe, err := InvalidateOnEvents(bus, reader, nil)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	tenantID := uuid.New()
+	filter := types.PreferenceFilter{
+		Scope: types.ScopeFilter{TenantID: tenantID},
+		Level: types.PreferenceLevelTenant,
+	}
+	_, err = reader.ListPreferences(ctx, filter)
+	require.NoError(t, err)
+
+	saved, err := writer.UpsertPreference(ctx, types.PreferenceRecord{
+		Scope: types.ScopeFilter{TenantID: tenantID},
+		Level: types.PreferenceLevelTenant,
+		Key:   "theme",
+		Value: map[string]any{"mode": "dark"},
+	})
+	require.NoError(t, err)
+
+	records, err := reader.ListPreferences(ctx, filter)
+	require.NoError(t, err)
+	require.Empty(t, records, "the reader still serves its cached result")
+
+	require.NoError(t, bus.Publish(ctx, types.PreferenceEvent{
+		Scope: saved.Scope,
+		Level: saved.Level,
+		Key:   saved.Key,
+	}))
+	spy.listCalls = 0
+	records, err = reader.ListPreferences(ctx, filter)
+	require.NoError(t, err)
+	require.Equal(t, 1, spy.listCalls)
+	require.Len(t, records, 1)
+}
+
+func TestPreferenceRepository_InvalidateWithoutCacheIsNoop(t *testing.T) {
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	require.NoError(t, repo.InvalidatePreferences(context.Background(), types.PreferenceEvent{Level: types.PreferenceLevelSystem}))
+}
+
 type spyRecordRepository struct {
 	repository.Repository[*Record]
 	listCalls int
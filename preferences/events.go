@@ -0,0 +1,125 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// EventBus is an in-process types.PreferenceEventBus. Publish delivers events
+// synchronously, in subscription order, to every matching subscriber.
+type EventBus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   []eventSubscription
+}
+
+type eventSubscription struct {
+	id      int
+	filter  types.PreferenceSubscriptionFilter
+	handler types.PreferenceEventHandler
+}
+
+var _ types.PreferenceEventBus = (*EventBus)(nil)
+
+// NewEventBus constructs an empty in-process event bus.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Publish delivers event to the matching subscribers.
+func (b *EventBus) Publish(ctx context.Context, event types.PreferenceEvent) error {
+	b.mu.RLock()
+	subs := append([]eventSubscription(nil), b.subs...)
+	b.mu.RUnlock()
+	for _, sub := range subs {
+		if MatchesSubscription(sub.filter, event) {
+			sub.handler(ctx, event)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers handler for events matching filter. The returned
+// function removes the subscription and is safe to call more than once.
+func (b *EventBus) Subscribe(filter types.PreferenceSubscriptionFilter, handler types.PreferenceEventHandler) (func(), error) {
+	if handler == nil {
+		return nil, errors.New("preferences: event handler required")
+	}
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, eventSubscription{id: id, filter: cloneSubscriptionFilter(filter), handler: handler})
+	b.mu.Unlock()
+	return func() { b.unsubscribe(id) }, nil
+}
+
+func (b *EventBus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, sub := range b.subs {
+		if sub.id == id {
+			b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// MatchesSubscription reports whether event affects a subscriber registered
+// with filter. Events from outer levels match every user in their scope, and
+// role-level events match regardless of the subscriber's roles. External bus
+// implementations can use it to filter deliveries the same way EventBus does.
+func MatchesSubscription(filter types.PreferenceSubscriptionFilter, event types.PreferenceEvent) bool {
+	if filter.UserID != uuid.Nil && event.UserID != uuid.Nil && userScopedEvent(event.Level) && event.UserID != filter.UserID {
+		return false
+	}
+	if !scopeContains(filter.Scope.TenantID, event.Scope.TenantID) || !scopeContains(filter.Scope.OrgID, event.Scope.OrgID) {
+		return false
+	}
+	if len(filter.Keys) == 0 || event.Key == "" {
+		return true
+	}
+	for _, key := range filter.Keys {
+		if strings.EqualFold(strings.TrimSpace(key), event.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateOnEvents subscribes invalidator to every event on bus so caches
+// drop entries changed by any process publishing to it.
+func InvalidateOnEvents(bus types.PreferenceEventBus, invalidator types.PreferenceInvalidator, logger types.Logger) (func(), error) {
+	if bus == nil || invalidator == nil {
+		return func() {}, nil
+	}
+	if logger == nil {
+		logger = types.NopLogger{}
+	}
+	return bus.Subscribe(types.PreferenceSubscriptionFilter{}, func(ctx context.Context, event types.PreferenceEvent) {
+		if err := invalidator.InvalidatePreferences(ctx, event); err != nil {
+			logger.Error("preferences: cache invalidation failed", err, "key", event.Key)
+		}
+	})
+}
+
+// userScopedEvent reports whether events at level only affect their UserID.
+func userScopedEvent(level types.PreferenceLevel) bool {
+	return level == "" || level == types.PreferenceLevelUser
+}
+
+// scopeContains reports whether a subscription scoped to want sees an event
+// scoped to got. Unscoped subscriptions and unscoped events always match.
+func scopeContains(want, got uuid.UUID) bool {
+	return want == uuid.Nil || got == uuid.Nil || want == got
+}
+
+func cloneSubscriptionFilter(filter types.PreferenceSubscriptionFilter) types.PreferenceSubscriptionFilter {
+	filter.Scope = filter.Scope.Clone()
+	filter.Keys = append([]string(nil), filter.Keys...)
+	return filter
+}
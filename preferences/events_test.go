@@ -0,0 +1,51 @@
+package preferences
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBus_DeliversMatchingEvents(t *testing.T) {
+	ctx := context.Background()
+	bus := NewEventBus()
+	userID := uuid.New()
+	tenantID := uuid.New()
+
+	var received []types.PreferenceEvent
+	unsubscribe, err := bus.Subscribe(types.PreferenceSubscriptionFilter{
+		UserID: userID,
+		Scope:  types.ScopeFilter{TenantID: tenantID},
+		Keys:   []string{"Theme"},
+	}, func(_ context.Context, event types.PreferenceEvent) {
+		received = append(received, event)
+	})
+	require.NoError(t, err)
+
+	events := []types.PreferenceEvent{
+		{UserID: userID, Scope: types.ScopeFilter{TenantID: tenantID}, Level: types.PreferenceLevelUser, Key: "theme"},
+		{UserID: uuid.New(), Scope: types.ScopeFilter{TenantID: tenantID}, Level: types.PreferenceLevelUser, Key: "theme"},
+		{UserID: uuid.New(), Scope: types.ScopeFilter{TenantID: tenantID}, Level: types.PreferenceLevelTenant, Key: "theme"},
+		{Scope: types.ScopeFilter{TenantID: uuid.New()}, Level: types.PreferenceLevelTenant, Key: "theme"},
+		{Level: types.PreferenceLevelSystem, Key: "theme"},
+		{UserID: userID, Scope: types.ScopeFilter{TenantID: tenantID}, Level: types.PreferenceLevelUser, Key: "locale"},
+	}
+	for _, event := range events {
+		require.NoError(t, bus.Publish(ctx, event))
+	}
+	require.Len(t, received, 3)
+	require.Equal(t, types.PreferenceLevelUser, received[0].Level)
+	require.Equal(t, types.PreferenceLevelTenant, received[1].Level)
+	require.Equal(t, types.PreferenceLevelSystem, received[2].Level)
+
+	unsubscribe()
+	unsubscribe()
+	require.NoError(t, bus.Publish(ctx, events[0]))
+	require.Len(t, received, 3)
+
+	_, err = bus.Subscribe(types.PreferenceSubscriptionFilter{}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package preferences
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// normalizeMergeStrategy defaults empty strategies to deep merge and rejects
+// unknown values.
+func normalizeMergeStrategy(strategy types.PreferenceMergeStrategy) (types.PreferenceMergeStrategy, error) {
+	switch strategy {
+	case "":
+		return types.PreferenceMergeDeep, nil
+	case types.PreferenceMergeDeep, types.PreferenceMergeReplace, types.PreferenceMergeAppendUnique, types.PreferenceMergeUnion:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", types.ErrUnsupportedPreferenceMergeStrategy, strategy)
+	}
+}
+
+// keyMerge folds one key's layer values from the outermost to the innermost
+// layer and records the layer that supplied each leaf path.
+type keyMerge struct {
+	strategy types.PreferenceMergeStrategy
+	value    any
+	found    bool
+	sources  map[string]resolutionLayer
+}
+
+func newKeyMerge(strategy types.PreferenceMergeStrategy) *keyMerge {
+	return &keyMerge{strategy: strategy, sources: make(map[string]resolutionLayer)}
+}
+
+func (m *keyMerge) add(layer resolutionLayer, value any) {
+	if !m.found || m.strategy == types.PreferenceMergeReplace {
+		m.value = cloneValue(value)
+		m.found = true
+		clear(m.sources)
+		m.record("", m.value, layer)
+		return
+	}
+	m.value = m.merge("", m.value, value, layer)
+}
+
+func (m *keyMerge) merge(path string, base, over any, layer resolutionLayer) any {
+	baseMap, baseIsMap := base.(map[string]any)
+	overMap, overIsMap := over.(map[string]any)
+	if baseIsMap && overIsMap {
+		out := make(map[string]any, len(baseMap)+len(overMap))
+		for key, value := range baseMap {
+			out[key] = value
+		}
+		for key, value := range overMap {
+			child := joinPath(path, key)
+			if existing, ok := out[key]; ok {
+				out[key] = m.merge(child, existing, value, layer)
+				continue
+			}
+			out[key] = cloneValue(value)
+			m.record(child, out[key], layer)
+		}
+		return out
+	}
+	if m.itemized() {
+		baseItems, baseIsList := listItems(base)
+		overItems, overIsList := listItems(over)
+		if baseIsList && overIsList {
+			return m.mergeItems(path, baseItems, overItems, layer)
+		}
+	}
+	m.forget(path)
+	out := cloneValue(over)
+	m.record(path, out, layer)
+	return out
+}
+
+func (m *keyMerge) mergeItems(path string, base, over []any, layer resolutionLayer) []any {
+	type item struct {
+		value any
+		layer resolutionLayer
+	}
+	items := make([]item, 0, len(base)+len(over))
+	for i, value := range base {
+		items = append(items, item{value: value, layer: m.sources[indexPath(path, i)]})
+	}
+	seen := append([]any(nil), base...)
+	for _, value := range over {
+		if containsValue(seen, value) {
+			continue
+		}
+		seen = append(seen, value)
+		items = append(items, item{value: cloneValue(value), layer: layer})
+	}
+	if m.strategy == types.PreferenceMergeUnion {
+		sort.SliceStable(items, func(i, j int) bool {
+			return fmt.Sprint(items[i].value) < fmt.Sprint(items[j].value)
+		})
+	}
+	m.forget(path)
+	out := make([]any, len(items))
+	for i, it := range items {
+		out[i] = it.value
+		m.sources[indexPath(path, i)] = it.layer
+	}
+	return out
+}
+
+// record marks every leaf under path as supplied by layer.
+func (m *keyMerge) record(path string, value any, layer resolutionLayer) {
+	if typed, ok := value.(map[string]any); ok && len(typed) > 0 {
+		for key, child := range typed {
+			m.record(joinPath(path, key), child, layer)
+		}
+		return
+	}
+	if m.itemized() {
+		if items, ok := listItems(value); ok && len(items) > 0 {
+			for i := range items {
+				m.sources[indexPath(path, i)] = layer
+			}
+			return
+		}
+	}
+	m.sources[path] = layer
+}
+
+// forget drops recorded sources at or below path.
+func (m *keyMerge) forget(path string) {
+	for existing := range m.sources {
+		if path == "" || existing == path || strings.HasPrefix(existing, path+".") || strings.HasPrefix(existing, path+"[") {
+			delete(m.sources, existing)
+		}
+	}
+}
+
+func (m *keyMerge) itemized() bool {
+	return m.strategy == types.PreferenceMergeAppendUnique || m.strategy == types.PreferenceMergeUnion
+}
+
+// paths returns the recorded sources sorted by path. Raw-value output drops
+// the "value" envelope segment.
+func (m *keyMerge) paths(outputMode types.PreferenceOutputMode) []types.PreferencePathTrace {
+	if len(m.sources) == 0 {
+		return nil
+	}
+	unwrap := outputMode == types.PreferenceOutputRawValue && isValueEnvelope(m.value)
+	out := make([]types.PreferencePathTrace, 0, len(m.sources))
+	for path, layer := range m.sources {
+		if unwrap {
+			path = strings.TrimPrefix(strings.TrimPrefix(path, "value"), ".")
+		}
+		out = append(out, types.PreferencePathTrace{Path: path, Level: layer.level, RoleID: layer.roleID})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+func isValueEnvelope(value any) bool {
+	typed, ok := value.(map[string]any)
+	if !ok || len(typed) != 1 {
+		return false
+	}
+	_, ok = typed["value"]
+	return ok
+}
+
+func listItems(value any) ([]any, bool) {
+	switch typed := value.(type) {
+	case []any:
+		return typed, true
+	case []string:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			out[i] = item
+		}
+		return out, true
+	}
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range rv.Len() {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+func containsValue(items []any, value any) bool {
+	for _, item := range items {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+func cloneValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for key, child := range typed {
+			out[key] = cloneValue(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, child := range typed {
+			out[i] = cloneValue(child)
+		}
+		return out
+	default:
+		return value
+	}
+}
@@ -15,12 +15,34 @@ type Record struct {
 	UserID     uuid.UUID      `bun:"user_id,type:uuid"`
 	TenantID   uuid.UUID      `bun:"tenant_id,type:uuid"`
 	OrgID      uuid.UUID      `bun:"org_id,type:uuid"`
+	RoleID     uuid.UUID      `bun:"role_id,type:uuid"`
 	ScopeLevel string         `bun:"scope_level"`
 	Key        string         `bun:"key"`
 	Value      map[string]any `bun:"value,type:jsonb"`
 	Version    int            `bun:"version"`
+	Locked     bool           `bun:"locked"`
 	CreatedAt  time.Time      `bun:"created_at"`
 	CreatedBy  uuid.UUID      `bun:"created_by,type:uuid"`
 	UpdatedAt  time.Time      `bun:"updated_at"`
 	UpdatedBy  uuid.UUID      `bun:"updated_by,type:uuid"`
 }
+
+// HistoryRecord models the append-only user_preference_history row.
+type HistoryRecord struct {
+	bun.BaseModel `bun:"table:user_preference_history"`
+
+	ID           uuid.UUID      `bun:"id,pk,type:uuid"`
+	PreferenceID uuid.UUID      `bun:"preference_id,type:uuid"`
+	UserID       uuid.UUID      `bun:"user_id,type:uuid"`
+	TenantID     uuid.UUID      `bun:"tenant_id,type:uuid"`
+	OrgID        uuid.UUID      `bun:"org_id,type:uuid"`
+	RoleID       uuid.UUID      `bun:"role_id,type:uuid"`
+	ScopeLevel   string         `bun:"scope_level"`
+	Key          string         `bun:"key"`
+	Value        map[string]any `bun:"value,type:jsonb"`
+	Version      int            `bun:"version"`
+	Locked       bool           `bun:"locked"`
+	Action       string         `bun:"action"`
+	ChangedAt    time.Time      `bun:"changed_at"`
+	ChangedBy    uuid.UUID      `bun:"changed_by,type:uuid"`
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	i18n "github.com/goliatone/go-i18n"
 	opts "github.com/goliatone/go-options"
@@ -16,12 +19,36 @@ import (
 type ResolverConfig struct {
 	Repository types.PreferenceRepository
 	Defaults   map[string]any
+	// MergeStrategies sets the merge strategy per key (case-insensitive).
+	// Keys without an entry are deep-merged.
+	MergeStrategies map[string]types.PreferenceMergeStrategy
+	// Roles enables the role level: one layer per role assigned to the user.
+	Roles RoleAssignmentSource
 }
 
+// RoleAssignmentSource lists the roles that contribute role-level layers.
+// types.RoleRegistry satisfies it.
+type RoleAssignmentSource interface {
+	ListAssignments(ctx context.Context, filter types.RoleAssignmentFilter) ([]types.RoleAssignment, error)
+	GetRole(ctx context.Context, id uuid.UUID, scope types.ScopeFilter) (*types.RoleDefinition, error)
+}
+
+// RoleBatchSource is implemented by role sources that load many roles in one
+// call. types.RoleRegistry satisfies it through RoleFilter.RoleIDs; other
+// sources fall back to one GetRole call per assigned role.
+type RoleBatchSource interface {
+	ListRoles(ctx context.Context, filter types.RoleFilter) (types.RolePage, error)
+}
+
+// roleBatchSize bounds the role IDs sent in one ListRoles call.
+const roleBatchSize = 200
+
 // Resolver merges scoped preference layers via go-options.
 type Resolver struct {
-	repo     types.PreferenceRepository
-	defaults map[string]any
+	repo       types.PreferenceRepository
+	defaults   map[string]any
+	strategies map[string]types.PreferenceMergeStrategy
+	roles      RoleAssignmentSource
 }
 
 // ResolveInput controls which scopes participate in the resolution process.
@@ -33,6 +60,9 @@ type ResolveInput struct {
 	Base            map[string]any
 	OutputMode      types.PreferenceOutputMode
 	IncludeVersions bool
+	// AsOf rebuilds every layer from preference history as it stood at the
+	// given time. The repository must implement types.PreferenceHistoryRepository.
+	AsOf *time.Time
 }
 
 // NewResolver constructs a preference resolver.
@@ -40,19 +70,40 @@ func NewResolver(cfg ResolverConfig) (*Resolver, error) {
 	if cfg.Repository == nil {
 		return nil, fmt.Errorf("preferences: repository required")
 	}
+	strategies := make(map[string]types.PreferenceMergeStrategy, len(cfg.MergeStrategies))
+	for key, strategy := range cfg.MergeStrategies {
+		normalized, err := normalizeMergeStrategy(strategy)
+		if err != nil {
+			return nil, fmt.Errorf("preferences: key %q: %w", key, err)
+		}
+		strategies[strings.ToLower(strings.TrimSpace(key))] = normalized
+	}
 	return &Resolver{
-		repo:     cfg.Repository,
-		defaults: cloneMap(cfg.Defaults),
+		repo:       cfg.Repository,
+		defaults:   cloneMap(cfg.Defaults),
+		strategies: strategies,
+		roles:      cfg.Roles,
 	}, nil
 }
 
+// strategy returns the merge strategy configured for key.
+func (r *Resolver) strategy(key string) types.PreferenceMergeStrategy {
+	if strategy, ok := r.strategies[strings.ToLower(strings.TrimSpace(key))]; ok {
+		return strategy
+	}
+	return types.PreferenceMergeDeep
+}
+
 // Resolve builds the effective preference snapshot for the supplied scope chain.
 func (r *Resolver) Resolve(ctx context.Context, input ResolveInput) (types.PreferenceSnapshot, error) {
 	outputMode, err := normalizeOutputMode(input.OutputMode)
 	if err != nil {
 		return types.PreferenceSnapshot{}, err
 	}
-	order := filterLevels(resolutionOrder(input.Levels), input)
+	order, err := r.expandLayers(ctx, input, filterLevels(resolutionOrder(input.Levels), input))
+	if err != nil {
+		return types.PreferenceSnapshot{}, err
+	}
 	layerData, err := r.buildResolutionLayers(ctx, input, order)
 	if err != nil {
 		return types.PreferenceSnapshot{}, err
@@ -65,8 +116,15 @@ func (r *Resolver) Resolve(ctx context.Context, input ResolveInput) (types.Prefe
 	if err != nil {
 		return types.PreferenceSnapshot{}, err
 	}
-	effective := transformEffective(cloneMap(merged.Value), outputMode)
-	traces, err := buildTraces(order, input.Keys, layerData.keyRecords, layerData.keyVersions, layerData.scopeMeta, layerData.values, outputMode)
+	merges := r.mergeKeys(order, layerData)
+	value := cloneMap(merged.Value)
+	for key, merge := range merges {
+		if merge.strategy != types.PreferenceMergeDeep && merge.found {
+			value[key] = merge.value
+		}
+	}
+	effective := transformEffective(value, outputMode)
+	traces, err := buildTraces(order, input.Keys, layerData, merges, r.strategy, outputMode)
 	if err != nil {
 		return types.PreferenceSnapshot{}, err
 	}
@@ -81,54 +139,369 @@ func (r *Resolver) Resolve(ctx context.Context, input ResolveInput) (types.Prefe
 	}, nil
 }
 
+// mergeKeys folds every key from the outermost to the innermost layer using
+// its merge strategy. Deep-merge keys keep the go-options result and are
+// folded only to trace nested paths.
+func (r *Resolver) mergeKeys(order []resolutionLayer, data resolutionLayerData) map[string]*keyMerge {
+	layers := append([]resolutionLayer(nil), order...)
+	sort.SliceStable(layers, func(i, j int) bool { return layers[i].priority < layers[j].priority })
+	merges := make(map[string]*keyMerge)
+	for _, layer := range layers {
+		for key, value := range data.values[layer] {
+			merge, ok := merges[key]
+			if !ok {
+				merge = newKeyMerge(r.strategy(key))
+				merges[key] = merge
+			}
+			merge.add(layer, value)
+		}
+	}
+	return merges
+}
+
+// resolutionLayer is one layer of the resolution chain. The role level
+// expands into one layer per assigned role.
+type resolutionLayer struct {
+	level    types.PreferenceLevel
+	roleID   uuid.UUID
+	roleName string
+	priority int
+}
+
+func levelLayer(level types.PreferenceLevel) resolutionLayer {
+	return resolutionLayer{level: level, priority: scopePriority(level)}
+}
+
+// name is the unique go-options scope name of the layer.
+func (l resolutionLayer) name() string {
+	if l.roleID != uuid.Nil {
+		return scopeName(l.level) + ":" + l.roleID.String()
+	}
+	return scopeName(l.level)
+}
+
+// scope returns base with the role label set for role layers.
+func (l resolutionLayer) scope(base types.ScopeFilter) types.ScopeFilter {
+	if l.roleID == uuid.Nil {
+		return base
+	}
+	return base.WithLabel(types.PreferenceScopeLabelRole, l.roleID)
+}
+
+// expandLayers replaces the role level with the user's role layers.
+func (r *Resolver) expandLayers(ctx context.Context, input ResolveInput, levels []types.PreferenceLevel) ([]resolutionLayer, error) {
+	order := make([]resolutionLayer, 0, len(levels))
+	roleCount := 0
+	for _, level := range levels {
+		if level != types.PreferenceLevelRole {
+			order = append(order, levelLayer(level))
+			continue
+		}
+		roles, err := r.roleLayers(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		roleCount += len(roles)
+		order = append(order, roles...)
+	}
+	if roleCount >= opts.ScopePriorityUser-opts.ScopePriorityOrg {
+		rankPriorities(order)
+	}
+	return order, nil
+}
+
+// roleLayers loads a layer for every role assigned to the user, ordered by
+// RoleDefinition.Order (then name and id). A lower Order takes precedence.
+// Layers are returned outermost first and sit between the org and user
+// priorities.
+func (r *Resolver) roleLayers(ctx context.Context, input ResolveInput) ([]resolutionLayer, error) {
+	if r.roles == nil {
+		return nil, nil
+	}
+	assignments, err := r.roles.ListAssignments(ctx, types.RoleAssignmentFilter{
+		Scope:  input.Scope,
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	roles, err := r.loadRoles(ctx, assignments)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(roles, func(i, j int) bool {
+		if roles[i].Order != roles[j].Order {
+			return roles[i].Order < roles[j].Order
+		}
+		if roles[i].Name != roles[j].Name {
+			return roles[i].Name < roles[j].Name
+		}
+		return roles[i].ID.String() < roles[j].ID.String()
+	})
+	layers := make([]resolutionLayer, len(roles))
+	for i, role := range roles {
+		layers[len(roles)-1-i] = resolutionLayer{
+			level:    types.PreferenceLevelRole,
+			roleID:   role.ID,
+			roleName: role.Name,
+			priority: opts.ScopePriorityUser - 1 - i,
+		}
+	}
+	return layers, nil
+}
+
+// loadRoles returns the definitions of the assigned roles, once per role.
+// Roles the source does not return keep the assignment's role name.
+func (r *Resolver) loadRoles(ctx context.Context, assignments []types.RoleAssignment) ([]types.RoleDefinition, error) {
+	seen := make(map[uuid.UUID]bool, len(assignments))
+	unique := make([]types.RoleAssignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		if assignment.RoleID == uuid.Nil || seen[assignment.RoleID] {
+			continue
+		}
+		seen[assignment.RoleID] = true
+		unique = append(unique, assignment)
+	}
+	found, err := r.fetchRoles(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]types.RoleDefinition, 0, len(unique))
+	for _, assignment := range unique {
+		if role, ok := found[assignment.RoleID]; ok {
+			roles = append(roles, role)
+			continue
+		}
+		roles = append(roles, types.RoleDefinition{ID: assignment.RoleID, Name: assignment.RoleName})
+	}
+	return roles, nil
+}
+
+// fetchRoles loads role definitions with one ListRoles call per assignment
+// scope and batch when the source supports it, and with GetRole otherwise.
+func (r *Resolver) fetchRoles(ctx context.Context, assignments []types.RoleAssignment) (map[uuid.UUID]types.RoleDefinition, error) {
+	found := make(map[uuid.UUID]types.RoleDefinition, len(assignments))
+	batch, ok := r.roles.(RoleBatchSource)
+	if !ok {
+		for _, assignment := range assignments {
+			role, err := r.roles.GetRole(ctx, assignment.RoleID, assignment.Scope)
+			if err != nil {
+				return nil, err
+			}
+			if role != nil {
+				found[assignment.RoleID] = *role
+			}
+		}
+		return found, nil
+	}
+
+	type scopeKey struct {
+		tenant uuid.UUID
+		org    uuid.UUID
+	}
+	scopes := make([]scopeKey, 0, 1)
+	ids := make(map[scopeKey][]uuid.UUID)
+	for _, assignment := range assignments {
+		key := scopeKey{tenant: assignment.Scope.TenantID, org: assignment.Scope.OrgID}
+		if _, ok := ids[key]; !ok {
+			scopes = append(scopes, key)
+		}
+		ids[key] = append(ids[key], assignment.RoleID)
+	}
+	for _, scope := range scopes {
+		for chunk := range slices.Chunk(ids[scope], roleBatchSize) {
+			page, err := batch.ListRoles(ctx, types.RoleFilter{
+				Scope:         types.ScopeFilter{TenantID: scope.tenant, OrgID: scope.org},
+				RoleIDs:       chunk,
+				IncludeSystem: true,
+				Pagination:    types.Pagination{Limit: len(chunk)},
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, role := range page.Roles {
+				found[role.ID] = role
+			}
+		}
+	}
+	return found, nil
+}
+
+// rankPriorities renumbers every layer by its rank when the roles do not fit
+// between the org and user priorities. Layers keep their relative order:
+// by level priority, with role layers after the org level in the order
+// roleLayers returned them.
+func rankPriorities(order []resolutionLayer) {
+	type rankKey struct {
+		base int
+		sub  int
+	}
+	keys := make([]rankKey, len(order))
+	roleIndex := 0
+	for i, layer := range order {
+		if layer.roleID == uuid.Nil {
+			keys[i] = rankKey{base: layer.priority}
+			continue
+		}
+		roleIndex++
+		keys[i] = rankKey{base: opts.ScopePriorityOrg, sub: roleIndex}
+	}
+	indexes := make([]int, len(order))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		ka, kb := keys[indexes[a]], keys[indexes[b]]
+		if ka.base != kb.base {
+			return ka.base < kb.base
+		}
+		return ka.sub < kb.sub
+	})
+	for rank, index := range indexes {
+		order[index].priority = rank + 1
+	}
+}
+
 type resolutionLayerData struct {
 	layers      []opts.Layer[map[string]any]
-	scopeMeta   map[types.PreferenceLevel]scopeValues
-	keyRecords  map[types.PreferenceLevel]map[string]uuid.UUID
-	keyVersions map[types.PreferenceLevel]map[string]int
-	values      map[types.PreferenceLevel]map[string]any
+	scopeMeta   map[resolutionLayer]scopeValues
+	keyRecords  map[resolutionLayer]map[string]uuid.UUID
+	keyVersions map[resolutionLayer]map[string]int
+	values      map[resolutionLayer]map[string]any
+	// keyLocks lists keys locked by each layer; lockedBy maps a key to the
+	// outermost level that locks it.
+	keyLocks map[resolutionLayer]map[string]bool
+	lockedBy map[string]types.PreferenceLevel
+	// suppressed holds values ignored because an outer level locks the key.
+	suppressed map[resolutionLayer]map[string]any
 }
 
-func (r *Resolver) buildResolutionLayers(ctx context.Context, input ResolveInput, order []types.PreferenceLevel) (resolutionLayerData, error) {
+func (r *Resolver) buildResolutionLayers(ctx context.Context, input ResolveInput, order []resolutionLayer) (resolutionLayerData, error) {
 	data := resolutionLayerData{
 		layers:      make([]opts.Layer[map[string]any], 0, len(order)),
-		scopeMeta:   make(map[types.PreferenceLevel]scopeValues, len(order)),
-		keyRecords:  make(map[types.PreferenceLevel]map[string]uuid.UUID, len(order)),
-		keyVersions: make(map[types.PreferenceLevel]map[string]int, len(order)),
-		values:      make(map[types.PreferenceLevel]map[string]any, len(order)),
-	}
-	for _, level := range order {
-		if err := r.appendResolutionLayer(ctx, input, level, &data); err != nil {
+		scopeMeta:   make(map[resolutionLayer]scopeValues, len(order)),
+		keyRecords:  make(map[resolutionLayer]map[string]uuid.UUID, len(order)),
+		keyVersions: make(map[resolutionLayer]map[string]int, len(order)),
+		values:      make(map[resolutionLayer]map[string]any, len(order)),
+		keyLocks:    make(map[resolutionLayer]map[string]bool, len(order)),
+		lockedBy:    make(map[string]types.PreferenceLevel),
+		suppressed:  make(map[resolutionLayer]map[string]any, len(order)),
+	}
+	for _, layer := range order {
+		if err := r.appendResolutionLayer(ctx, input, layer, &data); err != nil {
 			return resolutionLayerData{}, err
 		}
 	}
+	data.applyLocks(order)
+	for _, layer := range order {
+		data.layers = append(data.layers, newResolutionLayer(layer, cloneMap(data.values[layer]), data.scopeMeta[layer]))
+	}
 	return data, nil
 }
 
-func (r *Resolver) appendResolutionLayer(ctx context.Context, input ResolveInput, level types.PreferenceLevel, data *resolutionLayerData) error {
-	recs, err := r.repo.ListPreferences(ctx, types.PreferenceFilter{
-		UserID: input.UserID,
-		Scope:  input.Scope,
-		Level:  level,
-		Keys:   input.Keys,
-	})
+// applyLocks removes keys locked at an outer level from every inner level so
+// user (or org) values cannot override an enforced setting.
+func (d *resolutionLayerData) applyLocks(order []resolutionLayer) {
+	for i, level := range order {
+		for key := range d.keyLocks[level] {
+			if _, held := d.lockedBy[key]; held {
+				continue
+			}
+			d.lockedBy[key] = level.level
+			for _, inner := range order[i+1:] {
+				value, ok := d.values[inner][key]
+				if !ok {
+					continue
+				}
+				delete(d.values[inner], key)
+				if d.suppressed[inner] == nil {
+					d.suppressed[inner] = make(map[string]any)
+				}
+				d.suppressed[inner][key] = value
+			}
+		}
+	}
+}
+
+func (r *Resolver) appendResolutionLayer(ctx context.Context, input ResolveInput, layer resolutionLayer, data *resolutionLayerData) error {
+	recs, err := r.listLayerRecords(ctx, input, layer)
 	if err != nil {
 		return err
 	}
-	snapshot, idMap, versionMap := snapshotFromRecords(recs)
-	payload := resolutionPayload(level, snapshot, r.defaults, input.Base)
-	meta, err := scopeIDs(level, input.UserID, input.Scope)
+	snapshot, idMap, versionMap, locks := snapshotFromRecords(recs)
+	payload := resolutionPayload(layer.level, snapshot, r.defaults, input.Base)
+	meta, err := scopeIDs(layer.level, input.UserID, layer.scope(input.Scope))
 	if err != nil {
 		return err
 	}
-	data.keyRecords[level] = idMap
-	data.keyVersions[level] = versionMap
-	data.scopeMeta[level] = meta
-	data.values[level] = cloneMap(payload)
-	data.layers = append(data.layers, newResolutionLayer(level, payload, meta))
+	data.keyRecords[layer] = idMap
+	data.keyVersions[layer] = versionMap
+	data.scopeMeta[layer] = meta
+	data.values[layer] = cloneMap(payload)
+	if lockableLevel(layer.level) {
+		data.keyLocks[layer] = locks
+	}
 	return nil
 }
 
+func (r *Resolver) listLayerRecords(ctx context.Context, input ResolveInput, layer resolutionLayer) ([]types.PreferenceRecord, error) {
+	level, scope := layer.level, layer.scope(input.Scope)
+	if input.AsOf == nil {
+		return r.repo.ListPreferences(ctx, types.PreferenceFilter{
+			UserID: input.UserID,
+			Scope:  scope,
+			Level:  level,
+			Keys:   input.Keys,
+		})
+	}
+	history, ok := r.repo.(types.PreferenceHistoryRepository)
+	if !ok {
+		return nil, types.ErrPreferenceHistoryUnsupported
+	}
+	asOf := *input.AsOf
+	entries, err := history.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{
+		UserID: input.UserID,
+		Scope:  scope,
+		Level:  level,
+		Keys:   input.Keys,
+		Until:  &asOf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recordsFromHistory(entries), nil
+}
+
+// recordsFromHistory keeps the newest entry per key (entries arrive newest
+// first) and drops keys whose latest entry is a delete.
+func recordsFromHistory(entries []types.PreferenceHistoryEntry) []types.PreferenceRecord {
+	seen := make(map[string]bool, len(entries))
+	records := make([]types.PreferenceRecord, 0, len(entries))
+	for _, entry := range entries {
+		lower := strings.ToLower(entry.Key)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		if entry.Action == types.PreferenceHistoryDelete {
+			continue
+		}
+		records = append(records, types.PreferenceRecord{
+			ID:        entry.PreferenceID,
+			UserID:    entry.UserID,
+			Scope:     entry.Scope,
+			Level:     entry.Level,
+			Key:       entry.Key,
+			Value:     cloneMap(entry.Value),
+			Version:   entry.Version,
+			Locked:    entry.Locked,
+			UpdatedAt: entry.ChangedAt,
+			UpdatedBy: entry.ChangedBy,
+		})
+	}
+	return records
+}
+
 func resolutionPayload(level types.PreferenceLevel, snapshot, defaults, base map[string]any) map[string]any {
 	payload := snapshot
 	if level == types.PreferenceLevelSystem {
@@ -141,9 +514,13 @@ func resolutionPayload(level types.PreferenceLevel, snapshot, defaults, base map
 	return normalizeLocalePreferencePayload(payload)
 }
 
-func newResolutionLayer(level types.PreferenceLevel, payload map[string]any, meta scopeValues) opts.Layer[map[string]any] {
-	scope := opts.NewScope(scopeName(level), scopePriority(level),
-		opts.WithScopeLabel(scopeLabel(level)),
+func newResolutionLayer(layer resolutionLayer, payload map[string]any, meta scopeValues) opts.Layer[map[string]any] {
+	label := scopeLabel(layer.level)
+	if layer.roleName != "" {
+		label = layer.roleName
+	}
+	scope := opts.NewScope(layer.name(), layer.priority,
+		opts.WithScopeLabel(label),
 		opts.WithScopeMetadata(scopeMetadata(meta)))
 	return opts.NewLayer(scope, payload, opts.WithSnapshotID[map[string]any](scope.Name))
 }
@@ -156,6 +533,7 @@ func resolutionOrder(levels []types.PreferenceLevel) []types.PreferenceLevel {
 		types.PreferenceLevelSystem,
 		types.PreferenceLevelTenant,
 		types.PreferenceLevelOrg,
+		types.PreferenceLevelRole,
 		types.PreferenceLevelUser,
 	}
 }
@@ -164,7 +542,7 @@ func filterLevels(levels []types.PreferenceLevel, input ResolveInput) []types.Pr
 	filtered := make([]types.PreferenceLevel, 0, len(levels))
 	for _, level := range levels {
 		switch level {
-		case types.PreferenceLevelUser:
+		case types.PreferenceLevelUser, types.PreferenceLevelRole:
 			if input.UserID == uuid.Nil {
 				continue
 			}
@@ -185,19 +563,26 @@ func filterLevels(levels []types.PreferenceLevel, input ResolveInput) []types.Pr
 	return filtered
 }
 
-func snapshotFromRecords(records []types.PreferenceRecord) (map[string]any, map[string]uuid.UUID, map[string]int) {
+func snapshotFromRecords(records []types.PreferenceRecord) (map[string]any, map[string]uuid.UUID, map[string]int, map[string]bool) {
 	if len(records) == 0 {
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 	values := make(map[string]any, len(records))
 	index := make(map[string]uuid.UUID, len(records))
 	versions := make(map[string]int, len(records))
+	var locks map[string]bool
 	for _, rec := range records {
 		values[rec.Key] = cloneMap(rec.Value)
 		index[rec.Key] = rec.ID
 		versions[rec.Key] = rec.Version
+		if rec.Locked {
+			if locks == nil {
+				locks = make(map[string]bool)
+			}
+			locks[rec.Key] = true
+		}
 	}
-	return values, index, versions
+	return values, index, versions, locks
 }
 
 func mergeMaps(base map[string]any, overlay map[string]any) map[string]any {
@@ -220,6 +605,8 @@ func scopeName(level types.PreferenceLevel) string {
 		return "tenant"
 	case types.PreferenceLevelOrg:
 		return "org"
+	case types.PreferenceLevelRole:
+		return "role"
 	case types.PreferenceLevelUser:
 		return "user"
 	default:
@@ -233,6 +620,8 @@ func scopeLabel(level types.PreferenceLevel) string {
 		return "Tenant"
 	case types.PreferenceLevelOrg:
 		return "Organization"
+	case types.PreferenceLevelRole:
+		return "Role"
 	case types.PreferenceLevelUser:
 		return "User"
 	default:
@@ -259,10 +648,14 @@ func scopeMetadata(values scopeValues) map[string]any {
 		"tenant_id": values.tenant.String(),
 		"org_id":    values.org.String(),
 	}
+	if values.role != uuid.Nil {
+		meta["role_id"] = values.role.String()
+	}
 	return meta
 }
 
-func buildTraces(order []types.PreferenceLevel, keys []string, keyRecords map[types.PreferenceLevel]map[string]uuid.UUID, keyVersions map[types.PreferenceLevel]map[string]int, scopes map[types.PreferenceLevel]scopeValues, values map[types.PreferenceLevel]map[string]any, outputMode types.PreferenceOutputMode) ([]types.PreferenceTrace, error) {
+func buildTraces(order []resolutionLayer, keys []string, data resolutionLayerData, merges map[string]*keyMerge, strategy func(string) types.PreferenceMergeStrategy, outputMode types.PreferenceOutputMode) ([]types.PreferenceTrace, error) {
+	keyRecords, keyVersions, scopes, values := data.keyRecords, data.keyVersions, data.scopeMeta, data.values
 	keySet := make(map[string]struct{})
 	for _, key := range keys {
 		if strings.TrimSpace(key) == "" {
@@ -276,6 +669,11 @@ func buildTraces(order []types.PreferenceLevel, keys []string, keyRecords map[ty
 				keySet[key] = struct{}{}
 			}
 		}
+		for _, levelValues := range data.suppressed {
+			for key := range levelValues {
+				keySet[key] = struct{}{}
+			}
+		}
 	}
 	traces := make([]types.PreferenceTrace, 0, len(keySet))
 	for key := range keySet {
@@ -283,11 +681,14 @@ func buildTraces(order []types.PreferenceLevel, keys []string, keyRecords map[ty
 		for _, level := range order {
 			scopeVals := scopes[level]
 			layer := types.PreferenceTraceLayer{
-				Level:      level,
+				Level:      level.level,
 				UserID:     scopeVals.user,
 				Scope:      types.ScopeFilter{TenantID: scopeVals.tenant, OrgID: scopeVals.org},
+				RoleID:     level.roleID,
+				RoleName:   level.roleName,
 				SnapshotID: lookupSnapshotID(level, keyRecords, key),
 				Version:    lookupVersion(level, keyVersions, key),
+				Locked:     data.keyLocks[level][key],
 			}
 			if levelValues := values[level]; levelValues != nil {
 				if v, ok := levelValues[key]; ok {
@@ -295,17 +696,27 @@ func buildTraces(order []types.PreferenceLevel, keys []string, keyRecords map[ty
 					layer.Found = true
 				}
 			}
+			if v, ok := data.suppressed[level][key]; ok {
+				layer.Value = transformValue(v, outputMode)
+				layer.Suppressed = true
+			}
 			layers = append(layers, layer)
 		}
-		traces = append(traces, types.PreferenceTrace{
-			Key:    key,
-			Layers: layers,
-		})
+		trace := types.PreferenceTrace{
+			Key:      key,
+			LockedBy: data.lockedBy[key],
+			Strategy: strategy(key),
+			Layers:   layers,
+		}
+		if merge, ok := merges[key]; ok {
+			trace.Paths = merge.paths(outputMode)
+		}
+		traces = append(traces, trace)
 	}
 	return traces, nil
 }
 
-func lookupSnapshotID(level types.PreferenceLevel, keyRecords map[types.PreferenceLevel]map[string]uuid.UUID, key string) string {
+func lookupSnapshotID(level resolutionLayer, keyRecords map[resolutionLayer]map[string]uuid.UUID, key string) string {
 	if records := keyRecords[level]; records != nil {
 		if id, ok := records[key]; ok {
 			return id.String()
@@ -314,7 +725,7 @@ func lookupSnapshotID(level types.PreferenceLevel, keyRecords map[types.Preferen
 	return ""
 }
 
-func lookupVersion(level types.PreferenceLevel, keyVersions map[types.PreferenceLevel]map[string]int, key string) int {
+func lookupVersion(level resolutionLayer, keyVersions map[resolutionLayer]map[string]int, key string) int {
 	if versions := keyVersions[level]; versions != nil {
 		return versions[key]
 	}
@@ -2,7 +2,9 @@ package preferences
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/google/uuid"
@@ -203,12 +205,334 @@ func TestResolver_ResolveRejectsUnknownOutputMode(t *testing.T) {
 	require.ErrorIs(t, err, types.ErrUnsupportedPreferenceOutputMode)
 }
 
+func TestResolver_LockedKeysIgnoreInnerLayers(t *testing.T) {
+	userID := uuid.New()
+	tenantID := uuid.New()
+	orgID := uuid.New()
+	scope := types.ScopeFilter{TenantID: tenantID, OrgID: orgID}
+	repo := &fakePreferenceRepo{
+		values: map[types.PreferenceLevel][]types.PreferenceRecord{
+			types.PreferenceLevelTenant: {
+				{ID: uuid.New(), Scope: scope, Level: types.PreferenceLevelTenant, Key: "locale", Value: map[string]any{"value": "de"}, Version: 2, Locked: true},
+				{ID: uuid.New(), Scope: scope, Level: types.PreferenceLevelTenant, Key: "theme", Value: map[string]any{"value": "light"}},
+			},
+			types.PreferenceLevelOrg: {
+				{ID: uuid.New(), Scope: scope, Level: types.PreferenceLevelOrg, Key: "locale", Value: map[string]any{"value": "fr"}, Locked: true},
+			},
+			types.PreferenceLevelUser: {
+				{ID: uuid.New(), UserID: userID, Scope: scope, Level: types.PreferenceLevelUser, Key: "locale", Value: map[string]any{"value": "en"}, Version: 5},
+				{ID: uuid.New(), UserID: userID, Scope: scope, Level: types.PreferenceLevelUser, Key: "theme", Value: map[string]any{"value": "dark"}, Locked: true},
+			},
+		},
+	}
+	resolver, err := NewResolver(ResolverConfig{Repository: repo})
+	require.NoError(t, err)
+
+	snapshot, err := resolver.Resolve(context.Background(), ResolveInput{
+		UserID:          userID,
+		Scope:           scope,
+		OutputMode:      types.PreferenceOutputRawValue,
+		IncludeVersions: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "de", snapshot.Effective["locale"])
+	require.Equal(t, 2, snapshot.EffectiveVersions["locale"])
+	require.Equal(t, "dark", snapshot.Effective["theme"], "user-level locks are ignored")
+
+	var trace types.PreferenceTrace
+	for _, candidate := range snapshot.Traces {
+		if candidate.Key == "locale" {
+			trace = candidate
+		}
+	}
+	require.Equal(t, types.PreferenceLevelTenant, trace.LockedBy)
+	for _, layer := range trace.Layers {
+		switch layer.Level {
+		case types.PreferenceLevelTenant:
+			require.True(t, layer.Locked)
+			require.True(t, layer.Found)
+		case types.PreferenceLevelOrg, types.PreferenceLevelUser:
+			require.True(t, layer.Suppressed)
+			require.False(t, layer.Found)
+		}
+	}
+	require.Equal(t, "en", trace.Layers[len(trace.Layers)-1].Value)
+}
+
+func TestResolver_MergeStrategies(t *testing.T) {
+	userID := uuid.New()
+	tenantID := uuid.New()
+	scope := types.ScopeFilter{TenantID: tenantID}
+	repo := &fakePreferenceRepo{
+		values: map[types.PreferenceLevel][]types.PreferenceRecord{
+			types.PreferenceLevelTenant: {
+				{Scope: scope, Level: types.PreferenceLevelTenant, Key: "dashboard", Value: map[string]any{"layout": map[string]any{"columns": 2, "dense": true}}},
+				{Scope: scope, Level: types.PreferenceLevelTenant, Key: "channels", Value: map[string]any{"value": []any{"email", "sms"}}},
+				{Scope: scope, Level: types.PreferenceLevelTenant, Key: "tags", Value: map[string]any{"value": []any{"b", "c"}}},
+				{Scope: scope, Level: types.PreferenceLevelTenant, Key: "filters", Value: map[string]any{"status": "open", "owner": "me"}},
+			},
+			types.PreferenceLevelUser: {
+				{UserID: userID, Scope: scope, Level: types.PreferenceLevelUser, Key: "dashboard", Value: map[string]any{"layout": map[string]any{"columns": 3}}},
+				{UserID: userID, Scope: scope, Level: types.PreferenceLevelUser, Key: "channels", Value: map[string]any{"value": []any{"sms", "push"}}},
+				{UserID: userID, Scope: scope, Level: types.PreferenceLevelUser, Key: "tags", Value: map[string]any{"value": []any{"a", "c"}}},
+				{UserID: userID, Scope: scope, Level: types.PreferenceLevelUser, Key: "filters", Value: map[string]any{"status": "closed"}},
+			},
+		},
+	}
+	resolver, err := NewResolver(ResolverConfig{
+		Repository: repo,
+		MergeStrategies: map[string]types.PreferenceMergeStrategy{
+			"Channels": types.PreferenceMergeAppendUnique,
+			"tags":     types.PreferenceMergeUnion,
+			"filters":  types.PreferenceMergeReplace,
+		},
+	})
+	require.NoError(t, err)
+
+	snapshot, err := resolver.Resolve(context.Background(), ResolveInput{UserID: userID, Scope: scope, OutputMode: types.PreferenceOutputRawValue})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"layout": map[string]any{"columns": 3, "dense": true}}, snapshot.Effective["dashboard"])
+	require.Equal(t, []any{"email", "sms", "push"}, snapshot.Effective["channels"])
+	require.Equal(t, []any{"a", "b", "c"}, snapshot.Effective["tags"])
+	require.Equal(t, map[string]any{"status": "closed"}, snapshot.Effective["filters"])
+
+	traces := make(map[string]types.PreferenceTrace, len(snapshot.Traces))
+	for _, trace := range snapshot.Traces {
+		traces[trace.Key] = trace
+	}
+	require.Equal(t, types.PreferenceMergeDeep, traces["dashboard"].Strategy)
+	require.Equal(t, []types.PreferencePathTrace{
+		{Path: "layout.columns", Level: types.PreferenceLevelUser},
+		{Path: "layout.dense", Level: types.PreferenceLevelTenant},
+	}, traces["dashboard"].Paths)
+	require.Equal(t, []types.PreferencePathTrace{
+		{Path: "[0]", Level: types.PreferenceLevelTenant},
+		{Path: "[1]", Level: types.PreferenceLevelTenant},
+		{Path: "[2]", Level: types.PreferenceLevelUser},
+	}, traces["channels"].Paths)
+	require.Equal(t, types.PreferenceLevelUser, traces["tags"].Paths[0].Level)
+	require.Equal(t, []types.PreferencePathTrace{{Path: "status", Level: types.PreferenceLevelUser}}, traces["filters"].Paths)
+
+	_, err = NewResolver(ResolverConfig{Repository: repo, MergeStrategies: map[string]types.PreferenceMergeStrategy{"x": "zip"}})
+	require.ErrorIs(t, err, types.ErrUnsupportedPreferenceMergeStrategy)
+}
+
+func TestResolver_AsOfRebuildsLayersFromHistory(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+	repo, err := NewRepository(RepositoryConfig{DB: db, Clock: &stepClock{at: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}})
+	require.NoError(t, err)
+	resolver, err := NewResolver(ResolverConfig{Repository: repo})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	write := func(key, value string) time.Time {
+		saved, err := repo.UpsertPreference(ctx, types.PreferenceRecord{UserID: userID, Key: key, Value: map[string]any{"value": value}})
+		require.NoError(t, err)
+		return saved.UpdatedAt
+	}
+	write("theme", "light")
+	checkpoint := write("locale", "en")
+	write("theme", "dark")
+	require.NoError(t, repo.DeletePreference(ctx, userID, types.ScopeFilter{}, types.PreferenceLevelUser, "locale"))
+
+	input := ResolveInput{UserID: userID, OutputMode: types.PreferenceOutputRawValue, IncludeVersions: true}
+	current, err := resolver.Resolve(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"theme": "dark"}, current.Effective)
+
+	input.AsOf = &checkpoint
+	past, err := resolver.Resolve(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"theme": "light", "locale": "en"}, past.Effective)
+	require.Equal(t, 1, past.EffectiveVersions["theme"])
+
+	fake, err := NewResolver(ResolverConfig{Repository: &fakePreferenceRepo{}})
+	require.NoError(t, err)
+	_, err = fake.Resolve(ctx, input)
+	require.ErrorIs(t, err, types.ErrPreferenceHistoryUnsupported)
+}
+
+func TestResolver_RoleLayersFollowRoleOrder(t *testing.T) {
+	userID := uuid.New()
+	tenantID := uuid.New()
+	scope := types.ScopeFilter{TenantID: tenantID}
+	editor := types.RoleDefinition{ID: uuid.New(), Name: "editor", Order: 20}
+	admin := types.RoleDefinition{ID: uuid.New(), Name: "admin", Order: 10}
+	roleRecord := func(role types.RoleDefinition, value map[string]any) types.PreferenceRecord {
+		return types.PreferenceRecord{
+			ID:    uuid.New(),
+			Scope: scope.WithLabel(types.PreferenceScopeLabelRole, role.ID),
+			Level: types.PreferenceLevelRole,
+			Key:   "dashboard",
+			Value: value,
+		}
+	}
+	adminRecord := roleRecord(admin, map[string]any{"layout": "admin", "widgets": "all"})
+	repo := &fakePreferenceRepo{
+		values: map[types.PreferenceLevel][]types.PreferenceRecord{
+			types.PreferenceLevelTenant: {
+				{ID: uuid.New(), Scope: scope, Level: types.PreferenceLevelTenant, Key: "dashboard", Value: map[string]any{"layout": "tenant", "density": "compact"}},
+			},
+			types.PreferenceLevelRole: {
+				roleRecord(editor, map[string]any{"layout": "editor", "widgets": "drafts", "sidebar": true}),
+				adminRecord,
+			},
+		},
+	}
+	roles := &fakeRoleSource{
+		roles:       map[uuid.UUID]types.RoleDefinition{editor.ID: editor, admin.ID: admin},
+		assignments: []types.RoleAssignment{{UserID: userID, RoleID: editor.ID, Scope: scope}, {UserID: userID, RoleID: admin.ID, Scope: scope}},
+	}
+	resolver, err := NewResolver(ResolverConfig{Repository: repo, Roles: roles})
+	require.NoError(t, err)
+
+	snapshot, err := resolver.Resolve(context.Background(), ResolveInput{
+		UserID: userID,
+		Scope:  scope,
+		Keys:   []string{"dashboard"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"layout":  "admin",
+		"widgets": "all",
+		"sidebar": true,
+		"density": "compact",
+	}, snapshot.Effective["dashboard"])
+
+	require.Len(t, snapshot.Traces, 1)
+	trace := snapshot.Traces[0]
+	levels := make([]types.PreferenceLevel, 0, len(trace.Layers))
+	for _, layer := range trace.Layers {
+		levels = append(levels, layer.Level)
+	}
+	require.Equal(t, []types.PreferenceLevel{
+		types.PreferenceLevelSystem,
+		types.PreferenceLevelTenant,
+		types.PreferenceLevelRole,
+		types.PreferenceLevelRole,
+		types.PreferenceLevelUser,
+	}, levels)
+	require.Equal(t, editor.ID, trace.Layers[2].RoleID)
+	require.Equal(t, "editor", trace.Layers[2].RoleName)
+	require.Equal(t, admin.ID, trace.Layers[3].RoleID)
+	require.Equal(t, adminRecord.ID.String(), trace.Layers[3].SnapshotID)
+
+	sources := make(map[string]types.PreferencePathTrace, len(trace.Paths))
+	for _, path := range trace.Paths {
+		sources[path.Path] = path
+	}
+	require.Equal(t, admin.ID, sources["layout"].RoleID)
+	require.Equal(t, editor.ID, sources["sidebar"].RoleID)
+	require.Equal(t, types.PreferenceLevelTenant, sources["density"].Level)
+	require.Equal(t, uuid.Nil, sources["density"].RoleID)
+}
+
+func TestResolver_RoleLayersBatchLoadManyRoles(t *testing.T) {
+	userID := uuid.New()
+	scope := types.ScopeFilter{TenantID: uuid.New()}
+	roles := &batchRoleSource{fakeRoleSource: &fakeRoleSource{roles: map[uuid.UUID]types.RoleDefinition{}}}
+	repo := &fakePreferenceRepo{values: map[types.PreferenceLevel][]types.PreferenceRecord{
+		types.PreferenceLevelTenant: {
+			{ID: uuid.New(), Scope: scope, Level: types.PreferenceLevelTenant, Key: "theme", Value: map[string]any{"value": "tenant"}},
+		},
+	}}
+	var winner types.RoleDefinition
+	for i := range 250 {
+		role := types.RoleDefinition{ID: uuid.New(), Name: fmt.Sprintf("role-%03d", i), Order: 1000 - i}
+		roles.roles[role.ID] = role
+		roles.assignments = append(roles.assignments, types.RoleAssignment{UserID: userID, RoleID: role.ID, Scope: scope})
+		repo.values[types.PreferenceLevelRole] = append(repo.values[types.PreferenceLevelRole], types.PreferenceRecord{
+			ID:    uuid.New(),
+			Scope: scope.WithLabel(types.PreferenceScopeLabelRole, role.ID),
+			Level: types.PreferenceLevelRole,
+			Key:   "theme",
+			Value: map[string]any{"value": role.Name},
+		})
+		winner = role
+	}
+	resolver, err := NewResolver(ResolverConfig{Repository: repo, Roles: roles})
+	require.NoError(t, err)
+
+	snapshot, err := resolver.Resolve(context.Background(), ResolveInput{
+		UserID: userID,
+		Scope:  scope,
+		Keys:   []string{"theme"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"value": winner.Name}, snapshot.Effective["theme"])
+	require.Zero(t, roles.getCalls)
+	require.Equal(t, 2, roles.listCalls)
+
+	layers := snapshot.Traces[0].Layers
+	require.Equal(t, types.PreferenceLevelTenant, layers[1].Level)
+	require.Equal(t, types.PreferenceLevelRole, layers[2].Level)
+	require.Equal(t, winner.ID, layers[len(layers)-2].RoleID)
+	require.Equal(t, types.PreferenceLevelUser, layers[len(layers)-1].Level)
+}
+
+type batchRoleSource struct {
+	*fakeRoleSource
+	listCalls int
+	getCalls  int
+}
+
+func (b *batchRoleSource) GetRole(ctx context.Context, id uuid.UUID, scope types.ScopeFilter) (*types.RoleDefinition, error) {
+	b.getCalls++
+	return b.fakeRoleSource.GetRole(ctx, id, scope)
+}
+
+func (b *batchRoleSource) ListRoles(_ context.Context, filter types.RoleFilter) (types.RolePage, error) {
+	b.listCalls++
+	page := types.RolePage{}
+	for _, id := range filter.RoleIDs {
+		if role, ok := b.roles[id]; ok {
+			page.Roles = append(page.Roles, role)
+		}
+	}
+	return page, nil
+}
+
+type fakeRoleSource struct {
+	roles       map[uuid.UUID]types.RoleDefinition
+	assignments []types.RoleAssignment
+}
+
+func (f *fakeRoleSource) ListAssignments(_ context.Context, filter types.RoleAssignmentFilter) ([]types.RoleAssignment, error) {
+	out := make([]types.RoleAssignment, 0, len(f.assignments))
+	for _, assignment := range f.assignments {
+		if assignment.UserID == filter.UserID {
+			out = append(out, assignment)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRoleSource) GetRole(_ context.Context, id uuid.UUID, _ types.ScopeFilter) (*types.RoleDefinition, error) {
+	role, ok := f.roles[id]
+	if !ok {
+		return nil, nil
+	}
+	return &role, nil
+}
+
 type fakePreferenceRepo struct {
 	values map[types.PreferenceLevel][]types.PreferenceRecord
 }
 
 func (f *fakePreferenceRepo) ListPreferences(_ context.Context, filter types.PreferenceFilter) ([]types.PreferenceRecord, error) {
 	records := f.values[filter.Level]
+	if filter.Level == types.PreferenceLevelRole {
+		roleID := filter.Scope.Label(types.PreferenceScopeLabelRole)
+		scoped := make([]types.PreferenceRecord, 0, len(records))
+		for _, record := range records {
+			if record.Scope.Label(types.PreferenceScopeLabelRole) == roleID {
+				scoped = append(scoped, record)
+			}
+		}
+		records = scoped
+	}
 	if len(filter.Keys) == 0 {
 		return append([]types.PreferenceRecord(nil), records...), nil
 	}
@@ -0,0 +1,404 @@
+package preferences
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// ValueType is the JSON-Schema type a preference value must have.
+type ValueType string
+
+const (
+	ValueTypeString  ValueType = "string"
+	ValueTypeNumber  ValueType = "number"
+	ValueTypeInteger ValueType = "integer"
+	ValueTypeBoolean ValueType = "boolean"
+	ValueTypeObject  ValueType = "object"
+	ValueTypeArray   ValueType = "array"
+)
+
+// KeyDefinition declares the shape and constraints of a preference key.
+type KeyDefinition struct {
+	Key         string
+	Title       string
+	Description string
+	Type        ValueType
+	// ItemType constrains array elements when Type is array.
+	ItemType ValueType
+	// Levels lists the levels the key may be written at (all when empty).
+	Levels  []types.PreferenceLevel
+	Default any
+	// Enum restricts scalar values (or array items) to the listed values.
+	Enum      []any
+	Minimum   *float64
+	Maximum   *float64
+	MinLength *int
+	MaxLength *int
+	// Merge sets how values combine across levels (deep merge when empty).
+	Merge types.PreferenceMergeStrategy
+}
+
+// AllowsLevel reports whether the key may be written at level.
+func (d KeyDefinition) AllowsLevel(level types.PreferenceLevel) bool {
+	return len(d.Levels) == 0 || slices.Contains(d.Levels, level)
+}
+
+// JSONSchema renders the definition as a JSON-Schema fragment. Allowed levels
+// and merge strategies are exposed through the x-levels and x-merge
+// extensions.
+func (d KeyDefinition) JSONSchema() map[string]any {
+	out := map[string]any{"type": string(d.Type)}
+	if d.Title != "" {
+		out["title"] = d.Title
+	}
+	if d.Description != "" {
+		out["description"] = d.Description
+	}
+	if d.Default != nil {
+		out["default"] = d.Default
+	}
+	enum := []any(nil)
+	if len(d.Enum) > 0 {
+		enum = append(enum, d.Enum...)
+	}
+	if d.Type == ValueTypeArray {
+		items := map[string]any{}
+		if d.ItemType != "" {
+			items["type"] = string(d.ItemType)
+		}
+		if enum != nil {
+			items["enum"] = enum
+		}
+		out["items"] = items
+	} else if enum != nil {
+		out["enum"] = enum
+	}
+	if d.Minimum != nil {
+		out["minimum"] = *d.Minimum
+	}
+	if d.Maximum != nil {
+		out["maximum"] = *d.Maximum
+	}
+	lengthMin, lengthMax := "minLength", "maxLength"
+	if d.Type == ValueTypeArray {
+		lengthMin, lengthMax = "minItems", "maxItems"
+	}
+	if d.MinLength != nil {
+		out[lengthMin] = *d.MinLength
+	}
+	if d.MaxLength != nil {
+		out[lengthMax] = *d.MaxLength
+	}
+	if d.Merge != "" {
+		out["x-merge"] = string(d.Merge)
+	}
+	if len(d.Levels) > 0 {
+		levels := make([]string, 0, len(d.Levels))
+		for _, level := range d.Levels {
+			levels = append(levels, string(level))
+		}
+		out["x-levels"] = levels
+	}
+	return out
+}
+
+// SchemaOption customizes a schema registry.
+type SchemaOption func(*SchemaRegistry)
+
+// WithStrictKeys rejects writes for keys without a definition. By default
+// unknown keys pass through unvalidated.
+func WithStrictKeys() SchemaOption {
+	return func(r *SchemaRegistry) {
+		if r != nil {
+			r.strict = true
+		}
+	}
+}
+
+// SchemaRegistry holds preference key definitions and validates payloads
+// against them. It implements types.PreferenceValidator.
+type SchemaRegistry struct {
+	mu     sync.RWMutex
+	defs   map[string]KeyDefinition
+	strict bool
+}
+
+var _ types.PreferenceValidator = (*SchemaRegistry)(nil)
+
+// NewSchemaRegistry constructs an empty registry.
+func NewSchemaRegistry(opts ...SchemaOption) *SchemaRegistry {
+	reg := &SchemaRegistry{defs: make(map[string]KeyDefinition)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(reg)
+		}
+	}
+	return reg
+}
+
+// Register adds or replaces a definition. The default value must satisfy the
+// definition itself.
+func (r *SchemaRegistry) Register(defs ...KeyDefinition) error {
+	for _, def := range defs {
+		def.Key = strings.TrimSpace(def.Key)
+		if def.Key == "" {
+			return errors.New("preferences: schema key required")
+		}
+		if !knownValueType(def.Type) {
+			return fmt.Errorf("preferences: schema %q: unsupported type %q", def.Key, def.Type)
+		}
+		if def.ItemType != "" && !knownValueType(def.ItemType) {
+			return fmt.Errorf("preferences: schema %q: unsupported item type %q", def.Key, def.ItemType)
+		}
+		if _, err := normalizeMergeStrategy(def.Merge); err != nil {
+			return fmt.Errorf("preferences: schema %q: %w", def.Key, err)
+		}
+		if def.Default != nil {
+			if err := validateValue(def, def.Default); err != nil {
+				return fmt.Errorf("preferences: schema %q default: %w", def.Key, err)
+			}
+		}
+		r.mu.Lock()
+		r.defs[strings.ToLower(def.Key)] = def
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// MustRegister is Register that panics on invalid definitions.
+func (r *SchemaRegistry) MustRegister(defs ...KeyDefinition) *SchemaRegistry {
+	if err := r.Register(defs...); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Definition returns the definition for key.
+func (r *SchemaRegistry) Definition(key string) (KeyDefinition, bool) {
+	if r == nil {
+		return KeyDefinition{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.defs[strings.ToLower(strings.TrimSpace(key))]
+	return def, ok
+}
+
+// Definitions returns every definition sorted by key.
+func (r *SchemaRegistry) Definitions() []KeyDefinition {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]KeyDefinition, 0, len(r.defs))
+	for _, def := range r.defs {
+		out = append(out, def)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Defaults returns stored-payload shaped defaults suitable for
+// ResolverConfig.Defaults: objects as-is, other values as {"value": ...}.
+func (r *SchemaRegistry) Defaults() map[string]any {
+	defs := r.Definitions()
+	out := make(map[string]any, len(defs))
+	for _, def := range defs {
+		if def.Default == nil {
+			continue
+		}
+		if obj, ok := def.Default.(map[string]any); ok && def.Type == ValueTypeObject {
+			out[def.Key] = cloneMap(obj)
+			continue
+		}
+		out[def.Key] = map[string]any{"value": def.Default}
+	}
+	return out
+}
+
+// MergeStrategies returns the merge strategies declared by definitions,
+// suitable for ResolverConfig.MergeStrategies.
+func (r *SchemaRegistry) MergeStrategies() map[string]types.PreferenceMergeStrategy {
+	out := make(map[string]types.PreferenceMergeStrategy)
+	for _, def := range r.Definitions() {
+		if def.Merge != "" {
+			out[def.Key] = def.Merge
+		}
+	}
+	return out
+}
+
+// ValidatePreference implements types.PreferenceValidator. Payloads shaped as
+// {"value": ...} are validated on the wrapped value; object keys may also be
+// stored unwrapped.
+func (r *SchemaRegistry) ValidatePreference(key string, level types.PreferenceLevel, value map[string]any) error {
+	def, ok := r.Definition(key)
+	if !ok {
+		if r != nil && r.strict {
+			return fmt.Errorf("%w: %q", types.ErrPreferenceKeyUnknown, key)
+		}
+		return nil
+	}
+	if !def.AllowsLevel(level) {
+		return fmt.Errorf("%w: %q at %s level", types.ErrPreferenceLevelNotAllowed, def.Key, level)
+	}
+	raw, wrapped := value["value"]
+	if !wrapped || len(value) != 1 {
+		if def.Type != ValueTypeObject {
+			return fmt.Errorf("%w: %q: expected {\"value\": ...} payload", types.ErrPreferenceValueInvalid, def.Key)
+		}
+		raw = map[string]any(value)
+	}
+	if err := validateValue(def, raw); err != nil {
+		return fmt.Errorf("%w: %q: %s", types.ErrPreferenceValueInvalid, def.Key, err.Error())
+	}
+	return nil
+}
+
+func validateValue(def KeyDefinition, value any) error {
+	if value == nil {
+		return errors.New("value is null")
+	}
+	if err := checkType(def.Type, value); err != nil {
+		return err
+	}
+	switch def.Type {
+	case ValueTypeString:
+		if err := checkLength(def, len([]rune(value.(string)))); err != nil {
+			return err
+		}
+	case ValueTypeNumber, ValueTypeInteger:
+		num, _ := toFloat(value)
+		if def.Minimum != nil && num < *def.Minimum {
+			return fmt.Errorf("%v is below minimum %v", value, *def.Minimum)
+		}
+		if def.Maximum != nil && num > *def.Maximum {
+			return fmt.Errorf("%v is above maximum %v", value, *def.Maximum)
+		}
+	case ValueTypeArray:
+		items := reflect.ValueOf(value)
+		if err := checkLength(def, items.Len()); err != nil {
+			return err
+		}
+		for i := range items.Len() {
+			item := items.Index(i).Interface()
+			if def.ItemType != "" {
+				if err := checkType(def.ItemType, item); err != nil {
+					return fmt.Errorf("item %d: %w", i, err)
+				}
+			}
+			if len(def.Enum) > 0 && !enumContains(def.Enum, item) {
+				return fmt.Errorf("item %d: %v is not one of %v", i, item, def.Enum)
+			}
+		}
+		return nil
+	}
+	if len(def.Enum) > 0 && def.Type != ValueTypeObject && !enumContains(def.Enum, value) {
+		return fmt.Errorf("%v is not one of %v", value, def.Enum)
+	}
+	return nil
+}
+
+func checkType(expected ValueType, value any) error {
+	if value == nil {
+		return fmt.Errorf("expected %s, got null", expected)
+	}
+	ok := false
+	switch expected {
+	case ValueTypeString:
+		_, ok = value.(string)
+	case ValueTypeBoolean:
+		_, ok = value.(bool)
+	case ValueTypeNumber:
+		_, ok = toFloat(value)
+	case ValueTypeInteger:
+		num, isNum := toFloat(value)
+		ok = isNum && num == math.Trunc(num)
+	case ValueTypeObject:
+		_, ok = value.(map[string]any)
+	case ValueTypeArray:
+		kind := reflect.TypeOf(value).Kind()
+		ok = kind == reflect.Slice || kind == reflect.Array
+	}
+	if !ok {
+		return fmt.Errorf("expected %s, got %T", expected, value)
+	}
+	return nil
+}
+
+func checkLength(def KeyDefinition, length int) error {
+	if def.MinLength != nil && length < *def.MinLength {
+		return fmt.Errorf("length %d is below minimum %d", length, *def.MinLength)
+	}
+	if def.MaxLength != nil && length > *def.MaxLength {
+		return fmt.Errorf("length %d is above maximum %d", length, *def.MaxLength)
+	}
+	return nil
+}
+
+func toFloat(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case float32:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case int8:
+		return float64(typed), true
+	case int16:
+		return float64(typed), true
+	case int32:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint:
+		return float64(typed), true
+	case uint8:
+		return float64(typed), true
+	case uint16:
+		return float64(typed), true
+	case uint32:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case json.Number:
+		num, err := typed.Float64()
+		return num, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+		left, leftNum := toFloat(candidate)
+		right, rightNum := toFloat(value)
+		if leftNum && rightNum && left == right {
+			return true
+		}
+	}
+	return false
+}
+
+func knownValueType(t ValueType) bool {
+	switch t {
+	case ValueTypeString, ValueTypeNumber, ValueTypeInteger, ValueTypeBoolean, ValueTypeObject, ValueTypeArray:
+		return true
+	default:
+		return false
+	}
+}
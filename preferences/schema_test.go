@@ -0,0 +1,129 @@
+package preferences
+
+import (
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSchema(t *testing.T, opts ...SchemaOption) *SchemaRegistry {
+	t.Helper()
+	minSize, maxSize := 10.0, 24.0
+	maxLen := 3
+	reg := NewSchemaRegistry(opts...)
+	require.NoError(t, reg.Register(
+		KeyDefinition{
+			Key:         "theme",
+			Type:        ValueTypeString,
+			Default:     "light",
+			Enum:        []any{"light", "dark"},
+			Description: "UI color theme",
+		},
+		KeyDefinition{Key: "font_size", Type: ValueTypeInteger, Default: 14, Minimum: &minSize, Maximum: &maxSize},
+		KeyDefinition{Key: "beta", Type: ValueTypeBoolean, Levels: []types.PreferenceLevel{types.PreferenceLevelSystem, types.PreferenceLevelTenant}},
+		KeyDefinition{Key: "channels", Type: ValueTypeArray, ItemType: ValueTypeString, Enum: []any{"email", "sms"}, MaxLength: &maxLen},
+		KeyDefinition{Key: "layout", Type: ValueTypeObject, Default: map[string]any{"sidebar": true}},
+	))
+	return reg
+}
+
+func TestSchemaRegistry_ValidatePreference(t *testing.T) {
+	reg := newTestSchema(t)
+	user := types.PreferenceLevelUser
+
+	cases := []struct {
+		name  string
+		key   string
+		level types.PreferenceLevel
+		value map[string]any
+		err   error
+	}{
+		{"valid enum", "theme", user, map[string]any{"value": "dark"}, nil},
+		{"wrong type", "theme", user, map[string]any{"value": 42}, types.ErrPreferenceValueInvalid},
+		{"outside enum", "theme", user, map[string]any{"value": "blue"}, types.ErrPreferenceValueInvalid},
+		{"missing envelope", "theme", user, map[string]any{"theme": "dark"}, types.ErrPreferenceValueInvalid},
+		{"integer from json float", "font_size", user, map[string]any{"value": float64(16)}, nil},
+		{"fractional integer", "font_size", user, map[string]any{"value": 16.5}, types.ErrPreferenceValueInvalid},
+		{"below minimum", "font_size", user, map[string]any{"value": 2}, types.ErrPreferenceValueInvalid},
+		{"level not allowed", "beta", user, map[string]any{"value": true}, types.ErrPreferenceLevelNotAllowed},
+		{"level allowed", "beta", types.PreferenceLevelTenant, map[string]any{"value": true}, nil},
+		{"array items", "channels", user, map[string]any{"value": []any{"email", "sms"}}, nil},
+		{"array bad item", "channels", user, map[string]any{"value": []any{"pager"}}, types.ErrPreferenceValueInvalid},
+		{"array too long", "channels", user, map[string]any{"value": []string{"email", "sms", "email", "sms"}}, types.ErrPreferenceValueInvalid},
+		{"object unwrapped", "layout", user, map[string]any{"sidebar": false}, nil},
+		{"object wrapped", "layout", user, map[string]any{"value": map[string]any{"sidebar": false}}, nil},
+		{"object wrong type", "layout", user, map[string]any{"value": "compact"}, types.ErrPreferenceValueInvalid},
+		{"unknown key passes", "other", user, map[string]any{"value": 1}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := reg.ValidatePreference(tc.key, tc.level, tc.value)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	strict := newTestSchema(t, WithStrictKeys())
+	require.ErrorIs(t, strict.ValidatePreference("other", user, map[string]any{"value": 1}), types.ErrPreferenceKeyUnknown)
+}
+
+func TestSchemaRegistry_RegisterRejectsInvalidDefinitions(t *testing.T) {
+	reg := NewSchemaRegistry()
+	require.Error(t, reg.Register(KeyDefinition{Key: "", Type: ValueTypeString}))
+	require.Error(t, reg.Register(KeyDefinition{Key: "x", Type: "date"}))
+	require.Error(t, reg.Register(KeyDefinition{Key: "x", Type: ValueTypeString, Default: 3}))
+	require.ErrorIs(t, reg.Register(KeyDefinition{Key: "x", Type: ValueTypeArray, Merge: "zip"}), types.ErrUnsupportedPreferenceMergeStrategy)
+}
+
+func TestSchemaRegistry_MergeStrategies(t *testing.T) {
+	reg := NewSchemaRegistry().MustRegister(
+		KeyDefinition{Key: "channels", Type: ValueTypeArray, Merge: types.PreferenceMergeAppendUnique},
+		KeyDefinition{Key: "theme", Type: ValueTypeString},
+	)
+	require.Equal(t, map[string]types.PreferenceMergeStrategy{"channels": types.PreferenceMergeAppendUnique}, reg.MergeStrategies())
+	def, _ := reg.Definition("channels")
+	require.Equal(t, "append_unique", def.JSONSchema()["x-merge"])
+}
+
+func TestSchemaRegistry_DefaultsAndJSONSchema(t *testing.T) {
+	reg := newTestSchema(t)
+
+	defaults := reg.Defaults()
+	require.Equal(t, map[string]any{"value": "light"}, defaults["theme"])
+	require.Equal(t, map[string]any{"sidebar": true}, defaults["layout"])
+	require.NotContains(t, defaults, "beta")
+
+	def, ok := reg.Definition("THEME")
+	require.True(t, ok)
+	schema := def.JSONSchema()
+	require.Equal(t, "string", schema["type"])
+	require.Equal(t, []any{"light", "dark"}, schema["enum"])
+	require.Equal(t, "UI color theme", schema["description"])
+
+	channels, _ := reg.Definition("channels")
+	require.Equal(t, map[string]any{"type": "string", "enum": []any{"email", "sms"}}, channels.JSONSchema()["items"])
+	require.Equal(t, 3, channels.JSONSchema()["maxItems"])
+
+	beta, _ := reg.Definition("beta")
+	require.Equal(t, []string{"system", "tenant"}, beta.JSONSchema()["x-levels"])
+
+	keys := []string{}
+	for _, def := range reg.Definitions() {
+		keys = append(keys, def.Key)
+	}
+	require.Equal(t, []string{"beta", "channels", "font_size", "layout", "theme"}, keys)
+}
+
+func TestResolver_UsesSchemaDefaults(t *testing.T) {
+	reg := newTestSchema(t)
+	resolver, err := NewResolver(ResolverConfig{Repository: &fakePreferenceRepo{}, Defaults: reg.Defaults()})
+	require.NoError(t, err)
+	snapshot, err := resolver.Resolve(t.Context(), ResolveInput{OutputMode: types.PreferenceOutputRawValue})
+	require.NoError(t, err)
+	require.Equal(t, "light", snapshot.Effective["theme"])
+	require.Equal(t, 14, snapshot.Effective["font_size"])
+}
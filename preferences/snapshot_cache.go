@@ -0,0 +1,332 @@
+package preferences
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// DefaultSnapshotCacheCapacity bounds the snapshot cache when no capacity is
+// configured.
+const DefaultSnapshotCacheCapacity = 10_000
+
+// SnapshotResolver resolves effective preference snapshots.
+type SnapshotResolver interface {
+	Resolve(ctx context.Context, input ResolveInput) (types.PreferenceSnapshot, error)
+}
+
+// SnapshotCacheConfig configures CachedResolver.
+type SnapshotCacheConfig struct {
+	// Capacity caps the number of cached snapshots; the least recently used
+	// entry is evicted first. Defaults to DefaultSnapshotCacheCapacity.
+	Capacity int
+	// TTL expires entries after the given duration. Zero keeps entries until
+	// they are invalidated or evicted.
+	TTL   time.Duration
+	Clock types.Clock
+}
+
+// SnapshotCacheStats reports snapshot cache counters.
+type SnapshotCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	Evictions     uint64
+	Entries       int
+}
+
+// CachedResolver caches resolved snapshots in front of a resolver. Entries are
+// keyed by user, scope, levels, keys, and output options, and stamped with
+// per-scope versions: a write bumps the version of the scope it touched, so a
+// tenant-level write invalidates every user in that tenant without scanning.
+// AsOf and Base inputs bypass the cache.
+type CachedResolver struct {
+	next     SnapshotResolver
+	capacity int
+	ttl      time.Duration
+	clock    types.Clock
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	versions snapshotVersions
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+	evictions     atomic.Uint64
+}
+
+var (
+	_ SnapshotResolver            = (*CachedResolver)(nil)
+	_ types.PreferenceInvalidator = (*CachedResolver)(nil)
+	_ types.RoleChangeInvalidator = (*CachedResolver)(nil)
+)
+
+// NewCachedResolver wraps next with a snapshot cache.
+func NewCachedResolver(next SnapshotResolver, cfg SnapshotCacheConfig) (*CachedResolver, error) {
+	if next == nil {
+		return nil, errors.New("preferences: resolver required")
+	}
+	capacity := cfg.Capacity
+	if capacity <= 0 {
+		capacity = DefaultSnapshotCacheCapacity
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	return &CachedResolver{
+		next:     next,
+		capacity: capacity,
+		ttl:      cfg.TTL,
+		clock:    clock,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		versions: newSnapshotVersions(),
+	}, nil
+}
+
+type snapshotEntry struct {
+	key       string
+	stamp     snapshotStamp
+	expiresAt time.Time
+	snapshot  types.PreferenceSnapshot
+}
+
+// Resolve returns a cached snapshot when its stamp is current, otherwise it
+// resolves through the wrapped resolver and caches the result.
+func (c *CachedResolver) Resolve(ctx context.Context, input ResolveInput) (types.PreferenceSnapshot, error) {
+	if input.AsOf != nil || len(input.Base) > 0 {
+		return c.next.Resolve(ctx, input)
+	}
+	key := snapshotCacheKey(input)
+	c.mu.Lock()
+	stamp := c.versions.stamp(input.UserID, input.Scope)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*snapshotEntry)
+		if entry.stamp == stamp && (entry.expiresAt.IsZero() || c.clock.Now().Before(entry.expiresAt)) {
+			c.lru.MoveToFront(elem)
+			snapshot := cloneSnapshot(entry.snapshot)
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return snapshot, nil
+		}
+		c.removeElement(elem)
+	}
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	snapshot, err := c.next.Resolve(ctx, input)
+	if err != nil {
+		return types.PreferenceSnapshot{}, err
+	}
+	c.store(key, stamp, snapshot)
+	return cloneSnapshot(snapshot), nil
+}
+
+// store caches snapshot under the stamp read before resolving, so a write
+// that landed mid-resolve leaves the entry stale.
+func (c *CachedResolver) store(key string, stamp snapshotStamp, snapshot types.PreferenceSnapshot) {
+	entry := &snapshotEntry{key: key, stamp: stamp, snapshot: cloneSnapshot(snapshot)}
+	if c.ttl > 0 {
+		entry.expiresAt = c.clock.Now().Add(c.ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.capacity {
+		c.removeElement(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *CachedResolver) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*snapshotEntry)
+	delete(c.entries, entry.key)
+}
+
+// InvalidatePreferences bumps the version of the scope the event changed.
+func (c *CachedResolver) InvalidatePreferences(_ context.Context, event types.PreferenceEvent) error {
+	c.mu.Lock()
+	c.versions.bump(event)
+	c.versions.compact(c.capacity)
+	c.mu.Unlock()
+	c.invalidations.Add(1)
+	return nil
+}
+
+// InvalidateRoleChange invalidates snapshots affected by a role event. An
+// assignment change invalidates the user; a role-wide change invalidates
+// every snapshot that may include role layers.
+func (c *CachedResolver) InvalidateRoleChange(_ context.Context, event types.RoleEvent) {
+	c.mu.Lock()
+	if event.UserID != uuid.Nil {
+		c.versions.users[event.UserID]++
+	} else {
+		c.versions.role++
+	}
+	c.versions.compact(c.capacity)
+	c.mu.Unlock()
+	c.invalidations.Add(1)
+}
+
+// Purge drops every cached snapshot.
+func (c *CachedResolver) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Stats returns the cache counters.
+func (c *CachedResolver) Stats() SnapshotCacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+	return SnapshotCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Evictions:     c.evictions.Load(),
+		Entries:       entries,
+	}
+}
+
+// snapshotVersions holds the version counters for every scope a snapshot can
+// depend on. Counters are only ever incremented; when the per-scope maps grow
+// past the cache capacity they are dropped and epoch is bumped instead, which
+// stales every cached snapshot at once.
+type snapshotVersions struct {
+	epoch   uint64
+	system  uint64
+	role    uint64
+	tenants map[uuid.UUID]uint64
+	orgs    map[uuid.UUID]uint64
+	users   map[uuid.UUID]uint64
+}
+
+// snapshotStamp captures the counters a snapshot was resolved against.
+type snapshotStamp struct {
+	epoch, system, role, tenant, org, user uint64
+}
+
+func newSnapshotVersions() snapshotVersions {
+	return snapshotVersions{
+		tenants: make(map[uuid.UUID]uint64),
+		orgs:    make(map[uuid.UUID]uint64),
+		users:   make(map[uuid.UUID]uint64),
+	}
+}
+
+func (v snapshotVersions) stamp(userID uuid.UUID, scope types.ScopeFilter) snapshotStamp {
+	return snapshotStamp{
+		epoch:  v.epoch,
+		system: v.system,
+		role:   v.role,
+		tenant: v.tenants[scope.TenantID],
+		org:    v.orgs[scope.OrgID],
+		user:   v.users[userID],
+	}
+}
+
+// bump increments the counter for the scope the event wrote. Role-level
+// writes bump a single role counter because the cache does not track which
+// users hold a role.
+func (v *snapshotVersions) bump(event types.PreferenceEvent) {
+	switch event.Level {
+	case types.PreferenceLevelSystem:
+		v.system++
+	case types.PreferenceLevelTenant:
+		v.tenants[event.Scope.TenantID]++
+	case types.PreferenceLevelOrg:
+		v.orgs[event.Scope.OrgID]++
+	case types.PreferenceLevelRole:
+		v.role++
+	default:
+		v.users[event.UserID]++
+	}
+}
+
+// compact resets the per-scope counters once they track more than limit
+// scopes so the maps stay bounded.
+func (v *snapshotVersions) compact(limit int) {
+	if len(v.tenants)+len(v.orgs)+len(v.users) <= limit {
+		return
+	}
+	v.epoch++
+	clear(v.tenants)
+	clear(v.orgs)
+	clear(v.users)
+}
+
+func snapshotCacheKey(input ResolveInput) string {
+	var b strings.Builder
+	b.WriteString(input.UserID.String())
+	b.WriteByte('|')
+	b.WriteString(input.Scope.TenantID.String())
+	b.WriteByte('|')
+	b.WriteString(input.Scope.OrgID.String())
+	b.WriteByte('|')
+	labels := make([]string, 0, len(input.Scope.Labels))
+	for label, id := range input.Scope.Labels {
+		labels = append(labels, label+"="+id.String())
+	}
+	slices.Sort(labels)
+	b.WriteString(strings.Join(labels, ","))
+	b.WriteByte('|')
+	for i, level := range input.Levels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(string(level))
+	}
+	b.WriteByte('|')
+	keys := make([]string, 0, len(input.Keys))
+	for _, key := range input.Keys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	b.WriteString(strings.Join(slices.Compact(keys), ","))
+	b.WriteByte('|')
+	b.WriteString(string(input.OutputMode))
+	if input.IncludeVersions {
+		b.WriteString("|versions")
+	}
+	return b.String()
+}
+
+// cloneSnapshot copies the mutable parts of a snapshot so callers cannot
+// change cached data.
+func cloneSnapshot(snapshot types.PreferenceSnapshot) types.PreferenceSnapshot {
+	out := types.PreferenceSnapshot{EffectiveVersions: maps.Clone(snapshot.EffectiveVersions)}
+	if snapshot.Effective != nil {
+		out.Effective = cloneValue(snapshot.Effective).(map[string]any)
+	}
+	if snapshot.Traces != nil {
+		out.Traces = make([]types.PreferenceTrace, len(snapshot.Traces))
+		for i, trace := range snapshot.Traces {
+			trace.Layers = slices.Clone(trace.Layers)
+			for j := range trace.Layers {
+				trace.Layers[j].Value = cloneValue(trace.Layers[j].Value)
+			}
+			trace.Paths = slices.Clone(trace.Paths)
+			out.Traces[i] = trace
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package preferences
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+func BenchmarkResolver_Uncached(b *testing.B) {
+	resolver, input := newBenchResolver(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := resolver.Resolve(ctx, input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCachedResolver_Hit(b *testing.B) {
+	resolver, input := newBenchResolver(b)
+	cached := newBenchCache(b, resolver)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := cached.Resolve(ctx, input); err != nil {
+			b.Fatal(err)
+		}
+	}
+	reportHitRate(b, cached)
+}
+
+func BenchmarkCachedResolver_HitParallel(b *testing.B) {
+	resolver, input := newBenchResolver(b)
+	cached := newBenchCache(b, resolver)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := cached.Resolve(ctx, input); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	reportHitRate(b, cached)
+}
+
+func BenchmarkCachedResolver_TenantInvalidation(b *testing.B) {
+	for _, writeEvery := range []int{10, 100} {
+		b.Run(fmt.Sprintf("write_every_%d", writeEvery), func(b *testing.B) {
+			resolver, input := newBenchResolver(b)
+			cached := newBenchCache(b, resolver)
+			ctx := context.Background()
+			event := types.PreferenceEvent{Scope: input.Scope, Level: types.PreferenceLevelTenant, Key: "theme"}
+			b.ReportAllocs()
+			i := 0
+			for b.Loop() {
+				if i%writeEvery == 0 {
+					_ = cached.InvalidatePreferences(ctx, event)
+				}
+				i++
+				if _, err := cached.Resolve(ctx, input); err != nil {
+					b.Fatal(err)
+				}
+			}
+			reportHitRate(b, cached)
+		})
+	}
+}
+
+func newBenchResolver(b *testing.B) (*Resolver, ResolveInput) {
+	b.Helper()
+	userID := uuid.New()
+	scope := types.ScopeFilter{TenantID: uuid.New(), OrgID: uuid.New()}
+	records := func(level types.PreferenceLevel) []types.PreferenceRecord {
+		out := make([]types.PreferenceRecord, 0, 20)
+		for i := range 20 {
+			out = append(out, types.PreferenceRecord{
+				ID:     uuid.New(),
+				UserID: userID,
+				Scope:  scope,
+				Level:  level,
+				Key:    fmt.Sprintf("key.%02d", i),
+				Value:  map[string]any{"value": i, "level": string(level), "nested": map[string]any{"enabled": true}},
+			})
+		}
+		return out
+	}
+	repo := &fakePreferenceRepo{values: map[types.PreferenceLevel][]types.PreferenceRecord{
+		types.PreferenceLevelSystem: records(types.PreferenceLevelSystem),
+		types.PreferenceLevelTenant: records(types.PreferenceLevelTenant),
+		types.PreferenceLevelOrg:    records(types.PreferenceLevelOrg),
+		types.PreferenceLevelUser:   records(types.PreferenceLevelUser),
+	}}
+	resolver, err := NewResolver(ResolverConfig{Repository: repo})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return resolver, ResolveInput{UserID: userID, Scope: scope}
+}
+
+func newBenchCache(b *testing.B, resolver SnapshotResolver) *CachedResolver {
+	b.Helper()
+	cached, err := NewCachedResolver(resolver, SnapshotCacheConfig{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return cached
+}
+
+func reportHitRate(b *testing.B, cached *CachedResolver) {
+	stats := cached.Stats()
+	if total := stats.Hits + stats.Misses; total > 0 {
+		b.ReportMetric(float64(stats.Hits)/float64(total)*100, "hit%")
+	}
+}
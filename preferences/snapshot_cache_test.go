@@ -0,0 +1,163 @@
+package preferences
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedResolver_InvalidatesByScopeVersion(t *testing.T) {
+	ctx := context.Background()
+	next := &countingResolver{}
+	cached, err := NewCachedResolver(next, SnapshotCacheConfig{})
+	require.NoError(t, err)
+
+	tenantA, tenantB := uuid.New(), uuid.New()
+	alice := ResolveInput{UserID: uuid.New(), Scope: types.ScopeFilter{TenantID: tenantA}}
+	bob := ResolveInput{UserID: uuid.New(), Scope: types.ScopeFilter{TenantID: tenantA}}
+	carol := ResolveInput{UserID: uuid.New(), Scope: types.ScopeFilter{TenantID: tenantB}}
+	resolveAll := func() {
+		for _, input := range []ResolveInput{alice, bob, carol} {
+			_, err := cached.Resolve(ctx, input)
+			require.NoError(t, err)
+		}
+	}
+
+	resolveAll()
+	resolveAll()
+	require.Equal(t, 3, next.calls)
+	stats := cached.Stats()
+	require.Equal(t, uint64(3), stats.Hits)
+	require.Equal(t, uint64(3), stats.Misses)
+	require.Equal(t, 3, stats.Entries)
+
+	require.NoError(t, cached.InvalidatePreferences(ctx, types.PreferenceEvent{
+		UserID: alice.UserID,
+		Scope:  alice.Scope,
+		Level:  types.PreferenceLevelUser,
+		Key:    "theme",
+	}))
+	resolveAll()
+	require.Equal(t, 4, next.calls, "a user write only invalidates that user")
+
+	require.NoError(t, cached.InvalidatePreferences(ctx, types.PreferenceEvent{
+		Scope: types.ScopeFilter{TenantID: tenantA},
+		Level: types.PreferenceLevelTenant,
+		Key:   "theme",
+	}))
+	resolveAll()
+	require.Equal(t, 6, next.calls, "a tenant write invalidates every user in the tenant")
+
+	require.NoError(t, cached.InvalidatePreferences(ctx, types.PreferenceEvent{Level: types.PreferenceLevelSystem}))
+	resolveAll()
+	require.Equal(t, 9, next.calls)
+
+	cached.InvalidateRoleChange(ctx, types.RoleEvent{UserID: carol.UserID})
+	resolveAll()
+	require.Equal(t, 10, next.calls)
+	require.Equal(t, uint64(4), cached.Stats().Invalidations)
+}
+
+func TestCachedResolver_BoundsScopeVersions(t *testing.T) {
+	ctx := context.Background()
+	next := &countingResolver{}
+	cached, err := NewCachedResolver(next, SnapshotCacheConfig{Capacity: 2})
+	require.NoError(t, err)
+
+	input := ResolveInput{UserID: uuid.New()}
+	_, err = cached.Resolve(ctx, input)
+	require.NoError(t, err)
+
+	for range 10 {
+		cached.InvalidateRoleChange(ctx, types.RoleEvent{UserID: uuid.New()})
+	}
+	cached.mu.Lock()
+	tracked := len(cached.versions.users)
+	cached.mu.Unlock()
+	require.LessOrEqual(t, tracked, 2)
+
+	_, err = cached.Resolve(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, 2, next.calls, "dropping counters stales cached snapshots")
+}
+
+func TestCachedResolver_KeysInputAndIsolatesSnapshots(t *testing.T) {
+	ctx := context.Background()
+	next := &countingResolver{}
+	cached, err := NewCachedResolver(next, SnapshotCacheConfig{})
+	require.NoError(t, err)
+
+	input := ResolveInput{UserID: uuid.New(), Keys: []string{"theme", "locale"}}
+	first, err := cached.Resolve(ctx, input)
+	require.NoError(t, err)
+	first.Effective["theme"].(map[string]any)["mode"] = "mutated"
+
+	reordered := input
+	reordered.Keys = []string{"locale", "theme"}
+	second, err := cached.Resolve(ctx, reordered)
+	require.NoError(t, err)
+	require.Equal(t, 1, next.calls, "key order does not change the cache key")
+	require.Equal(t, "dark", second.Effective["theme"].(map[string]any)["mode"])
+
+	raw := input
+	raw.OutputMode = types.PreferenceOutputRawValue
+	_, err = cached.Resolve(ctx, raw)
+	require.NoError(t, err)
+	require.Equal(t, 2, next.calls)
+
+	asOf := time.Now()
+	historic := input
+	historic.AsOf = &asOf
+	_, err = cached.Resolve(ctx, historic)
+	require.NoError(t, err)
+	_, err = cached.Resolve(ctx, historic)
+	require.NoError(t, err)
+	require.Equal(t, 4, next.calls, "AsOf bypasses the cache")
+}
+
+func TestCachedResolver_EvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	next := &countingResolver{}
+	cached, err := NewCachedResolver(next, SnapshotCacheConfig{
+		Capacity: 1,
+		TTL:      90 * time.Second,
+		Clock:    &stepClock{at: time.Unix(0, 0)},
+	})
+	require.NoError(t, err)
+
+	first := ResolveInput{UserID: uuid.New()}
+	second := ResolveInput{UserID: uuid.New()}
+	_, err = cached.Resolve(ctx, first)
+	require.NoError(t, err)
+	_, err = cached.Resolve(ctx, second)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), cached.Stats().Evictions)
+	require.Equal(t, 1, cached.Stats().Entries)
+
+	// The clock advances a minute per reading: the entry stored at +2m
+	// expires at +3m30s, so the +3m read hits and the +4m read misses.
+	_, err = cached.Resolve(ctx, second)
+	require.NoError(t, err)
+	require.Equal(t, 2, next.calls)
+	_, err = cached.Resolve(ctx, second)
+	require.NoError(t, err)
+	require.Equal(t, 3, next.calls)
+}
+
+type countingResolver struct {
+	calls int
+}
+
+func (r *countingResolver) Resolve(_ context.Context, input ResolveInput) (types.PreferenceSnapshot, error) {
+	r.calls++
+	return types.PreferenceSnapshot{
+		Effective: map[string]any{
+			"theme": map[string]any{"mode": "dark"},
+			"user":  input.UserID.String(),
+		},
+	}, nil
+}
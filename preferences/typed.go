@@ -0,0 +1,159 @@
+package preferences
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+)
+
+// Key describes a typed preference key. Default is returned when the key is
+// not set in a snapshot.
+type Key[T any] struct {
+	Name    string
+	Default T
+}
+
+// Common typed keys.
+var (
+	LocaleKey   = Key[string]{Name: "locale"}
+	TimezoneKey = Key[string]{Name: "timezone"}
+)
+
+// Get returns the key's value from snapshot, or Default when it is not set.
+func (k Key[T]) Get(snapshot types.PreferenceSnapshot) (T, error) {
+	value, ok, err := Lookup[T](snapshot, k.Name)
+	if err != nil || !ok {
+		return k.Default, err
+	}
+	return value, nil
+}
+
+// Read resolves prefs and returns the key's value, or Default when it is not
+// set.
+func (k Key[T]) Read(ctx context.Context, prefs *Reader) (T, error) {
+	snapshot, err := prefs.Snapshot(ctx)
+	if err != nil {
+		return k.Default, err
+	}
+	return k.Get(snapshot)
+}
+
+// Get returns key's effective value decoded into T. Missing keys yield the
+// zero value; use Lookup to tell them apart from stored zero values.
+func Get[T any](snapshot types.PreferenceSnapshot, key string) (T, error) {
+	value, _, err := Lookup[T](snapshot, key)
+	return value, err
+}
+
+// Lookup returns key's effective value decoded into T and whether the key is
+// set. Keys match case-insensitively. Values resolved in envelope mode are
+// unwrapped from their {"value": ...} payload unless T decodes the envelope
+// itself, so callers do not depend on the snapshot's output mode. Values that
+// are not already a T are decoded through JSON, so structs, numeric types,
+// and slices decode from their generic map and slice forms.
+func Lookup[T any](snapshot types.PreferenceSnapshot, key string) (T, bool, error) {
+	var zero T
+	raw, ok := effectiveValue(snapshot.Effective, key)
+	if !ok || raw == nil {
+		return zero, false, nil
+	}
+	if inner, wrapped := envelopeValue(raw); wrapped {
+		if value, err := decodeValue[T](inner); err == nil {
+			return value, true, nil
+		}
+	}
+	value, err := decodeValue[T](raw)
+	if err != nil {
+		return zero, true, fmt.Errorf("preferences: decode %q: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Reader resolves one user's preferences for typed key reads.
+type Reader struct {
+	resolver SnapshotResolver
+	input    ResolveInput
+}
+
+// NewReader binds resolver to the user, scope, and options in input.
+func NewReader(resolver SnapshotResolver, input ResolveInput) (*Reader, error) {
+	if resolver == nil {
+		return nil, errors.New("preferences: resolver required")
+	}
+	return &Reader{resolver: resolver, input: input}, nil
+}
+
+// Snapshot resolves the bound preferences.
+func (r *Reader) Snapshot(ctx context.Context) (types.PreferenceSnapshot, error) {
+	return r.resolver.Resolve(ctx, r.input)
+}
+
+// Locale returns the snapshot's locale preference normalized with
+// types.NormalizeLocale, or the normalized fallback when it is unset.
+func Locale(snapshot types.PreferenceSnapshot, fallback string) (string, error) {
+	locale, err := LocaleKey.Get(snapshot)
+	if err != nil {
+		return types.NormalizeLocale(fallback), err
+	}
+	if strings.TrimSpace(locale) == "" {
+		locale = fallback
+	}
+	return types.NormalizeLocale(locale), nil
+}
+
+// Location returns the time zone named by the snapshot's timezone preference,
+// or fallback when it is unset. Unknown zone names return an error.
+func Location(snapshot types.PreferenceSnapshot, fallback *time.Location) (*time.Location, error) {
+	name, err := TimezoneKey.Get(snapshot)
+	if err != nil {
+		return fallback, err
+	}
+	if strings.TrimSpace(name) == "" {
+		return fallback, nil
+	}
+	location, err := time.LoadLocation(strings.TrimSpace(name))
+	if err != nil {
+		return fallback, fmt.Errorf("preferences: timezone %q: %w", name, err)
+	}
+	return location, nil
+}
+
+func effectiveValue(effective map[string]any, key string) (any, bool) {
+	key = strings.TrimSpace(key)
+	if value, ok := effective[key]; ok {
+		return value, true
+	}
+	for candidate, value := range effective {
+		if strings.EqualFold(candidate, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func envelopeValue(value any) (any, bool) {
+	if !isValueEnvelope(value) {
+		return nil, false
+	}
+	return value.(map[string]any)["value"], true
+}
+
+func decodeValue[T any](value any) (T, error) {
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+	var out T
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
@@ -0,0 +1,97 @@
+package preferences
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type notificationPrefs struct {
+	Email   bool     `json:"email"`
+	Digest  string   `json:"digest"`
+	Channel []string `json:"channels"`
+}
+
+func TestLookup_DecodesEnvelopeAndRawValues(t *testing.T) {
+	envelope := types.PreferenceSnapshot{Effective: map[string]any{
+		"theme":         map[string]any{"value": "dark"},
+		"page_size":     map[string]any{"value": float64(50)},
+		"notifications": map[string]any{"email": true, "digest": "daily", "channels": []any{"email", "sms"}},
+	}}
+	raw := types.PreferenceSnapshot{Effective: transformEffective(envelope.Effective, types.PreferenceOutputRawValue)}
+
+	for name, snapshot := range map[string]types.PreferenceSnapshot{"envelope": envelope, "raw_value": raw} {
+		t.Run(name, func(t *testing.T) {
+			theme, err := Get[string](snapshot, "Theme")
+			require.NoError(t, err)
+			require.Equal(t, "dark", theme)
+
+			size, err := Get[int](snapshot, "page_size")
+			require.NoError(t, err)
+			require.Equal(t, 50, size)
+
+			notifications, err := Get[notificationPrefs](snapshot, "notifications")
+			require.NoError(t, err)
+			require.Equal(t, notificationPrefs{Email: true, Digest: "daily", Channel: []string{"email", "sms"}}, notifications)
+
+			_, ok, err := Lookup[string](snapshot, "missing")
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			_, err = Get[int](snapshot, "theme")
+			require.Error(t, err)
+		})
+	}
+
+	payload, err := Get[map[string]any](envelope, "theme")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"value": "dark"}, payload)
+}
+
+func TestKey_ReadFallsBackToDefault(t *testing.T) {
+	ctx := context.Background()
+	densityKey := Key[string]{Name: "density", Default: "comfortable"}
+	reader, err := NewReader(&countingResolver{}, ResolveInput{UserID: uuid.New()})
+	require.NoError(t, err)
+
+	theme, err := Key[map[string]string]{Name: "theme"}.Read(ctx, reader)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"mode": "dark"}, theme)
+
+	density, err := densityKey.Read(ctx, reader)
+	require.NoError(t, err)
+	require.Equal(t, "comfortable", density)
+
+	_, err = NewReader(nil, ResolveInput{})
+	require.Error(t, err)
+}
+
+func TestLocaleAndLocation(t *testing.T) {
+	snapshot := types.PreferenceSnapshot{Effective: map[string]any{
+		"locale":   map[string]any{"value": "en_us"},
+		"timezone": "Europe/Madrid",
+	}}
+
+	locale, err := Locale(snapshot, "fr")
+	require.NoError(t, err)
+	require.Equal(t, types.NormalizeLocale("en_us"), locale)
+
+	location, err := Location(snapshot, time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, "Europe/Madrid", location.String())
+
+	empty := types.PreferenceSnapshot{}
+	locale, err = Locale(empty, "es_ES")
+	require.NoError(t, err)
+	require.Equal(t, types.NormalizeLocale("es_ES"), locale)
+	location, err = Location(empty, time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, location)
+
+	_, err = Location(types.PreferenceSnapshot{Effective: map[string]any{"timezone": "Mars/Olympus"}}, time.UTC)
+	require.Error(t, err)
+}
@@ -0,0 +1,295 @@
+package profile
+
+import (
+	"context"
+	"strings"
+
+	"github.com/goliatone/go-masker"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Profile field audiences. Any other audience names an actor role, for
+// example types.ActorRoleTenantAdmin.
+const (
+	// AudiencePublic matches every actor allowed to read the profile.
+	AudiencePublic = "public"
+	// AudienceSelf matches the profile owner.
+	AudienceSelf = "self"
+	// AudienceAdmin matches the policy's admin roles.
+	AudienceAdmin = "admin"
+)
+
+// FieldRule restricts who may read a profile field. Path names a top-level
+// field (display_name, avatar_url, locale, timezone, bio, contact, metadata,
+// custom_fields) or a key inside one of the map fields ("contact.phone",
+// "custom_fields.badge"); the most specific rule wins. Actors outside
+// Audiences see string values masked with the go-masker tag in Mask (for
+// example "preserveEnds(2,2)"), and nothing when Mask is empty.
+type FieldRule struct {
+	Path      string
+	Audiences []string
+	Mask      string
+}
+
+// ProfileAccessPolicy applies field-level read rules to profiles.
+type ProfileAccessPolicy interface {
+	// Sanitize returns the profile as actor may see it.
+	Sanitize(ctx context.Context, actor types.ActorRef, profile types.UserProfile) (types.UserProfile, error)
+	// Public returns the projection of profile visible to the public audience.
+	Public(ctx context.Context, profile types.UserProfile) (types.PublicProfile, error)
+}
+
+// AccessPolicyOption customizes the default profile access policy.
+type AccessPolicyOption func(*DefaultAccessPolicy)
+
+// DefaultAccessPolicy limits contact, metadata, and custom fields to the
+// profile owner and admins, leaves the remaining fields public, and lets
+// system admins read everything. Custom fields follow their definition's
+// visibility when a field repository is configured.
+type DefaultAccessPolicy struct {
+	rules      map[string]FieldRule
+	adminRoles []string
+	masker     *masker.Masker
+	fields     types.ProfileFieldRepository
+}
+
+var _ ProfileAccessPolicy = (*DefaultAccessPolicy)(nil)
+
+// DefaultFieldRules returns the rules applied by NewDefaultAccessPolicy.
+func DefaultFieldRules() []FieldRule {
+	private := []string{AudienceSelf, AudienceAdmin}
+	return []FieldRule{
+		{Path: "contact", Audiences: private},
+		{Path: "metadata", Audiences: private},
+		{Path: "custom_fields", Audiences: private},
+	}
+}
+
+// NewDefaultAccessPolicy returns the default policy implementation.
+func NewDefaultAccessPolicy(opts ...AccessPolicyOption) *DefaultAccessPolicy {
+	policy := &DefaultAccessPolicy{
+		rules:      make(map[string]FieldRule),
+		adminRoles: []string{types.ActorRoleSystemAdmin, types.ActorRoleTenantAdmin, types.ActorRoleOrgAdmin},
+		masker:     activity.DefaultMasker(),
+	}
+	WithFieldRules(DefaultFieldRules()...)(policy)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(policy)
+		}
+	}
+	if policy.masker == nil {
+		policy.masker = activity.DefaultMasker()
+	}
+	return policy
+}
+
+// WithFieldRules adds rules, replacing earlier rules for the same path.
+func WithFieldRules(rules ...FieldRule) AccessPolicyOption {
+	return func(policy *DefaultAccessPolicy) {
+		if policy == nil {
+			return
+		}
+		for _, rule := range rules {
+			path := normalizeRulePath(rule.Path)
+			if path == "" {
+				continue
+			}
+			rule.Path = path
+			rule.Audiences = append([]string(nil), rule.Audiences...)
+			policy.rules[path] = rule
+		}
+	}
+}
+
+// WithAdminRoles overrides the roles matched by AudienceAdmin.
+func WithAdminRoles(roles ...string) AccessPolicyOption {
+	return func(policy *DefaultAccessPolicy) {
+		if policy == nil {
+			return
+		}
+		policy.adminRoles = append([]string(nil), roles...)
+	}
+}
+
+// WithPolicyMasker overrides the masker used for masked fields.
+func WithPolicyMasker(mask *masker.Masker) AccessPolicyOption {
+	return func(policy *DefaultAccessPolicy) {
+		if policy == nil {
+			return
+		}
+		policy.masker = mask
+	}
+}
+
+// WithProfileFieldDefinitions derives custom field rules from the tenant's
+// definitions: public fields are public, self fields are limited to the owner
+// and admins, and admin fields to admins. Explicit "custom_fields.<key>"
+// rules still take precedence.
+func WithProfileFieldDefinitions(repo types.ProfileFieldRepository) AccessPolicyOption {
+	return func(policy *DefaultAccessPolicy) {
+		if policy == nil {
+			return
+		}
+		policy.fields = repo
+	}
+}
+
+// Sanitize removes or masks the fields actor may not read.
+func (p *DefaultAccessPolicy) Sanitize(ctx context.Context, actor types.ActorRef, profile types.UserProfile) (types.UserProfile, error) {
+	if actor.IsSystemAdmin() {
+		return profile, nil
+	}
+	custom, err := p.customFieldRules(ctx, profile.Scope.TenantID)
+	if err != nil {
+		return types.UserProfile{}, err
+	}
+	out := profile
+	out.DisplayName = p.scalar(actor, profile.UserID, "display_name", profile.DisplayName)
+	out.AvatarURL = p.scalar(actor, profile.UserID, "avatar_url", profile.AvatarURL)
+	out.Locale = p.scalar(actor, profile.UserID, "locale", profile.Locale)
+	out.Timezone = p.scalar(actor, profile.UserID, "timezone", profile.Timezone)
+	out.Bio = p.scalar(actor, profile.UserID, "bio", profile.Bio)
+	out.Contact = p.mapField(actor, profile.UserID, "contact", profile.Contact, nil)
+	out.Metadata = p.mapField(actor, profile.UserID, "metadata", profile.Metadata, nil)
+	out.CustomFields = p.mapField(actor, profile.UserID, "custom_fields", profile.CustomFields, custom)
+	return out, nil
+}
+
+// Public sanitizes profile for an anonymous actor and projects the result.
+func (p *DefaultAccessPolicy) Public(ctx context.Context, profile types.UserProfile) (types.PublicProfile, error) {
+	visible, err := p.Sanitize(ctx, types.ActorRef{}, profile)
+	if err != nil {
+		return types.PublicProfile{}, err
+	}
+	return PublicProjection(visible), nil
+}
+
+// PublicProjection copies the public-safe fields of profile without applying
+// any rules; use ProfileAccessPolicy.Public for sanitized projections.
+func PublicProjection(profile types.UserProfile) types.PublicProfile {
+	public := types.PublicProfile{
+		UserID:      profile.UserID,
+		DisplayName: profile.DisplayName,
+		AvatarURL:   profile.AvatarURL,
+		Locale:      profile.Locale,
+		Timezone:    profile.Timezone,
+		Bio:         profile.Bio,
+	}
+	if len(profile.CustomFields) > 0 {
+		public.CustomFields = cloneMap(profile.CustomFields)
+	}
+	return public
+}
+
+func (p *DefaultAccessPolicy) customFieldRules(ctx context.Context, tenantID uuid.UUID) (map[string]FieldRule, error) {
+	if p.fields == nil {
+		return nil, nil
+	}
+	defs, err := p.fields.ListProfileFields(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]FieldRule, len(defs))
+	for _, def := range defs {
+		var audiences []string
+		switch def.Visibility {
+		case types.ProfileFieldVisibilitySelf:
+			audiences = []string{AudienceSelf, AudienceAdmin}
+		case types.ProfileFieldVisibilityAdmin:
+			audiences = []string{AudienceAdmin}
+		default:
+			audiences = []string{AudiencePublic}
+		}
+		rules[strings.ToLower(def.Key)] = FieldRule{Path: "custom_fields." + def.Key, Audiences: audiences}
+	}
+	return rules, nil
+}
+
+func (p *DefaultAccessPolicy) scalar(actor types.ActorRef, owner uuid.UUID, path, value string) string {
+	rule, ok := p.rules[path]
+	if !ok || value == "" || p.allowed(actor, owner, rule.Audiences) {
+		return value
+	}
+	masked, ok := p.mask(rule.Mask, value)
+	if !ok {
+		return ""
+	}
+	return masked
+}
+
+func (p *DefaultAccessPolicy) mapField(actor types.ActorRef, owner uuid.UUID, path string, values map[string]any, keyRules map[string]FieldRule) map[string]any {
+	if len(values) == 0 {
+		return values
+	}
+	parent, hasParent := p.rules[path]
+	out := make(map[string]any, len(values))
+	for key, value := range values {
+		rule, ok := p.rules[path+"."+strings.ToLower(key)]
+		if !ok {
+			rule, ok = keyRules[strings.ToLower(key)]
+		}
+		if !ok {
+			rule, ok = parent, hasParent
+		}
+		if !ok || p.allowed(actor, owner, rule.Audiences) {
+			out[key] = value
+			continue
+		}
+		text, isText := value.(string)
+		if !isText {
+			continue
+		}
+		if masked, maskedOK := p.mask(rule.Mask, text); maskedOK {
+			out[key] = masked
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func (p *DefaultAccessPolicy) allowed(actor types.ActorRef, owner uuid.UUID, audiences []string) bool {
+	for _, audience := range audiences {
+		switch strings.ToLower(strings.TrimSpace(audience)) {
+		case AudiencePublic:
+			return true
+		case AudienceSelf:
+			if actor.ID != uuid.Nil && actor.ID == owner {
+				return true
+			}
+		case AudienceAdmin:
+			for _, role := range p.adminRoles {
+				if actor.IsRole(role) {
+					return true
+				}
+			}
+		default:
+			if actor.RoleName() != "" && actor.IsRole(audience) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mask applies tag to value. Unknown tags leave values unchanged, so those
+// values are withheld instead of leaking.
+func (p *DefaultAccessPolicy) mask(tag, value string) (string, bool) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || p.masker == nil {
+		return "", false
+	}
+	masked, err := p.masker.String(tag, value)
+	if err != nil || masked == value {
+		return "", false
+	}
+	return masked, true
+}
+
+func normalizeRulePath(path string) string {
+	return strings.ToLower(strings.TrimSpace(path))
+}
@@ -0,0 +1,101 @@
+package profile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultAccessPolicy_FieldRules(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	source := types.UserProfile{
+		UserID:      ownerID,
+		DisplayName: "Ada",
+		Bio:         "Engineer",
+		Contact:     map[string]any{"email": "ada@example.com", "phone": "+1 555 123 4567"},
+		Metadata:    map[string]any{"source": "import"},
+		CustomFields: map[string]any{
+			"department": "eng",
+			"salaryBand": "L5",
+			"badge":      "B-1024",
+		},
+	}
+	fields := staticFields{
+		{Key: "department", Visibility: types.ProfileFieldVisibilityPublic},
+		{Key: "salaryBand", Visibility: types.ProfileFieldVisibilityAdmin},
+		{Key: "badge", Visibility: types.ProfileFieldVisibilitySelf},
+	}
+	policy := NewDefaultAccessPolicy(
+		WithProfileFieldDefinitions(fields),
+		WithFieldRules(
+			FieldRule{Path: "contact.phone", Audiences: []string{AudienceSelf, types.ActorRoleTenantAdmin}, Mask: "preserveEnds(2,2)"},
+			FieldRule{Path: "bio", Audiences: []string{AudienceSelf}},
+		),
+	)
+
+	other, err := policy.Sanitize(ctx, types.ActorRef{ID: uuid.New(), Type: "member"}, source)
+	require.NoError(t, err)
+	require.Equal(t, "Ada", other.DisplayName)
+	require.Empty(t, other.Bio)
+	require.Equal(t, map[string]any{"phone": "+1***********67"}, other.Contact)
+	require.Nil(t, other.Metadata)
+	require.Equal(t, map[string]any{"department": "eng"}, other.CustomFields)
+
+	self, err := policy.Sanitize(ctx, types.ActorRef{ID: ownerID, Type: "member"}, source)
+	require.NoError(t, err)
+	require.Equal(t, source.Contact, self.Contact)
+	require.Equal(t, "Engineer", self.Bio)
+	require.Equal(t, map[string]any{"department": "eng", "badge": "B-1024"}, self.CustomFields)
+
+	admin, err := policy.Sanitize(ctx, types.ActorRef{ID: uuid.New(), Type: types.ActorRoleTenantAdmin}, source)
+	require.NoError(t, err)
+	require.Equal(t, source.Contact, admin.Contact)
+	require.Empty(t, admin.Bio)
+	require.Equal(t, source.CustomFields, admin.CustomFields)
+
+	system, err := policy.Sanitize(ctx, types.ActorRef{ID: uuid.New(), Type: types.ActorRoleSystemAdmin}, source)
+	require.NoError(t, err)
+	require.Equal(t, source, system)
+
+	public, err := policy.Public(ctx, source)
+	require.NoError(t, err)
+	require.Equal(t, types.PublicProfile{
+		UserID:       ownerID,
+		DisplayName:  "Ada",
+		CustomFields: map[string]any{"department": "eng"},
+	}, public)
+
+	// Without definitions custom fields stay private.
+	public, err = NewDefaultAccessPolicy().Public(ctx, source)
+	require.NoError(t, err)
+	require.Nil(t, public.CustomFields)
+	require.Equal(t, "Engineer", public.Bio)
+}
+
+func TestDefaultAccessPolicy_UnknownMaskWithholdsValue(t *testing.T) {
+	policy := NewDefaultAccessPolicy(WithFieldRules(FieldRule{Path: "contact.email", Mask: "no-such-mask"}))
+	out, err := policy.Sanitize(context.Background(), types.ActorRef{ID: uuid.New()}, types.UserProfile{
+		UserID:  uuid.New(),
+		Contact: map[string]any{"email": "ada@example.com"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, out.Contact)
+}
+
+type staticFields []types.ProfileFieldDefinition
+
+func (s staticFields) ListProfileFields(context.Context, uuid.UUID) ([]types.ProfileFieldDefinition, error) {
+	return s, nil
+}
+
+func (staticFields) UpsertProfileField(_ context.Context, def types.ProfileFieldDefinition) (*types.ProfileFieldDefinition, error) {
+	return &def, nil
+}
+
+func (staticFields) DeleteProfileField(context.Context, uuid.UUID, string) error {
+	return nil
+}
@@ -15,7 +15,11 @@ import (
 type RepositoryConfig struct {
 	DB         *bun.DB
 	Repository repository.Repository[*Record]
-	Clock      types.Clock
+	// HistoryRepository stores the append-only revision log. It defaults to a
+	// store built from DB; history is disabled when neither is set.
+	HistoryRepository repository.Repository[*HistoryRecord]
+	Clock             types.Clock
+	IDGen             types.IDGenerator
 }
 
 type profileStore interface {
@@ -25,7 +29,10 @@ type profileStore interface {
 // Repository implements types.ProfileRepository using Bun.
 type Repository struct {
 	profileStore
-	clock types.Clock
+	db      *bun.DB
+	history repository.Repository[*HistoryRecord]
+	clock   types.Clock
+	idGen   types.IDGenerator
 }
 
 // NewRepository constructs the default profile repository.
@@ -50,24 +57,51 @@ func NewRepository(cfg RepositoryConfig) (*Repository, error) {
 			},
 		})
 	}
+	history := cfg.HistoryRepository
+	if history == nil && cfg.DB != nil {
+		history = repository.NewRepository(cfg.DB, repository.ModelHandlers[*HistoryRecord]{
+			NewRecord: func() *HistoryRecord { return &HistoryRecord{} },
+			GetID: func(rec *HistoryRecord) uuid.UUID {
+				if rec == nil {
+					return uuid.Nil
+				}
+				return rec.ID
+			},
+			SetID: func(rec *HistoryRecord, id uuid.UUID) {
+				if rec != nil {
+					rec.ID = id
+				}
+			},
+		})
+	}
 
 	clock := cfg.Clock
 	if clock == nil {
 		clock = types.SystemClock{}
 	}
+	idGen := cfg.IDGen
+	if idGen == nil {
+		idGen = types.UUIDGenerator{}
+	}
 
 	return &Repository{
 		profileStore: repo,
+		db:           cfg.DB,
+		history:      history,
 		clock:        clock,
+		idGen:        idGen,
 	}, nil
 }
 
 var (
 	_ repository.Repository[*Record] = (*Repository)(nil)
 	_ types.ProfileRepository        = (*Repository)(nil)
+	_ types.ProfileHistoryRepository = (*Repository)(nil)
+	_ types.ProfileBatchRepository   = (*Repository)(nil)
 )
 
-// GetProfile returns the profile for the supplied user within the provided scope.
+// GetProfile returns the profile stored for the user in exactly the provided
+// scope. Use types.ResolveProfile to fall back to tenant and global profiles.
 func (r *Repository) GetProfile(ctx context.Context, userID uuid.UUID, scope types.ScopeFilter) (*types.UserProfile, error) {
 	if userID == uuid.Nil {
 		return nil, types.ErrUserIDRequired
@@ -82,11 +116,55 @@ func (r *Repository) GetProfile(ctx context.Context, userID uuid.UUID, scope typ
 	return toDomain(rec), nil
 }
 
-// UpsertProfile inserts or updates the user profile based on whether it already exists.
+// GetProfiles implements types.ProfileBatchRepository.
+func (r *Repository) GetProfiles(ctx context.Context, userIDs []uuid.UUID, scope types.ScopeFilter) (map[uuid.UUID]*types.UserProfile, error) {
+	out := make(map[uuid.UUID]*types.UserProfile, len(userIDs))
+	if len(userIDs) == 0 {
+		return out, nil
+	}
+	ids := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != uuid.Nil {
+			ids = append(ids, userID.String())
+		}
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	records, _, err := r.List(ctx, scopeCriteria(scope), func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("user_id IN (?)", bun.In(ids))
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		out[rec.UserID] = toDomain(rec)
+	}
+	return out, nil
+}
+
+// UpsertProfile inserts or updates the user profile based on whether it
+// already exists, incrementing its version. When profile.ExpectedVersion is
+// set the write is applied with a conditional UPDATE and fails with a
+// *types.ProfileVersionConflictError on mismatch. When history is enabled the
+// write and its history entry share a transaction.
 func (r *Repository) UpsertProfile(ctx context.Context, profile types.UserProfile) (*types.UserProfile, error) {
 	if profile.UserID == uuid.Nil {
 		return nil, types.ErrUserIDRequired
 	}
+	var saved *types.UserProfile
+	err := r.inTx(ctx, func(ctx context.Context, tx bun.IDB) error {
+		var err error
+		saved, err = r.upsert(ctx, tx, profile)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (r *Repository) upsert(ctx context.Context, tx bun.IDB, profile types.UserProfile) (*types.UserProfile, error) {
 	now := r.clock.Now()
 	rec := fromDomain(profile)
 	rec.UpdatedAt = now
@@ -98,10 +176,20 @@ func (r *Repository) UpsertProfile(ctx context.Context, profile types.UserProfil
 	}
 	rec.Contact = cloneMap(rec.Contact)
 	rec.Metadata = cloneMap(rec.Metadata)
+	rec.CustomFields = cloneMap(rec.CustomFields)
+	rec.TenantID = scopeUUID(profile.Scope.TenantID)
+	rec.OrgID = scopeUUID(profile.Scope.OrgID)
 
-	existing, err := r.Get(ctx, selectUserID(profile.UserID), scopeCriteria(profile.Scope))
-	switch {
-	case err == nil:
+	existing, err := r.find(ctx, tx, profile.UserID, profile.Scope)
+	if err != nil && !repository.IsRecordNotFound(err) {
+		return nil, err
+	}
+	if profile.ExpectedVersion != nil {
+		if conflict := versionConflict(profile.UserID, *profile.ExpectedVersion, existing); conflict != nil {
+			return nil, conflict
+		}
+	}
+	if existing != nil {
 		rec.CreatedAt = existing.CreatedAt
 		if rec.CreatedAt.IsZero() {
 			rec.CreatedAt = now
@@ -112,41 +200,177 @@ func (r *Repository) UpsertProfile(ctx context.Context, profile types.UserProfil
 				rec.CreatedBy = rec.UpdatedBy
 			}
 		}
-		rec.TenantID = scopeUUID(profile.Scope.TenantID)
-		rec.OrgID = scopeUUID(profile.Scope.OrgID)
-		updated, updateErr := r.Update(ctx, rec)
+		rec.Version = existing.Version + 1
+		var criteria []repository.UpdateCriteria
+		if profile.ExpectedVersion != nil {
+			criteria = append(criteria, whereVersion(existing.Version))
+		}
+		updated, updateErr := r.update(ctx, tx, rec, criteria...)
 		if updateErr != nil {
+			if profile.ExpectedVersion != nil && repository.IsSQLExpectedCountViolation(updateErr) {
+				return nil, r.currentConflict(ctx, tx, profile.UserID, profile.Scope, *profile.ExpectedVersion)
+			}
 			return nil, updateErr
 		}
+		if err := r.appendHistory(ctx, tx, updated); err != nil {
+			return nil, err
+		}
 		return toDomain(updated), nil
-	case repository.IsRecordNotFound(err):
-		if rec.CreatedAt.IsZero() {
-			rec.CreatedAt = now
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = now
+	}
+	if rec.CreatedBy == uuid.Nil {
+		rec.CreatedBy = rec.UpdatedBy
+	}
+	rec.Version = 1
+	created, createErr := r.create(ctx, tx, rec)
+	if createErr != nil {
+		if profile.ExpectedVersion != nil && repository.IsDuplicatedKey(createErr) {
+			return nil, r.currentConflict(ctx, tx, profile.UserID, profile.Scope, *profile.ExpectedVersion)
 		}
-		if rec.CreatedBy == uuid.Nil {
-			rec.CreatedBy = rec.UpdatedBy
+		return nil, createErr
+	}
+	if err := r.appendHistory(ctx, tx, created); err != nil {
+		return nil, err
+	}
+	return toDomain(created), nil
+}
+
+// ListProfileHistory returns revisions of a profile, newest first. It reports
+// types.ErrProfileHistoryUnsupported when history is disabled.
+func (r *Repository) ListProfileHistory(ctx context.Context, filter types.ProfileHistoryFilter) ([]types.ProfileHistoryEntry, error) {
+	if r.history == nil {
+		return nil, types.ErrProfileHistoryUnsupported
+	}
+	if filter.UserID == uuid.Nil {
+		return nil, types.ErrUserIDRequired
+	}
+	rows, _, err := r.history.List(ctx, selectUserID(filter.UserID), scopeCriteria(filter.Scope), func(q *bun.SelectQuery) *bun.SelectQuery {
+		q = q.OrderExpr("version DESC, changed_at DESC")
+		if filter.BeforeVersion > 0 {
+			q = q.Where("version < ?", filter.BeforeVersion)
+		}
+		if filter.Since != nil {
+			q = q.Where("changed_at >= ?", *filter.Since)
+		}
+		if filter.Until != nil {
+			q = q.Where("changed_at <= ?", *filter.Until)
 		}
-		rec.TenantID = scopeUUID(profile.Scope.TenantID)
-		rec.OrgID = scopeUUID(profile.Scope.OrgID)
-		created, createErr := r.Create(ctx, rec)
-		if createErr != nil {
-			return nil, createErr
+		if filter.Limit > 0 {
+			q = q.Limit(filter.Limit)
 		}
-		return toDomain(created), nil
-	default:
+		return q
+	})
+	if err != nil {
 		return nil, err
 	}
+	entries := make([]types.ProfileHistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, historyToDomain(row))
+	}
+	return entries, nil
 }
 
+// inTx runs fn in a transaction when history is enabled so a write and its
+// history entry commit together. Otherwise fn runs without a transaction.
+func (r *Repository) inTx(ctx context.Context, fn func(ctx context.Context, tx bun.IDB) error) error {
+	if r.history == nil || r.db == nil {
+		return fn(ctx, nil)
+	}
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(ctx, tx)
+	})
+}
+
+func (r *Repository) appendHistory(ctx context.Context, tx bun.IDB, rec *Record) error {
+	if r.history == nil || rec == nil {
+		return nil
+	}
+	entry := &HistoryRecord{
+		ID:           r.idGen.UUID(),
+		UserID:       rec.UserID,
+		TenantID:     rec.TenantID,
+		OrgID:        rec.OrgID,
+		Version:      rec.Version,
+		DisplayName:  rec.DisplayName,
+		AvatarURL:    rec.AvatarURL,
+		Locale:       rec.Locale,
+		Timezone:     rec.Timezone,
+		Bio:          rec.Bio,
+		Contact:      jsonObject(rec.Contact),
+		Metadata:     jsonObject(rec.Metadata),
+		CustomFields: jsonObject(rec.CustomFields),
+		ChangedAt:    rec.UpdatedAt,
+		ChangedBy:    rec.UpdatedBy,
+	}
+	var err error
+	if tx != nil {
+		_, err = r.history.CreateTx(ctx, tx, entry)
+	} else {
+		_, err = r.history.Create(ctx, entry)
+	}
+	return err
+}
+
+func (r *Repository) find(ctx context.Context, tx bun.IDB, userID uuid.UUID, scope types.ScopeFilter) (*Record, error) {
+	if tx != nil {
+		return r.GetTx(ctx, tx, selectUserID(userID), scopeCriteria(scope))
+	}
+	return r.Get(ctx, selectUserID(userID), scopeCriteria(scope))
+}
+
+func (r *Repository) update(ctx context.Context, tx bun.IDB, rec *Record, criteria ...repository.UpdateCriteria) (*Record, error) {
+	if tx != nil {
+		return r.UpdateTx(ctx, tx, rec, criteria...)
+	}
+	return r.Update(ctx, rec, criteria...)
+}
+
+func (r *Repository) create(ctx context.Context, tx bun.IDB, rec *Record) (*Record, error) {
+	if tx != nil {
+		return r.CreateTx(ctx, tx, rec)
+	}
+	return r.Create(ctx, rec)
+}
+
+func (r *Repository) currentConflict(ctx context.Context, tx bun.IDB, userID uuid.UUID, scope types.ScopeFilter, expected int) error {
+	// A failed re-read (e.g. an aborted transaction) still reports the conflict,
+	// just without the current profile.
+	current, _ := r.find(ctx, tx, userID, scope)
+	conflict := &types.ProfileVersionConflictError{UserID: userID, ExpectedVersion: expected}
+	if current != nil {
+		conflict.Current = toDomain(current)
+	}
+	return conflict
+}
+
+func versionConflict(userID uuid.UUID, expected int, existing *Record) error {
+	current := 0
+	if existing != nil {
+		current = existing.Version
+	}
+	if current == expected {
+		return nil
+	}
+	conflict := &types.ProfileVersionConflictError{UserID: userID, ExpectedVersion: expected}
+	if existing != nil {
+		conflict.Current = toDomain(existing)
+	}
+	return conflict
+}
+
+func whereVersion(version int) repository.UpdateCriteria {
+	return func(q *bun.UpdateQuery) *bun.UpdateQuery {
+		return q.Where("version = ?", version)
+	}
+}
+
+// scopeCriteria matches the profile stored for exactly scope; nil IDs match
+// the global (or tenant-wide) profile rather than any scope.
 func scopeCriteria(scope types.ScopeFilter) repository.SelectCriteria {
 	return func(q *bun.SelectQuery) *bun.SelectQuery {
-		if scope.TenantID != uuid.Nil {
-			q = q.Where("tenant_id = ?", scope.TenantID)
-		}
-		if scope.OrgID != uuid.Nil {
-			q = q.Where("org_id = ?", scope.OrgID)
-		}
-		return q
+		return q.Where("tenant_id = ?", scope.TenantID).Where("org_id = ?", scope.OrgID)
 	}
 }
 
@@ -163,20 +387,22 @@ func selectUserID(userID uuid.UUID) repository.SelectCriteria {
 
 func fromDomain(profile types.UserProfile) *Record {
 	rec := &Record{
-		UserID:      profile.UserID,
-		DisplayName: profile.DisplayName,
-		AvatarURL:   profile.AvatarURL,
-		Locale:      profile.Locale,
-		Timezone:    profile.Timezone,
-		Bio:         profile.Bio,
-		Contact:     cloneMap(profile.Contact),
-		Metadata:    cloneMap(profile.Metadata),
-		TenantID:    scopeUUID(profile.Scope.TenantID),
-		OrgID:       scopeUUID(profile.Scope.OrgID),
-		CreatedAt:   profile.CreatedAt,
-		CreatedBy:   profile.CreatedBy,
-		UpdatedAt:   profile.UpdatedAt,
-		UpdatedBy:   profile.UpdatedBy,
+		UserID:       profile.UserID,
+		DisplayName:  profile.DisplayName,
+		AvatarURL:    profile.AvatarURL,
+		Locale:       profile.Locale,
+		Timezone:     profile.Timezone,
+		Bio:          profile.Bio,
+		Contact:      cloneMap(profile.Contact),
+		Metadata:     cloneMap(profile.Metadata),
+		CustomFields: cloneMap(profile.CustomFields),
+		TenantID:     scopeUUID(profile.Scope.TenantID),
+		OrgID:        scopeUUID(profile.Scope.OrgID),
+		Version:      profile.Version,
+		CreatedAt:    profile.CreatedAt,
+		CreatedBy:    profile.CreatedBy,
+		UpdatedAt:    profile.UpdatedAt,
+		UpdatedBy:    profile.UpdatedBy,
 	}
 	rec.CanonicalizeLocale()
 	return rec
@@ -187,18 +413,20 @@ func toDomain(rec *Record) *types.UserProfile {
 		return nil
 	}
 	profile := &types.UserProfile{
-		UserID:      rec.UserID,
-		DisplayName: rec.DisplayName,
-		AvatarURL:   rec.AvatarURL,
-		Locale:      rec.Locale,
-		Timezone:    rec.Timezone,
-		Bio:         rec.Bio,
-		Contact:     cloneMap(rec.Contact),
-		Metadata:    cloneMap(rec.Metadata),
+		UserID:       rec.UserID,
+		DisplayName:  rec.DisplayName,
+		AvatarURL:    rec.AvatarURL,
+		Locale:       rec.Locale,
+		Timezone:     rec.Timezone,
+		Bio:          rec.Bio,
+		Contact:      cloneMap(rec.Contact),
+		Metadata:     cloneMap(rec.Metadata),
+		CustomFields: cloneMap(rec.CustomFields),
 		Scope: types.ScopeFilter{
 			TenantID: rec.TenantID,
 			OrgID:    rec.OrgID,
 		},
+		Version:   rec.Version,
 		CreatedAt: rec.CreatedAt,
 		UpdatedAt: rec.UpdatedAt,
 		CreatedBy: rec.CreatedBy,
@@ -208,6 +436,55 @@ func toDomain(rec *Record) *types.UserProfile {
 	return profile
 }
 
+// FromUserProfile converts the domain profile into the Bun model.
+func FromUserProfile(profile types.UserProfile) *Record {
+	return fromDomain(profile)
+}
+
+// ToUserProfile converts the Bun model into the domain profile.
+func ToUserProfile(rec *Record) *types.UserProfile {
+	return toDomain(rec)
+}
+
+func historyToDomain(rec *HistoryRecord) types.ProfileHistoryEntry {
+	if rec == nil {
+		return types.ProfileHistoryEntry{}
+	}
+	profile := types.UserProfile{
+		UserID:       rec.UserID,
+		DisplayName:  rec.DisplayName,
+		AvatarURL:    rec.AvatarURL,
+		Locale:       rec.Locale,
+		Timezone:     rec.Timezone,
+		Bio:          rec.Bio,
+		Contact:      cloneMap(rec.Contact),
+		Metadata:     cloneMap(rec.Metadata),
+		CustomFields: cloneMap(rec.CustomFields),
+		Scope: types.ScopeFilter{
+			TenantID: rec.TenantID,
+			OrgID:    rec.OrgID,
+		},
+		Version:   rec.Version,
+		UpdatedAt: rec.ChangedAt,
+		UpdatedBy: rec.ChangedBy,
+	}
+	return types.ProfileHistoryEntry{
+		ID:        rec.ID,
+		Profile:   profile,
+		ChangedAt: rec.ChangedAt,
+		ChangedBy: rec.ChangedBy,
+	}
+}
+
+// jsonObject returns a copy of origin that is never nil, for NOT NULL JSON
+// columns.
+func jsonObject(origin map[string]any) map[string]any {
+	if out := cloneMap(origin); out != nil {
+		return out
+	}
+	return map[string]any{}
+}
+
 func cloneMap(origin map[string]any) map[string]any {
 	if len(origin) == 0 {
 		return nil
@@ -84,14 +84,25 @@ func newTestDB(t *testing.T) *bun.DB {
 }
 
 func applyDDL(t *testing.T, db *bun.DB) {
-	content, err := os.ReadFile("../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql")
-	require.NoError(t, err)
-	for _, stmt := range splitStatements(string(content)) {
-		if strings.TrimSpace(stmt) == "" {
-			continue
-		}
-		_, err := db.Exec(stmt)
+	applyMigrations(t, db,
+		"../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql",
+		"../data/sql/migrations/sqlite/00015_profile_custom_fields.up.sql",
+		"../data/sql/migrations/sqlite/00016_profile_versions.up.sql",
+		"../data/sql/migrations/sqlite/00017_profile_scopes.up.sql",
+	)
+}
+
+func applyMigrations(t *testing.T, db *bun.DB, paths ...string) {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
 		require.NoError(t, err)
+		for _, stmt := range splitStatements(string(content)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			_, err := db.Exec(stmt)
+			require.NoError(t, err)
+		}
 	}
 }
 
@@ -117,3 +128,154 @@ func splitStatements(sql string) []string {
 	}
 	return statements
 }
+
+func TestRepository_VersionsAndHistory(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	scope := types.ScopeFilter{TenantID: uuid.New()}
+	actor := uuid.New()
+	zero := 0
+	created, err := repo.UpsertProfile(ctx, types.UserProfile{
+		UserID:          userID,
+		DisplayName:     "First",
+		Scope:           scope,
+		CreatedBy:       actor,
+		ExpectedVersion: &zero,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, created.Version)
+
+	next := *created
+	next.DisplayName = "Second"
+	next.Contact = map[string]any{"phone": "555"}
+	expected := 1
+	next.ExpectedVersion = &expected
+	updated, err := repo.UpsertProfile(ctx, next)
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.Version)
+
+	stale := *created
+	stale.DisplayName = "Stale"
+	stale.ExpectedVersion = &expected
+	_, err = repo.UpsertProfile(ctx, stale)
+	require.ErrorIs(t, err, types.ErrProfileVersionConflict)
+	var conflict *types.ProfileVersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, 1, conflict.ExpectedVersion)
+	require.NotNil(t, conflict.Current)
+	require.Equal(t, 2, conflict.Current.Version)
+	require.Equal(t, "Second", conflict.Current.DisplayName)
+
+	_, err = repo.UpsertProfile(ctx, types.UserProfile{UserID: userID, Scope: scope, ExpectedVersion: &zero})
+	require.ErrorIs(t, err, types.ErrProfileVersionConflict)
+
+	unconditional := *updated
+	unconditional.DisplayName = "Third"
+	third, err := repo.UpsertProfile(ctx, unconditional)
+	require.NoError(t, err)
+	require.Equal(t, 3, third.Version)
+
+	history, err := repo.ListProfileHistory(ctx, types.ProfileHistoryFilter{UserID: userID, Scope: scope})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, 3, history[0].Profile.Version)
+	require.Equal(t, "Third", history[0].Profile.DisplayName)
+	require.Equal(t, "Second", history[1].Profile.DisplayName)
+	require.Equal(t, "555", history[1].Profile.Contact["phone"])
+	require.Equal(t, "First", history[2].Profile.DisplayName)
+	require.Equal(t, actor, history[2].ChangedBy)
+
+	page, err := repo.ListProfileHistory(ctx, types.ProfileHistoryFilter{UserID: userID, Scope: scope, BeforeVersion: 3, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, 2, page[0].Profile.Version)
+}
+
+func TestRepository_ProfilesPerScope(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	actor := uuid.New()
+	tenant := types.ScopeFilter{TenantID: uuid.New()}
+	org := types.ScopeFilter{TenantID: tenant.TenantID, OrgID: uuid.New()}
+	_, err = repo.UpsertProfile(ctx, types.UserProfile{
+		UserID:      userID,
+		DisplayName: "Ada Lovelace",
+		AvatarURL:   "https://cdn.example.com/ada.png",
+		Locale:      "en-GB",
+		Contact:     map[string]any{"email": "ada@example.com"},
+		CreatedBy:   actor,
+	})
+	require.NoError(t, err)
+	_, err = repo.UpsertProfile(ctx, types.UserProfile{
+		UserID:      userID,
+		DisplayName: "Ada (Analytics)",
+		Contact:     map[string]any{"phone": "555"},
+		Scope:       tenant,
+		CreatedBy:   actor,
+	})
+	require.NoError(t, err)
+
+	global, err := repo.GetProfile(ctx, userID, types.ScopeFilter{})
+	require.NoError(t, err)
+	require.Equal(t, "Ada Lovelace", global.DisplayName)
+	scoped, err := repo.GetProfile(ctx, userID, tenant)
+	require.NoError(t, err)
+	require.Equal(t, "Ada (Analytics)", scoped.DisplayName)
+	require.Equal(t, 1, scoped.Version)
+	missing, err := repo.GetProfile(ctx, userID, org)
+	require.NoError(t, err)
+	require.Nil(t, missing)
+
+	resolved, err := types.ResolveProfile(ctx, repo, userID, org)
+	require.NoError(t, err)
+	require.Equal(t, "Ada (Analytics)", resolved.DisplayName)
+	require.Equal(t, "https://cdn.example.com/ada.png", resolved.AvatarURL)
+	require.Equal(t, "en-GB", resolved.Locale)
+	require.Equal(t, map[string]any{"email": "ada@example.com", "phone": "555"}, resolved.Contact)
+	require.Equal(t, tenant, resolved.Scope)
+
+	otherID := uuid.New()
+	_, err = repo.UpsertProfile(ctx, types.UserProfile{UserID: otherID, DisplayName: "Grace", CreatedBy: actor})
+	require.NoError(t, err)
+	batch, err := types.ResolveProfiles(ctx, repo, []uuid.UUID{userID, otherID, uuid.New()}, org)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+	require.Equal(t, resolved, batch[userID])
+	require.Equal(t, "Grace", batch[otherID].DisplayName)
+}
+
+func TestProfileScopesMigration_KeepsRowsAsGlobal(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyMigrations(t, db,
+		"../data/sql/migrations/sqlite/00005_profiles_preferences.up.sql",
+		"../data/sql/migrations/sqlite/00015_profile_custom_fields.up.sql",
+		"../data/sql/migrations/sqlite/00016_profile_versions.up.sql",
+	)
+	userID := uuid.New()
+	_, err := db.ExecContext(ctx, "INSERT INTO user_profiles (user_id, display_name, tenant_id, created_by, updated_by) VALUES (?, ?, ?, ?, ?)",
+		userID.String(), "Legacy", uuid.New().String(), userID.String(), userID.String())
+	require.NoError(t, err)
+
+	applyMigrations(t, db, "../data/sql/migrations/sqlite/00017_profile_scopes.up.sql")
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	global, err := repo.GetProfile(ctx, userID, types.ScopeFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, global)
+	require.Equal(t, "Legacy", global.DisplayName)
+	require.Equal(t, 1, global.Version)
+}
@@ -0,0 +1,308 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	repository "github.com/goliatone/go-repository-bun"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// FieldRepositoryConfig wires the Bun-backed profile field repository.
+type FieldRepositoryConfig struct {
+	DB         *bun.DB
+	Repository repository.Repository[*FieldRecord]
+	Clock      types.Clock
+	IDGen      types.IDGenerator
+}
+
+// FieldRepository implements types.ProfileFieldRepository using Bun.
+type FieldRepository struct {
+	store repository.Repository[*FieldRecord]
+	clock types.Clock
+	idGen types.IDGenerator
+}
+
+var _ types.ProfileFieldRepository = (*FieldRepository)(nil)
+
+// NewFieldRepository constructs the default profile field repository.
+func NewFieldRepository(cfg FieldRepositoryConfig) (*FieldRepository, error) {
+	if cfg.Repository == nil && cfg.DB == nil {
+		return nil, errors.New("profile: db or repository required")
+	}
+	store := cfg.Repository
+	if store == nil {
+		store = repository.NewRepository(cfg.DB, repository.ModelHandlers[*FieldRecord]{
+			NewRecord: func() *FieldRecord { return &FieldRecord{} },
+			GetID: func(rec *FieldRecord) uuid.UUID {
+				if rec == nil {
+					return uuid.Nil
+				}
+				return rec.ID
+			},
+			SetID: func(rec *FieldRecord, id uuid.UUID) {
+				if rec != nil {
+					rec.ID = id
+				}
+			},
+		})
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+	idGen := cfg.IDGen
+	if idGen == nil {
+		idGen = types.UUIDGenerator{}
+	}
+	return &FieldRepository{store: store, clock: clock, idGen: idGen}, nil
+}
+
+// ListProfileFields returns the global definitions merged with tenantID's own.
+func (r *FieldRepository) ListProfileFields(ctx context.Context, tenantID uuid.UUID) ([]types.ProfileFieldDefinition, error) {
+	records, _, err := r.store.List(ctx, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("tenant_id IN (?)", bun.In([]uuid.UUID{uuid.Nil, tenantID}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]types.ProfileFieldDefinition, len(records))
+	for _, rec := range records {
+		key := strings.ToLower(rec.Key)
+		if existing, ok := byKey[key]; ok && existing.TenantID != uuid.Nil {
+			continue
+		}
+		byKey[key] = fieldToDomain(rec)
+	}
+	defs := make([]types.ProfileFieldDefinition, 0, len(byKey))
+	for _, def := range byKey {
+		defs = append(defs, def)
+	}
+	SortFieldDefinitions(defs)
+	return defs, nil
+}
+
+// UpsertProfileField creates or replaces the definition keyed by tenant and
+// case-insensitive key.
+func (r *FieldRepository) UpsertProfileField(ctx context.Context, def types.ProfileFieldDefinition) (*types.ProfileFieldDefinition, error) {
+	def.Normalize()
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	now := r.clock.Now()
+	rec := fieldFromDomain(def)
+	rec.UpdatedAt = now
+	existing, err := r.store.Get(ctx, fieldKeyCriteria(def.TenantID, def.Key))
+	switch {
+	case err == nil:
+		rec.ID = existing.ID
+		rec.CreatedAt = existing.CreatedAt
+		updated, updateErr := r.store.Update(ctx, rec)
+		if updateErr != nil {
+			return nil, updateErr
+		}
+		out := fieldToDomain(updated)
+		return &out, nil
+	case repository.IsRecordNotFound(err):
+		if rec.ID == uuid.Nil {
+			rec.ID = r.idGen.UUID()
+		}
+		rec.CreatedAt = now
+		created, createErr := r.store.Create(ctx, rec)
+		if createErr != nil {
+			return nil, createErr
+		}
+		out := fieldToDomain(created)
+		return &out, nil
+	default:
+		return nil, err
+	}
+}
+
+// DeleteProfileField removes the tenant's definition for key. Stored values
+// are left in place.
+func (r *FieldRepository) DeleteProfileField(ctx context.Context, tenantID uuid.UUID, key string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return types.ErrProfileFieldKeyRequired
+	}
+	return r.store.DeleteWhere(ctx, func(q *bun.DeleteQuery) *bun.DeleteQuery {
+		return q.Where("tenant_id = ?", tenantID).Where("lower(key) = ?", strings.ToLower(key))
+	})
+}
+
+// SortFieldDefinitions orders definitions by Position, then Key.
+func SortFieldDefinitions(defs []types.ProfileFieldDefinition) {
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Position != defs[j].Position {
+			return defs[i].Position < defs[j].Position
+		}
+		return defs[i].Key < defs[j].Key
+	})
+}
+
+// ProfileFieldValueTaken implements types.ProfileFieldUniquenessChecker.
+func (r *Repository) ProfileFieldValueTaken(ctx context.Context, tenantID uuid.UUID, key string, value any, excludeUserID uuid.UUID) (bool, error) {
+	count, err := r.Count(ctx, func(q *bun.SelectQuery) *bun.SelectQuery {
+		expr, args := customFieldTextExpr(q.Dialect().Name(), "custom_fields", key)
+		return q.
+			Where("tenant_id = ?", tenantID).
+			Where("user_id <> ?", excludeUserID).
+			Where(expr+" = ?", append(args, customFieldText(q.Dialect().Name(), value))...)
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// InventoryCriteria applies the ProfileFields filters and SortProfileField
+// order of filter to a user listing query, for UserInventoryRepository
+// implementations backed by Bun. userIDColumn names the user id column of the
+// query (for example "users.id"). Each field is read from the user's resolved
+// profile for the filter's scope: the most specific profile along
+// types.ProfileScopeChain that sets it, as types.ResolveProfile merges them.
+// It does not check field visibility; query.UserInventoryQuery rejects fields
+// the actor may not read before the filter reaches the repository.
+func InventoryCriteria(filter types.UserInventoryFilter, userIDColumn string) repository.SelectCriteria {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		driver := q.Dialect().Name()
+		keys := make([]string, 0, len(filter.ProfileFields))
+		for key := range filter.ProfileFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			expr, args := customFieldTextExpr(driver, "cf.custom_fields", key)
+			value, valueArgs := profileFieldSubquery(filter.Scope, userIDColumn, expr, args)
+			q = q.Where(value+" = ?", append(valueArgs, customFieldText(driver, filter.ProfileFields[key]))...)
+		}
+		if key := strings.TrimSpace(filter.SortProfileField); key != "" {
+			expr, args := customFieldSortExpr(driver, "cf.custom_fields", key)
+			value, valueArgs := profileFieldSubquery(filter.Scope, userIDColumn, expr, args)
+			direction := "ASC"
+			if filter.SortDescending {
+				direction = "DESC"
+			}
+			q = q.OrderExpr(value+" "+direction, valueArgs...)
+		}
+		return q
+	}
+}
+
+// profileFieldSubquery selects expr from at most one profile row: the most
+// specific profile in the scope chain of scope where expr is set.
+func profileFieldSubquery(scope types.ScopeFilter, userIDColumn, expr string, exprArgs []any) (string, []any) {
+	chain := types.ProfileScopeChain(scope)
+	matches := make([]string, 0, len(chain))
+	args := append([]any{}, exprArgs...)
+	args = append(args, bun.Ident(userIDColumn))
+	for _, candidate := range chain {
+		matches = append(matches, "(cf.tenant_id = ? AND cf.org_id = ?)")
+		args = append(args, candidate.TenantID, candidate.OrgID)
+	}
+	args = append(args, exprArgs...)
+	args = append(args, uuid.Nil, uuid.Nil)
+	return "(SELECT " + expr + " FROM user_profiles AS cf" +
+		" WHERE cf.user_id = CAST(? AS TEXT) AND (" + strings.Join(matches, " OR ") + ") AND " + expr + " IS NOT NULL" +
+		" ORDER BY cf.org_id = ?, cf.tenant_id = ? LIMIT 1)", args
+}
+
+// customFieldTextExpr reads key from a JSON column as text.
+func customFieldTextExpr(driver dialect.Name, column, key string) (string, []any) {
+	if driver == dialect.PG {
+		return "? ->> ?", []any{bun.Ident(column), key}
+	}
+	return "CAST(json_extract(?, ?) AS TEXT)", []any{bun.Ident(column), jsonPath(key)}
+}
+
+// customFieldSortExpr reads key from a JSON column keeping its JSON type, so
+// numbers sort numerically.
+func customFieldSortExpr(driver dialect.Name, column, key string) (string, []any) {
+	if driver == dialect.PG {
+		return "? -> ?", []any{bun.Ident(column), key}
+	}
+	return "json_extract(?, ?)", []any{bun.Ident(column), jsonPath(key)}
+}
+
+// customFieldText renders value the way customFieldTextExpr reads it.
+func customFieldText(driver dialect.Name, value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case bool:
+		if driver == dialect.PG {
+			return strconv.FormatBool(typed)
+		}
+		if typed {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32)
+	case int:
+		return strconv.Itoa(typed)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func jsonPath(key string) string {
+	return `$."` + key + `"`
+}
+
+func fieldKeyCriteria(tenantID uuid.UUID, key string) repository.SelectCriteria {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("tenant_id = ?", tenantID).Where("lower(key) = ?", strings.ToLower(strings.TrimSpace(key)))
+	}
+}
+
+func fieldFromDomain(def types.ProfileFieldDefinition) *FieldRecord {
+	return &FieldRecord{
+		ID:          def.ID,
+		TenantID:    def.TenantID,
+		Key:         def.Key,
+		Label:       def.Label,
+		Description: def.Description,
+		FieldType:   string(def.Type),
+		Options:     append([]string{}, def.Options...),
+		Required:    def.Required,
+		Unique:      def.Unique,
+		Visibility:  string(def.Visibility),
+		Position:    def.Position,
+		CreatedAt:   def.CreatedAt,
+		UpdatedAt:   def.UpdatedAt,
+	}
+}
+
+func fieldToDomain(rec *FieldRecord) types.ProfileFieldDefinition {
+	if rec == nil {
+		return types.ProfileFieldDefinition{}
+	}
+	return types.ProfileFieldDefinition{
+		ID:          rec.ID,
+		TenantID:    rec.TenantID,
+		Key:         rec.Key,
+		Label:       rec.Label,
+		Description: rec.Description,
+		Type:        types.ProfileFieldType(rec.FieldType),
+		Options:     append([]string(nil), rec.Options...),
+		Required:    rec.Required,
+		Unique:      rec.Unique,
+		Visibility:  types.ProfileFieldVisibility(rec.Visibility),
+		Position:    rec.Position,
+		CreatedAt:   rec.CreatedAt,
+		UpdatedAt:   rec.UpdatedAt,
+	}
+}
@@ -0,0 +1,142 @@
+package profile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldRepository_TenantOverridesGlobal(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+
+	repo, err := NewFieldRepository(FieldRepositoryConfig{DB: db})
+	require.NoError(t, err)
+	tenantID := uuid.New()
+
+	_, err = repo.UpsertProfileField(ctx, types.ProfileFieldDefinition{Key: "department", Type: types.ProfileFieldTypeString, Position: 2})
+	require.NoError(t, err)
+	_, err = repo.UpsertProfileField(ctx, types.ProfileFieldDefinition{Key: "badge", Type: types.ProfileFieldTypeString, Position: 1})
+	require.NoError(t, err)
+	override, err := repo.UpsertProfileField(ctx, types.ProfileFieldDefinition{
+		TenantID: tenantID,
+		Key:      "Department",
+		Type:     types.ProfileFieldTypeEnum,
+		Options:  []string{"eng", "ops"},
+		Position: 2,
+	})
+	require.NoError(t, err)
+
+	updated, err := repo.UpsertProfileField(ctx, types.ProfileFieldDefinition{
+		TenantID: tenantID,
+		Key:      "department",
+		Type:     types.ProfileFieldTypeEnum,
+		Options:  []string{"eng", "ops", "sales"},
+		Required: true,
+		Position: 0,
+	})
+	require.NoError(t, err)
+	require.Equal(t, override.ID, updated.ID)
+
+	defs, err := repo.ListProfileFields(ctx, tenantID)
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+	require.Equal(t, "department", defs[0].Key)
+	require.Equal(t, tenantID, defs[0].TenantID)
+	require.Equal(t, []string{"eng", "ops", "sales"}, defs[0].Options)
+	require.True(t, defs[0].Required)
+	require.Equal(t, types.ProfileFieldVisibilityPublic, defs[0].Visibility)
+	require.Equal(t, "badge", defs[1].Key)
+
+	global, err := repo.ListProfileFields(ctx, uuid.New())
+	require.NoError(t, err)
+	require.Equal(t, []string{"badge", "department"}, []string{global[0].Key, global[1].Key})
+	require.Equal(t, types.ProfileFieldTypeString, global[1].Type)
+
+	require.NoError(t, repo.DeleteProfileField(ctx, tenantID, "DEPARTMENT"))
+	defs, err = repo.ListProfileFields(ctx, tenantID)
+	require.NoError(t, err)
+	require.Equal(t, types.ProfileFieldTypeString, defs[1].Type)
+
+	_, err = repo.UpsertProfileField(ctx, types.ProfileFieldDefinition{Key: "shoe size", Type: types.ProfileFieldTypeNumber})
+	require.ErrorIs(t, err, types.ErrProfileFieldDefinitionInvalid)
+}
+
+func TestRepository_CustomFieldUniquenessAndInventory(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	applyDDL(t, db)
+	_, err := db.Exec("CREATE TABLE users (id TEXT PRIMARY KEY)")
+	require.NoError(t, err)
+
+	repo, err := NewRepository(RepositoryConfig{DB: db})
+	require.NoError(t, err)
+	tenantID := uuid.New()
+	scope := types.ScopeFilter{TenantID: tenantID}
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	fields := []map[string]any{
+		{"badge": "A-1", "level": 3, "remote": true},
+		{"badge": "B-2", "level": 10, "remote": false},
+		{"badge": "C-3", "level": 7, "remote": true},
+	}
+	for i, id := range ids {
+		_, err := db.Exec("INSERT INTO users (id) VALUES (?)", id.String())
+		require.NoError(t, err)
+		_, err = repo.UpsertProfile(ctx, types.UserProfile{UserID: id, Scope: scope, CustomFields: fields[i], CreatedBy: id, UpdatedBy: id})
+		require.NoError(t, err)
+	}
+
+	taken, err := repo.ProfileFieldValueTaken(ctx, tenantID, "badge", "A-1", ids[1])
+	require.NoError(t, err)
+	require.True(t, taken)
+	taken, err = repo.ProfileFieldValueTaken(ctx, tenantID, "badge", "A-1", ids[0])
+	require.NoError(t, err)
+	require.False(t, taken)
+	taken, err = repo.ProfileFieldValueTaken(ctx, uuid.New(), "badge", "A-1", ids[1])
+	require.NoError(t, err)
+	require.False(t, taken)
+	taken, err = repo.ProfileFieldValueTaken(ctx, tenantID, "level", float64(10), ids[0])
+	require.NoError(t, err)
+	require.True(t, taken)
+
+	var userIDs []string
+	err = db.NewSelect().Table("users").Column("users.id").
+		Apply(InventoryCriteria(types.UserInventoryFilter{
+			Scope:            scope,
+			ProfileFields:    map[string]any{"remote": true},
+			SortProfileField: "level",
+			SortDescending:   true,
+		}, "users.id")).
+		Scan(ctx, &userIDs)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[2].String(), ids[0].String()}, userIDs)
+
+	// A global profile is only consulted where the tenant profile is silent.
+	_, err = repo.UpsertProfile(ctx, types.UserProfile{UserID: ids[1], CustomFields: map[string]any{"level": 1, "team": "ops"}, CreatedBy: ids[1], UpdatedBy: ids[1]})
+	require.NoError(t, err)
+
+	userIDs = nil
+	err = db.NewSelect().Table("users").Column("users.id").
+		Apply(InventoryCriteria(types.UserInventoryFilter{Scope: scope, SortProfileField: "level"}, "users.id")).
+		Scan(ctx, &userIDs)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[0].String(), ids[2].String(), ids[1].String()}, userIDs)
+
+	userIDs = nil
+	err = db.NewSelect().Table("users").Column("users.id").
+		Apply(InventoryCriteria(types.UserInventoryFilter{Scope: scope, ProfileFields: map[string]any{"team": "ops"}}, "users.id")).
+		Scan(ctx, &userIDs)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[1].String()}, userIDs)
+
+	userIDs = nil
+	err = db.NewSelect().Table("users").Column("users.id").
+		Apply(InventoryCriteria(types.UserInventoryFilter{ProfileFields: map[string]any{"level": 1}}, "users.id")).
+		Scan(ctx, &userIDs)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[1].String()}, userIDs, "unscoped listings read global profiles only")
+}
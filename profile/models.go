@@ -20,12 +20,18 @@ type Record struct {
 	Bio         string         `bun:"bio"`
 	Contact     map[string]any `bun:"contact,type:jsonb"`
 	Metadata    map[string]any `bun:"metadata,type:jsonb"`
-	TenantID    uuid.UUID      `bun:"tenant_id,type:uuid"`
-	OrgID       uuid.UUID      `bun:"org_id,type:uuid"`
-	CreatedAt   time.Time      `bun:"created_at"`
-	CreatedBy   uuid.UUID      `bun:"created_by,type:uuid"`
-	UpdatedAt   time.Time      `bun:"updated_at"`
-	UpdatedBy   uuid.UUID      `bun:"updated_by,type:uuid"`
+	// CustomFields requires migration 00015_profile_custom_fields.
+	CustomFields map[string]any `bun:"custom_fields,type:jsonb"`
+	// TenantID and OrgID complete the primary key (migration
+	// 00017_profile_scopes); nil IDs identify the global profile.
+	TenantID uuid.UUID `bun:"tenant_id,pk,type:uuid"`
+	OrgID    uuid.UUID `bun:"org_id,pk,type:uuid"`
+	// Version requires migration 00016_profile_versions.
+	Version   int       `bun:"version"`
+	CreatedAt time.Time `bun:"created_at"`
+	CreatedBy uuid.UUID `bun:"created_by,type:uuid"`
+	UpdatedAt time.Time `bun:"updated_at"`
+	UpdatedBy uuid.UUID `bun:"updated_by,type:uuid"`
 }
 
 // CanonicalizeLocale normalizes the stored locale field into canonical form.
@@ -35,3 +41,43 @@ func (r *Record) CanonicalizeLocale() {
 	}
 	r.Locale = i18n.NormalizeLocale(r.Locale)
 }
+
+// HistoryRecord models the append-only user_profiles_history row.
+type HistoryRecord struct {
+	bun.BaseModel `bun:"table:user_profiles_history"`
+
+	ID           uuid.UUID      `bun:"id,pk,type:uuid"`
+	UserID       uuid.UUID      `bun:"user_id,type:uuid"`
+	TenantID     uuid.UUID      `bun:"tenant_id,type:uuid"`
+	OrgID        uuid.UUID      `bun:"org_id,type:uuid"`
+	Version      int            `bun:"version"`
+	DisplayName  string         `bun:"display_name"`
+	AvatarURL    string         `bun:"avatar_url"`
+	Locale       string         `bun:"locale"`
+	Timezone     string         `bun:"timezone"`
+	Bio          string         `bun:"bio"`
+	Contact      map[string]any `bun:"contact,type:jsonb"`
+	Metadata     map[string]any `bun:"metadata,type:jsonb"`
+	CustomFields map[string]any `bun:"custom_fields,type:jsonb"`
+	ChangedAt    time.Time      `bun:"changed_at"`
+	ChangedBy    uuid.UUID      `bun:"changed_by,type:uuid"`
+}
+
+// FieldRecord models the user_profile_fields row.
+type FieldRecord struct {
+	bun.BaseModel `bun:"table:user_profile_fields"`
+
+	ID          uuid.UUID `bun:"id,pk,type:uuid"`
+	TenantID    uuid.UUID `bun:"tenant_id,type:uuid"`
+	Key         string    `bun:"key"`
+	Label       string    `bun:"label"`
+	Description string    `bun:"description"`
+	FieldType   string    `bun:"field_type"`
+	Options     []string  `bun:"options,type:jsonb"`
+	Required    bool      `bun:"required"`
+	Unique      bool      `bun:"is_unique"`
+	Visibility  string    `bun:"visibility"`
+	Position    int       `bun:"position"`
+	CreatedAt   time.Time `bun:"created_at"`
+	UpdatedAt   time.Time `bun:"updated_at"`
+}
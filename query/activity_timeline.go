@@ -0,0 +1,128 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// ErrActivityTimelineObjectRequired indicates timeline lookups omitted the object reference.
+var ErrActivityTimelineObjectRequired = errors.New("go-users: activity timeline object type and id required")
+
+// ActivityTimelineInput scopes a per-object history lookup.
+type ActivityTimelineInput struct {
+	Actor      types.ActorRef
+	Scope      types.ScopeFilter
+	ObjectType string
+	ObjectID   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+}
+
+// Type implements gocommand.Message.
+func (ActivityTimelineInput) Type() string {
+	return "query.activity.timeline"
+}
+
+// Validate implements gocommand.Message.
+func (input ActivityTimelineInput) Validate() error {
+	switch {
+	case input.Actor.ID == uuid.Nil:
+		return types.ErrActorRequired
+	case strings.TrimSpace(input.ObjectType) == "" || strings.TrimSpace(input.ObjectID) == "":
+		return ErrActivityTimelineObjectRequired
+	default:
+		return nil
+	}
+}
+
+// ActivityTimelineEntry is a single step in an object's history.
+type ActivityTimelineEntry struct {
+	ID         uuid.UUID
+	ActorID    uuid.UUID
+	Verb       string
+	Channel    string
+	OccurredAt time.Time
+	Changes    []activity.FieldChange
+}
+
+// ActivityTimeline lists an object's activity oldest first.
+type ActivityTimeline struct {
+	ObjectType string
+	ObjectID   string
+	Entries    []ActivityTimelineEntry
+	HasMore    bool
+}
+
+// ActivityTimelineQuery renders the change history of a single object using
+// the structured diffs stored on update activity.
+type ActivityTimelineQuery struct {
+	feed *ActivityFeedQuery
+}
+
+// NewActivityTimelineQuery constructs the timeline helper. Options are shared
+// with the feed query so the same access policy applies.
+func NewActivityTimelineQuery(repo types.ActivityRepository, guard scope.Guard, opts ...ActivityQueryOption) *ActivityTimelineQuery {
+	return &ActivityTimelineQuery{
+		feed: NewActivityFeedQuery(repo, guard, opts...),
+	}
+}
+
+var _ gocommand.Querier[ActivityTimelineInput, ActivityTimeline] = (*ActivityTimelineQuery)(nil)
+
+// Query fetches the object's activity and decodes recorded field changes.
+func (q *ActivityTimelineQuery) Query(ctx context.Context, input ActivityTimelineInput) (ActivityTimeline, error) {
+	if err := input.Validate(); err != nil {
+		return ActivityTimeline{}, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	page, err := q.feed.Query(ctx, types.ActivityFilter{
+		Actor:      input.Actor,
+		Scope:      input.Scope,
+		ObjectType: strings.TrimSpace(input.ObjectType),
+		ObjectID:   strings.TrimSpace(input.ObjectID),
+		Since:      input.Since,
+		Until:      input.Until,
+		Pagination: types.Pagination{Limit: limit},
+	})
+	if err != nil {
+		return ActivityTimeline{}, err
+	}
+	return BuildActivityTimeline(input.ObjectType, input.ObjectID, page.Records, page.HasMore), nil
+}
+
+// BuildActivityTimeline converts records into a chronological timeline.
+func BuildActivityTimeline(objectType, objectID string, records []types.ActivityRecord, hasMore bool) ActivityTimeline {
+	entries := make([]ActivityTimelineEntry, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, ActivityTimelineEntry{
+			ID:         record.ID,
+			ActorID:    record.ActorID,
+			Verb:       record.Verb,
+			Channel:    record.Channel,
+			OccurredAt: record.OccurredAt,
+			Changes:    activity.ChangesFromData(record.Data),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].OccurredAt.Before(entries[j].OccurredAt)
+	})
+	return ActivityTimeline{
+		ObjectType: strings.TrimSpace(objectType),
+		ObjectID:   strings.TrimSpace(objectID),
+		Entries:    entries,
+		HasMore:    hasMore,
+	}
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildActivityTimelineOrdersOldestFirst(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newer := activity.AttachChanges(types.ActivityRecord{
+		ID:         uuid.New(),
+		Verb:       "user.updated",
+		OccurredAt: base.Add(time.Hour),
+	}, nil, []activity.FieldChange{{Field: "email", Before: "a@example.com", After: "b@example.com"}})
+	older := types.ActivityRecord{
+		ID:         uuid.New(),
+		Verb:       "user.created",
+		OccurredAt: base,
+	}
+
+	timeline := BuildActivityTimeline("user", "42", []types.ActivityRecord{newer, older}, false)
+
+	require.Len(t, timeline.Entries, 2)
+	require.Equal(t, "user.created", timeline.Entries[0].Verb)
+	require.Empty(t, timeline.Entries[0].Changes)
+	require.Equal(t, "user.updated", timeline.Entries[1].Verb)
+	require.Len(t, timeline.Entries[1].Changes, 1)
+	require.Equal(t, "email", timeline.Entries[1].Changes[0].Field)
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"context"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// PasswordRotationInput asks whether a user must rotate their password.
+type PasswordRotationInput struct {
+	UserID uuid.UUID
+	Actor  types.ActorRef
+	Scope  types.ScopeFilter
+}
+
+// Type implements gocommand.Message.
+func (PasswordRotationInput) Type() string {
+	return "query.user.password_rotation"
+}
+
+// Validate implements gocommand.Message.
+func (input PasswordRotationInput) Validate() error {
+	switch {
+	case input.UserID == uuid.Nil:
+		return types.ErrUserIDRequired
+	case input.Actor.ID == uuid.Nil:
+		return types.ErrActorRequired
+	default:
+		return nil
+	}
+}
+
+// PasswordRotationQuery reports password age and whether a change is due.
+type PasswordRotationQuery struct {
+	repo     types.AuthRepository
+	rotation types.PasswordRotationPolicy
+	guard    scope.Guard
+}
+
+// NewPasswordRotationQuery constructs the password rotation query.
+func NewPasswordRotationQuery(repo types.AuthRepository, rotation types.PasswordRotationPolicy, guard scope.Guard) *PasswordRotationQuery {
+	return &PasswordRotationQuery{
+		repo:     repo,
+		rotation: rotation,
+		guard:    safeScopeGuard(guard),
+	}
+}
+
+var _ gocommand.Querier[PasswordRotationInput, types.PasswordRotationStatus] = (*PasswordRotationQuery)(nil)
+
+// Query returns the rotation status using the max age configured for the
+// enforced scope.
+func (q *PasswordRotationQuery) Query(ctx context.Context, input PasswordRotationInput) (types.PasswordRotationStatus, error) {
+	if q.repo == nil {
+		return types.PasswordRotationStatus{}, types.ErrMissingAuthRepository
+	}
+	if q.rotation == nil {
+		return types.PasswordRotationStatus{}, types.ErrMissingPasswordRotation
+	}
+	if err := input.Validate(); err != nil {
+		return types.PasswordRotationStatus{}, err
+	}
+	scope, err := q.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionUsersRead, input.UserID)
+	if err != nil {
+		return types.PasswordRotationStatus{}, err
+	}
+	user, err := q.repo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return types.PasswordRotationStatus{}, err
+	}
+	return q.rotation.RotationStatus(ctx, user, scope), nil
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// ErrPreferenceHistoryKeyRequired indicates a history listing without a key.
+var ErrPreferenceHistoryKeyRequired = errors.New("go-users: preference history key required")
+
+// PreferenceHistoryInput scopes a preference history listing.
+type PreferenceHistoryInput struct {
+	UserID uuid.UUID
+	Scope  types.ScopeFilter
+	Level  types.PreferenceLevel
+	Key    string
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	Actor  types.ActorRef
+}
+
+// Type implements gocommand.Message.
+func (PreferenceHistoryInput) Type() string {
+	return "query.preferences.history"
+}
+
+// Validate implements gocommand.Message.
+func (input PreferenceHistoryInput) Validate() error {
+	switch {
+	case input.Actor.ID == uuid.Nil:
+		return types.ErrActorRequired
+	case strings.TrimSpace(input.Key) == "":
+		return ErrPreferenceHistoryKeyRequired
+	case (input.Level == "" || input.Level == types.PreferenceLevelUser) && input.UserID == uuid.Nil:
+		return types.ErrUserIDRequired
+	default:
+		return nil
+	}
+}
+
+// PreferenceHistoryQuery lists the change history of a preference key.
+type PreferenceHistoryQuery struct {
+	repo  types.PreferenceRepository
+	guard scope.Guard
+}
+
+// NewPreferenceHistoryQuery constructs the history query helper.
+func NewPreferenceHistoryQuery(repo types.PreferenceRepository, guard scope.Guard) *PreferenceHistoryQuery {
+	return &PreferenceHistoryQuery{
+		repo:  repo,
+		guard: safeScopeGuard(guard),
+	}
+}
+
+var _ gocommand.Querier[PreferenceHistoryInput, []types.PreferenceHistoryEntry] = (*PreferenceHistoryQuery)(nil)
+
+// Query returns history entries for the key, newest first.
+func (q *PreferenceHistoryQuery) Query(ctx context.Context, input PreferenceHistoryInput) ([]types.PreferenceHistoryEntry, error) {
+	if q.repo == nil {
+		return nil, types.ErrMissingPreferenceRepository
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	history, ok := q.repo.(types.PreferenceHistoryRepository)
+	if !ok {
+		return nil, types.ErrPreferenceHistoryUnsupported
+	}
+	scope, err := q.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionPreferencesRead, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return history.ListPreferenceHistory(ctx, types.PreferenceHistoryFilter{
+		UserID: input.UserID,
+		Scope:  scope,
+		Level:  input.Level,
+		Keys:   []string{strings.TrimSpace(input.Key)},
+		Since:  input.Since,
+		Until:  input.Until,
+		Limit:  input.Limit,
+	})
+}
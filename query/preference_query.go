@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"time"
 
 	gocommand "github.com/goliatone/go-command"
 	"github.com/goliatone/go-users/pkg/types"
@@ -19,7 +20,9 @@ type PreferenceQueryInput struct {
 	Base            map[string]any
 	OutputMode      types.PreferenceOutputMode
 	IncludeVersions bool
-	Actor           types.ActorRef
+	// AsOf resolves preferences as they stood at the given time using history.
+	AsOf  *time.Time
+	Actor types.ActorRef
 }
 
 // Type implements gocommand.Message.
@@ -75,5 +78,6 @@ func (q *PreferenceQuery) Query(ctx context.Context, input PreferenceQueryInput)
 		Base:            input.Base,
 		OutputMode:      input.OutputMode,
 		IncludeVersions: input.IncludeVersions,
+		AsOf:            input.AsOf,
 	})
 }
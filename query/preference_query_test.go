@@ -33,6 +33,26 @@ func TestPreferenceQuery_DelegatesToResolver(t *testing.T) {
 	require.Equal(t, []string{"theme"}, resolver.lastInput.Keys)
 }
 
+func TestPreferenceHistoryQuery_ListsKeyHistory(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeHistoryRepo{entries: []types.PreferenceHistoryEntry{{Key: "theme", Version: 2}}}
+	query := NewPreferenceHistoryQuery(repo, nil)
+	actor := types.ActorRef{ID: uuid.New()}
+
+	entries, err := query.Query(context.Background(), PreferenceHistoryInput{UserID: userID, Key: " theme ", Limit: 5, Actor: actor})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, []string{"theme"}, repo.lastFilter.Keys)
+	require.Equal(t, userID, repo.lastFilter.UserID)
+	require.Equal(t, 5, repo.lastFilter.Limit)
+
+	_, err = query.Query(context.Background(), PreferenceHistoryInput{UserID: userID, Actor: actor})
+	require.ErrorIs(t, err, ErrPreferenceHistoryKeyRequired)
+
+	_, err = NewPreferenceHistoryQuery(nil, nil).Query(context.Background(), PreferenceHistoryInput{UserID: userID, Key: "theme", Actor: actor})
+	require.ErrorIs(t, err, types.ErrMissingPreferenceRepository)
+}
+
 type fakeResolver struct {
 	called    bool
 	lastInput preferences.ResolveInput
@@ -47,3 +67,25 @@ func (f *fakeResolver) Resolve(_ context.Context, input preferences.ResolveInput
 	}
 	return f.snapshot, nil
 }
+
+type fakeHistoryRepo struct {
+	entries    []types.PreferenceHistoryEntry
+	lastFilter types.PreferenceHistoryFilter
+}
+
+func (f *fakeHistoryRepo) ListPreferences(context.Context, types.PreferenceFilter) ([]types.PreferenceRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeHistoryRepo) UpsertPreference(_ context.Context, record types.PreferenceRecord) (*types.PreferenceRecord, error) {
+	return &record, nil
+}
+
+func (f *fakeHistoryRepo) DeletePreference(context.Context, uuid.UUID, types.ScopeFilter, types.PreferenceLevel, string) error {
+	return nil
+}
+
+func (f *fakeHistoryRepo) ListPreferenceHistory(_ context.Context, filter types.PreferenceHistoryFilter) ([]types.PreferenceHistoryEntry, error) {
+	f.lastFilter = filter
+	return f.entries, nil
+}
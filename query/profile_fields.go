@@ -0,0 +1,61 @@
+package query
+
+import (
+	"context"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// ProfileFieldsInput scopes custom profile field definition lookups.
+type ProfileFieldsInput struct {
+	Scope types.ScopeFilter
+	Actor types.ActorRef
+}
+
+// Type implements gocommand.Message.
+func (ProfileFieldsInput) Type() string {
+	return "query.profile.fields"
+}
+
+// Validate implements gocommand.Message.
+func (input ProfileFieldsInput) Validate() error {
+	if input.Actor.ID == uuid.Nil {
+		return types.ErrActorRequired
+	}
+	return nil
+}
+
+// ProfileFieldsQuery lists the custom profile field definitions in effect for
+// a tenant.
+type ProfileFieldsQuery struct {
+	repo  types.ProfileFieldRepository
+	guard scope.Guard
+}
+
+// NewProfileFieldsQuery constructs the definition query helper.
+func NewProfileFieldsQuery(repo types.ProfileFieldRepository, guard scope.Guard) *ProfileFieldsQuery {
+	return &ProfileFieldsQuery{
+		repo:  repo,
+		guard: safeScopeGuard(guard),
+	}
+}
+
+var _ gocommand.Querier[ProfileFieldsInput, []types.ProfileFieldDefinition] = (*ProfileFieldsQuery)(nil)
+
+// Query returns the global definitions merged with the scope tenant's own.
+func (q *ProfileFieldsQuery) Query(ctx context.Context, input ProfileFieldsInput) ([]types.ProfileFieldDefinition, error) {
+	if q.repo == nil {
+		return nil, types.ErrMissingProfileFieldRepository
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	scope, err := q.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionProfilesRead, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	return q.repo.ListProfileFields(ctx, scope.TenantID)
+}
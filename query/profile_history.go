@@ -0,0 +1,136 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	gocommand "github.com/goliatone/go-command"
+	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/goliatone/go-users/scope"
+	"github.com/google/uuid"
+)
+
+// ProfileHistoryInput scopes a profile revision listing. BeforeVersion pages
+// through older revisions.
+type ProfileHistoryInput struct {
+	UserID        uuid.UUID
+	Scope         types.ScopeFilter
+	BeforeVersion int
+	Since         *time.Time
+	Until         *time.Time
+	Limit         int
+	Actor         types.ActorRef
+}
+
+// Type implements gocommand.Message.
+func (ProfileHistoryInput) Type() string {
+	return "query.profile.history"
+}
+
+// Validate implements gocommand.Message.
+func (input ProfileHistoryInput) Validate() error {
+	switch {
+	case input.UserID == uuid.Nil:
+		return types.ErrUserIDRequired
+	case input.Actor.ID == uuid.Nil:
+		return types.ErrActorRequired
+	default:
+		return nil
+	}
+}
+
+// ProfileRevision is a profile history entry with the field changes it made
+// relative to the previous revision. The first revision diffs against an
+// empty profile.
+type ProfileRevision struct {
+	types.ProfileHistoryEntry
+	Changes []activity.FieldChange
+}
+
+// ProfileHistoryQuery lists prior revisions of a profile.
+type ProfileHistoryQuery struct {
+	repo   types.ProfileRepository
+	guard  scope.Guard
+	policy profile.ProfileAccessPolicy
+}
+
+// NewProfileHistoryQuery constructs the profile history query helper. With
+// WithProfileAccessPolicy, snapshots are sanitized for the actor before they
+// are diffed.
+func NewProfileHistoryQuery(repo types.ProfileRepository, guard scope.Guard, opts ...ProfileQueryOption) *ProfileHistoryQuery {
+	cfg := applyProfileQueryOptions(opts)
+	return &ProfileHistoryQuery{
+		repo:   repo,
+		guard:  safeScopeGuard(guard),
+		policy: cfg.policy,
+	}
+}
+
+var _ gocommand.Querier[ProfileHistoryInput, []ProfileRevision] = (*ProfileHistoryQuery)(nil)
+
+// Query returns revisions newest first. Changed values are masked with the
+// activity masker.
+func (q *ProfileHistoryQuery) Query(ctx context.Context, input ProfileHistoryInput) ([]ProfileRevision, error) {
+	if q.repo == nil {
+		return nil, types.ErrMissingProfileRepository
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	history, ok := q.repo.(types.ProfileHistoryRepository)
+	if !ok {
+		return nil, types.ErrProfileHistoryUnsupported
+	}
+	scope, err := q.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionProfilesRead, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := history.ListProfileHistory(ctx, types.ProfileHistoryFilter{
+		UserID:        input.UserID,
+		Scope:         scope,
+		BeforeVersion: input.BeforeVersion,
+		Since:         input.Since,
+		Until:         input.Until,
+		Limit:         input.Limit,
+	})
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	// Load the revision preceding the oldest returned entry as its baseline.
+	count := len(entries)
+	if oldest := entries[count-1].Profile.Version; oldest > 1 {
+		baseline, err := history.ListProfileHistory(ctx, types.ProfileHistoryFilter{
+			UserID:        input.UserID,
+			Scope:         scope,
+			BeforeVersion: oldest,
+			Limit:         1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, baseline...)
+	}
+	if q.policy != nil {
+		for i := range entries {
+			sanitized, err := q.policy.Sanitize(ctx, input.Actor, entries[i].Profile)
+			if err != nil {
+				return nil, err
+			}
+			entries[i].Profile = sanitized
+		}
+	}
+	revisions := make([]ProfileRevision, 0, count)
+	for i := range count {
+		var previous *types.UserProfile
+		if i+1 < len(entries) {
+			previous = &entries[i+1].Profile
+		}
+		revisions = append(revisions, ProfileRevision{
+			ProfileHistoryEntry: entries[i],
+			Changes:             activity.MaskChanges(nil, activity.DiffUserProfile(previous, &entries[i].Profile)),
+		})
+	}
+	return revisions, nil
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileHistoryQuery_DiffsRevisions(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := &historyProfileRepo{entries: []types.ProfileHistoryEntry{
+		{Profile: types.UserProfile{UserID: userID, Version: 3, DisplayName: "Ada L.", Contact: map[string]any{"phone": "555"}}},
+		{Profile: types.UserProfile{UserID: userID, Version: 2, DisplayName: "Ada L."}},
+		{Profile: types.UserProfile{UserID: userID, Version: 1, DisplayName: "Ada"}},
+	}}
+	owner := types.ActorRef{ID: userID}
+
+	revisions, err := NewProfileHistoryQuery(repo, nil).Query(ctx, ProfileHistoryInput{UserID: userID, Actor: owner})
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	require.Equal(t, 3, revisions[0].Profile.Version)
+	require.Len(t, revisions[0].Changes, 1)
+	require.Equal(t, "contact.phone", revisions[0].Changes[0].Field)
+	require.Equal(t, "display_name", revisions[1].Changes[0].Field)
+	require.Equal(t, "Ada", revisions[1].Changes[0].Before)
+	require.Equal(t, "Ada", revisions[2].Changes[0].After)
+
+	page, err := NewProfileHistoryQuery(repo, nil).Query(ctx, ProfileHistoryInput{UserID: userID, Actor: owner, BeforeVersion: 3, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, 2, page[0].Profile.Version)
+	require.Equal(t, "Ada", page[0].Changes[0].Before)
+
+	viewer := types.ActorRef{ID: uuid.New()}
+	sanitized, err := NewProfileHistoryQuery(repo, nil, WithProfileAccessPolicy(profile.NewDefaultAccessPolicy())).Query(ctx, ProfileHistoryInput{UserID: userID, Actor: viewer, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, sanitized, 1)
+	require.Nil(t, sanitized[0].Profile.Contact)
+	require.Empty(t, sanitized[0].Changes)
+
+	_, err = NewProfileHistoryQuery(staticProfileRepo{}, nil).Query(ctx, ProfileHistoryInput{UserID: userID, Actor: owner})
+	require.ErrorIs(t, err, types.ErrProfileHistoryUnsupported)
+}
+
+type historyProfileRepo struct {
+	staticProfileRepo
+	entries []types.ProfileHistoryEntry
+}
+
+func (r *historyProfileRepo) ListProfileHistory(_ context.Context, filter types.ProfileHistoryFilter) ([]types.ProfileHistoryEntry, error) {
+	var out []types.ProfileHistoryEntry
+	for _, entry := range r.entries {
+		if filter.BeforeVersion > 0 && entry.Profile.Version >= filter.BeforeVersion {
+			continue
+		}
+		out = append(out, entry)
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			break
+		}
+	}
+	return out, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	gocommand "github.com/goliatone/go-command"
 	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
 )
@@ -14,6 +15,11 @@ type ProfileQueryInput struct {
 	UserID uuid.UUID
 	Scope  types.ScopeFilter
 	Actor  types.ActorRef
+	// ExactScope returns only the profile stored for exactly the enforced
+	// scope. By default the org, tenant, and global profiles are merged (see
+	// types.ResolveProfile), so profiles stored globally are visible in every
+	// tenant.
+	ExactScope bool
 }
 
 // Type implements gocommand.Message.
@@ -33,17 +39,47 @@ func (input ProfileQueryInput) Validate() error {
 	}
 }
 
+type profileQueryConfig struct {
+	policy profile.ProfileAccessPolicy
+}
+
+// ProfileQueryOption customizes profile query behavior.
+type ProfileQueryOption func(*profileQueryConfig)
+
+// WithProfileAccessPolicy applies field-level read rules to returned profiles.
+func WithProfileAccessPolicy(policy profile.ProfileAccessPolicy) ProfileQueryOption {
+	return func(cfg *profileQueryConfig) {
+		if cfg == nil {
+			return
+		}
+		cfg.policy = policy
+	}
+}
+
+func applyProfileQueryOptions(opts []ProfileQueryOption) profileQueryConfig {
+	cfg := profileQueryConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
+
 // ProfileQuery fetches user profile records.
 type ProfileQuery struct {
-	repo  types.ProfileRepository
-	guard scope.Guard
+	repo   types.ProfileRepository
+	guard  scope.Guard
+	policy profile.ProfileAccessPolicy
 }
 
 // NewProfileQuery constructs the profile query helper.
-func NewProfileQuery(repo types.ProfileRepository, guard scope.Guard) *ProfileQuery {
+func NewProfileQuery(repo types.ProfileRepository, guard scope.Guard, opts ...ProfileQueryOption) *ProfileQuery {
+	cfg := applyProfileQueryOptions(opts)
 	return &ProfileQuery{
-		repo:  repo,
-		guard: safeScopeGuard(guard),
+		repo:   repo,
+		guard:  safeScopeGuard(guard),
+		policy: cfg.policy,
 	}
 }
 
@@ -61,5 +97,70 @@ func (q *ProfileQuery) Query(ctx context.Context, input ProfileQueryInput) (*typ
 	if err != nil {
 		return nil, err
 	}
-	return q.repo.GetProfile(ctx, input.UserID, scope)
+	record, err := loadProfile(ctx, q.repo, input, scope)
+	if err != nil || record == nil || q.policy == nil {
+		return record, err
+	}
+	sanitized, err := q.policy.Sanitize(ctx, input.Actor, *record)
+	if err != nil {
+		return nil, err
+	}
+	return &sanitized, nil
+}
+
+// PublicProfileQuery fetches the public projection of a user profile, safe to
+// show to any actor allowed to read profiles.
+type PublicProfileQuery struct {
+	repo   types.ProfileRepository
+	guard  scope.Guard
+	policy profile.ProfileAccessPolicy
+}
+
+// NewPublicProfileQuery constructs the public profile query helper. The
+// default profile access policy is used unless WithProfileAccessPolicy is
+// supplied.
+func NewPublicProfileQuery(repo types.ProfileRepository, guard scope.Guard, opts ...ProfileQueryOption) *PublicProfileQuery {
+	cfg := applyProfileQueryOptions(opts)
+	policy := cfg.policy
+	if policy == nil {
+		policy = profile.NewDefaultAccessPolicy()
+	}
+	return &PublicProfileQuery{
+		repo:   repo,
+		guard:  safeScopeGuard(guard),
+		policy: policy,
+	}
+}
+
+var _ gocommand.Querier[ProfileQueryInput, *types.PublicProfile] = (*PublicProfileQuery)(nil)
+
+// Query returns the public projection for the supplied identifiers, or nil
+// when the profile does not exist.
+func (q *PublicProfileQuery) Query(ctx context.Context, input ProfileQueryInput) (*types.PublicProfile, error) {
+	if q.repo == nil {
+		return nil, types.ErrMissingProfileRepository
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	scope, err := q.guard.Enforce(ctx, input.Actor, input.Scope, types.PolicyActionProfilesRead, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := loadProfile(ctx, q.repo, input, scope)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	public, err := q.policy.Public(ctx, *record)
+	if err != nil {
+		return nil, err
+	}
+	return &public, nil
+}
+
+func loadProfile(ctx context.Context, repo types.ProfileRepository, input ProfileQueryInput, scope types.ScopeFilter) (*types.UserProfile, error) {
+	if input.ExactScope {
+		return repo.GetProfile(ctx, input.UserID, scope)
+	}
+	return types.ResolveProfile(ctx, repo, input.UserID, scope)
 }
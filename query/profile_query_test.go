@@ -0,0 +1,57 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileQueries_ApplyAccessPolicy(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	repo := staticProfileRepo{profile: &types.UserProfile{
+		UserID:      ownerID,
+		DisplayName: "Ada",
+		Contact:     map[string]any{"email": "ada@example.com"},
+		CreatedBy:   ownerID,
+	}}
+	viewer := types.ActorRef{ID: uuid.New(), Type: "member"}
+	input := ProfileQueryInput{UserID: ownerID, Actor: viewer}
+
+	unrestricted, err := NewProfileQuery(repo, nil).Query(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, "ada@example.com", unrestricted.Contact["email"])
+
+	redacted, err := NewProfileQuery(repo, nil, WithProfileAccessPolicy(profile.NewDefaultAccessPolicy())).Query(ctx, input)
+	require.NoError(t, err)
+	require.Nil(t, redacted.Contact)
+	require.Equal(t, "ada@example.com", repo.profile.Contact["email"])
+
+	public, err := NewPublicProfileQuery(repo, nil).Query(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, &types.PublicProfile{UserID: ownerID, DisplayName: "Ada"}, public)
+
+	missing, err := NewPublicProfileQuery(staticProfileRepo{}, nil).Query(ctx, input)
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+type staticProfileRepo struct {
+	profile *types.UserProfile
+}
+
+func (s staticProfileRepo) GetProfile(context.Context, uuid.UUID, types.ScopeFilter) (*types.UserProfile, error) {
+	if s.profile == nil {
+		return nil, nil
+	}
+	clone := *s.profile
+	return &clone, nil
+}
+
+func (s staticProfileRepo) UpsertProfile(_ context.Context, profile types.UserProfile) (*types.UserProfile, error) {
+	return &profile, nil
+}
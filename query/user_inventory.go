@@ -2,9 +2,14 @@ package query
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	gocommand "github.com/goliatone/go-command"
 	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/profile"
 	"github.com/goliatone/go-users/scope"
 	"github.com/google/uuid"
 )
@@ -14,20 +19,65 @@ const (
 	maxInventoryLimit     = 200
 )
 
+type inventoryQueryConfig struct {
+	fields types.ProfileFieldRepository
+	policy profile.ProfileAccessPolicy
+}
+
+// UserInventoryOption customizes user inventory query behavior.
+type UserInventoryOption func(*inventoryQueryConfig)
+
+// WithInventoryProfileFields validates ProfileFields and SortProfileField
+// filters against the tenant's custom profile field definitions.
+func WithInventoryProfileFields(repo types.ProfileFieldRepository) UserInventoryOption {
+	return func(cfg *inventoryQueryConfig) {
+		if cfg == nil {
+			return
+		}
+		cfg.fields = repo
+	}
+}
+
+// WithInventoryAccessPolicy limits ProfileFields and SortProfileField to the
+// custom fields policy lets the actor read on other users' profiles. Without
+// it, the default profile access policy is applied to the field definitions.
+func WithInventoryAccessPolicy(policy profile.ProfileAccessPolicy) UserInventoryOption {
+	return func(cfg *inventoryQueryConfig) {
+		if cfg == nil {
+			return
+		}
+		cfg.policy = policy
+	}
+}
+
 // UserInventoryQuery wraps ListUsers repositories and normalizes filters for
 // admin dashboards.
 type UserInventoryQuery struct {
 	repo   types.UserInventoryRepository
 	logger types.Logger
 	guard  scope.Guard
+	fields types.ProfileFieldRepository
+	policy profile.ProfileAccessPolicy
 }
 
 // NewUserInventoryQuery constructs the query helper.
-func NewUserInventoryQuery(repo types.UserInventoryRepository, logger types.Logger, guard scope.Guard) *UserInventoryQuery {
+func NewUserInventoryQuery(repo types.UserInventoryRepository, logger types.Logger, guard scope.Guard, opts ...UserInventoryOption) *UserInventoryQuery {
+	cfg := inventoryQueryConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	policy := cfg.policy
+	if policy == nil {
+		policy = profile.NewDefaultAccessPolicy(profile.WithProfileFieldDefinitions(cfg.fields))
+	}
 	return &UserInventoryQuery{
 		repo:   repo,
 		logger: logger,
 		guard:  safeScopeGuard(guard),
+		fields: cfg.fields,
+		policy: policy,
 	}
 }
 
@@ -46,10 +96,84 @@ func (q *UserInventoryQuery) Query(ctx context.Context, filter types.UserInvento
 		return types.UserInventoryPage{}, err
 	}
 	filter.Scope = scope
+	if err := q.resolveProfileFields(ctx, &filter); err != nil {
+		return types.UserInventoryPage{}, err
+	}
 	normalized := normalizeInventoryFilter(filter)
 	return q.repo.ListUsers(ctx, normalized)
 }
 
+// resolveProfileFields rewrites custom field filter and sort keys to their
+// canonical definition keys, rejecting keys the tenant does not define and
+// keys the actor may not read, since filtering and sorting on a field reveal
+// its values.
+func (q *UserInventoryQuery) resolveProfileFields(ctx context.Context, filter *types.UserInventoryFilter) error {
+	sortKey := strings.TrimSpace(filter.SortProfileField)
+	if len(filter.ProfileFields) == 0 && sortKey == "" {
+		return nil
+	}
+	if q.fields == nil {
+		return types.ErrMissingProfileFieldRepository
+	}
+	defs, err := q.fields.ListProfileFields(ctx, filter.Scope.TenantID)
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]types.ProfileFieldDefinition, len(defs))
+	for _, def := range defs {
+		byKey[strings.ToLower(def.Key)] = def
+	}
+	if len(filter.ProfileFields) > 0 {
+		fields := make(map[string]any, len(filter.ProfileFields))
+		for key, value := range filter.ProfileFields {
+			def, ok := byKey[strings.ToLower(strings.TrimSpace(key))]
+			if !ok {
+				return fmt.Errorf("%w: %q", types.ErrProfileFieldUnknown, key)
+			}
+			if err := def.ValidateValue(value); err != nil {
+				return err
+			}
+			fields[def.Key] = value
+		}
+		filter.ProfileFields = fields
+	}
+	if sortKey != "" {
+		def, ok := byKey[strings.ToLower(sortKey)]
+		if !ok {
+			return fmt.Errorf("%w: %q", types.ErrProfileFieldUnknown, sortKey)
+		}
+		filter.SortProfileField = def.Key
+	}
+	return q.ensureFieldsReadable(ctx, *filter)
+}
+
+// ensureFieldsReadable sanitizes a probe profile holding the filter and sort
+// fields for the actor. The probe has no owner, so the self audience never
+// applies, and every field must survive unmasked.
+func (q *UserInventoryQuery) ensureFieldsReadable(ctx context.Context, filter types.UserInventoryFilter) error {
+	const probe = "probe"
+	values := make(map[string]any, len(filter.ProfileFields)+1)
+	for key := range filter.ProfileFields {
+		values[key] = probe
+	}
+	if filter.SortProfileField != "" {
+		values[filter.SortProfileField] = probe
+	}
+	visible, err := q.policy.Sanitize(ctx, filter.Actor, types.UserProfile{
+		Scope:        filter.Scope,
+		CustomFields: values,
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if visible.CustomFields[key] != probe {
+			return fmt.Errorf("%w: %q", types.ErrProfileFieldNotReadable, key)
+		}
+	}
+	return nil
+}
+
 func normalizeInventoryFilter(filter types.UserInventoryFilter) types.UserInventoryFilter {
 	out := filter
 	if out.Pagination.Limit <= 0 {
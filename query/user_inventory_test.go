@@ -53,3 +53,93 @@ func (r *recordingInventoryRepo) ListUsers(_ context.Context, filter types.UserI
 	r.lastFilter = filter
 	return r.page, nil
 }
+
+func TestUserInventoryQuery_ResolvesProfileFieldFilters(t *testing.T) {
+	repo := &recordingInventoryRepo{}
+	fields := staticProfileFields{
+		{Key: "department", Type: types.ProfileFieldTypeEnum, Options: []string{"eng", "ops"}},
+		{Key: "employeeNumber", Type: types.ProfileFieldTypeInteger},
+	}
+	actor := types.ActorRef{ID: uuid.New()}
+
+	_, err := NewUserInventoryQuery(repo, types.NopLogger{}, nil).Query(context.Background(), types.UserInventoryFilter{
+		Actor:         actor,
+		ProfileFields: map[string]any{"department": "eng"},
+	})
+	require.ErrorIs(t, err, types.ErrMissingProfileFieldRepository)
+
+	query := NewUserInventoryQuery(repo, types.NopLogger{}, nil, WithInventoryProfileFields(fields))
+	_, err = query.Query(context.Background(), types.UserInventoryFilter{
+		Actor:            actor,
+		ProfileFields:    map[string]any{"Department": "eng"},
+		SortProfileField: "EMPLOYEENUMBER",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"department": "eng"}, repo.lastFilter.ProfileFields)
+	require.Equal(t, "employeeNumber", repo.lastFilter.SortProfileField)
+
+	_, err = query.Query(context.Background(), types.UserInventoryFilter{
+		Actor:         actor,
+		ProfileFields: map[string]any{"department": "sales"},
+	})
+	require.ErrorIs(t, err, types.ErrProfileFieldInvalid)
+
+	_, err = query.Query(context.Background(), types.UserInventoryFilter{
+		Actor:            actor,
+		SortProfileField: "shoe_size",
+	})
+	require.ErrorIs(t, err, types.ErrProfileFieldUnknown)
+}
+
+func TestUserInventoryQuery_RejectsProfileFieldsTheActorCannotRead(t *testing.T) {
+	ctx := context.Background()
+	repo := &recordingInventoryRepo{}
+	fields := staticProfileFields{
+		{Key: "department", Type: types.ProfileFieldTypeString},
+		{Key: "badge", Type: types.ProfileFieldTypeString, Visibility: types.ProfileFieldVisibilitySelf},
+		{Key: "salary", Type: types.ProfileFieldTypeInteger, Visibility: types.ProfileFieldVisibilityAdmin},
+	}
+	query := NewUserInventoryQuery(repo, types.NopLogger{}, nil, WithInventoryProfileFields(fields))
+	member := types.ActorRef{ID: uuid.New()}
+
+	_, err := query.Query(ctx, types.UserInventoryFilter{
+		Actor:         member,
+		ProfileFields: map[string]any{"department": "eng", "salary": 100},
+	})
+	require.ErrorIs(t, err, types.ErrProfileFieldNotReadable)
+
+	_, err = query.Query(ctx, types.UserInventoryFilter{
+		Actor:            member,
+		SortProfileField: "badge",
+	})
+	require.ErrorIs(t, err, types.ErrProfileFieldNotReadable)
+
+	_, err = query.Query(ctx, types.UserInventoryFilter{
+		Actor:         member,
+		ProfileFields: map[string]any{"department": "eng"},
+	})
+	require.NoError(t, err)
+
+	admin := types.ActorRef{ID: uuid.New(), Type: types.ActorRoleTenantAdmin}
+	_, err = query.Query(ctx, types.UserInventoryFilter{
+		Actor:            admin,
+		ProfileFields:    map[string]any{"salary": 100},
+		SortProfileField: "badge",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "badge", repo.lastFilter.SortProfileField)
+}
+
+type staticProfileFields []types.ProfileFieldDefinition
+
+func (s staticProfileFields) ListProfileFields(context.Context, uuid.UUID) ([]types.ProfileFieldDefinition, error) {
+	return append([]types.ProfileFieldDefinition(nil), s...), nil
+}
+
+func (staticProfileFields) UpsertProfileField(_ context.Context, def types.ProfileFieldDefinition) (*types.ProfileFieldDefinition, error) {
+	return &def, nil
+}
+
+func (staticProfileFields) DeleteProfileField(context.Context, uuid.UUID, string) error {
+	return nil
+}
@@ -255,7 +255,11 @@ func (r *mtAuthRepo) Create(_ context.Context, input *types.AuthUser) (*types.Au
 }
 
 func (r *mtAuthRepo) Update(_ context.Context, input *types.AuthUser) (*types.AuthUser, error) {
-	r.users[input.ID] = &mtUser{user: input}
+	entry := &mtUser{user: input}
+	if existing, ok := r.users[input.ID]; ok {
+		entry.tenant = existing.tenant
+	}
+	r.users[input.ID] = entry
 	return input, nil
 }
 
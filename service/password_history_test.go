@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/password"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PasswordHistoryVerifiesWithPasswordServiceHasher(t *testing.T) {
+	ctx := context.Background()
+	authRepo := newMTAuthRepo()
+	userID := authRepo.seedUser(uuid.New())
+	actor := types.ActorRef{ID: uuid.New(), Type: "system"}
+	history := &memoryPasswordHistory{}
+
+	svc := service.New(service.Config{
+		AuthRepository:            authRepo,
+		PasswordService:           password.NewService(password.ServiceConfig{Hasher: password.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}}),
+		PasswordHistoryRepository: history,
+	})
+
+	reset := func() error {
+		return svc.Commands().UserPasswordReset.Execute(ctx, command.UserPasswordResetInput{
+			UserID:      userID,
+			NewPassword: "Correct-Horse-42",
+			Actor:       actor,
+		})
+	}
+	require.NoError(t, reset())
+	require.Len(t, history.entries, 1)
+	require.Contains(t, history.entries[0].PasswordHash, "$argon2id$")
+	require.ErrorIs(t, reset(), types.ErrPasswordReused)
+}
+
+type memoryPasswordHistory struct {
+	entries []types.PasswordHistoryEntry
+}
+
+func (m *memoryPasswordHistory) AppendPasswordHistory(_ context.Context, entry types.PasswordHistoryEntry, _ int) error {
+	m.entries = append([]types.PasswordHistoryEntry{entry}, m.entries...)
+	return nil
+}
+
+func (m *memoryPasswordHistory) ListPasswordHistory(_ context.Context, userID uuid.UUID, limit int) ([]types.PasswordHistoryEntry, error) {
+	var out []types.PasswordHistoryEntry
+	for _, entry := range m.entries {
+		if entry.UserID == userID && (limit <= 0 || len(out) < limit) {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryPasswordHistory) DeletePasswordHistory(context.Context, uuid.UUID) error {
+	m.entries = nil
+	return nil
+}
@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/query"
+	"github.com/goliatone/go-users/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PasswordRotationQueryAndSweep(t *testing.T) {
+	ctx := context.Background()
+	tenant := uuid.New()
+	authRepo := newMTAuthRepo()
+	userID := authRepo.seedUser(tenant)
+	actor := types.ActorRef{ID: uuid.New(), Type: "system"}
+	activityStore := newMTActivityStore()
+	clock := &rotationClock{at: time.Now().UTC()}
+
+	svc := service.New(service.Config{
+		AuthRepository:      authRepo,
+		InventoryRepository: authRepo,
+		ActivitySink:        activityStore,
+		ScopeResolver: staticScopeResolver{scopes: map[uuid.UUID]types.ScopeFilter{
+			userID: {TenantID: tenant},
+		}},
+		Clock:          clock,
+		PasswordMaxAge: 30 * 24 * time.Hour,
+	})
+
+	status, err := svc.Queries().PasswordRotation.Query(ctx, query.PasswordRotationInput{
+		UserID: userID,
+		Actor:  actor,
+	})
+	require.NoError(t, err)
+	require.False(t, status.Required)
+	require.True(t, status.ChangedAt.IsZero())
+
+	// The first sweep records a baseline for accounts without a change time
+	// instead of expiring them.
+	var result command.PasswordExpirySweepResult
+	require.NoError(t, svc.Commands().PasswordExpirySweep.Execute(ctx, command.PasswordExpirySweepInput{
+		Actor:  actor,
+		Result: &result,
+	}))
+	require.Empty(t, result.Expired)
+	require.Equal(t, []uuid.UUID{userID}, result.Baselined)
+
+	clock.at = clock.at.Add(31 * 24 * time.Hour)
+	result = command.PasswordExpirySweepResult{}
+	require.NoError(t, svc.Commands().PasswordExpirySweep.Execute(ctx, command.PasswordExpirySweepInput{
+		Actor:  actor,
+		Result: &result,
+	}))
+	require.Equal(t, []uuid.UUID{userID}, result.Expired)
+	require.Len(t, activityStore.records, 1)
+	require.Equal(t, tenant, activityStore.records[0].TenantID)
+
+	status, err = svc.Queries().PasswordRotation.Query(ctx, query.PasswordRotationInput{
+		UserID: userID,
+		Actor:  actor,
+	})
+	require.NoError(t, err)
+	require.Equal(t, types.PasswordRotationChangeRequired, status.Reason)
+}
+
+type rotationClock struct {
+	at time.Time
+}
+
+func (c *rotationClock) Now() time.Time {
+	return c.at
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/pkg/types"
+	"github.com/goliatone/go-users/preferences"
+	"github.com/goliatone/go-users/query"
+	"github.com/goliatone/go-users/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PreferenceEventsPublishesChanges(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	bus := preferences.NewEventBus()
+
+	var hooked []types.PreferenceEvent
+	svc := service.New(service.Config{
+		PreferenceRepository: newMTPreferenceRepo(),
+		PreferenceEvents:     bus,
+		Hooks: types.Hooks{
+			AfterPreferenceChange: func(_ context.Context, event types.PreferenceEvent) {
+				hooked = append(hooked, event)
+			},
+		},
+	})
+	require.Same(t, bus, svc.PreferenceEvents())
+
+	var received []types.PreferenceEvent
+	unsubscribe, err := svc.PreferenceEvents().Subscribe(types.PreferenceSubscriptionFilter{UserID: userID}, func(_ context.Context, event types.PreferenceEvent) {
+		received = append(received, event)
+	})
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	err = svc.Commands().PreferenceUpsert.Execute(ctx, command.PreferenceUpsertInput{
+		UserID: userID,
+		Key:    "theme",
+		Value:  map[string]any{"mode": "dark"},
+		Actor:  types.ActorRef{ID: userID},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, hooked, 1, "the configured hook still runs")
+	require.Len(t, received, 1)
+	require.Equal(t, "theme", received[0].Key)
+	require.Equal(t, types.PreferenceLevelUser, received[0].Level)
+}
+
+func TestService_PreferenceSnapshotCacheInvalidatesOnWrite(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	actor := types.ActorRef{ID: userID}
+	svc := service.New(service.Config{
+		PreferenceRepository:    newMTPreferenceRepo(),
+		PreferenceSnapshotCache: &preferences.SnapshotCacheConfig{},
+	})
+	cache := svc.PreferenceSnapshotCache()
+	require.NotNil(t, cache)
+
+	upsert := func(mode string) {
+		err := svc.Commands().PreferenceUpsert.Execute(ctx, command.PreferenceUpsertInput{
+			UserID: userID,
+			Key:    "theme",
+			Value:  map[string]any{"mode": mode},
+			Actor:  actor,
+		})
+		require.NoError(t, err)
+	}
+	read := func() any {
+		snapshot, err := svc.Queries().Preferences.Query(ctx, query.PreferenceQueryInput{
+			UserID:     userID,
+			OutputMode: types.PreferenceOutputRawValue,
+			Actor:      actor,
+		})
+		require.NoError(t, err)
+		return snapshot.Effective["theme"]
+	}
+
+	upsert("dark")
+	require.Equal(t, map[string]any{"mode": "dark"}, read())
+	require.Equal(t, map[string]any{"mode": "dark"}, read())
+	require.Equal(t, uint64(1), cache.Stats().Hits)
+
+	upsert("light")
+	require.Equal(t, map[string]any{"mode": "light"}, read())
+	require.Equal(t, uint64(2), cache.Stats().Misses)
+}
+
+func TestService_PreferenceSnapshotCacheInvalidatesOnRoleAssignment(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	actor := types.ActorRef{ID: uuid.New()}
+	svc := service.New(service.Config{
+		RoleRegistry:            newMTRoleRegistry(),
+		PreferenceRepository:    newMTPreferenceRepo(),
+		PreferenceSnapshotCache: &preferences.SnapshotCacheConfig{},
+	})
+	cache := svc.PreferenceSnapshotCache()
+	require.NotNil(t, cache)
+
+	read := func() {
+		_, err := svc.Queries().Preferences.Query(ctx, query.PreferenceQueryInput{
+			UserID: userID,
+			Actor:  actor,
+		})
+		require.NoError(t, err)
+	}
+	read()
+	read()
+	require.Equal(t, uint64(1), cache.Stats().Hits)
+
+	roleID := uuid.New()
+	require.NoError(t, svc.Commands().AssignRole.Execute(ctx, command.AssignRoleInput{
+		UserID: userID,
+		RoleID: roleID,
+		Actor:  actor,
+	}))
+	read()
+	require.Equal(t, uint64(2), cache.Stats().Misses)
+
+	require.NoError(t, svc.Commands().UnassignRole.Execute(ctx, command.UnassignRoleInput{
+		UserID: userID,
+		RoleID: roleID,
+		Actor:  actor,
+	}))
+	read()
+	require.Equal(t, uint64(3), cache.Stats().Misses)
+	require.Equal(t, uint64(2), cache.Stats().Invalidations)
+}
+
+func TestService_PreferenceReaderDecodesTypedKeys(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	svc := service.New(service.Config{PreferenceRepository: newMTPreferenceRepo()})
+	err := svc.Commands().PreferenceUpsertMany.Execute(ctx, command.PreferenceUpsertManyInput{
+		UserID: userID,
+		Values: map[string]any{"locale": "es_ES", "page_size": 25},
+		Actor:  types.ActorRef{ID: userID},
+	})
+	require.NoError(t, err)
+
+	reader, err := svc.PreferenceReader(preferences.ResolveInput{UserID: userID})
+	require.NoError(t, err)
+	locale, err := preferences.LocaleKey.Read(ctx, reader)
+	require.NoError(t, err)
+	require.Equal(t, types.NormalizeLocale("es_ES"), locale)
+	pageSize, err := preferences.Key[int]{Name: "page_size", Default: 10}.Read(ctx, reader)
+	require.NoError(t, err)
+	require.Equal(t, 25, pageSize)
+
+	_, err = service.New(service.Config{}).PreferenceReader(preferences.ResolveInput{})
+	require.ErrorIs(t, err, types.ErrMissingPreferenceResolver)
+}
@@ -6,9 +6,14 @@ import (
 
 	featuregate "github.com/goliatone/go-featuregate/gate"
 	"github.com/goliatone/go-users/activity"
+	"github.com/goliatone/go-users/avatar"
 	"github.com/goliatone/go-users/command"
+	"github.com/goliatone/go-users/lockout"
+	"github.com/goliatone/go-users/notification"
+	"github.com/goliatone/go-users/password"
 	"github.com/goliatone/go-users/pkg/types"
 	"github.com/goliatone/go-users/preferences"
+	"github.com/goliatone/go-users/profile"
 	"github.com/goliatone/go-users/query"
 	"github.com/goliatone/go-users/scope"
 )
@@ -24,47 +29,70 @@ type Service struct {
 	profileRepo    types.ProfileRepository
 	preferenceRepo types.PreferenceRepository
 	prefResolver   PreferenceResolver
+	snapshotCache  *preferences.CachedResolver
+	notifier       notification.Notifier
+	history        types.PasswordHistory
+	rotation       types.PasswordRotationPolicy
+	lockout        *lockout.Tracker
 	scopeGuard     scope.Guard
 }
 
 // Commands exposes the service command handlers.
 type Commands struct {
-	UserLifecycleTransition  *command.UserLifecycleTransitionCommand
-	BulkUserTransition       *command.BulkUserTransitionCommand
-	BulkUserImport           *command.BulkUserImportCommand
-	UserCreate               *command.UserCreateCommand
-	UserBootstrapPassword    *command.UserBootstrapPasswordCommand
-	UserUpdate               *command.UserUpdateCommand
-	UserInvite               *command.UserInviteCommand
-	UserRegistrationRequest  *command.UserRegistrationRequestCommand
-	UserTokenValidate        *command.UserTokenValidateCommand
-	UserTokenConsume         *command.UserTokenConsumeCommand
-	UserPasswordResetRequest *command.UserPasswordResetRequestCommand
-	UserPasswordResetConfirm *command.UserPasswordResetConfirmCommand
-	UserPasswordReset        *command.UserPasswordResetCommand
-	CreateRole               *command.CreateRoleCommand
-	UpdateRole               *command.UpdateRoleCommand
-	DeleteRole               *command.DeleteRoleCommand
-	AssignRole               *command.AssignRoleCommand
-	UnassignRole             *command.UnassignRoleCommand
-	LogActivity              *command.ActivityLogCommand
-	ProfileUpsert            *command.ProfileUpsertCommand
-	PreferenceUpsert         *command.PreferenceUpsertCommand
-	PreferenceDelete         *command.PreferenceDeleteCommand
-	PreferenceUpsertMany     *command.PreferenceUpsertManyCommand
-	PreferenceDeleteMany     *command.PreferenceDeleteManyCommand
+	UserLifecycleTransition    *command.UserLifecycleTransitionCommand
+	BulkUserTransition         *command.BulkUserTransitionCommand
+	BulkUserImport             *command.BulkUserImportCommand
+	UserCreate                 *command.UserCreateCommand
+	UserBootstrapPassword      *command.UserBootstrapPasswordCommand
+	UserUpdate                 *command.UserUpdateCommand
+	UserInvite                 *command.UserInviteCommand
+	UserRegistrationRequest    *command.UserRegistrationRequestCommand
+	UserTokenValidate          *command.UserTokenValidateCommand
+	UserTokenConsume           *command.UserTokenConsumeCommand
+	UserPasswordResetRequest   *command.UserPasswordResetRequestCommand
+	UserPasswordResetConfirm   *command.UserPasswordResetConfirmCommand
+	UserPasswordReset          *command.UserPasswordResetCommand
+	UserUnlock                 *command.UserUnlockCommand
+	PasswordExpirySweep        *command.PasswordExpirySweepCommand
+	ContactVerificationStart   *command.ContactVerificationStartCommand
+	ContactVerificationConfirm *command.ContactVerificationConfirmCommand
+	CreateRole                 *command.CreateRoleCommand
+	UpdateRole                 *command.UpdateRoleCommand
+	DeleteRole                 *command.DeleteRoleCommand
+	AssignRole                 *command.AssignRoleCommand
+	UnassignRole               *command.UnassignRoleCommand
+	LogActivity                *command.ActivityLogCommand
+	ActivityExport             *command.ActivityExportCommand
+	ProfileUpsert              *command.ProfileUpsertCommand
+	ProfileFieldUpsert         *command.ProfileFieldUpsertCommand
+	ProfileFieldDelete         *command.ProfileFieldDeleteCommand
+	AvatarUpload               *command.AvatarUploadCommand
+	PreferenceUpsert           *command.PreferenceUpsertCommand
+	PreferenceDelete           *command.PreferenceDeleteCommand
+	PreferenceUpsertMany       *command.PreferenceUpsertManyCommand
+	PreferenceDeleteMany       *command.PreferenceDeleteManyCommand
+	PreferenceRestore          *command.PreferenceRestoreCommand
+	PreferenceExport           *command.PreferenceExportCommand
+	PreferenceImport           *command.PreferenceImportCommand
+	PreferenceCopy             *command.PreferenceCopyCommand
 }
 
 // Queries exposes read-model helpers.
 type Queries struct {
-	UserInventory   *query.UserInventoryQuery
-	RoleList        *query.RoleListQuery
-	RoleDetail      *query.RoleDetailQuery
-	RoleAssignments *query.RoleAssignmentsQuery
-	ActivityFeed    *query.ActivityFeedQuery
-	ActivityStats   *query.ActivityStatsQuery
-	ProfileDetail   *query.ProfileQuery
-	Preferences     *query.PreferenceQuery
+	UserInventory     *query.UserInventoryQuery
+	RoleList          *query.RoleListQuery
+	RoleDetail        *query.RoleDetailQuery
+	RoleAssignments   *query.RoleAssignmentsQuery
+	ActivityFeed      *query.ActivityFeedQuery
+	ActivityStats     *query.ActivityStatsQuery
+	ActivityTimeline  *query.ActivityTimelineQuery
+	ProfileDetail     *query.ProfileQuery
+	PublicProfile     *query.PublicProfileQuery
+	ProfileFields     *query.ProfileFieldsQuery
+	ProfileHistory    *query.ProfileHistoryQuery
+	Preferences       *query.PreferenceQuery
+	PreferenceHistory *query.PreferenceHistoryQuery
+	PasswordRotation  *query.PasswordRotationQuery
 }
 
 // Config captures all required dependencies so callers can provide their own
@@ -98,11 +126,81 @@ type Config struct {
 	PasswordResetLinkRoute          string
 	TokenScopeEnforcer              types.ScopeEnforcer
 	ProfileRepository               types.ProfileRepository
+	ProfileFieldRepository          types.ProfileFieldRepository
 	PreferenceRepository            types.PreferenceRepository
 	PreferenceResolver              PreferenceResolver
 	ScopeResolver                   types.ScopeResolver
 	AuthorizationPolicy             types.AuthorizationPolicy
 	FeatureGate                     featuregate.FeatureGate
+	// PreferenceSchema validates preference writes and supplies system-level
+	// defaults to the built-in resolver.
+	PreferenceSchema *preferences.SchemaRegistry
+	// ProfileAccessPolicy applies field-level read rules to the ProfileDetail
+	// query and drives the PublicProfile projection. When nil, ProfileDetail
+	// returns whole profiles and PublicProfile uses
+	// profile.NewDefaultAccessPolicy.
+	ProfileAccessPolicy profile.ProfileAccessPolicy
+	// ActivityAccessPolicy restricts and sanitizes activity for the
+	// ActivityFeed, ActivityStats, and ActivityTimeline queries and the
+	// ActivityExport command. When nil, the queries return records as stored
+	// and exports drop record data.
+	ActivityAccessPolicy activity.ActivityAccessPolicy
+	// AvatarStorage stores images uploaded through the AvatarUpload command;
+	// uploads fail with types.ErrMissingAvatarStorage when it is nil.
+	AvatarStorage types.BlobStorage
+	// AvatarPolicy validates avatar uploads. The zero value enforces
+	// avatar.DefaultMaxBytes and avatar.DefaultContentTypes only.
+	AvatarPolicy avatar.Policy
+	// PreferenceEvents receives every preference change published by the
+	// commands and drives invalidation of the preference repository cache.
+	// Use preferences.NewEventBus in-process or an adapter for an external
+	// broker to fan changes out across processes.
+	PreferenceEvents types.PreferenceEventBus
+	// PreferenceSnapshotCache, when set, caches resolved preference snapshots
+	// in front of the resolver. Preference writes and role changes made
+	// through the service invalidate it, as do events on PreferenceEvents.
+	PreferenceSnapshotCache *preferences.SnapshotCacheConfig
+	// Notifier delivers invite, reset, lifecycle, and role notifications. When
+	// nil and NotificationTransports is set, a notification.Dispatcher is built
+	// that selects channels through the preference resolver.
+	Notifier               notification.Notifier
+	NotificationTransports map[notification.Channel]notification.Transport
+	NotificationTemplates  *notification.TemplateSet
+	// ContactChangeRepository stores email/phone changes pending verification
+	// for the ContactVerificationStart and ContactVerificationConfirm commands.
+	ContactChangeRepository      types.ContactChangeRepository
+	ContactVerificationLinkRoute string
+	// RequireContactVerification makes UserUpdate reject email changes and
+	// ProfileUpsert reject contact phone changes, so both only change through
+	// the contact verification commands.
+	RequireContactVerification bool
+	// PasswordService checks and hashes the plaintext password variants of
+	// the reset, reset confirm, and bootstrap commands (see
+	// password.NewService). Hash-only inputs keep working when it is nil.
+	PasswordService types.PasswordService
+	// PasswordHistory rejects reuse of recent plaintext passwords on reset
+	// and records every applied hash. When nil and PasswordHistoryRepository
+	// is set, a password.History is built that verifies with the
+	// PasswordService hasher (see types.PasswordHasherProvider), keeps
+	// PasswordHistoryRetention entries, and lets tenants override the
+	// retention through types.PreferenceKeyPasswordHistory. A PasswordService
+	// that does not expose its hasher leaves history disabled and logs an
+	// error; supply PasswordHistory directly in that case.
+	PasswordHistory           types.PasswordHistory
+	PasswordHistoryRepository types.PasswordHistoryRepository
+	PasswordHistoryRetention  int
+	// LoginAttemptStore enables the lockout tracker returned by Lockout,
+	// which counts failed sign-ins per identifier and IP and locks accounts
+	// according to LockoutPolicy (see lockout.NewMemoryStore and
+	// lockout.NewBunStore).
+	LoginAttemptStore types.LoginAttemptStore
+	LockoutPolicy     lockout.Policy
+	// PasswordMaxAge expires passwords older than the duration; tenants
+	// override it in days through types.PreferenceKeyPasswordMaxAge. Zero
+	// disables expiry unless a preference sets it. PasswordRotation
+	// replaces the password.Rotation built from these settings.
+	PasswordMaxAge   time.Duration
+	PasswordRotation types.PasswordRotationPolicy
 }
 
 // PreferenceResolver resolves scoped preferences for queries.
@@ -127,14 +225,90 @@ func New(cfg Config) *Service {
 	}
 	prefResolver := norm.PreferenceResolver
 	if prefResolver == nil && norm.PreferenceRepository != nil {
-		if resolver, err := preferences.NewResolver(preferences.ResolverConfig{
+		resolverCfg := preferences.ResolverConfig{
 			Repository: norm.PreferenceRepository,
-		}); err == nil {
+			Roles:      norm.RoleRegistry,
+		}
+		if norm.PreferenceSchema != nil {
+			resolverCfg.Defaults = norm.PreferenceSchema.Defaults()
+			resolverCfg.MergeStrategies = norm.PreferenceSchema.MergeStrategies()
+		}
+		if resolver, err := preferences.NewResolver(resolverCfg); err == nil {
 			prefResolver = resolver
 		} else if norm.Logger != nil {
 			norm.Logger.Error("go-users: preference resolver initialization failed", err)
 		}
 	}
+	var snapshotCache *preferences.CachedResolver
+	if prefResolver != nil && norm.PreferenceSnapshotCache != nil {
+		if cached, err := preferences.NewCachedResolver(prefResolver, *norm.PreferenceSnapshotCache); err == nil {
+			snapshotCache = cached
+			prefResolver = cached
+			norm.Hooks = invalidateSnapshotCache(norm.Hooks, cached)
+			if _, err := preferences.InvalidateOnEvents(norm.PreferenceEvents, cached, norm.Logger); err != nil {
+				norm.Logger.Error("go-users: preference snapshot cache subscription failed", err)
+			}
+		} else {
+			norm.Logger.Error("go-users: preference snapshot cache initialization failed", err)
+		}
+	}
+
+	notifier := norm.Notifier
+	if notifier == nil && len(norm.NotificationTransports) > 0 {
+		notifier = notification.NewDispatcher(notification.Config{
+			Resolver:   prefResolver,
+			Users:      norm.AuthRepository,
+			Profiles:   norm.ProfileRepository,
+			Transports: norm.NotificationTransports,
+			Templates:  norm.NotificationTemplates,
+			Activity:   norm.ActivitySink,
+			Hooks:      norm.Hooks,
+			Clock:      norm.Clock,
+			IDGen:      norm.IDGenerator,
+			Logger:     norm.Logger,
+		})
+	}
+
+	history := norm.PasswordHistory
+	if history == nil && norm.PasswordHistoryRepository != nil {
+		historyCfg := password.HistoryConfig{
+			Repository: norm.PasswordHistoryRepository,
+			Retention:  norm.PasswordHistoryRetention,
+			Clock:      norm.Clock,
+			Logger:     norm.Logger,
+		}
+		if prefResolver != nil {
+			historyCfg.Preferences = prefResolver
+		}
+		switch passwords := norm.PasswordService.(type) {
+		case nil:
+			history = password.NewHistory(historyCfg)
+		case types.PasswordHasherProvider:
+			historyCfg.Hasher = passwords.PasswordHasher()
+			history = password.NewHistory(historyCfg)
+		default:
+			norm.Logger.Error("go-users: password history initialization failed", types.ErrPasswordHasherUnavailable)
+		}
+	}
+
+	rotation := norm.PasswordRotation
+	if rotation == nil {
+		rotationCfg := password.RotationConfig{
+			MaxAge: norm.PasswordMaxAge,
+			Clock:  norm.Clock,
+			Logger: norm.Logger,
+		}
+		if prefResolver != nil {
+			rotationCfg.Preferences = prefResolver
+		}
+		rotation = password.NewRotation(rotationCfg)
+	}
+
+	if invalidator, ok := norm.PreferenceRepository.(types.PreferenceInvalidator); ok && norm.PreferenceEvents != nil {
+		if _, err := preferences.InvalidateOnEvents(norm.PreferenceEvents, invalidator, norm.Logger); err != nil {
+			norm.Logger.Error("go-users: preference cache subscription failed", err)
+		}
+	}
 
 	scopeGuard := scope.Ensure(scope.NewGuard(norm.ScopeResolver, norm.AuthorizationPolicy))
 
@@ -145,10 +319,15 @@ func New(cfg Config) *Service {
 		profileRepo:    norm.ProfileRepository,
 		preferenceRepo: norm.PreferenceRepository,
 		prefResolver:   prefResolver,
+		snapshotCache:  snapshotCache,
+		notifier:       notifier,
+		history:        history,
+		rotation:       rotation,
 		scopeGuard:     scopeGuard,
 	}
 	s.commands = s.buildCommands()
 	s.queries = s.buildQueries()
+	s.lockout = s.newLockoutTracker()
 	return s
 }
 
@@ -165,9 +344,24 @@ func normalizeConfig(cfg Config) Config {
 	if cfg.TransitionPolicy == nil {
 		cfg.TransitionPolicy = types.DefaultTransitionPolicy()
 	}
+	if cfg.PreferenceEvents != nil {
+		cfg.Hooks.AfterPreferenceChange = publishPreferenceEvents(cfg.Hooks.AfterPreferenceChange, cfg.PreferenceEvents, cfg.Logger)
+	}
 	return cfg
 }
 
+// publishPreferenceEvents chains bus publishing after the configured hook.
+func publishPreferenceEvents(next func(context.Context, types.PreferenceEvent), bus types.PreferenceEventBus, logger types.Logger) func(context.Context, types.PreferenceEvent) {
+	return func(ctx context.Context, event types.PreferenceEvent) {
+		if next != nil {
+			next(ctx, event)
+		}
+		if err := bus.Publish(ctx, event); err != nil {
+			logger.Error("go-users: preference event publish failed", err, "key", event.Key)
+		}
+	}
+}
+
 // Commands returns the command facade.
 func (s *Service) Commands() Commands {
 	return s.commands
@@ -234,6 +428,15 @@ func (s *Service) ScopeGuard() scope.Guard {
 	return scope.Ensure(s.scopeGuard)
 }
 
+// Lockout returns the login attempt tracker, or nil when
+// Config.LoginAttemptStore is not set.
+func (s *Service) Lockout() *lockout.Tracker {
+	if s == nil {
+		return nil
+	}
+	return s.lockout
+}
+
 // ActivitySink returns the configured sink so transports can emit activity
 // records for auxiliary workflows (e.g. CRUD controllers).
 func (s *Service) ActivitySink() types.ActivitySink {
@@ -243,6 +446,54 @@ func (s *Service) ActivitySink() types.ActivitySink {
 	return s.cfg.ActivitySink
 }
 
+// invalidateSnapshotCache chains local snapshot invalidation ahead of the
+// preference and role hooks so writes in this process are visible at once.
+func invalidateSnapshotCache(hooks types.Hooks, cache *preferences.CachedResolver) types.Hooks {
+	afterPreference := hooks.AfterPreferenceChange
+	hooks.AfterPreferenceChange = func(ctx context.Context, event types.PreferenceEvent) {
+		_ = cache.InvalidatePreferences(ctx, event)
+		if afterPreference != nil {
+			afterPreference(ctx, event)
+		}
+	}
+	afterRole := hooks.AfterRoleChange
+	hooks.AfterRoleChange = func(ctx context.Context, event types.RoleEvent) {
+		cache.InvalidateRoleChange(ctx, event)
+		if afterRole != nil {
+			afterRole(ctx, event)
+		}
+	}
+	return hooks
+}
+
+// PreferenceReader binds the service's preference resolver to input for
+// typed key reads such as preferences.LocaleKey.Read(ctx, reader). It does not
+// apply scope guards; use Queries().Preferences for actor-facing reads.
+func (s *Service) PreferenceReader(input preferences.ResolveInput) (*preferences.Reader, error) {
+	if s == nil || s.prefResolver == nil {
+		return nil, types.ErrMissingPreferenceResolver
+	}
+	return preferences.NewReader(s.prefResolver, input)
+}
+
+// PreferenceSnapshotCache returns the snapshot cache, or nil when
+// Config.PreferenceSnapshotCache is unset. Use it to read hit/miss stats.
+func (s *Service) PreferenceSnapshotCache() *preferences.CachedResolver {
+	if s == nil {
+		return nil
+	}
+	return s.snapshotCache
+}
+
+// PreferenceEvents returns the configured preference event bus so transports
+// can subscribe clients to live preference updates.
+func (s *Service) PreferenceEvents() types.PreferenceEventBus {
+	if s == nil {
+		return nil
+	}
+	return s.cfg.PreferenceEvents
+}
+
 func (s *Service) buildCommands() Commands {
 	lifecycle := s.newLifecycleCommand()
 	userCreate := s.newUserCreateCommand()
@@ -257,17 +508,40 @@ func (s *Service) buildCommands() Commands {
 			Create:     userCreate,
 			Reset:      userPasswordReset,
 			Clock:      s.cfg.Clock,
+			Passwords:  s.cfg.PasswordService,
 		}),
 		UserUpdate: command.NewUserUpdateCommand(command.UserUpdateCommandConfig{
+			Repository:               s.cfg.AuthRepository,
+			Policy:                   s.cfg.TransitionPolicy,
+			Clock:                    s.cfg.Clock,
+			Activity:                 s.cfg.ActivitySink,
+			Hooks:                    s.cfg.Hooks,
+			Logger:                   s.cfg.Logger,
+			ScopeGuard:               s.scopeGuard,
+			RequireEmailVerification: s.cfg.RequireContactVerification,
+		}),
+		UserPasswordReset: userPasswordReset,
+		UserUnlock: command.NewUserUnlockCommand(command.UserUnlockCommandConfig{
 			Repository: s.cfg.AuthRepository,
-			Policy:     s.cfg.TransitionPolicy,
+			Lifecycle:  lifecycle,
+			Attempts:   s.cfg.LoginAttemptStore,
+			Clock:      s.cfg.Clock,
+			Activity:   s.cfg.ActivitySink,
+			Hooks:      s.cfg.Hooks,
+			Logger:     s.cfg.Logger,
+			ScopeGuard: s.scopeGuard,
+		}),
+		PasswordExpirySweep: command.NewPasswordExpirySweepCommand(command.PasswordExpirySweepCommandConfig{
+			Repository: s.cfg.AuthRepository,
+			Inventory:  s.inventoryRepo,
+			Rotation:   s.rotation,
+			UserScopes: s.cfg.ScopeResolver,
 			Clock:      s.cfg.Clock,
 			Activity:   s.cfg.ActivitySink,
 			Hooks:      s.cfg.Hooks,
 			Logger:     s.cfg.Logger,
 			ScopeGuard: s.scopeGuard,
 		}),
-		UserPasswordReset: userPasswordReset,
 	}
 	s.attachSecureLinkCommands(&cmds, userPasswordReset)
 	s.attachRoleCommands(&cmds)
@@ -277,14 +551,49 @@ func (s *Service) buildCommands() Commands {
 
 func (s *Service) newLifecycleCommand() *command.UserLifecycleTransitionCommand {
 	return command.NewUserLifecycleTransitionCommand(command.LifecycleCommandConfig{
+		Repository:      s.cfg.AuthRepository,
+		Policy:          s.cfg.TransitionPolicy,
+		Clock:           s.cfg.Clock,
+		Logger:          s.cfg.Logger,
+		Hooks:           s.cfg.Hooks,
+		Activity:        s.cfg.ActivitySink,
+		ScopeGuard:      s.scopeGuard,
+		Notifier:        s.notifier,
+		PasswordHistory: s.history,
+	})
+}
+
+// newLockoutTracker builds the tracker without the scope guard: locks and
+// cool-down unlocks are system actions taken during sign-in, before any
+// actor is authenticated.
+func (s *Service) newLockoutTracker() *lockout.Tracker {
+	if s.cfg.LoginAttemptStore == nil || s.cfg.AuthRepository == nil {
+		return nil
+	}
+	lifecycle := command.NewUserLifecycleTransitionCommand(command.LifecycleCommandConfig{
 		Repository: s.cfg.AuthRepository,
 		Policy:     s.cfg.TransitionPolicy,
 		Clock:      s.cfg.Clock,
 		Logger:     s.cfg.Logger,
 		Hooks:      s.cfg.Hooks,
 		Activity:   s.cfg.ActivitySink,
-		ScopeGuard: s.scopeGuard,
+		Notifier:   s.notifier,
 	})
+	tracker, err := lockout.NewTracker(lockout.Config{
+		Store:      s.cfg.LoginAttemptStore,
+		Repository: s.cfg.AuthRepository,
+		Lifecycle:  lifecycle,
+		Policy:     s.cfg.LockoutPolicy,
+		Clock:      s.cfg.Clock,
+		Activity:   s.cfg.ActivitySink,
+		Hooks:      s.cfg.Hooks,
+		Logger:     s.cfg.Logger,
+	})
+	if err != nil {
+		s.cfg.Logger.Error("go-users: lockout tracker initialization failed", err)
+		return nil
+	}
+	return tracker
 }
 
 func (s *Service) newUserCreateCommand() *command.UserCreateCommand {
@@ -306,6 +615,8 @@ func (s *Service) newPasswordResetCommand() *command.UserPasswordResetCommand {
 		Hooks:      s.cfg.Hooks,
 		Logger:     s.cfg.Logger,
 		ScopeGuard: s.scopeGuard,
+		Passwords:  s.cfg.PasswordService,
+		History:    s.history,
 	})
 }
 
@@ -323,6 +634,7 @@ func (s *Service) attachSecureLinkCommands(cmds *Commands, reset *command.UserPa
 		ScopeGuard:      s.scopeGuard,
 		FeatureGate:     s.cfg.FeatureGate,
 		Route:           s.cfg.InviteLinkRoute,
+		Notifier:        s.notifier,
 	})
 	cmds.UserRegistrationRequest = command.NewUserRegistrationRequestCommand(command.RegistrationRequestConfig{
 		Repository:      s.cfg.AuthRepository,
@@ -337,6 +649,7 @@ func (s *Service) attachSecureLinkCommands(cmds *Commands, reset *command.UserPa
 		ScopeGuard:      s.scopeGuard,
 		FeatureGate:     s.cfg.FeatureGate,
 		Route:           s.cfg.RegistrationLinkRoute,
+		Notifier:        s.notifier,
 	})
 	cmds.UserTokenValidate = command.NewUserTokenValidateCommand(command.TokenValidateConfig{
 		TokenRepository: s.cfg.UserTokenRepository,
@@ -363,6 +676,7 @@ func (s *Service) attachSecureLinkCommands(cmds *Commands, reset *command.UserPa
 		Logger:          s.cfg.Logger,
 		FeatureGate:     s.cfg.FeatureGate,
 		Route:           s.cfg.PasswordResetLinkRoute,
+		Notifier:        s.notifier,
 	})
 	cmds.UserPasswordResetConfirm = command.NewUserPasswordResetConfirmCommand(command.PasswordResetConfirmConfig{
 		ResetRepository: s.cfg.PasswordResetRepository,
@@ -372,14 +686,42 @@ func (s *Service) attachSecureLinkCommands(cmds *Commands, reset *command.UserPa
 		ScopeEnforcer:   s.cfg.TokenScopeEnforcer,
 		Logger:          s.cfg.Logger,
 	})
+	contactCfg := command.ContactVerificationConfig{
+		Repository:      s.cfg.AuthRepository,
+		Profiles:        s.cfg.ProfileRepository,
+		Changes:         s.cfg.ContactChangeRepository,
+		TokenRepository: s.cfg.UserTokenRepository,
+		SecureLinks:     s.cfg.SecureLinkManager,
+		Clock:           s.cfg.Clock,
+		IDGen:           s.cfg.IDGenerator,
+		Activity:        s.cfg.ActivitySink,
+		Hooks:           s.cfg.Hooks,
+		Logger:          s.cfg.Logger,
+		ScopeGuard:      s.scopeGuard,
+		Route:           s.cfg.ContactVerificationLinkRoute,
+		ScopeEnforcer:   s.cfg.TokenScopeEnforcer,
+		Notifier:        s.notifier,
+	}
+	cmds.ContactVerificationStart = command.NewContactVerificationStartCommand(contactCfg)
+	cmds.ContactVerificationConfirm = command.NewContactVerificationConfirmCommand(contactCfg)
 }
 
 func (s *Service) attachRoleCommands(cmds *Commands) {
 	cmds.CreateRole = command.NewCreateRoleCommand(s.cfg.RoleRegistry, s.scopeGuard)
-	cmds.UpdateRole = command.NewUpdateRoleCommand(s.cfg.RoleRegistry, s.scopeGuard)
-	cmds.DeleteRole = command.NewDeleteRoleCommand(s.cfg.RoleRegistry, s.scopeGuard)
-	cmds.AssignRole = command.NewAssignRoleCommand(s.cfg.RoleRegistry, s.scopeGuard)
-	cmds.UnassignRole = command.NewUnassignRoleCommand(s.cfg.RoleRegistry, s.scopeGuard)
+	roleOpts := []command.RoleCommandOption{
+		command.WithRoleActivity(s.cfg.ActivitySink, s.cfg.Hooks),
+		command.WithRoleClock(s.cfg.Clock),
+		command.WithRoleNotifier(s.notifier, s.cfg.Logger),
+	}
+	var deleteOpts []command.RoleCommandOption
+	if s.snapshotCache != nil {
+		roleOpts = append(roleOpts, command.WithRoleInvalidator(s.snapshotCache))
+		deleteOpts = append(deleteOpts, command.WithRoleInvalidator(s.snapshotCache))
+	}
+	cmds.UpdateRole = command.NewUpdateRoleCommand(s.cfg.RoleRegistry, s.scopeGuard, roleOpts...)
+	cmds.DeleteRole = command.NewDeleteRoleCommand(s.cfg.RoleRegistry, s.scopeGuard, deleteOpts...)
+	cmds.AssignRole = command.NewAssignRoleCommand(s.cfg.RoleRegistry, s.scopeGuard, roleOpts...)
+	cmds.UnassignRole = command.NewUnassignRoleCommand(s.cfg.RoleRegistry, s.scopeGuard, roleOpts...)
 }
 
 func (s *Service) attachActivityProfilePreferenceCommands(cmds *Commands) {
@@ -389,13 +731,40 @@ func (s *Service) attachActivityProfilePreferenceCommands(cmds *Commands) {
 		Clock:      s.cfg.Clock,
 		ScopeGuard: s.scopeGuard,
 	}
+	if s.cfg.PreferenceSchema != nil {
+		prefCfg.Validator = s.cfg.PreferenceSchema
+	}
 	cmds.LogActivity = command.NewActivityLogCommand(command.ActivityLogConfig{
 		Sink:  s.cfg.ActivitySink,
 		Hooks: s.cfg.Hooks,
 		Clock: s.cfg.Clock,
 	})
-	cmds.ProfileUpsert = command.NewProfileUpsertCommand(command.ProfileCommandConfig{
+	cmds.ActivityExport = command.NewActivityExportCommand(command.ActivityExportConfig{
+		Repository: s.activityRepo,
+		ScopeGuard: s.scopeGuard,
+		Policy:     s.cfg.ActivityAccessPolicy,
+		Activity:   s.cfg.ActivitySink,
+		Hooks:      s.cfg.Hooks,
+		Clock:      s.cfg.Clock,
+	})
+	profileCfg := command.ProfileCommandConfig{
 		Repository: s.cfg.ProfileRepository,
+		Activity:   s.cfg.ActivitySink,
+		Hooks:      s.cfg.Hooks,
+		Clock:      s.cfg.Clock,
+		ScopeGuard: s.scopeGuard,
+		Fields:     s.cfg.ProfileFieldRepository,
+	}
+	upsertCfg := profileCfg
+	upsertCfg.RequirePhoneVerification = s.cfg.RequireContactVerification
+	cmds.ProfileUpsert = command.NewProfileUpsertCommand(upsertCfg)
+	cmds.ProfileFieldUpsert = command.NewProfileFieldUpsertCommand(profileCfg)
+	cmds.ProfileFieldDelete = command.NewProfileFieldDeleteCommand(profileCfg)
+	cmds.AvatarUpload = command.NewAvatarUploadCommand(command.AvatarCommandConfig{
+		Repository: s.cfg.ProfileRepository,
+		Storage:    s.cfg.AvatarStorage,
+		Policy:     s.cfg.AvatarPolicy,
+		Activity:   s.cfg.ActivitySink,
 		Hooks:      s.cfg.Hooks,
 		Clock:      s.cfg.Clock,
 		ScopeGuard: s.scopeGuard,
@@ -404,17 +773,31 @@ func (s *Service) attachActivityProfilePreferenceCommands(cmds *Commands) {
 	cmds.PreferenceDelete = command.NewPreferenceDeleteCommand(prefCfg)
 	cmds.PreferenceUpsertMany = command.NewPreferenceUpsertManyCommand(prefCfg)
 	cmds.PreferenceDeleteMany = command.NewPreferenceDeleteManyCommand(prefCfg)
+	cmds.PreferenceRestore = command.NewPreferenceRestoreCommand(prefCfg)
+	cmds.PreferenceExport = command.NewPreferenceExportCommand(prefCfg)
+	cmds.PreferenceImport = command.NewPreferenceImportCommand(prefCfg)
+	cmds.PreferenceCopy = command.NewPreferenceCopyCommand(prefCfg)
 }
 
 func (s *Service) buildQueries() Queries {
+	var activityOpts []query.ActivityQueryOption
+	if s.cfg.ActivityAccessPolicy != nil {
+		activityOpts = append(activityOpts, query.WithActivityAccessPolicy(s.cfg.ActivityAccessPolicy))
+	}
 	return Queries{
-		UserInventory:   query.NewUserInventoryQuery(s.inventoryRepo, s.cfg.Logger, s.scopeGuard),
-		RoleList:        query.NewRoleListQuery(s.cfg.RoleRegistry, s.scopeGuard),
-		RoleDetail:      query.NewRoleDetailQuery(s.cfg.RoleRegistry, s.scopeGuard),
-		RoleAssignments: query.NewRoleAssignmentsQuery(s.cfg.RoleRegistry, s.scopeGuard),
-		ActivityFeed:    query.NewActivityFeedQuery(s.activityRepo, s.scopeGuard),
-		ActivityStats:   query.NewActivityStatsQuery(s.activityRepo, s.scopeGuard),
-		ProfileDetail:   query.NewProfileQuery(s.profileRepo, s.scopeGuard),
-		Preferences:     query.NewPreferenceQuery(s.prefResolver, s.scopeGuard),
+		UserInventory:     query.NewUserInventoryQuery(s.inventoryRepo, s.cfg.Logger, s.scopeGuard, query.WithInventoryProfileFields(s.cfg.ProfileFieldRepository), query.WithInventoryAccessPolicy(s.cfg.ProfileAccessPolicy)),
+		RoleList:          query.NewRoleListQuery(s.cfg.RoleRegistry, s.scopeGuard),
+		RoleDetail:        query.NewRoleDetailQuery(s.cfg.RoleRegistry, s.scopeGuard),
+		RoleAssignments:   query.NewRoleAssignmentsQuery(s.cfg.RoleRegistry, s.scopeGuard),
+		ActivityFeed:      query.NewActivityFeedQuery(s.activityRepo, s.scopeGuard, activityOpts...),
+		ActivityStats:     query.NewActivityStatsQuery(s.activityRepo, s.scopeGuard, activityOpts...),
+		ActivityTimeline:  query.NewActivityTimelineQuery(s.activityRepo, s.scopeGuard, activityOpts...),
+		ProfileDetail:     query.NewProfileQuery(s.profileRepo, s.scopeGuard, query.WithProfileAccessPolicy(s.cfg.ProfileAccessPolicy)),
+		PublicProfile:     query.NewPublicProfileQuery(s.profileRepo, s.scopeGuard, query.WithProfileAccessPolicy(s.cfg.ProfileAccessPolicy)),
+		ProfileFields:     query.NewProfileFieldsQuery(s.cfg.ProfileFieldRepository, s.scopeGuard),
+		ProfileHistory:    query.NewProfileHistoryQuery(s.profileRepo, s.scopeGuard, query.WithProfileAccessPolicy(s.cfg.ProfileAccessPolicy)),
+		Preferences:       query.NewPreferenceQuery(s.prefResolver, s.scopeGuard),
+		PreferenceHistory: query.NewPreferenceHistoryQuery(s.preferenceRepo, s.scopeGuard),
+		PasswordRotation:  query.NewPasswordRotationQuery(s.cfg.AuthRepository, s.rotation, s.scopeGuard),
 	}
 }